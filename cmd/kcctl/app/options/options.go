@@ -90,10 +90,19 @@ var (
 )
 
 const (
-	ResourceNode    = "node"
-	ResourceCluster = "cluster"
-	ResourceUser    = "user"
-	ResourceRole    = "role"
+	ResourceNode        = "node"
+	ResourceCluster     = "cluster"
+	ResourceUser        = "user"
+	ResourceRole        = "role"
+	ResourceOperation   = "operation"
+	ResourceBackup      = "backup"
+	ResourceBackupPoint = "backuppoint"
+	ResourceCronBackup  = "cronbackup"
+	ResourceRegion      = "region"
+	ResourceDomain      = "domain"
+	ResourceRecord      = "record"
+	ResourceTemplate    = "template"
+	ResourceToken       = "token"
 )
 
 type IOStreams struct {
@@ -21,6 +21,9 @@ package app
 import (
 	"io"
 
+	"github.com/kubeclipper/kubeclipper/pkg/cli/apply"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/backup"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/cluster"
 	"github.com/kubeclipper/kubeclipper/pkg/cli/completion"
 
 	"github.com/kubeclipper/kubeclipper/pkg/cli/logger"
@@ -44,6 +47,10 @@ import (
 
 	"github.com/kubeclipper/kubeclipper/pkg/cli/login"
 
+	"github.com/kubeclipper/kubeclipper/pkg/cli/logs"
+
+	"github.com/kubeclipper/kubeclipper/pkg/cli/operation"
+
 	"github.com/spf13/cobra"
 
 	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
@@ -88,6 +95,12 @@ func NewKubeClipperCommand(in io.Reader, out, err io.Writer) *cobra.Command {
 	cmds.AddCommand(drain.NewCmdDrain(ioStreams))
 	cmds.AddCommand(registry.NewCmdRegistry(ioStreams))
 	cmds.AddCommand(resource.NewCmdResource(ioStreams))
+	cmds.AddCommand(operation.NewCmdOperation(ioStreams))
+	cmds.AddCommand(logs.NewCmdLogs(ioStreams))
+	cmds.AddCommand(cluster.NewCmdCluster(ioStreams))
+	cmds.AddCommand(backup.NewCmdBackup(ioStreams))
+	cmds.AddCommand(apply.NewCmdApply(ioStreams))
+	cmds.AddCommand(apply.NewCmdDiff(ioStreams))
 	cmds.AddCommand(completion.NewCmdCompletion(ioStreams.Out))
 
 	return cmds
@@ -25,6 +25,7 @@ import (
 	"github.com/kubeclipper/kubeclipper/cmd/kubeclipper-agent/app"
 	_ "github.com/kubeclipper/kubeclipper/pkg/component/nfs"
 	_ "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1/cri"
+	_ "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1/k3s"
 	_ "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1/k8s"
 )
 
@@ -54,6 +54,8 @@ func (s *ServerOptions) Flags() (fss cliflag.NamedFlagSets) {
 	s.MQOptions.AddFlags(fss.FlagSet("mq"))
 	s.LogOptions.AddFlags(fss.FlagSet("log"))
 	s.AuthenticationOptions.AddFlags(fss.FlagSet("authentication"))
+	s.DeliveryOptions.AddFlags(fss.FlagSet("delivery"))
+	s.AuditOptions.AddFlags(fss.FlagSet("audit"))
 	return fss
 }
 
@@ -64,6 +66,8 @@ func (s *ServerOptions) Validate() []error {
 	errors = append(errors, s.MQOptions.Validate()...)
 	errors = append(errors, s.LogOptions.Validate()...)
 	errors = append(errors, s.AuthenticationOptions.Validate()...)
+	errors = append(errors, s.DeliveryOptions.Validate()...)
+	errors = append(errors, s.AuditOptions.Validate()...)
 	return errors
 }
 
@@ -25,9 +25,12 @@ import (
 	"time"
 
 	"github.com/kubeclipper/kubeclipper/cmd/kubeclipper-server/app"
+	_ "github.com/kubeclipper/kubeclipper/pkg/authentication/identityprovider/ldap"
 	_ "github.com/kubeclipper/kubeclipper/pkg/authentication/identityprovider/oidc"
+	_ "github.com/kubeclipper/kubeclipper/pkg/authentication/mfa/totp"
 	_ "github.com/kubeclipper/kubeclipper/pkg/component/nfs"
 	_ "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1/cri"
+	_ "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1/k3s"
 	_ "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1/k8s"
 )
 
@@ -19,16 +19,25 @@
 package agent
 
 import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	"go.uber.org/zap"
+
 	"github.com/kubeclipper/kubeclipper/pkg/agent/config"
 	"github.com/kubeclipper/kubeclipper/pkg/logger"
 	"github.com/kubeclipper/kubeclipper/pkg/oplog"
 	"github.com/kubeclipper/kubeclipper/pkg/service"
 	"github.com/kubeclipper/kubeclipper/pkg/service/task"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/metrics"
 )
 
 type Server struct {
-	taskService service.Interface
-	Config      *config.Config
+	taskService   service.Interface
+	metricsServer *http.Server
+	Config        *config.Config
 }
 
 func (s *Server) PrepareRun(stopCh <-chan struct{}) error {
@@ -42,6 +51,11 @@ func (s *Server) PrepareRun(stopCh <-chan struct{}) error {
 		task.WithOplog(opLog),
 		task.WithRepoMirror(s.Config.ImageProxyOptions.KcImageRepoMirror),
 	)
+	if s.Config.MetricsAddress != "" {
+		container := restful.NewContainer()
+		metrics.Defaults.Install(container)
+		s.metricsServer = &http.Server{Addr: s.Config.MetricsAddress, Handler: container}
+	}
 	return s.taskService.PrepareRun(stopCh)
 }
 
@@ -49,8 +63,21 @@ func (s *Server) Run(stopCh <-chan struct{}) error {
 	if err := s.taskService.Run(stopCh); err != nil {
 		return err
 	}
+	if s.metricsServer != nil {
+		go func() {
+			logger.Info("start metrics server", zap.String("addr", s.metricsServer.Addr))
+			if err := s.metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Error("metrics server exited", zap.Error(err))
+			}
+		}()
+	}
 	<-stopCh
 	logger.Debugf("get stopCh signal, exit...")
+	if s.metricsServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = s.metricsServer.Shutdown(ctx)
+	}
 	s.taskService.Close()
 	return nil
 }
@@ -44,6 +44,9 @@ const (
 
 	// DefaultConfigurationPath the default location of the configuration file
 	defaultConfigurationPath = "/etc/kubeclipper-agent"
+
+	// defaultMetricsAddress is the address the agent serves /metrics on, empty metricsAddress disables it.
+	defaultMetricsAddress = "0.0.0.0:9891"
 )
 
 // Config defines everything needed for apiserver to deal with external services
@@ -53,6 +56,7 @@ type Config struct {
 	IPDetect                  string              `json:"ipDetect,omitempty" yaml:"ipDetect"`
 	RegisterNode              bool                `json:"registerNode,omitempty" yaml:"registerNode"`
 	NodeStatusUpdateFrequency time.Duration       `json:"nodeStatusUpdateFrequency,omitempty" yaml:"nodeStatusUpdateFrequency"`
+	MetricsAddress            string              `json:"metricsAddress,omitempty" yaml:"metricsAddress"`
 	DownloaderOptions         *downloader.Options `json:"downloader" yaml:"downloader" mapstructure:"downloader"`
 	LogOptions                *logger.Options     `json:"log,omitempty" yaml:"log,omitempty" mapstructure:"log"`
 	MQOptions                 *natsio.NatsOptions `json:"mq,omitempty" yaml:"mq,omitempty"  mapstructure:"mq"`
@@ -75,6 +79,7 @@ func New() *Config {
 	return &Config{
 		RegisterNode:              true,
 		NodeStatusUpdateFrequency: 5 * time.Minute,
+		MetricsAddress:            defaultMetricsAddress,
 		LogOptions:                logger.NewLogOptions(),
 		MQOptions:                 natsio.NewOptions(),
 		DownloaderOptions:         downloader.NewOptions(),
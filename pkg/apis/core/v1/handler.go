@@ -26,6 +26,7 @@ import (
 	"math/rand"
 	"net/http"
 	"path/filepath"
+	"reflect"
 	"strconv"
 	"strings"
 	"time"
@@ -44,6 +45,7 @@ import (
 
 	"github.com/kubeclipper/kubeclipper/pkg/controller"
 	"github.com/kubeclipper/kubeclipper/pkg/oplog"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/wssstream"
 
 	"github.com/kubeclipper/kubeclipper/pkg/utils/certs"
 
@@ -70,6 +72,7 @@ import (
 
 	apimachineryErrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	"github.com/emicklei/go-restful"
 	"go.uber.org/zap"
@@ -181,6 +184,40 @@ func (h *handler) AddOrRemoveNodes(request *restful.Request, response *restful.R
 		return
 	}
 
+	op, err := h.makeNodesOperation(ctx, c, pn)
+	if err != nil {
+		if errors.Is(err, ErrZeroNode) {
+			// No node needs to be operated.
+			_ = response.WriteHeaderAndEntity(http.StatusOK, c)
+			return
+		}
+		handleOperationError(response, request, err)
+		return
+	}
+
+	op.Labels[common.LabelTimeoutSeconds] = timeoutSecs
+	op.Status.Status = v1.OperationStatusRunning
+	if !dryRun {
+		c.Status.Phase = v1.ClusterUpdating
+		if c, err = h.clusterOperator.UpdateCluster(ctx, c); err != nil {
+			restplus.HandleInternalError(response, request, err)
+			return
+		}
+		if op, err = h.opOperator.CreateOperation(ctx, op); err != nil {
+			restplus.HandleInternalError(response, request, err)
+			return
+		}
+	}
+
+	// distribute tasks
+	go h.doOperation(context.TODO(), op, &service.Options{DryRun: dryRun})
+
+	_ = response.WriteHeaderAndEntity(http.StatusOK, c)
+}
+
+// makeNodesOperation builds the operation which adds or removes the nodes of the cluster, the nodes
+// are added to or removed from the workers of the cluster.
+func (h *handler) makeNodesOperation(ctx context.Context, c *v1.Cluster, pn *PatchNodes) (*v1.Operation, error) {
 	// backing up old masters and workers
 	nodeSet := c.GetAllNodes()
 
@@ -188,20 +225,16 @@ func (h *handler) AddOrRemoveNodes(request *restful.Request, response *restful.R
 	extraMeta, err := h.getClusterMetadata(ctx, c)
 	if err != nil {
 		if apimachineryErrors.IsNotFound(err) || err == ErrNodesRegionDifferent {
-			restplus.HandleBadRequest(response, request, err)
-			return
+			return nil, badRequestError{err}
 		}
-		restplus.HandleInternalError(response, request, err)
-		return
+		return nil, err
 	}
 
 	if err := pn.MakeCompare(c); err != nil {
 		if errors.Is(err, ErrInvalidNodesOperation) || errors.Is(err, ErrInvalidNodesRole) {
-			restplus.HandleBadRequest(response, request, err)
-			return
+			return nil, badRequestError{err}
 		}
-		restplus.HandleInternalError(response, request, err)
-		return
+		return nil, err
 	}
 
 	// Get workers node information must be called before pn.MakeCompare, in order to ensure pn.Nodes = extraMeta.Workers.
@@ -209,11 +242,9 @@ func (h *handler) AddOrRemoveNodes(request *restful.Request, response *restful.R
 	nodes, err := h.getNodeInfo(ctx, pn.Nodes)
 	if err != nil {
 		if err == ErrNodesRegionDifferent {
-			restplus.HandleBadRequest(response, request, err)
-			return
+			return nil, badRequestError{err}
 		}
-		restplus.HandleInternalError(response, request, err)
-		return
+		return nil, err
 	}
 
 	if pn.Role == common.NodeRoleWorker {
@@ -221,65 +252,54 @@ func (h *handler) AddOrRemoveNodes(request *restful.Request, response *restful.R
 	}
 
 	if len(nodes) == 0 {
-		restplus.HandleBadRequest(response, request, fmt.Errorf("nodes is already in use"))
-		return
+		return nil, badRequestError{fmt.Errorf("nodes is already in use")}
 	}
 
 	for _, n := range nodes {
 		switch pn.Operation {
 		case NodesOperationAdd:
 			if n.Disable {
-				restplus.HandleBadRequest(response, request, fmt.Errorf("this node(%s) is disabled", n.IPv4))
-				return
+				return nil, badRequestError{fmt.Errorf("this node(%s) is disabled", n.IPv4)}
 			}
 			if nodeSet.Has(n.ID) {
-				restplus.HandleBadRequest(response, request, fmt.Errorf("this node(%s) is already in use", n.IPv4))
-				return
+				return nil, badRequestError{fmt.Errorf("this node(%s) is already in use", n.IPv4)}
 			}
 			if n.Region != extraMeta.Masters[0].Region {
-				restplus.HandleBadRequest(response, request, fmt.Errorf("the node(%s) belongs to different region", n.IPv4))
-				return
+				return nil, badRequestError{fmt.Errorf("the node(%s) belongs to different region", n.IPv4)}
 			}
 		case NodesOperationRemove:
 			if !nodeSet.Has(n.ID) {
-				restplus.HandleBadRequest(response, request, fmt.Errorf("the node(%s) is not part of this cluster and cannot be removed", n.IPv4))
-				return
+				return nil, badRequestError{fmt.Errorf("the node(%s) is not part of this cluster and cannot be removed", n.IPv4)}
 			}
 		}
 	}
 
 	op, err := pn.MakeOperation(*extraMeta, c)
 	if err != nil {
-		if errors.Is(err, ErrZeroNode) {
-			// No node needs to be operated.
-			_ = response.WriteHeaderAndEntity(http.StatusOK, c)
-			return
-		} else if errors.Is(err, ErrInvalidNodesOperation) || errors.Is(err, ErrInvalidNodesRole) {
-			restplus.HandleBadRequest(response, request, err)
-			return
+		if errors.Is(err, ErrInvalidNodesOperation) || errors.Is(err, ErrInvalidNodesRole) {
+			return nil, badRequestError{err}
 		}
-		restplus.HandleInternalError(response, request, err)
-		return
+		return nil, err
 	}
+	return op, nil
+}
 
-	op.Labels[common.LabelTimeoutSeconds] = timeoutSecs
-	op.Status.Status = v1.OperationStatusRunning
-	if !dryRun {
-		c.Status.Phase = v1.ClusterUpdating
-		if c, err = h.clusterOperator.UpdateCluster(ctx, c); err != nil {
-			restplus.HandleInternalError(response, request, err)
-			return
-		}
-		if op, err = h.opOperator.CreateOperation(ctx, op); err != nil {
-			restplus.HandleInternalError(response, request, err)
-			return
-		}
-	}
+// badRequestError is an error of the request, it is reported as a bad request.
+type badRequestError struct {
+	error
+}
 
-	// distribute tasks
-	go h.doOperation(context.TODO(), op, &service.Options{DryRun: dryRun})
+func (e badRequestError) Unwrap() error {
+	return e.error
+}
 
-	_ = response.WriteHeaderAndEntity(http.StatusOK, c)
+// handleOperationError reports an error of building an operation.
+func handleOperationError(response *restful.Response, request *restful.Request, err error) {
+	if errors.As(err, &badRequestError{}) {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	restplus.HandleInternalError(response, request, err)
 }
 
 func (h *handler) watchCluster(req *restful.Request, resp *restful.Response, q *query.Query) {
@@ -340,7 +360,7 @@ func (h *handler) DeleteCluster(request *restful.Request, response *restful.Resp
 		return
 	}
 	extraMeta.OperationType = v1.OperationDeleteCluster
-	op, err := h.parseOperationFromCluster(extraMeta, c, v1.ActionUninstall)
+	op, err := h.parseOperationFromCluster(context.TODO(), extraMeta, c, v1.ActionUninstall)
 	if err != nil {
 		restplus.HandleInternalError(response, request, err)
 		return
@@ -405,6 +425,21 @@ func (h *handler) CreateClusters(request *restful.Request, response *restful.Res
 		return
 	}
 
+	// the kubernetes version of the restored cluster is defaulted to the version of the backup
+	ctx := context.TODO()
+	if c.RestoreFrom != nil {
+		restore, err := h.getClusterRestore(request.Request.Context(), &c, extraMeta)
+		if err != nil {
+			if apimachineryErrors.IsNotFound(err) || apimachineryErrors.IsBadRequest(err) {
+				restplus.HandleBadRequest(response, request, err)
+				return
+			}
+			restplus.HandleInternalError(response, request, err)
+			return
+		}
+		ctx = k8s.WithClusterRestore(ctx, restore)
+	}
+
 	if err := h.createClusterCheck(request.Request.Context(), &c); err != nil {
 		restplus.HandleBadRequest(response, request, err)
 		return
@@ -422,7 +457,7 @@ func (h *handler) CreateClusters(request *restful.Request, response *restful.Res
 	c.Complete(cniVersion)
 
 	extraMeta.OperationType = v1.OperationCreateCluster
-	op, err := h.parseOperationFromCluster(extraMeta, &c, v1.ActionInstall)
+	op, err := h.parseOperationFromCluster(ctx, extraMeta, &c, v1.ActionInstall)
 	if err != nil {
 		restplus.HandleInternalError(response, request, err)
 		return
@@ -483,6 +518,21 @@ func (h *handler) UpdateClusters(request *restful.Request, response *restful.Res
 
 		clu.Labels = c.Labels
 		clu.Annotations = c.Annotations
+		clu.Etcd.Maintenance = c.Etcd.Maintenance
+		if err = validateEtcdMaintenance(clu); err != nil {
+			restplus.HandleBadRequest(response, request, err)
+			return
+		}
+		clu.CertRotationPolicy = c.CertRotationPolicy
+		if err = validateCertRotationPolicy(clu); err != nil {
+			restplus.HandleBadRequest(response, request, err)
+			return
+		}
+		clu.Networking.UpstreamDNS = c.Networking.UpstreamDNS
+		if err = validateUpstreamDNS(clu); err != nil {
+			restplus.HandleBadRequest(response, request, err)
+			return
+		}
 		_, err = h.clusterOperator.UpdateCluster(context.TODO(), clu)
 		if err != nil {
 			restplus.HandleInternalError(response, request, err)
@@ -539,6 +589,64 @@ func (h *handler) UpdateClusterCertification(request *restful.Request, response
 	_ = response.WriteHeaderAndEntity(http.StatusOK, c)
 }
 
+func (h *handler) EtcdMaintenance(request *restful.Request, response *restful.Response) {
+	cluName := request.PathParameter(query.ParameterName)
+	ctx := request.Request.Context()
+	dryRun := query.GetBoolValueWithDefault(request, query.ParamDryRun, false)
+	c, err := h.clusterOperator.GetCluster(ctx, cluName)
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	if c.Provider.Name != "" && c.Provider.Name != v1.ClusterKubeadm {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("etcd maintenance is not supported by %s cluster", c.Provider.Name))
+		return
+	}
+	if c.Status.Phase != v1.ClusterRunning {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("cluster is %s, etcd maintenance is not allowed", c.Status.Phase))
+		return
+	}
+
+	extraMeta, err := h.getClusterMetadata(ctx, c)
+	if err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	op, err := h.parseEtcdMaintenanceOperation(extraMeta)
+	if err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	op.Name = uuid.New().String()
+	op.Labels = map[string]string{
+		common.LabelClusterName:     c.Name,
+		common.LabelTimeoutSeconds:  v1.DefaultOperationTimeoutSecs,
+		common.LabelOperationAction: v1.OperationEtcdMaintenance,
+		common.LabelTopologyRegion:  c.Masters[0].Labels[common.LabelTopologyRegion],
+	}
+	op.Status.Status = v1.OperationStatusRunning
+	c.Status.Phase = v1.ClusterUpdating
+	if !dryRun {
+		op, err = h.opOperator.CreateOperation(ctx, op)
+		if err != nil {
+			restplus.HandleInternalError(response, request, err)
+			return
+		}
+		_, err = h.clusterOperator.UpdateCluster(ctx, c)
+		if err != nil {
+			restplus.HandleInternalError(response, request, err)
+			return
+		}
+	}
+
+	go h.doOperation(context.TODO(), op, &service.Options{DryRun: dryRun})
+	_ = response.WriteHeaderAndEntity(http.StatusOK, c)
+}
+
 func (h *handler) GetKubeConfig(request *restful.Request, response *restful.Response) {
 	name := request.PathParameter(query.ParameterName)
 	ctx := request.Request.Context()
@@ -750,6 +858,29 @@ func (h *handler) DescribeOperation(request *restful.Request, response *restful.
 	_ = response.WriteHeaderAndEntity(http.StatusOK, c)
 }
 
+func (h *handler) CancelOperation(request *restful.Request, response *restful.Response) {
+	name := request.PathParameter(query.ParameterName)
+	op, err := h.opOperator.GetOperationEx(request.Request.Context(), name, "0")
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	if op.Status.Status != v1.OperationStatusRunning {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("operation %s is %s, only the running operation can be cancelled", name, op.Status.Status))
+		return
+	}
+	op, err = h.delivery.CancelOperation(request.Request.Context(), name)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusOK, op)
+}
+
 func (h *handler) ListOperations(request *restful.Request, response *restful.Response) {
 	q := query.ParseQueryParameter(request)
 	if q.Watch {
@@ -925,6 +1056,154 @@ func (h *handler) GetOperationLog(request *restful.Request, response *restful.Re
 	})
 }
 
+// StreamOperationLog streams the step logs appended on all nodes while the operation is running.
+// The log chunks are sent as websocket text messages, or as newline-delimited JSON in a chunked response.
+func (h *handler) StreamOperationLog(request *restful.Request, response *restful.Response) {
+	name := request.PathParameter(query.ParameterName)
+	op, err := h.opOperator.GetOperationEx(request.Request.Context(), name, "0")
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	var stepKey string
+	if stepID := request.QueryParameter(query.ParameterStep); stepID != "" {
+		step, ok := op.GetStep(stepID)
+		if !ok {
+			restplus.HandleBadRequest(response, request, errors.New("step ID is invalid"))
+			return
+		}
+		stepKey = fmt.Sprintf("%s-%s", stepID, step.Name)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	chunks := make(chan oplog.LogStreamChunk, 64)
+	if op.Status.Status == v1.OperationStatusRunning {
+		err = h.delivery.SubscribeOperationLog(ctx, name, func(chunk oplog.LogStreamChunk) {
+			if stepKey != "" && chunk.StepID != stepKey {
+				return
+			}
+			select {
+			case chunks <- chunk:
+			case <-ctx.Done():
+			}
+		})
+		if err != nil {
+			restplus.HandleInternalError(response, request, err)
+			return
+		}
+		go h.cancelWhenOperationDone(ctx, cancel, name)
+	} else {
+		// nothing will be appended to the logs
+		cancel()
+	}
+
+	if wssstream.IsWebSocketRequest(request.Request) {
+		serveLogStreamWebsocket(ctx, cancel, request, response, chunks)
+		return
+	}
+	flusher, ok := response.ResponseWriter.(http.Flusher)
+	if !ok {
+		restplus.HandleInternalError(response, request, fmt.Errorf("unable to stream logs - can't get http.Flusher: %#v", response.ResponseWriter))
+		return
+	}
+	response.Header().Set("Content-Type", restful.MIME_JSON)
+	response.Header().Set("Transfer-Encoding", "chunked")
+	response.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	done := request.Request.Context().Done()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+			return
+		case chunk := <-chunks:
+			data, err := json.Marshal(chunk)
+			if err != nil {
+				logger.Error("marshal step log chunk error", zap.Error(err))
+				continue
+			}
+			if _, err = response.Write(append(data, '\n')); err != nil {
+				return
+			}
+			if len(chunks) == 0 {
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+// cancelWhenOperationDone cancels the log stream after the operation is no longer running.
+func (h *handler) cancelWhenOperationDone(ctx context.Context, cancel context.CancelFunc, name string) {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			op, err := h.opOperator.GetOperationEx(ctx, name, "0")
+			if err != nil && !apimachineryErrors.IsNotFound(err) {
+				continue
+			}
+			if err == nil && op.Status.Status == v1.OperationStatusRunning {
+				continue
+			}
+			// wait for the last logs published by the agents
+			select {
+			case <-ctx.Done():
+			case <-time.After(2 * time.Second):
+			}
+			cancel()
+			return
+		}
+	}
+}
+
+func serveLogStreamWebsocket(ctx context.Context, cancel context.CancelFunc, request *restful.Request, response *restful.Response, chunks <-chan oplog.LogStreamChunk) {
+	wsConn, err := upGrader.Upgrade(response.ResponseWriter, request.Request, nil)
+	if err != nil {
+		logger.Error("upgrade http request failed", zap.Error(err))
+		return
+	}
+	defer wsConn.Close()
+	go func() {
+		// the read fails when the peer closes the connection
+		for {
+			if _, _, err := wsConn.ReadMessage(); err != nil {
+				cancel()
+				return
+			}
+		}
+	}()
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			message := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+			if err := wsConn.WriteControl(websocket.CloseMessage, message, time.Now().Add(time.Second)); err != nil {
+				logger.Debug("close websocket error", zap.Error(err))
+			}
+			return
+		case <-ticker.C:
+			if err := wsConn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case chunk := <-chunks:
+			if err := wsConn.WriteJSON(chunk); err != nil {
+				logger.Debug("write step log chunk error", zap.Error(err))
+				return
+			}
+		}
+	}
+}
+
 func (h *handler) ListRegions(request *restful.Request, response *restful.Response) {
 	q := query.ParseQueryParameter(request)
 	if q.Watch {
@@ -996,6 +1275,22 @@ func (h *handler) createClusterCheck(ctx context.Context, c *v1.Cluster) error {
 	if len(c.Masters) == 0 {
 		return fmt.Errorf("cluster must have one master node")
 	}
+	provider, err := getClusterProvider(c)
+	if err != nil {
+		return err
+	}
+	if err = provider.Validate(c); err != nil {
+		return err
+	}
+	if err = validateEtcdMaintenance(c); err != nil {
+		return err
+	}
+	if err = validateCertRotationPolicy(c); err != nil {
+		return err
+	}
+	if err = validateUpstreamDNS(c); err != nil {
+		return err
+	}
 
 	cluInfo, err := h.clusterOperator.GetClusterEx(ctx, c.Name, "0")
 	if err != nil && !apimachineryErrors.IsNotFound(err) {
@@ -1319,6 +1614,94 @@ func (h *handler) DeleteBackup(request *restful.Request, response *restful.Respo
 	response.WriteHeader(http.StatusOK)
 }
 
+func (h *handler) VerifyBackup(request *restful.Request, response *restful.Response) {
+	// cluster name in path
+	clusterName := request.PathParameter("cluster")
+	backupName := request.PathParameter("backup")
+	ctx := request.Request.Context()
+	dryRun := query.GetBoolValueWithDefault(request, query.ParamDryRun, false)
+	c, err := h.clusterOperator.GetClusterEx(ctx, clusterName, "0")
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	b, err := h.clusterOperator.GetBackupEx(ctx, clusterName, backupName)
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	if b.Status.ClusterBackupStatus != v1.ClusterBackupAvailable {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("backup is %s now, can't verify", b.Status.ClusterBackupStatus))
+		return
+	}
+	if o, err := h.opOperator.GetOperation(ctx, b.Labels[common.LabelVerifyOperationName]); err == nil &&
+		o.Status.Status == v1.OperationStatusRunning {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("backup is being verified by operation %s", o.Name))
+		return
+	}
+	bp, err := h.clusterOperator.GetBackupPointEx(ctx, b.BackupPointName, "0")
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	// the snapshot is verified on the preferred node, which has the etcd tools
+	nodeName := b.PreferredNode
+	if nodeName == "" {
+		nodeName = c.Masters[0].ID
+	}
+	node, err := h.clusterOperator.GetNodeEx(ctx, nodeName, "0")
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+
+	op := &v1.Operation{}
+	op.Name = uuid.New().String()
+	op.Labels = make(map[string]string)
+	op.Labels[common.LabelOperationAction] = v1.OperationVerifyBackup
+	op.Labels[common.LabelTimeoutSeconds] = strconv.Itoa(v1.DefaultBackupTimeoutSec)
+	op.Labels[common.LabelClusterName] = c.Name
+	op.Labels[common.LabelBackupName] = b.Name
+	op.Labels[common.LabelTopologyRegion] = node.Labels[common.LabelTopologyRegion]
+	op.Status.Status = v1.OperationStatusRunning
+	verify, err := k8s.NewVerifyBackup(bp, b)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	op.Steps, err = verify.InstallSteps(v1.StepNode{
+		ID:       node.Name,
+		IPv4:     node.Status.Ipv4DefaultIP,
+		Hostname: node.Status.NodeInfo.Hostname,
+	}, false)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+
+	if !dryRun {
+		if op, err = h.opOperator.CreateOperation(ctx, op); err != nil {
+			restplus.HandleInternalError(response, request, err)
+			return
+		}
+		b.Labels[common.LabelVerifyOperationName] = op.Name
+		if b, err = h.clusterOperator.UpdateBackup(ctx, b); err != nil {
+			restplus.HandleInternalError(response, request, err)
+			return
+		}
+		go h.doOperation(context.TODO(), op, &service.Options{DryRun: dryRun})
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusOK, b)
+}
+
 func (h *handler) UpdateBackup(request *restful.Request, response *restful.Response) {
 	b := &v1.Backup{}
 	if err := request.ReadEntity(b); err != nil {
@@ -1371,15 +1754,14 @@ func (h *handler) RetryCluster(request *restful.Request, response *restful.Respo
 		return
 	}
 
-	// backup/recovery/upgrade does not support retries
-	switch op.Labels[common.LabelOperationAction] {
-	case v1.OperationBackupCluster, v1.OperationRecoverCluster, v1.OperationUpgradeCluster:
-		restplus.HandleBadRequest(response, request, fmt.Errorf("backup/recovery/upgrade operation-action does not support retries"))
-		return
-	case "":
+	if op.Labels[common.LabelOperationAction] == "" {
 		restplus.HandleBadRequest(response, request, fmt.Errorf("operation %s action is empty", name))
 		return
 	}
+	if !operation.SupportRetry(op) {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("backup/recovery/upgrade operation-action does not support retries"))
+		return
+	}
 
 	// only the last retry is supported
 	q := query.New()
@@ -1403,62 +1785,20 @@ func (h *handler) RetryCluster(request *restful.Request, response *restful.Respo
 		return
 	}
 
-	// error step index
-	failedIndex := len(op.Status.Conditions) - 1
-	ctx := component.WithRetry(context.TODO(), true)
-
-	// if there is an uninstall error, continue directly from the current step
-	var continueSteps []v1.Step
-	if op.Steps[0].Action == v1.ActionInstall {
-		findStepNode := func(nodes []v1.StepNode, nodeID string) v1.StepNode {
-			for _, v := range nodes {
-				if v.ID == nodeID {
-					return v
-				}
-			}
-			return v1.StepNode{}
-		}
-		var failedNodes []v1.StepNode
-		successStatus := make([]v1.StepStatus, 0)
-		for _, status := range op.Status.Conditions[failedIndex].Status {
-			if status.Status == v1.StepStatusFailed {
-				// select the nodes whose execution fails
-				if node := findStepNode(op.Steps[0].Nodes, status.Node); node.ID != "" {
-					failedNodes = append(failedNodes, node)
-				}
-				continue
-			}
-			successStatus = append(successStatus, status)
-		}
-		// the step to continue
-		continueSteps = op.Steps[failedIndex:]
-
-		// the node that failed to execute the task
-		continueSteps[0].Nodes = failedNodes
-
-		if len(successStatus) != 0 {
-			// failed status
-			failedStepStatus := op.Status.Conditions[failedIndex]
-			// retain successful status
-			failedStepStatus.Status = successStatus
-			// Remove the failed status and keep the successful status
-			op.Status.Conditions = op.Status.Conditions[0:failedIndex]
-			op.Status.Conditions = append(op.Status.Conditions, failedStepStatus)
-		} else {
-			op.Status.Conditions = op.Status.Conditions[0:failedIndex]
-		}
-		if failedIndex > 0 && op.Status.Conditions[failedIndex-1].Status[0].Response != nil {
-			ctx = component.WithExtraData(ctx, op.Status.Conditions[failedIndex-1].Status[0].Response)
-		}
+	continueSteps, replies, err := operation.ParseRetrySteps(op, request.QueryParameter(query.ParameterFromStep))
+	if err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
 	}
-
-	// if there is an uninstall error, start from the beginning
-	if op.Steps[0].Action == v1.ActionUninstall {
-		continueSteps = op.Steps
-		op.Status.Conditions = make([]v1.OperationCondition, 0)
+	ctx := component.WithRetry(context.TODO(), true)
+	if len(replies) > 0 {
+		ctx = component.WithStepReplies(ctx, replies)
 	}
 
 	op.Status.Status = v1.OperationStatusRunning
+	op.Status.Reason, op.Status.Message = "", ""
+	// the delivery service running the retry records itself as the new runner.
+	delete(op.Annotations, common.AnnotationOperationRunner)
 
 	if !dryRun {
 		_, err = h.opOperator.UpdateOperation(context.TODO(), op)
@@ -1629,33 +1969,9 @@ func (h *handler) InstallOrUninstallPlugins(request *restful.Request, response *
 		restplus.HandleInternalError(response, request, err)
 		return
 	}
-	// We need IP addresses of all master nodes later.
-	extraMeta, err := h.getClusterMetadata(ctx, clu)
+	op, err := h.makeComponentsOperation(ctx, clu, pcs)
 	if err != nil {
-		if apimachineryErrors.IsNotFound(err) || err == ErrNodesRegionDifferent {
-			restplus.HandleBadRequest(response, request, err)
-			return
-		}
-		restplus.HandleInternalError(response, request, err)
-		return
-	}
-	if err = pcs.checkComponents(clu); err != nil {
-		restplus.HandleBadRequest(response, request, err)
-		return
-	}
-	action, operationAction := v1.ActionInstall, v1.OperationInstallComponents
-	if pcs.Uninstall {
-		action = v1.ActionUninstall
-		operationAction = v1.OperationUninstallComponents
-	}
-	op, err := h.parseOperationFromComponent(extraMeta, pcs.Addons, clu, action)
-	if err != nil {
-		restplus.HandleInternalError(response, request, err)
-		return
-	}
-	// The current component does not support uninstallation, so the steps will be empty
-	if len(op.Steps) == 0 {
-		restplus.HandleBadRequest(response, request, errors.New("the current operation steps is empty and cannot be performed"))
+		handleOperationError(response, request, err)
 		return
 	}
 	if !dryRun {
@@ -1667,7 +1983,6 @@ func (h *handler) InstallOrUninstallPlugins(request *restful.Request, response *
 		}
 	}
 	op.Labels[common.LabelTimeoutSeconds] = timeoutSecs
-	op.Labels[common.LabelOperationAction] = operationAction
 	op.Status.Status = v1.OperationStatusRunning
 	if !dryRun {
 		op, err = h.opOperator.CreateOperation(context.TODO(), op)
@@ -1704,6 +2019,36 @@ func (h *handler) InstallOrUninstallPlugins(request *restful.Request, response *
 	_ = response.WriteHeaderAndEntity(http.StatusOK, clu)
 }
 
+// makeComponentsOperation builds the operation which installs or uninstalls the addons of the cluster.
+func (h *handler) makeComponentsOperation(ctx context.Context, clu *v1.Cluster, pcs *PatchComponents) (*v1.Operation, error) {
+	// We need IP addresses of all master nodes later.
+	extraMeta, err := h.getClusterMetadata(ctx, clu)
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) || err == ErrNodesRegionDifferent {
+			return nil, badRequestError{err}
+		}
+		return nil, err
+	}
+	if err = pcs.checkComponents(clu); err != nil {
+		return nil, badRequestError{err}
+	}
+	action, operationAction := v1.ActionInstall, v1.OperationInstallComponents
+	if pcs.Uninstall {
+		action = v1.ActionUninstall
+		operationAction = v1.OperationUninstallComponents
+	}
+	op, err := h.parseOperationFromComponent(extraMeta, pcs.Addons, clu, action)
+	if err != nil {
+		return nil, err
+	}
+	// The current component does not support uninstallation, so the steps will be empty
+	if len(op.Steps) == 0 {
+		return nil, badRequestError{errors.New("the current operation steps is empty and cannot be performed")}
+	}
+	op.Labels[common.LabelOperationAction] = operationAction
+	return op, nil
+}
+
 func (h *handler) UpgradeCluster(request *restful.Request, response *restful.Response) {
 	name := request.PathParameter(query.ParameterName)
 	body := &ClusterUpgrade{}
@@ -1726,38 +2071,12 @@ func (h *handler) UpgradeCluster(request *restful.Request, response *restful.Res
 	if v := request.QueryParameter("timeout"); v != "" {
 		timeoutSecs = v
 	}
-	extraMeta, err := h.getClusterMetadata(request.Request.Context(), clu)
+	op, err := h.makeUpgradeOperation(request.Request.Context(), clu, body)
 	if err != nil {
-		if apimachineryErrors.IsNotFound(err) || err == ErrNodesRegionDifferent {
-			restplus.HandleBadRequest(response, request, err)
-			return
-		}
-		restplus.HandleInternalError(response, request, err)
-		return
-	}
-	extraMeta.Offline = body.Offline
-	extraMeta.KubeVersion = body.Version
-	extraMeta.LocalRegistry = body.LocalRegistry
-	upgradeComp := &k8s.Upgrade{}
-	upgradeComp.InitStepper(extraMeta, clu)
-	if err := upgradeComp.Validate(); err != nil {
-		restplus.HandleBadRequest(response, request, err)
+		handleOperationError(response, request, err)
 		return
 	}
 
-	if err := upgradeComp.InitSteps(component.WithExtraMetadata(context.TODO(), *extraMeta)); err != nil {
-		restplus.HandleBadRequest(response, request, err)
-		return
-	}
-
-	op := &v1.Operation{}
-	op.Name = uuid.New().String()
-	op.Labels = map[string]string{
-		common.LabelClusterName:    clu.Name,
-		common.LabelTopologyRegion: extraMeta.Masters[0].Region,
-	}
-	op.Steps = upgradeComp.GetInstallSteps()
-
 	// TODO: make dry run path to etcd
 	if !dryRun {
 		clu.Status.Phase = v1.ClusterUpgrading
@@ -1769,8 +2088,6 @@ func (h *handler) UpgradeCluster(request *restful.Request, response *restful.Res
 	}
 
 	op.Labels[common.LabelTimeoutSeconds] = timeoutSecs
-	op.Labels[common.LabelOperationAction] = v1.OperationUpgradeCluster
-	op.Labels[common.LabelUpgradeVersion] = body.Version
 	op.Status.Status = v1.OperationStatusRunning
 	if !dryRun {
 		op, err = h.opOperator.CreateOperation(context.TODO(), op)
@@ -1783,6 +2100,48 @@ func (h *handler) UpgradeCluster(request *restful.Request, response *restful.Res
 	response.WriteHeader(http.StatusOK)
 }
 
+// makeUpgradeOperation builds the operation which upgrades the kubernetes version of the cluster,
+// the cni is upgraded to the version matching the new kubernetes version.
+func (h *handler) makeUpgradeOperation(ctx context.Context, clu *v1.Cluster, body *ClusterUpgrade) (*v1.Operation, error) {
+	extraMeta, err := h.getClusterMetadata(ctx, clu)
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) || err == ErrNodesRegionDifferent {
+			return nil, badRequestError{err}
+		}
+		return nil, err
+	}
+	extraMeta.Offline = body.Offline
+	extraMeta.KubeVersion = body.Version
+	extraMeta.LocalRegistry = body.LocalRegistry
+	packageMetadata := scheme.PackageMetadata{}
+	if err = packageMetadata.ReadMetadata(!body.Offline, h.cfg.StaticServerOptions.Path); err != nil {
+		return nil, badRequestError{err}
+	}
+	extraMeta.CNIVersion = packageMetadata.FindK8sMatchCniVersion(body.Version, clu.CNI.Type)
+	provider, err := getClusterProvider(clu)
+	if err != nil {
+		return nil, badRequestError{err}
+	}
+	steps, err := provider.UpgradeSteps(component.WithExtraMetadata(ctx, *extraMeta), clu)
+	if err != nil {
+		return nil, badRequestError{err}
+	}
+
+	op := &v1.Operation{}
+	op.Name = uuid.New().String()
+	op.Labels = map[string]string{
+		common.LabelClusterName:     clu.Name,
+		common.LabelTopologyRegion:  extraMeta.Masters[0].Region,
+		common.LabelOperationAction: v1.OperationUpgradeCluster,
+		common.LabelUpgradeVersion:  body.Version,
+	}
+	if extraMeta.CNIVersion != "" && extraMeta.CNIVersion != clu.CNI.Version {
+		op.Labels[common.LabelUpgradeCNIVersion] = extraMeta.CNIVersion
+	}
+	op.Steps = steps
+	return op, nil
+}
+
 func (h *handler) ResetClusterStatus(request *restful.Request, response *restful.Response) {
 	dryRun := query.GetBoolValueWithDefault(request, query.ParamDryRun, false)
 	cluName := request.PathParameter(query.ParameterName)
@@ -2054,6 +2413,12 @@ func (h *handler) UpdateDomain(request *restful.Request, response *restful.Respo
 		restplus.HandleBadRequest(response, request, err)
 		return
 	}
+	if d.Spec.Forwarders != nil {
+		if errs := validation.ValidateForwarders(d.Spec.Forwarders, field.NewPath("spec", "forwarders")); len(errs) > 0 {
+			restplus.HandleBadRequest(response, request, errs.ToAggregate())
+			return
+		}
+	}
 
 	updated, err := h.updateDomain(request.Request.Context(), d)
 	if err != nil {
@@ -2070,6 +2435,7 @@ func (h *handler) updateDomain(ctx context.Context, domain *v1.Domain) (*v1.Doma
 	}
 	d.Spec.Description = domain.Spec.Description
 	d.Spec.SyncCluster = domain.Spec.SyncCluster
+	d.Spec.Forwarders = domain.Spec.Forwarders
 	return h.clusterOperator.UpdateDomain(ctx, d)
 }
 
@@ -2186,8 +2552,8 @@ func (h *handler) CreateRecords(request *restful.Request, response *restful.Resp
 }
 
 func checkRecord(r *v1.Record) error {
-	if len(r.ParseRecord) == 0 {
-		return fmt.Errorf("resolve record cann not be empty")
+	if errs := validation.ValidateRecord(r, field.NewPath("record")); len(errs) > 0 {
+		return errs.ToAggregate()
 	}
 	return nil
 }
@@ -2621,10 +2987,21 @@ func (h *handler) CreateBackupPoint(request *restful.Request, response *restful.
 	}
 
 	bp.StorageType = strings.ToLower(bp.StorageType)
+	if errs := validation.ValidateBackupPoint(bp); len(errs) > 0 {
+		restplus.HandleBadRequest(response, request, errs.ToAggregate())
+		return
+	}
 	if bp.StorageType == bs.S3Storage && len([]rune(bp.S3Config.Bucket)) <= 3 {
 		restplus.HandleBadRequest(response, request, fmt.Errorf("bucket name cannot be shorter than 3 characters"))
 		return
 	}
+	if bp.Encryption != nil {
+		bp.Encryption.Algorithm = strutil.StringDefaultIfEmpty(bs.EncryptionAESGCM, bp.Encryption.Algorithm)
+	}
+	if _, err := k8s.NewBackupCodec(bp, nil); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
 
 	createdBp, err := h.clusterOperator.CreateBackupPoint(request.Request.Context(), bp)
 	if err != nil {
@@ -2688,6 +3065,35 @@ func (h *handler) UpdateBackupPoint(req *restful.Request, resp *restful.Response
 		obp.S3Config.AccessKeySecret = bp.S3Config.AccessKeySecret
 	}
 
+	if bp.StorageType == bs.SFTPStorage && obp.StorageType == bs.SFTPStorage && bp.SftpConfig != nil {
+		obp.SftpConfig.Password = bp.SftpConfig.Password
+		obp.SftpConfig.PrivateKey = bp.SftpConfig.PrivateKey
+		obp.SftpConfig.Description = bp.SftpConfig.Description
+	}
+
+	if bp.StorageType == bs.WebDAVStorage && obp.StorageType == bs.WebDAVStorage && bp.WebDAVConfig != nil {
+		obp.WebDAVConfig.Username = bp.WebDAVConfig.Username
+		obp.WebDAVConfig.Password = bp.WebDAVConfig.Password
+		obp.WebDAVConfig.Description = bp.WebDAVConfig.Description
+	}
+
+	obp.RetentionPolicy = bp.RetentionPolicy
+	if errs := validation.ValidateBackupPoint(obp); len(errs) > 0 {
+		restplus.HandleBadRequest(resp, req, errs.ToAggregate())
+		return
+	}
+
+	// the existing backups are decrypted by the encryption of the backup point, so it can not be modified
+	if bp.Encryption != nil && !reflect.DeepEqual(bp.Encryption, obp.Encryption) {
+		restplus.HandleBadRequest(resp, req, fmt.Errorf("encryption of the backup point can not be modified"))
+		return
+	}
+	obp.Compression = bp.Compression
+	if _, err = k8s.NewBackupCodec(obp, nil); err != nil {
+		restplus.HandleBadRequest(resp, req, err)
+		return
+	}
+
 	_, err = h.clusterOperator.UpdateBackupPoint(req.Request.Context(), obp)
 	if err != nil {
 		restplus.HandleInternalError(resp, req, err)
@@ -2697,6 +3103,54 @@ func (h *handler) UpdateBackupPoint(req *restful.Request, resp *restful.Response
 	_ = resp.WriteHeaderAndEntity(http.StatusOK, obp)
 }
 
+func (h *handler) ScanBackupPoint(request *restful.Request, response *restful.Response) {
+	name := request.PathParameter(query.ParameterName)
+	dryRun := query.GetBoolValueWithDefault(request, query.ParamDryRun, false)
+	ctx := request.Request.Context()
+
+	bp, err := h.clusterOperator.GetBackupPointEx(ctx, name, "0")
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	// fs backups are saved on the master nodes of the clusters by the agents, not on the server
+	if bp.StorageType == bs.FSStorage {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("backup point %s is a %s backup point, its backups are saved on the cluster nodes and can't be scanned", bp.Name, bp.StorageType))
+		return
+	}
+	store, err := k8s.NewBackupStore(bp)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	existing, err := h.clusterOperator.ListBackups(ctx, query.New())
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	backups, err := scanBackups(ctx, store, bp.Name, existing)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+
+	imported := &v1.BackupList{Items: make([]v1.Backup, 0, len(backups))}
+	for _, b := range backups {
+		if !dryRun {
+			if b, err = h.clusterOperator.CreateBackup(ctx, b); err != nil {
+				restplus.HandleInternalError(response, request, err)
+				return
+			}
+		}
+		imported.Items = append(imported.Items, *b)
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusOK, imported)
+}
+
 func (h *handler) DescribeCronBackup(request *restful.Request, response *restful.Response) {
 	name := request.PathParameter(query.ParameterName)
 	resourceVersion := strutil.StringDefaultIfEmpty("0", request.QueryParameter(query.ParameterResourceVersion))
@@ -2754,6 +3208,12 @@ func (h *handler) CreateCronBackup(request *restful.Request, response *restful.R
 		restplus.HandleBadRequest(response, request, err)
 		return
 	}
+	if cb.Spec.RetentionPolicy != nil {
+		if err := cb.Spec.RetentionPolicy.Validate(); err != nil {
+			restplus.HandleBadRequest(response, request, err)
+			return
+		}
+	}
 	if cb.Spec.Schedule != "" {
 		s, _ := cron.NewParser(4 | 8 | 16 | 32 | 64).Parse(cb.Spec.Schedule)
 		// update the next schedule time
@@ -2829,6 +3289,14 @@ func (h *handler) UpdateCronBackup(req *restful.Request, resp *restful.Response)
 		nextRunAt := metav1.NewTime(s.Next(time.Now()))
 		ocb.Status.NextScheduleTime = &nextRunAt
 	}
+	ocb.Spec.Verify = cb.Spec.Verify
+	if cb.Spec.RetentionPolicy != nil {
+		if err = cb.Spec.RetentionPolicy.Validate(); err != nil {
+			restplus.HandleBadRequest(resp, req, err)
+			return
+		}
+	}
+	ocb.Spec.RetentionPolicy = cb.Spec.RetentionPolicy
 
 	_, err = h.clusterOperator.UpdateCronBackup(req.Request.Context(), ocb)
 	if err != nil {
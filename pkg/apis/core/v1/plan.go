@@ -0,0 +1,105 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	apimachineryErrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/server/restplus"
+)
+
+// PlanCluster compares the posted cluster with the live one and returns the changes, with the
+// operation each of them would run, which bring the live cluster to the posted one. Nothing is
+// persisted, the changes are applied one by one with the existing APIs.
+func (h *handler) PlanCluster(request *restful.Request, response *restful.Response) {
+	desired := &v1.Cluster{}
+	if err := request.ReadEntity(desired); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	name := request.PathParameter(query.ParameterName)
+	if desired.Name == "" {
+		desired.Name = name
+	}
+	ctx := request.Request.Context()
+	clu, err := h.clusterOperator.GetClusterEx(ctx, name, "0")
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	changes, err := DiffCluster(clu, desired)
+	if err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	// every operation is built against the cluster the previous changes leave behind
+	simulated := clu.DeepCopy()
+	for i := range changes {
+		op, err := h.planClusterChange(ctx, simulated, changes[i])
+		if err != nil {
+			handleOperationError(response, request, fmt.Errorf("%s: %w", changes[i].Action, err))
+			return
+		}
+		changes[i].Operation = op
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusOK, ClusterPlan{Changes: changes})
+}
+
+// planClusterChange builds the operation of the change with the builder its API uses and applies
+// the change to the cluster.
+func (h *handler) planClusterChange(ctx context.Context, clu *v1.Cluster, change ClusterChange) (*v1.Operation, error) {
+	switch {
+	case change.Nodes != nil:
+		// the nodes are added to or removed from the cluster when the operation is built
+		pn := *change.Nodes
+		return h.makeNodesOperation(ctx, clu, &pn)
+	case change.Components != nil:
+		op, err := h.makeComponentsOperation(ctx, clu, change.Components)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = change.Components.addOrRemoveComponentFromCluster(clu); err != nil {
+			return nil, err
+		}
+		return op, nil
+	case change.Upgrade != nil:
+		op, err := h.makeUpgradeOperation(ctx, clu, change.Upgrade)
+		if err != nil {
+			return nil, err
+		}
+		clu.KubernetesVersion = change.Upgrade.Version
+		if v := op.Labels[common.LabelUpgradeCNIVersion]; v != "" {
+			clu.CNI.Version = v
+		}
+		return op, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedClusterChange, change.Action)
+}
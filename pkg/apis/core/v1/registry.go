@@ -40,6 +40,7 @@ import (
 
 	"github.com/kubeclipper/kubeclipper/pkg/models"
 	"github.com/kubeclipper/kubeclipper/pkg/models/operation"
+	"github.com/kubeclipper/kubeclipper/pkg/oplog"
 	"github.com/kubeclipper/kubeclipper/pkg/query"
 	corev1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
 	"github.com/kubeclipper/kubeclipper/pkg/service"
@@ -149,6 +150,19 @@ func SetupWebService(h *handler) *restful.WebService {
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Cluster{}).
 		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
 
+	webservice.Route(webservice.POST("/clusters/{name}/etcd/maintenance").
+		To(h.EtcdMaintenance).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Compact and defragment etcd of cluster.").
+		Param(webservice.PathParameter(query.ParameterName, "cluster name").
+			Required(true).
+			DataType("string")).
+		Param(webservice.QueryParameter(query.ParamDryRun, "dry run").
+			Required(false).
+			DataType("bool")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Cluster{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
+
 	webservice.Route(webservice.GET("/clusters/{name}/kubeconfig").
 		To(h.GetKubeConfig).
 		Produces("text/plain", restful.MIME_JSON).
@@ -218,6 +232,17 @@ func SetupWebService(h *handler) *restful.WebService {
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Backup{}).
 		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
 
+	webservice.Route(webservice.POST("/clusters/{cluster}/backups/{backup}/verify").
+		To(h.VerifyBackup).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Verify backups by restoring the snapshot in a scratch directory.").
+		Param(webservice.PathParameter("cluster", "cluster name")).
+		Param(webservice.PathParameter("backup", "backup name")).
+		Param(webservice.QueryParameter(query.ParamDryRun, "dry run verify backups").
+			Required(false).DataType("boolean")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Backup{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
+
 	webservice.Route(webservice.POST("/clusters/{cluster}/recovery").
 		To(h.CreateRecovery).
 		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
@@ -359,6 +384,19 @@ func SetupWebService(h *handler) *restful.WebService {
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), StepLog{}).
 		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
 
+	webservice.Route(webservice.GET("/operations/{name}/logs").
+		To(h.StreamOperationLog).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Stream the step logs of the running operation on all nodes, via websocket or chunked response of newline-delimited json.").
+		Param(webservice.PathParameter(query.ParameterName, "operation name").
+			Required(true).
+			DataType("string")).
+		Param(webservice.QueryParameter(query.ParameterStep, "step id, defaults to all steps").
+			Required(false).
+			DataFormat("step=%s")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), oplog.LogStreamChunk{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
+
 	webservice.Route(webservice.GET("/operations").
 		To(h.ListOperations).
 		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
@@ -402,11 +440,23 @@ func SetupWebService(h *handler) *restful.WebService {
 		Doc("clusters retry operation.").
 		Param(webservice.QueryParameter(query.ParamDryRun, "dry run clusters retry operation.").
 			Required(false).DataType("boolean")).
+		Param(webservice.QueryParameter(query.ParameterFromStep, "id of the step to resume from, defaults to the failed step.").
+			Required(false).DataType("string")).
 		Param(webservice.PathParameter(query.ParameterName, "operation name").
 			Required(true).
 			DataType("string")).
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Cluster{}))
 
+	webservice.Route(webservice.POST("/operations/{name}/cancel").
+		To(h.CancelOperation).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("cancel the running operation.").
+		Param(webservice.PathParameter(query.ParameterName, "operation name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Operation{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
+
 	webservice.Route(webservice.POST("/clusters/{name}/upgrade").
 		To(h.UpgradeCluster).
 		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
@@ -419,6 +469,17 @@ func SetupWebService(h *handler) *restful.WebService {
 			DataType("string")).
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), nil))
 
+	webservice.Route(webservice.POST("/clusters/{name}/plan").
+		To(h.PlanCluster).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("plan the operations which bring the cluster to the posted one, nothing is persisted.").
+		Reads(corev1.Cluster{}).
+		Param(webservice.PathParameter(query.ParameterName, "cluster name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), ClusterPlan{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
+
 	webservice.Route(webservice.PATCH("/clusters/{name}/status").
 		To(h.ResetClusterStatus).
 		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
@@ -800,6 +861,21 @@ func SetupWebService(h *handler) *restful.WebService {
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), nil).
 		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
 
+	webservice.Route(webservice.POST("/backuppoints/{name}/scan").
+		To(h.ScanBackupPoint).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Import the backups found in the backup point by their metadata files, fs backup points are not supported.").
+		Param(webservice.PathParameter(query.ParameterName, "backup point name").
+			Required(true).
+			DataType("string")).
+		Param(webservice.QueryParameter(query.ParamDryRun, "dry run scan backup point").
+			Required(false).
+			DataType("boolean")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.BackupList{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), errors.HTTPError{}).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
 	webservice.Route(webservice.GET("/cronbackups").
 		Doc("List of cronbackup.").
 		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
@@ -23,9 +23,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
+	"strings"
 
 	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
-	"github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1/k8s"
 
 	"github.com/google/uuid"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -133,6 +134,9 @@ func (p *PatchNodes) makeWorkerOperation(extra component.ExtraMetadata, cluster
 		common.LabelClusterName: cluster.Name,
 		// v1.LabelTopologyRegion ???
 	}
+	op.Annotations = map[string]string{
+		common.AnnotationOperationNodes: strings.Join(p.Nodes.GetNodeIDs(), ","),
+	}
 	ctx := context.TODO()
 	ctx = component.WithExtraMetadata(ctx, extra)
 	stepNodes := []corev1.StepNode{}
@@ -146,66 +150,23 @@ func (p *PatchNodes) makeWorkerOperation(extra component.ExtraMetadata, cluster
 		stepNodes = append(stepNodes, stepNode)
 	}
 
-	var action corev1.StepAction
+	provider, err := getClusterProvider(cluster)
+	if err != nil {
+		return nil, err
+	}
 	switch p.Operation {
 	case NodesOperationAdd:
-		// add nodes to cluster
-		// check nodes in cluster
-		// nodes to be added
-
-		//p.Nodes = cluster.Workers.Complement(p.Nodes...)
-		//cluster.Workers = append(cluster.Workers, p.Nodes...)
-		action = corev1.ActionInstall
 		op.Labels[common.LabelOperationAction] = corev1.OperationAddNodes
-
-		// container runtime
-		steps, err := getCriStep(ctx, &cluster.ContainerRuntime, action, stepNodes)
+		op.Steps, err = provider.AddNodeSteps(ctx, cluster, stepNodes, p.Role.String())
 		if err != nil {
 			return nil, err
 		}
-		op.Steps = append(op.Steps, steps...)
-
-		// kubernetes
-		steps, err = p.getPackageSteps(cluster, action, stepNodes)
-		if err != nil {
-			return nil, err
-		}
-		op.Steps = append(op.Steps, steps...)
-
-		// join component
-		steps, err = p.makeWorkerNodeSteps(&extra, cluster, stepNodes, corev1.ActionInstall)
-		if err != nil {
-			return nil, err
-		}
-		op.Steps = append(op.Steps, steps...)
 	case NodesOperationRemove:
-		// // remove nodes from cluster
-		// // filter nodes in cluster
-		// // nodes to be removed
-		// p.Nodes = cluster.Workers.Intersect(p.Nodes...)
-		// cluster.Workers = cluster.Workers.Complement(p.Nodes...)
-		action = corev1.ActionUninstall
 		op.Labels[common.LabelOperationAction] = corev1.OperationRemoveNodes
-
-		steps, err := p.makeWorkerNodeSteps(&extra, cluster, stepNodes, corev1.ActionUninstall)
-		if err != nil {
-			return nil, err
-		}
-		op.Steps = append(op.Steps, steps...)
-
-		// kubernetes
-		steps, err = p.getPackageSteps(cluster, action, stepNodes)
+		op.Steps, err = provider.RemoveNodeSteps(ctx, cluster, stepNodes, p.Role.String())
 		if err != nil {
 			return nil, err
 		}
-		op.Steps = append(op.Steps, steps...)
-
-		// container runtime
-		steps, err = getCriStep(ctx, &cluster.ContainerRuntime, action, stepNodes)
-		if err != nil {
-			return nil, err
-		}
-		op.Steps = append(op.Steps, steps...)
 	default:
 		return nil, ErrInvalidNodesOperation
 	}
@@ -213,31 +174,6 @@ func (p *PatchNodes) makeWorkerOperation(extra component.ExtraMetadata, cluster
 	return op, nil
 }
 
-func (p *PatchNodes) getPackageSteps(cluster *corev1.Cluster, action corev1.StepAction, pNodes []corev1.StepNode) ([]corev1.Step, error) {
-	pack := &k8s.Package{}
-	pack = pack.InitStepper(cluster)
-
-	switch action {
-	case corev1.ActionInstall:
-		return pack.InstallSteps(pNodes)
-	case corev1.ActionUninstall:
-		return pack.UninstallSteps(pNodes)
-	}
-
-	return nil, fmt.Errorf("packageSteps no support action: %s", action)
-}
-
-func (p *PatchNodes) makeWorkerNodeSteps(extra *component.ExtraMetadata, c *corev1.Cluster, patchNodes []corev1.StepNode, action corev1.StepAction) ([]corev1.Step, error) {
-	role := p.Role.String()
-	gen := k8s.GenNode{}
-	err := gen.InitStepper(extra, c, role).MakeSteps(extra, patchNodes, role)
-	if err != nil {
-		return nil, err
-	}
-
-	return gen.GetSteps(action), nil
-}
-
 // checkComponent check whether the component is installed in the current cluster
 func (p *PatchComponents) checkComponents(cluster *corev1.Cluster) error {
 	for _, v := range p.Addons {
@@ -337,3 +273,169 @@ type ClusterUpgrade struct {
 	Offline       bool   `json:"offline"`
 	LocalRegistry string `json:"localRegistry"`
 }
+
+// ClusterChange is one step to turn the live cluster into the desired one, it is applied with the
+// existing API of its action and Operation is the operation that API creates for it.
+// Reconfigure marks the uninstall and the install of a reconfigured addon, the addon is not
+// available between them.
+type ClusterChange struct {
+	Action      string            `json:"action"`
+	Nodes       *PatchNodes       `json:"nodes,omitempty"`
+	Components  *PatchComponents  `json:"components,omitempty"`
+	Upgrade     *ClusterUpgrade   `json:"upgrade,omitempty"`
+	Reconfigure bool              `json:"reconfigure,omitempty"`
+	Operation   *corev1.Operation `json:"operation,omitempty"`
+}
+
+type ClusterPlan struct {
+	Changes []ClusterChange `json:"changes"`
+}
+
+var ErrUnsupportedClusterChange = errors.New("unsupported cluster change")
+
+// DiffCluster returns the changes which turn the live cluster into the desired one, in the order
+// they must be applied: workers are removed and addons uninstalled before the upgrade so fewer
+// nodes and addons are upgraded, then new workers join with the new version and addons are
+// installed last. An addon whose version or config changes is reconfigured: it is uninstalled and
+// installed again by two adjacent changes after the new workers join, so it is only unavailable
+// in between. Labels and taints of the existing workers are not compared.
+func DiffCluster(live, desired *corev1.Cluster) ([]ClusterChange, error) {
+	if err := checkClusterImmutable(live, desired); err != nil {
+		return nil, err
+	}
+	var changes []ClusterChange
+	if removed := live.Workers.Complement(desired.Workers...); len(removed) > 0 {
+		changes = append(changes, ClusterChange{
+			Action: corev1.OperationRemoveNodes,
+			Nodes:  &PatchNodes{Operation: NodesOperationRemove, Nodes: removed, Role: common.NodeRoleWorker},
+		})
+	}
+	installed, uninstalled, reconfigured, err := diffAddons(live.Addons, desired.Addons)
+	if err != nil {
+		return nil, err
+	}
+	if len(uninstalled) > 0 {
+		changes = append(changes, ClusterChange{
+			Action:     corev1.OperationUninstallComponents,
+			Components: &PatchComponents{Uninstall: true, Addons: uninstalled},
+		})
+	}
+	if desired.KubernetesVersion != "" && desired.KubernetesVersion != live.KubernetesVersion {
+		changes = append(changes, ClusterChange{
+			Action: corev1.OperationUpgradeCluster,
+			Upgrade: &ClusterUpgrade{
+				Version:       desired.KubernetesVersion,
+				Offline:       live.Offline(),
+				LocalRegistry: live.LocalRegistry,
+			},
+		})
+	}
+	if added := desired.Workers.Complement(live.Workers...); len(added) > 0 {
+		changes = append(changes, ClusterChange{
+			Action: corev1.OperationAddNodes,
+			Nodes:  &PatchNodes{Operation: NodesOperationAdd, Nodes: added, Role: common.NodeRoleWorker},
+		})
+	}
+	for _, r := range reconfigured {
+		changes = append(changes, ClusterChange{
+			Action:      corev1.OperationUninstallComponents,
+			Components:  &PatchComponents{Uninstall: true, Addons: []corev1.Addon{r.live}},
+			Reconfigure: true,
+		}, ClusterChange{
+			Action:      corev1.OperationInstallComponents,
+			Components:  &PatchComponents{Uninstall: false, Addons: []corev1.Addon{r.desired}},
+			Reconfigure: true,
+		})
+	}
+	if len(installed) > 0 {
+		changes = append(changes, ClusterChange{
+			Action:     corev1.OperationInstallComponents,
+			Components: &PatchComponents{Uninstall: false, Addons: installed},
+		})
+	}
+	return changes, nil
+}
+
+// checkClusterImmutable returns an error when the desired cluster changes a field no operation
+// can change on a running cluster.
+func checkClusterImmutable(live, desired *corev1.Cluster) error {
+	if live.Name != desired.Name {
+		return fmt.Errorf("%w: cluster name %s does not match %s", ErrUnsupportedClusterChange, desired.Name, live.Name)
+	}
+	if len(live.Masters.Complement(desired.Masters...)) > 0 || len(desired.Masters.Complement(live.Masters...)) > 0 {
+		return fmt.Errorf("%w: masters can not be changed", ErrUnsupportedClusterChange)
+	}
+	if desired.ContainerRuntime.Type != "" && desired.ContainerRuntime.Type != live.ContainerRuntime.Type {
+		return fmt.Errorf("%w: container runtime can not be changed", ErrUnsupportedClusterChange)
+	}
+	if desired.CNI.Type != "" && desired.CNI.Type != live.CNI.Type {
+		return fmt.Errorf("%w: cni can not be changed", ErrUnsupportedClusterChange)
+	}
+	return nil
+}
+
+// addonReconfigure is an addon of the live cluster replaced by the desired one of the same name.
+type addonReconfigure struct {
+	live, desired corev1.Addon
+}
+
+// diffAddons compares the addons as a multiset of name, version and config, the config is compared
+// after decoding so that formatting differences of the manifest are not taken as a change. A changed
+// addon which has the same name as a removed one is returned as reconfigured.
+func diffAddons(live, desired []corev1.Addon) (installed, uninstalled []corev1.Addon, reconfigured []addonReconfigure, err error) {
+	matched := make([]bool, len(live))
+	var changed []corev1.Addon
+	for _, d := range desired {
+		found := false
+		for i, l := range live {
+			if matched[i] || l.Name != d.Name || l.Version != d.Version {
+				continue
+			}
+			equal, err := addonConfigEqual(l, d)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			if equal {
+				matched[i], found = true, true
+				break
+			}
+		}
+		if !found {
+			changed = append(changed, d)
+		}
+	}
+	for _, d := range changed {
+		found := false
+		for i, l := range live {
+			if !matched[i] && l.Name == d.Name {
+				matched[i], found = true, true
+				reconfigured = append(reconfigured, addonReconfigure{live: l, desired: d})
+				break
+			}
+		}
+		if !found {
+			installed = append(installed, d)
+		}
+	}
+	for i, l := range live {
+		if !matched[i] {
+			uninstalled = append(uninstalled, l)
+		}
+	}
+	return installed, uninstalled, reconfigured, nil
+}
+
+func addonConfigEqual(a, b corev1.Addon) (bool, error) {
+	var ac, bc interface{}
+	if len(a.Config.Raw) > 0 {
+		if err := json.Unmarshal(a.Config.Raw, &ac); err != nil {
+			return false, fmt.Errorf("%s-%s component configuration resolution error: %s", a.Name, a.Version, err.Error())
+		}
+	}
+	if len(b.Config.Raw) > 0 {
+		if err := json.Unmarshal(b.Config.Raw, &bc); err != nil {
+			return false, fmt.Errorf("%s-%s component configuration resolution error: %s", b.Name, b.Version, err.Error())
+		}
+	}
+	return reflect.DeepEqual(ac, bc), nil
+}
@@ -31,7 +31,9 @@ import (
 
 	"github.com/kubeclipper/kubeclipper/pkg/component"
 
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
 	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	_ "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1/k3s"
 )
 
 var (
@@ -198,10 +200,13 @@ func Test_MakeOperation(t *testing.T) {
 		meta       component.ExtraMetadata
 		patchNodes *PatchNodes
 	}
+	k3s := c2.DeepCopy()
+	k3s.Provider.Name = v1.ClusterK3s
 	tests := []struct {
-		name    string
-		arg     args
-		wantErr error
+		name      string
+		arg       args
+		wantErr   error
+		wantNodes string
 	}{
 		{
 			name: "test add worker node operation",
@@ -218,7 +223,8 @@ func Test_MakeOperation(t *testing.T) {
 					Role: "worker",
 				},
 			},
-			wantErr: nil,
+			wantErr:   nil,
+			wantNodes: "6b8456e8-2489-4321-bbb0-f8d75c065384",
 		},
 		{
 			name: "test remove worker node operation",
@@ -235,7 +241,22 @@ func Test_MakeOperation(t *testing.T) {
 					Role: "worker",
 				},
 			},
-			wantErr: nil,
+			wantErr:   nil,
+			wantNodes: "4cf1ad74-704c-4290-a523-e524e930245d",
+		},
+		{
+			name: "test remove worker node operation of k3s cluster",
+			arg: args{
+				cluster: k3s,
+				meta:    *extraMeta,
+				patchNodes: &PatchNodes{
+					Operation: "remove",
+					Nodes:     worker,
+					Role:      "worker",
+				},
+			},
+			wantErr:   nil,
+			wantNodes: "4cf1ad74-704c-4290-a523-e524e930245d,ae4ba282-27f9-4a93-8fe9-63f786781d48",
 		},
 		{
 			name: "test add master node operation",
@@ -257,10 +278,13 @@ func Test_MakeOperation(t *testing.T) {
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			_, err := test.arg.patchNodes.MakeOperation(test.arg.meta, test.arg.cluster)
+			op, err := test.arg.patchNodes.MakeOperation(test.arg.meta, test.arg.cluster)
 			if err != nil && err != test.wantErr {
 				t.Errorf(" MakeOperation() error: %v ", err)
 			}
+			if err == nil && op.Annotations[common.AnnotationOperationNodes] != test.wantNodes {
+				t.Errorf(" MakeOperation() nodes = %s, want %s", op.Annotations[common.AnnotationOperationNodes], test.wantNodes)
+			}
 		})
 	}
 
@@ -401,3 +425,135 @@ func Test_addOrRemoveComponentFromCluster(t *testing.T) {
 		})
 	}
 }
+
+func Test_DiffCluster(t *testing.T) {
+	nfs := v1.Addon{
+		Name:    "nfs-provisioner",
+		Version: "v1",
+		Config:  runtime.RawExtension{Raw: []byte(`{"scName": "nfs-provisioner-v1"}`)},
+	}
+	nfsReformatted := v1.Addon{
+		Name:    "nfs-provisioner",
+		Version: "v1",
+		Config:  runtime.RawExtension{Raw: []byte(`{"scName":"nfs-provisioner-v1"}`)},
+	}
+	nfsReconfigured := v1.Addon{
+		Name:    "nfs-provisioner",
+		Version: "v1",
+		Config:  runtime.RawExtension{Raw: []byte(`{"scName": "nfs-provisioner-v2"}`)},
+	}
+	cinder := v1.Addon{Name: "cinder", Version: "v1"}
+	newWorker := v1.WorkerNode{ID: "0b1b8c1c-3bb6-4b8e-9a3c-2a2b7e4c9d10"}
+	cluster := func(mutate func(c *v1.Cluster)) *v1.Cluster {
+		c := c2.DeepCopy()
+		c.KubernetesVersion = "v1.20.13"
+		c.Workers = worker.DeepCopy()
+		c.Addons = []v1.Addon{nfs}
+		if mutate != nil {
+			mutate(c)
+		}
+		return c
+	}
+	tests := []struct {
+		name    string
+		desired *v1.Cluster
+		want    []ClusterChange
+		wantErr bool
+	}{
+		{
+			name:    "no change",
+			desired: cluster(func(c *v1.Cluster) { c.Addons = []v1.Addon{nfsReformatted} }),
+			want:    nil,
+		},
+		{
+			name: "replace worker and upgrade",
+			desired: cluster(func(c *v1.Cluster) {
+				c.KubernetesVersion = "v1.23.6"
+				c.Workers = v1.WorkerNodeList{worker[0], newWorker}
+			}),
+			want: []ClusterChange{
+				{
+					Action: v1.OperationRemoveNodes,
+					Nodes:  &PatchNodes{Operation: NodesOperationRemove, Nodes: v1.WorkerNodeList{worker[1]}, Role: "worker"},
+				},
+				{
+					Action:  v1.OperationUpgradeCluster,
+					Upgrade: &ClusterUpgrade{Version: "v1.23.6"},
+				},
+				{
+					Action: v1.OperationAddNodes,
+					Nodes:  &PatchNodes{Operation: NodesOperationAdd, Nodes: v1.WorkerNodeList{newWorker}, Role: "worker"},
+				},
+			},
+		},
+		{
+			name:    "reconfigure addon",
+			desired: cluster(func(c *v1.Cluster) { c.Addons = []v1.Addon{nfsReconfigured} }),
+			want: []ClusterChange{
+				{
+					Action:      v1.OperationUninstallComponents,
+					Components:  &PatchComponents{Uninstall: true, Addons: []v1.Addon{nfs}},
+					Reconfigure: true,
+				},
+				{
+					Action:      v1.OperationInstallComponents,
+					Components:  &PatchComponents{Addons: []v1.Addon{nfsReconfigured}},
+					Reconfigure: true,
+				},
+			},
+		},
+		{
+			name: "reconfigure addon after upgrade and new workers",
+			desired: cluster(func(c *v1.Cluster) {
+				c.KubernetesVersion = "v1.23.6"
+				c.Workers = append(c.Workers, newWorker)
+				c.Addons = []v1.Addon{nfsReconfigured, cinder}
+			}),
+			want: []ClusterChange{
+				{
+					Action:  v1.OperationUpgradeCluster,
+					Upgrade: &ClusterUpgrade{Version: "v1.23.6"},
+				},
+				{
+					Action: v1.OperationAddNodes,
+					Nodes:  &PatchNodes{Operation: NodesOperationAdd, Nodes: v1.WorkerNodeList{newWorker}, Role: "worker"},
+				},
+				{
+					Action:      v1.OperationUninstallComponents,
+					Components:  &PatchComponents{Uninstall: true, Addons: []v1.Addon{nfs}},
+					Reconfigure: true,
+				},
+				{
+					Action:      v1.OperationInstallComponents,
+					Components:  &PatchComponents{Addons: []v1.Addon{nfsReconfigured}},
+					Reconfigure: true,
+				},
+				{
+					Action:     v1.OperationInstallComponents,
+					Components: &PatchComponents{Addons: []v1.Addon{cinder}},
+				},
+			},
+		},
+		{
+			name:    "change masters",
+			desired: cluster(func(c *v1.Cluster) { c.Masters = master[:1] }),
+			wantErr: true,
+		},
+		{
+			name:    "change cni",
+			desired: cluster(func(c *v1.Cluster) { c.CNI.Type = "cilium" }),
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := DiffCluster(cluster(nil), test.desired)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("DiffCluster() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("DiffCluster() got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
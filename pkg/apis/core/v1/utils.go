@@ -23,18 +23,26 @@ import (
 	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+	apimachineryErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	"github.com/kubeclipper/kubeclipper/pkg/query"
 
 	"github.com/kubeclipper/kubeclipper/pkg/component"
 	"github.com/kubeclipper/kubeclipper/pkg/component/utils"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
 	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
 	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
-	"github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1/cri"
 	"github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1/k8s"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/core/validation"
 	bs "github.com/kubeclipper/kubeclipper/pkg/simple/backupstore"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
 )
 
 func reverseComponents(components []v1.Addon) {
@@ -44,30 +52,14 @@ func reverseComponents(components []v1.Addon) {
 	}
 }
 
-func getCriStep(ctx context.Context, c *v1.ContainerRuntime, action v1.StepAction, nodes []v1.StepNode) ([]v1.Step, error) {
-	switch c.Type {
-	case v1.CRIDocker:
-		r := cri.DockerRunnable{}
-		err := r.InitStep(ctx, c, nodes)
-		if err != nil {
-			return nil, err
-		}
-		return r.GetActionSteps(action), nil
-	case v1.CRIContainerd:
-		r := cri.ContainerdRunnable{}
-		err := r.InitStep(ctx, c, nodes)
-		if err != nil {
-			return nil, err
-		}
-		return r.GetActionSteps(action), nil
+// getClusterProvider returns the provider of the cluster, kubeadm by default.
+func getClusterProvider(c *v1.Cluster) (component.ClusterProvider, error) {
+	name := strutil.StringDefaultIfEmpty(v1.ClusterKubeadm, c.Provider.Name)
+	p, ok := component.LoadClusterProvider(name)
+	if !ok {
+		return nil, fmt.Errorf("no support %s cluster provider", name)
 	}
-	return nil, fmt.Errorf("no support %v type cri", c.Type)
-}
-
-func getK8sSteps(ctx context.Context, c *v1.Cluster, action v1.StepAction) ([]v1.Step, error) {
-	runnable := k8s.Runnable(*c)
-
-	return runnable.GetStep(ctx, action)
+	return p, nil
 }
 
 func getSteps(c component.Interface, action v1.StepAction) ([]v1.Step, error) {
@@ -83,7 +75,7 @@ func getSteps(c component.Interface, action v1.StepAction) ([]v1.Step, error) {
 	}
 }
 
-func (h *handler) parseOperationFromCluster(extraMetadata *component.ExtraMetadata, c *v1.Cluster, action v1.StepAction) (*v1.Operation, error) {
+func (h *handler) parseOperationFromCluster(ctx context.Context, extraMetadata *component.ExtraMetadata, c *v1.Cluster, action v1.StepAction) (*v1.Operation, error) {
 	var steps []v1.Step
 	region := extraMetadata.Masters[0].Region
 	if c.Labels == nil {
@@ -98,16 +90,20 @@ func (h *handler) parseOperationFromCluster(extraMetadata *component.ExtraMetada
 		common.LabelTopologyRegion: region,
 	}
 
-	// Container runtime should be installed on all nodes.
-	ctx := component.WithExtraMetadata(context.TODO(), *extraMetadata)
-	stepNodes := utils.UnwrapNodeList(extraMetadata.GetAllNodes())
-	cSteps, err := getCriStep(ctx, &c.ContainerRuntime, action, stepNodes)
+	ctx = component.WithExtraMetadata(ctx, *extraMetadata)
+	provider, err := getClusterProvider(c)
 	if err != nil {
 		return nil, err
 	}
-
-	// kubernetes
-	k8sSteps, err := getK8sSteps(ctx, c, action)
+	var clusterSteps []v1.Step
+	switch action {
+	case v1.ActionInstall:
+		clusterSteps, err = provider.InstallSteps(ctx, c)
+	case v1.ActionUninstall:
+		clusterSteps, err = provider.UninstallSteps(ctx, c)
+	default:
+		err = fmt.Errorf("unsupported cluster action %s", action)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -118,8 +114,7 @@ func (h *handler) parseOperationFromCluster(extraMetadata *component.ExtraMetada
 		// reverse order of the addons
 		reverseComponents(carr)
 	} else {
-		steps = append(steps, cSteps...)
-		steps = append(steps, k8sSteps...)
+		steps = append(steps, clusterSteps...)
 	}
 
 	for _, com := range carr {
@@ -149,8 +144,7 @@ func (h *handler) parseOperationFromCluster(extraMetadata *component.ExtraMetada
 	}
 
 	if action == v1.ActionUninstall {
-		steps = append(steps, k8sSteps...)
-		steps = append(steps, cSteps...)
+		steps = append(steps, clusterSteps...)
 	}
 
 	op.Steps = steps
@@ -230,14 +224,19 @@ func getRecoveryStep(c *v1.Cluster, bp *v1.BackupPoint, b *v1.Backup, restoreDir
 	}
 
 	r := k8s.Recovery{
-		StoreType:      bp.StorageType,
-		RestoreDir:     restoreDir,
-		BackupFileName: b.Status.FileName,
-		NodeNameList:   nodeNames,
-		NodeIPList:     nodeIPs,
-		BackupFileSize: b.Status.BackupFileSize,
-		BackupFileMD5:  b.Status.BackupFileMD5,
-		FileDir:        f,
+		StoreType:          bp.StorageType,
+		RestoreDir:         restoreDir,
+		BackupFileName:     b.Status.FileName,
+		NodeNameList:       nodeNames,
+		NodeIPList:         nodeIPs,
+		BackupFileSize:     b.Status.BackupFileSize,
+		BackupFileMD5:      b.Status.BackupFileMD5,
+		BackupFileChecksum: b.Status.Checksum,
+		FileDir:            f,
+		AfterRecovery:      k8s.AfterRecovery{CNI: c.CNI.Type},
+	}
+	if r.Codec, err = k8s.NewBackupCodec(bp, b); err != nil {
+		return nil, err
 	}
 
 	switch bp.StorageType {
@@ -248,6 +247,10 @@ func getRecoveryStep(c *v1.Cluster, bp *v1.BackupPoint, b *v1.Backup, restoreDir
 		r.AccessKeySecret = bp.S3Config.AccessKeySecret
 		r.Bucket = bp.S3Config.Bucket
 		r.Endpoint = bp.S3Config.Endpoint
+	case bs.SFTPStorage:
+		r.SftpConfig = bp.SftpConfig
+	case bs.WebDAVStorage:
+		r.WebDAVConfig = bp.WebDAVConfig
 	}
 
 	err = r.InitSteps(ctx)
@@ -355,6 +358,17 @@ func getActBackupStep(c *v1.Cluster, b *v1.Backup, bp *v1.BackupPoint, pNode *v1
 			BackupFileName:     b.Status.FileName,
 			BackupPointRootDir: bp.FsConfig.BackupRootDir,
 		}
+	case bs.SFTPStorage, bs.WebDAVStorage:
+		actBackup = &k8s.ActBackup{
+			StoreType:      bp.StorageType,
+			BackupFileName: b.Status.FileName,
+			SftpConfig:     bp.SftpConfig,
+			WebDAVConfig:   bp.WebDAVConfig,
+		}
+	}
+	actBackup.Metadata = k8s.NewBackupMetadata(b)
+	if actBackup.Codec, err = k8s.NewBackupCodec(bp, b); err != nil {
+		return
 	}
 
 	if err = actBackup.InitSteps(ctx); err != nil {
@@ -372,6 +386,14 @@ func (h *handler) parseUpdateCertOperation(extraMetadata *component.ExtraMetadat
 	return op, nil
 }
 
+func (h *handler) parseEtcdMaintenanceOperation(extraMetadata *component.ExtraMetadata) (*v1.Operation, error) {
+	steps, err := (&k8s.EtcdMaintenance{}).InstallSteps(utils.UnwrapNodeList(extraMetadata.Masters))
+	if err != nil {
+		return nil, err
+	}
+	return &v1.Operation{Steps: steps}, nil
+}
+
 func (h *handler) checkBackupPointInUse(backups *v1.BackupList, name string) bool {
 	for _, item := range backups.Items {
 		if item.BackupPointName == name {
@@ -380,3 +402,124 @@ func (h *handler) checkBackupPointInUse(backups *v1.BackupList, name string) boo
 	}
 	return false
 }
+
+// scanBackups returns the backups found in the backup point which are not in the existing backups.
+// A backup is found by its metadata file, the backups without a metadata file or a backup file are skipped.
+func scanBackups(ctx context.Context, store bs.BackupStore, backupPoint string, existing *v1.BackupList) ([]*v1.Backup, error) {
+	files, err := store.List(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	fileSet := sets.NewString(files...)
+	existingNames, existingFiles := sets.NewString(), sets.NewString()
+	for _, item := range existing.Items {
+		existingNames.Insert(item.Name)
+		if item.BackupPointName == backupPoint {
+			existingFiles.Insert(item.Status.FileName)
+		}
+	}
+
+	var backups []*v1.Backup
+	for _, f := range files {
+		if !bs.IsMetadataFile(f) {
+			continue
+		}
+		m, err := bs.ReadMetadata(ctx, store, f)
+		if err != nil {
+			logger.Warn("read backup metadata file failed", zap.String("backupPoint", backupPoint),
+				zap.String("file", f), zap.Error(err))
+			continue
+		}
+		// the backup file may be renamed when it is copied from another backup point
+		m.FileName = strings.TrimSuffix(f, bs.MetadataSuffix)
+		if !fileSet.Has(m.FileName) {
+			logger.Warn("backup file of the metadata file does not exist", zap.String("backupPoint", backupPoint),
+				zap.String("file", f))
+			continue
+		}
+		b := k8s.BackupFromMetadata(m, backupPoint)
+		if existingFiles.Has(b.Status.FileName) || existingNames.Has(b.Name) {
+			continue
+		}
+		existingNames.Insert(b.Name)
+		backups = append(backups, b)
+	}
+	return backups, nil
+}
+
+// validateEtcdMaintenance checks the etcd maintenance schedule of the cluster.
+func validateEtcdMaintenance(c *v1.Cluster) error {
+	if c.Etcd.Maintenance == nil || c.Etcd.Maintenance.Schedule == "" {
+		return nil
+	}
+	if c.Provider.Name != "" && c.Provider.Name != v1.ClusterKubeadm {
+		return fmt.Errorf("etcd maintenance is not supported by %s cluster", c.Provider.Name)
+	}
+	if _, err := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow).Parse(c.Etcd.Maintenance.Schedule); err != nil {
+		return fmt.Errorf("invalid etcd maintenance schedule %s: %v", c.Etcd.Maintenance.Schedule, err)
+	}
+	return nil
+}
+
+// validateCertRotationPolicy checks the certificate rotation policy of the cluster.
+func validateCertRotationPolicy(c *v1.Cluster) error {
+	p := c.CertRotationPolicy
+	if p == nil {
+		return nil
+	}
+	if c.Provider.Name != "" && c.Provider.Name != v1.ClusterKubeadm {
+		return fmt.Errorf("certificate rotation is not supported by %s cluster", c.Provider.Name)
+	}
+	// kubeadm renews the certificates for one year
+	if p.RenewBeforeDays < 0 || p.RenewBeforeDays >= 365 {
+		return fmt.Errorf("renewBeforeDays of certificate rotation policy must be in range [0, 365)")
+	}
+	if p.MaintenanceWindow != nil {
+		return p.MaintenanceWindow.Validate()
+	}
+	return nil
+}
+
+// validateUpstreamDNS checks the upstreams of the cluster CoreDNS.
+func validateUpstreamDNS(c *v1.Cluster) error {
+	if c.Networking.UpstreamDNS == nil {
+		return nil
+	}
+	return validation.ValidateForwarders(c.Networking.UpstreamDNS, field.NewPath("networking", "upstreamDNS")).ToAggregate()
+}
+
+// getClusterRestore returns the restore of the backup the new cluster is restored from.
+// The backup is restored on the new nodes, so the kubernetes version of the cluster must be the same as the backup.
+func (h *handler) getClusterRestore(ctx context.Context, c *v1.Cluster, extraMetadata *component.ExtraMetadata) (*k8s.ClusterRestore, error) {
+	if name := strutil.StringDefaultIfEmpty(v1.ClusterKubeadm, c.Provider.Name); name != v1.ClusterKubeadm {
+		return nil, apimachineryErrors.NewBadRequest(fmt.Sprintf("%s cluster provider does not support restoring from backup", name))
+	}
+	if c.RestoreFrom.Backup == "" {
+		return nil, apimachineryErrors.NewBadRequest("backup of restoreFrom is required")
+	}
+	q := query.New()
+	q.FieldSelector = fmt.Sprintf("metadata.name=%s", c.RestoreFrom.Backup)
+	backups, err := h.clusterOperator.ListBackups(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if len(backups.Items) == 0 {
+		return nil, apimachineryErrors.NewNotFound(v1.Resource("backup"), c.RestoreFrom.Backup)
+	}
+	backup := &backups.Items[0]
+	if backup.Status.ClusterBackupStatus != v1.ClusterBackupAvailable {
+		return nil, apimachineryErrors.NewBadRequest(fmt.Sprintf("backup %s is %s, only available backup can be restored", backup.Name, backup.Status.ClusterBackupStatus))
+	}
+	if c.KubernetesVersion == "" {
+		c.KubernetesVersion = backup.Status.KubernetesVersion
+	}
+	if c.KubernetesVersion != backup.Status.KubernetesVersion {
+		return nil, apimachineryErrors.NewBadRequest(fmt.Sprintf("kubernetes version %s is different from version %s of backup %s",
+			c.KubernetesVersion, backup.Status.KubernetesVersion, backup.Name))
+	}
+	bp, err := h.clusterOperator.GetBackupPoint(ctx, backup.BackupPointName, "0")
+	if err != nil {
+		return nil, err
+	}
+	return k8s.NewClusterRestore(bp, backup, extraMetadata.GetAllNodes())
+}
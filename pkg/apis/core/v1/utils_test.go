@@ -19,7 +19,10 @@
 package v1
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"reflect"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -33,6 +36,7 @@ import (
 	"github.com/kubeclipper/kubeclipper/pkg/component"
 	nfsprovisioner "github.com/kubeclipper/kubeclipper/pkg/component/nfs"
 	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	bs "github.com/kubeclipper/kubeclipper/pkg/simple/backupstore"
 )
 
 var (
@@ -176,7 +180,7 @@ func Test_parseOperationFromCluster(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := h.parseOperationFromCluster(tt.args.meta, tt.args.c, tt.args.action)
+			_, err := h.parseOperationFromCluster(context.TODO(), tt.args.meta, tt.args.c, tt.args.action)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("parseOperationFromCluster() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -185,6 +189,65 @@ func Test_parseOperationFromCluster(t *testing.T) {
 	}
 }
 
+func Test_parseOperationFromCluster_stepDependencies(t *testing.T) {
+	h := newHandler(nil, nil, nil, nil, nil, nil)
+	nfsByte, _ := json.Marshal(nfsprovisioner.NFSProvisioner{
+		ManifestsDir:     "/tmp/.nfs",
+		Namespace:        "kube-system",
+		Replicas:         1,
+		ServerAddr:       "172.20.151.105",
+		SharedPath:       "/tmp/nfs/data",
+		StorageClassName: "nfs-sc",
+		ReclaimPolicy:    "Delete",
+	})
+	c := c1.DeepCopy()
+	c.Addons = []v1.Addon{{Name: "nfs-provisioner", Version: "v1", Config: runtime.RawExtension{Raw: nfsByte}}}
+	op, err := h.parseOperationFromCluster(context.TODO(), extraMeta, c, v1.ActionInstall)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !op.HasStepDependencies() {
+		t.Fatal("create cluster operation declares no step dependencies")
+	}
+	// ancestors[i] holds the indexes of all steps which must be done before step i
+	ancestors := make([]map[int]bool, len(op.Steps))
+	envSetup, lastK8s := -1, -1
+	for i, step := range op.Steps {
+		deps, err := op.StepDependencies(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ancestors[i] = map[int]bool{}
+		for _, d := range deps {
+			ancestors[i][d] = true
+			for a := range ancestors[d] {
+				ancestors[i][a] = true
+			}
+		}
+		switch step.Name {
+		case "nodeEnvSetup":
+			envSetup = i
+		case "applyKubectlPod":
+			lastK8s = i
+		}
+	}
+	if envSetup <= 0 || lastK8s < envSetup || lastK8s == len(op.Steps)-1 {
+		t.Fatalf("unexpected steps: env setup %d, last kubernetes step %d of %d steps", envSetup, lastK8s, len(op.Steps))
+	}
+	for j := envSetup; j < len(op.Steps); j++ {
+		// the kubernetes steps run after the container runtime and the addons after kubernetes
+		before := envSetup
+		if j > lastK8s {
+			before = lastK8s + 1
+		}
+		for i := 0; i < before; i++ {
+			if !ancestors[j][i] {
+				t.Errorf("step %d %s does not wait for step %d %s", j, op.Steps[j].Name, i, op.Steps[i].Name)
+			}
+		}
+	}
+}
+
 func Test_parseOperationFromComponent(t *testing.T) {
 	type args struct {
 		action     v1.StepAction
@@ -449,3 +512,167 @@ func Test_parseActBackupSteps(t *testing.T) {
 		})
 	}
 }
+
+func Test_validateEtcdMaintenance(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		schedule string
+		wantErr  bool
+	}{
+		{
+			name: "no schedule",
+		},
+		{
+			name:     "kubeadm",
+			provider: v1.ClusterKubeadm,
+			schedule: "0 3 * * 0",
+		},
+		{
+			name:     "invalid schedule",
+			provider: v1.ClusterKubeadm,
+			schedule: "every sunday",
+			wantErr:  true,
+		},
+		{
+			name:     "k3s",
+			provider: v1.ClusterK3s,
+			schedule: "0 3 * * 0",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &v1.Cluster{}
+			c.Provider.Name = tt.provider
+			if tt.schedule != "" {
+				c.Etcd.Maintenance = &v1.EtcdMaintenance{Schedule: tt.schedule}
+			}
+			if err := validateEtcdMaintenance(c); (err != nil) != tt.wantErr {
+				t.Errorf("validateEtcdMaintenance() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_validateCertRotationPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		policy   *v1.CertRotationPolicy
+		wantErr  bool
+	}{
+		{
+			name: "no policy",
+		},
+		{
+			name:   "default policy",
+			policy: &v1.CertRotationPolicy{},
+		},
+		{
+			name: "maintenance window",
+			policy: &v1.CertRotationPolicy{
+				RenewBeforeDays:   60,
+				MaintenanceWindow: &v1.MaintenanceWindow{Start: "22:00", End: "04:00", TimeZone: "Asia/Shanghai"},
+			},
+		},
+		{
+			name:    "renew before one year",
+			policy:  &v1.CertRotationPolicy{RenewBeforeDays: 365},
+			wantErr: true,
+		},
+		{
+			name:    "invalid window time",
+			policy:  &v1.CertRotationPolicy{MaintenanceWindow: &v1.MaintenanceWindow{Start: "2am", End: "04:00"}},
+			wantErr: true,
+		},
+		{
+			name:    "empty window",
+			policy:  &v1.CertRotationPolicy{MaintenanceWindow: &v1.MaintenanceWindow{Start: "02:00", End: "02:00"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid time zone",
+			policy:  &v1.CertRotationPolicy{MaintenanceWindow: &v1.MaintenanceWindow{Start: "02:00", End: "04:00", TimeZone: "Mars/Olympus"}},
+			wantErr: true,
+		},
+		{
+			name:     "k3s",
+			provider: v1.ClusterK3s,
+			policy:   &v1.CertRotationPolicy{},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &v1.Cluster{CertRotationPolicy: tt.policy}
+			c.Provider.Name = tt.provider
+			if err := validateCertRotationPolicy(c); (err != nil) != tt.wantErr {
+				t.Errorf("validateCertRotationPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_scanBackups(t *testing.T) {
+	ctx := context.TODO()
+	store := &bs.FilesystemStore{RootDir: t.TempDir()}
+	save := func(m *bs.Metadata, withFile bool) {
+		if withFile {
+			if err := store.Save(ctx, bytes.NewReader([]byte("snapshot")), m.FileName); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := bs.SaveMetadata(ctx, store, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	save(&bs.Metadata{Name: "c1-b1", ClusterName: "c1", FileName: "c1-c1-b1", MD5: "md5-1",
+		ClusterNodes: map[string]string{"10.0.0.1": "master-1"}}, true)
+	save(&bs.Metadata{Name: "c1-b2", ClusterName: "c1", FileName: "c1-c1-b2"}, true)
+	// the backup file is deleted
+	save(&bs.Metadata{Name: "c1-b3", ClusterName: "c1", FileName: "c1-c1-b3"}, false)
+	// backup without metadata file
+	if err := store.Save(ctx, bytes.NewReader([]byte("snapshot")), "c2-c2-b1"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		existing *v1.BackupList
+		want     []string
+	}{
+		{
+			name:     "import all",
+			existing: &v1.BackupList{},
+			want:     []string{"c1-b1", "c1-b2"},
+		},
+		{
+			name: "skip existing backup",
+			existing: &v1.BackupList{Items: []v1.Backup{
+				{ObjectMeta: metav1.ObjectMeta{Name: "c1-b2"}, BackupPointName: "other", Status: v1.BackupStatus{FileName: "c1-c1-b2"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "renamed"}, BackupPointName: "bp1", Status: v1.BackupStatus{FileName: "c1-c1-b1"}},
+			}},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := scanBackups(ctx, store, "bp1", tt.existing)
+			if err != nil {
+				t.Fatalf("scanBackups() error = %v", err)
+			}
+			var names []string
+			for _, b := range got {
+				names = append(names, b.Name)
+				if b.BackupPointName != "bp1" || b.Status.ClusterBackupStatus != v1.ClusterBackupAvailable ||
+					b.Labels[common.LabelClusterName] != "c1" {
+					t.Errorf("scanBackups() backup = %+v", b)
+				}
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("scanBackups() = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
@@ -37,6 +37,7 @@ import (
 	iamv1 "github.com/kubeclipper/kubeclipper/pkg/scheme/iam/v1"
 	"github.com/kubeclipper/kubeclipper/pkg/server/request"
 	"github.com/kubeclipper/kubeclipper/pkg/server/restplus"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/cache"
 	"github.com/kubeclipper/kubeclipper/pkg/utils/netutil"
 )
 
@@ -124,6 +125,30 @@ func (h *handler) SendVerificationCode(req *restful.Request, response *restful.R
 	response.WriteHeader(http.StatusOK)
 }
 
+func (h *handler) EnrollMFAProvider(req *restful.Request, response *restful.Response) {
+	var enrollRequest mfa.UserMFAProvider
+	if err := req.ReadEntity(&enrollRequest); err != nil {
+		restplus.HandleBadRequest(response, req, err)
+		return
+	}
+	enrollRequest.Type = req.PathParameter("provider")
+	enrollment, err := h.mfaAuthenticator.Enroll(enrollRequest)
+	if err != nil {
+		switch {
+		case cache.IsNotExists(err):
+			restplus.HandleNotFound(response, req, fmt.Errorf("session not exists"))
+		case err == mfa.ErrProviderNotFound:
+			restplus.HandleBadRequest(response, req, err)
+		case err == mfa.ErrAlreadyEnrolled:
+			restplus.HandleConflict(response, req, err)
+		default:
+			restplus.HandleInternalError(response, req, err)
+		}
+		return
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusOK, enrollment)
+}
+
 func (h *handler) passwordGrant(username string, password string, req *restful.Request, response *restful.Response) {
 	authenticated, provider, err := h.passwordAuthenticator.Authenticate(username, password)
 	if err != nil {
@@ -208,7 +233,7 @@ func (h *handler) verificationCodeGrant(req *restful.Request, response *restful.
 		restplus.HandleBadRequest(response, req, err)
 		return
 	}
-	var values url.Values
+	values := url.Values{}
 	values.Set("code", code)
 	authenticated, err := h.mfaAuthenticator.Authenticate(provider, token, values)
 	if err != nil {
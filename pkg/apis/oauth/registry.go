@@ -105,6 +105,19 @@ func AddToContainer(c *restful.Container, operator iam.Operator, tokenOperator a
 		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}).
 		Metadata(restfulspec.KeyOpenAPITags, []string{AuthenticationTag}))
 
+	ws.Route(ws.POST("/mfa/{provider}/enroll").
+		Doc("enroll mfa provider").
+		Notes("Enroll the authenticator app with the token returned by login, the secret and recovery codes are only returned once.\n"+
+			"The enrollment takes effect after the first verification code is verified by the mfa grant.").
+		Param(ws.PathParameter("provider", "mfa provider type, e.g. totp")).
+		To(h.EnrollMFAProvider).
+		Reads(mfa.UserMFAProvider{}).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), mfa.Enrollment{}).
+		Returns(http.StatusNotFound, "session not exists", errors.HTTPError{}).
+		Returns(http.StatusConflict, "provider already enrolled", errors.HTTPError{}).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}).
+		Metadata(restfulspec.KeyOpenAPITags, []string{AuthenticationTag}))
+
 	ws.Route(ws.POST("/logout").
 		Doc("Logout").
 		Notes("logout").
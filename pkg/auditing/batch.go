@@ -0,0 +1,150 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package auditing
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+	"k8s.io/apiserver/pkg/apis/audit"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+)
+
+// batchWriter writes a batch of events to a sink, the batch is retried if an error is returned.
+type batchWriter interface {
+	write(events []auditv1.Event) error
+}
+
+var _ Backend = (*batchBackend)(nil)
+
+// batchBackend queues the events in a bounded queue and writes them to the sink in batches.
+// SendEvent never blocks the request, the events are dropped when the queue is full.
+type batchBackend struct {
+	name   string
+	writer batchWriter
+	opts   BatchOptions
+	queue  chan auditv1.Event
+	stopCh <-chan struct{}
+	done   chan struct{}
+}
+
+func newBatchBackend(name string, writer batchWriter, opts BatchOptions, stopCh <-chan struct{}) *batchBackend {
+	opts = opts.complete()
+	b := &batchBackend{
+		name:   name,
+		writer: writer,
+		opts:   opts,
+		queue:  make(chan auditv1.Event, opts.QueueSize),
+		stopCh: stopCh,
+		done:   make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+func (b *batchBackend) SendEvent(e audit.Event) {
+	event, err := convertEvent(&e)
+	if err != nil {
+		logger.Error("convert audit event failed", zap.String("backend", b.name), zap.Error(err))
+		return
+	}
+	select {
+	case b.queue <- *event:
+	default:
+		logger.Warn("audit queue is full, drop event", zap.String("backend", b.name), zap.String("audit_id", string(e.AuditID)))
+	}
+}
+
+func (b *batchBackend) run() {
+	defer close(b.done)
+	ticker := time.NewTicker(b.opts.MaxBatchWait)
+	defer ticker.Stop()
+	batch := make([]auditv1.Event, 0, b.opts.MaxBatchSize)
+	for {
+		select {
+		case <-b.stopCh:
+			// write the queued events without retry before exit
+			for {
+				select {
+				case e := <-b.queue:
+					batch = append(batch, e)
+					if len(batch) >= b.opts.MaxBatchSize {
+						b.flush(batch, false)
+						batch = batch[:0]
+					}
+				default:
+					if len(batch) > 0 {
+						b.flush(batch, false)
+					}
+					return
+				}
+			}
+		case e := <-b.queue:
+			batch = append(batch, e)
+			if len(batch) >= b.opts.MaxBatchSize {
+				b.flush(batch, true)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				b.flush(batch, true)
+				batch = batch[:0]
+			}
+		}
+	}
+}
+
+// flush writes the batch, the failed batch is retried with exponential backoff and dropped after the max retries.
+func (b *batchBackend) flush(batch []auditv1.Event, retry bool) {
+	backoff := b.opts.InitialBackoff
+	for i := 0; ; i++ {
+		err := b.writer.write(batch)
+		if err == nil {
+			return
+		}
+		if !retry || i >= b.opts.MaxRetries {
+			logger.Error("write audit events failed, drop them", zap.String("backend", b.name),
+				zap.Int("events", len(batch)), zap.Error(err))
+			return
+		}
+		logger.Warn("write audit events failed, retry later", zap.String("backend", b.name),
+			zap.Duration("backoff", backoff), zap.Error(err))
+		select {
+		case <-b.stopCh:
+			retry = false
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > b.opts.MaxBackoff {
+			backoff = b.opts.MaxBackoff
+		}
+	}
+}
+
+// convertEvent converts the internal event to audit.k8s.io/v1 which is understood by the receivers.
+func convertEvent(e *audit.Event) (*auditv1.Event, error) {
+	out := &auditv1.Event{}
+	if err := auditv1.Convert_audit_Event_To_v1_Event(e, out, nil); err != nil {
+		return nil, err
+	}
+	out.APIVersion = auditv1.SchemeGroupVersion.String()
+	out.Kind = "Event"
+	return out, nil
+}
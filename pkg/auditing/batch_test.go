@@ -0,0 +1,182 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package auditing
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apiserver/pkg/apis/audit"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+)
+
+type fakeBatchWriter struct {
+	mu       sync.Mutex
+	fails    int
+	block    chan struct{}
+	attempts int
+	batches  [][]auditv1.Event
+}
+
+func (w *fakeBatchWriter) write(events []auditv1.Event) error {
+	if w.block != nil {
+		<-w.block
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.attempts++
+	if w.fails > 0 {
+		w.fails--
+		return fmt.Errorf("sink unavailable")
+	}
+	w.batches = append(w.batches, append([]auditv1.Event(nil), events...))
+	return nil
+}
+
+func (w *fakeBatchWriter) sizes() []int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var sizes []int
+	for _, b := range w.batches {
+		sizes = append(sizes, len(b))
+	}
+	return sizes
+}
+
+func newTestEvent(id int) audit.Event {
+	return audit.Event{
+		AuditID:    types.UID(fmt.Sprintf("audit-%d", id)),
+		Level:      audit.LevelRequest,
+		Stage:      audit.StageResponseComplete,
+		RequestURI: "/api/core.kubeclipper.io/v1/clusters",
+		Verb:       "create",
+	}
+}
+
+func TestBatchBackend(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   BatchOptions
+		fails  int
+		events int
+		// attempts before stop, the retries are aborted once the backend is stopped
+		waitAttempts int
+		wantSizes    []int
+		wantAttempts int
+	}{
+		{
+			name:         "batch by size and flush the rest on stop",
+			opts:         BatchOptions{MaxBatchSize: 2, MaxBatchWait: time.Hour},
+			events:       5,
+			waitAttempts: 2,
+			wantSizes:    []int{2, 2, 1},
+			wantAttempts: 3,
+		},
+		{
+			name:         "retry failed batch",
+			opts:         BatchOptions{MaxBatchSize: 3, MaxBatchWait: time.Hour, InitialBackoff: time.Millisecond},
+			fails:        2,
+			events:       3,
+			waitAttempts: 3,
+			wantSizes:    []int{3},
+			wantAttempts: 3,
+		},
+		{
+			name:         "drop batch after max retries",
+			opts:         BatchOptions{MaxBatchSize: 1, MaxBatchWait: time.Hour, MaxRetries: 1, InitialBackoff: time.Millisecond},
+			fails:        2,
+			events:       2,
+			waitAttempts: 3,
+			wantSizes:    []int{1},
+			wantAttempts: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &fakeBatchWriter{fails: tt.fails}
+			stopCh := make(chan struct{})
+			b := newBatchBackend("test", w, tt.opts, stopCh)
+			for i := 0; i < tt.events; i++ {
+				b.SendEvent(newTestEvent(i))
+				// wait for the event to be consumed so that the batches are deterministic
+				waitFor(t, func() bool { return len(b.queue) == 0 })
+			}
+			waitFor(t, func() bool {
+				w.mu.Lock()
+				defer w.mu.Unlock()
+				return w.attempts >= tt.waitAttempts
+			})
+			close(stopCh)
+			<-b.done
+			if got := w.sizes(); fmt.Sprint(got) != fmt.Sprint(tt.wantSizes) {
+				t.Errorf("batch sizes = %v, want %v", got, tt.wantSizes)
+			}
+			if w.attempts != tt.wantAttempts {
+				t.Errorf("write attempts = %d, want %d", w.attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestBatchBackend_flushInterval(t *testing.T) {
+	w := &fakeBatchWriter{}
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	b := newBatchBackend("test", w, BatchOptions{MaxBatchSize: 100, MaxBatchWait: 10 * time.Millisecond}, stopCh)
+	b.SendEvent(newTestEvent(0))
+	waitFor(t, func() bool { return len(w.sizes()) == 1 })
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	e := w.batches[0][0]
+	if e.AuditID != "audit-0" || e.Kind != "Event" || e.APIVersion != "audit.k8s.io/v1" {
+		t.Errorf("unexpected event %+v", e)
+	}
+}
+
+func TestBatchBackend_queueFull(t *testing.T) {
+	w := &fakeBatchWriter{block: make(chan struct{})}
+	stopCh := make(chan struct{})
+	b := newBatchBackend("test", w, BatchOptions{QueueSize: 2, MaxBatchSize: 1, MaxBatchWait: time.Hour}, stopCh)
+	b.SendEvent(newTestEvent(0))
+	// the first event is being written, the next two fill the queue and the rest are dropped
+	waitFor(t, func() bool { return len(b.queue) == 0 })
+	for i := 1; i < 10; i++ {
+		b.SendEvent(newTestEvent(i))
+	}
+	close(w.block)
+	close(stopCh)
+	<-b.done
+	if got := w.sizes(); len(got) != 3 {
+		t.Errorf("written batches = %v, want 3 batches", got)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,171 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package auditing
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	defaultQueueSize      = 10000
+	defaultMaxBatchSize   = 100
+	defaultMaxBatchWait   = 5 * time.Second
+	defaultMaxRetries     = 5
+	defaultInitialBackoff = time.Second
+	defaultMaxBackoff     = time.Minute
+	defaultWebhookTimeout = 10 * time.Second
+	defaultEventTTL       = 30 * 24 * time.Hour
+)
+
+// Options configures the sinks of the audit events, the sinks which are not configured are disabled.
+type Options struct {
+	Webhook *WebhookOptions `json:"webhook,omitempty" yaml:"webhook,omitempty" mapstructure:"webhook"`
+	File    *FileOptions    `json:"file,omitempty" yaml:"file,omitempty" mapstructure:"file"`
+	Syslog  *SyslogOptions  `json:"syslog,omitempty" yaml:"syslog,omitempty" mapstructure:"syslog"`
+	// EventTTL is how long the audit events are kept in etcd, 0 means the events are never pruned.
+	EventTTL time.Duration `json:"eventTTL" yaml:"eventTTL" mapstructure:"eventTTL"`
+}
+
+// BatchOptions configures the bounded queue of a sink, the events are dropped when the queue is full.
+type BatchOptions struct {
+	QueueSize    int           `json:"queueSize" yaml:"queueSize" mapstructure:"queueSize"`
+	MaxBatchSize int           `json:"maxBatchSize" yaml:"maxBatchSize" mapstructure:"maxBatchSize"`
+	MaxBatchWait time.Duration `json:"maxBatchWait" yaml:"maxBatchWait" mapstructure:"maxBatchWait"`
+	// MaxRetries is the number of retries of a failed batch, the batch is dropped after that.
+	MaxRetries     int           `json:"maxRetries" yaml:"maxRetries" mapstructure:"maxRetries"`
+	InitialBackoff time.Duration `json:"initialBackoff" yaml:"initialBackoff" mapstructure:"initialBackoff"`
+	MaxBackoff     time.Duration `json:"maxBackoff" yaml:"maxBackoff" mapstructure:"maxBackoff"`
+}
+
+// WebhookOptions sends the events to an http endpoint as audit.k8s.io/v1 EventList.
+type WebhookOptions struct {
+	URL                string            `json:"url" yaml:"url" mapstructure:"url"`
+	Headers            map[string]string `json:"-" yaml:"headers" mapstructure:"headers"`
+	Timeout            time.Duration     `json:"timeout" yaml:"timeout" mapstructure:"timeout"`
+	CAFile             string            `json:"caFile" yaml:"caFile" mapstructure:"caFile"`
+	InsecureSkipVerify bool              `json:"insecureSkipVerify" yaml:"insecureSkipVerify" mapstructure:"insecureSkipVerify"`
+	BatchOptions       `json:",inline" yaml:",inline" mapstructure:",squash"`
+}
+
+// FileOptions writes the events to a rotating file, one json event per line.
+type FileOptions struct {
+	Path         string `json:"path" yaml:"path" mapstructure:"path"`
+	MaxSizeMB    int    `json:"maxSizeMB" yaml:"maxSizeMB" mapstructure:"maxSizeMB"`
+	MaxBackups   int    `json:"maxBackups" yaml:"maxBackups" mapstructure:"maxBackups"`
+	MaxAgeDays   int    `json:"maxAgeDays" yaml:"maxAgeDays" mapstructure:"maxAgeDays"`
+	Compress     bool   `json:"compress" yaml:"compress" mapstructure:"compress"`
+	BatchOptions `json:",inline" yaml:",inline" mapstructure:",squash"`
+}
+
+// SyslogOptions sends the events to a syslog server in RFC 5424 format.
+type SyslogOptions struct {
+	// Network is one of udp, tcp and tls.
+	Network string `json:"network" yaml:"network" mapstructure:"network"`
+	Address string `json:"address" yaml:"address" mapstructure:"address"`
+	// Facility of the messages, defaults to local0(16).
+	Facility           int    `json:"facility" yaml:"facility" mapstructure:"facility"`
+	AppName            string `json:"appName" yaml:"appName" mapstructure:"appName"`
+	CAFile             string `json:"caFile" yaml:"caFile" mapstructure:"caFile"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify" yaml:"insecureSkipVerify" mapstructure:"insecureSkipVerify"`
+	BatchOptions       `json:",inline" yaml:",inline" mapstructure:",squash"`
+}
+
+func NewOptions() *Options {
+	return &Options{
+		EventTTL: defaultEventTTL,
+	}
+}
+
+func (o *Options) Validate() []error {
+	if o == nil {
+		return nil
+	}
+	var errs []error
+	if o.EventTTL < 0 {
+		errs = append(errs, fmt.Errorf("audit event ttl must not be negative"))
+	}
+	if o.Webhook != nil {
+		if u, err := url.Parse(o.Webhook.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("audit webhook url %q is invalid", o.Webhook.URL))
+		}
+		errs = append(errs, o.Webhook.BatchOptions.validate("webhook")...)
+	}
+	if o.File != nil {
+		if o.File.Path == "" {
+			errs = append(errs, fmt.Errorf("audit file path is required"))
+		}
+		errs = append(errs, o.File.BatchOptions.validate("file")...)
+	}
+	if o.Syslog != nil {
+		switch o.Syslog.Network {
+		case "", "udp", "tcp", "tls":
+		default:
+			errs = append(errs, fmt.Errorf("audit syslog network %s is not supported", o.Syslog.Network))
+		}
+		if o.Syslog.Address == "" {
+			errs = append(errs, fmt.Errorf("audit syslog address is required"))
+		}
+		if o.Syslog.Facility < 0 || o.Syslog.Facility > 23 {
+			errs = append(errs, fmt.Errorf("audit syslog facility must be in range [0, 23]"))
+		}
+		errs = append(errs, o.Syslog.BatchOptions.validate("syslog")...)
+	}
+	return errs
+}
+
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	fs.DurationVar(&o.EventTTL, "audit-event-ttl", o.EventTTL, "How long the audit events are kept in etcd, 0 means the events are never pruned.")
+}
+
+func (b *BatchOptions) validate(sink string) []error {
+	var errs []error
+	if b.QueueSize < 0 || b.MaxBatchSize < 0 || b.MaxRetries < 0 {
+		errs = append(errs, fmt.Errorf("audit %s queue size, batch size and retries must not be negative", sink))
+	}
+	if b.MaxBatchWait < 0 || b.InitialBackoff < 0 || b.MaxBackoff < 0 {
+		errs = append(errs, fmt.Errorf("audit %s durations must not be negative", sink))
+	}
+	return errs
+}
+
+func (b BatchOptions) complete() BatchOptions {
+	if b.QueueSize == 0 {
+		b.QueueSize = defaultQueueSize
+	}
+	if b.MaxBatchSize == 0 {
+		b.MaxBatchSize = defaultMaxBatchSize
+	}
+	if b.MaxBatchWait == 0 {
+		b.MaxBatchWait = defaultMaxBatchWait
+	}
+	if b.MaxRetries == 0 {
+		b.MaxRetries = defaultMaxRetries
+	}
+	if b.InitialBackoff == 0 {
+		b.InitialBackoff = defaultInitialBackoff
+	}
+	if b.MaxBackoff == 0 {
+		b.MaxBackoff = defaultMaxBackoff
+	}
+	return b
+}
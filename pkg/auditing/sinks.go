@@ -0,0 +1,261 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package auditing
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+)
+
+// NewWebhookBackend returns a backend which posts the events to the webhook as audit.k8s.io/v1 EventList.
+func NewWebhookBackend(opts *WebhookOptions, stopCh <-chan struct{}) (Backend, error) {
+	w, err := newWebhookWriter(opts)
+	if err != nil {
+		return nil, err
+	}
+	return newBatchBackend("webhook", w, opts.BatchOptions, stopCh), nil
+}
+
+// NewFileBackend returns a backend which writes the events to a rotating file, one json event per line.
+func NewFileBackend(opts *FileOptions, stopCh <-chan struct{}) Backend {
+	return newBatchBackend("file", newFileWriter(opts), opts.BatchOptions, stopCh)
+}
+
+// NewSyslogBackend returns a backend which sends the events to a syslog server in RFC 5424 format.
+func NewSyslogBackend(opts *SyslogOptions, stopCh <-chan struct{}) (Backend, error) {
+	w, err := newSyslogWriter(opts)
+	if err != nil {
+		return nil, err
+	}
+	return newBatchBackend("syslog", w, opts.BatchOptions, stopCh), nil
+}
+
+func tlsConfig(caFile string, insecure bool) (*tls.Config, error) {
+	conf := &tls.Config{InsecureSkipVerify: insecure} // #nosec G402 configured by the administrator
+	if caFile == "" {
+		return conf, nil
+	}
+	ca, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("read ca file %s failed: %v", caFile, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("no certificate found in ca file %s", caFile)
+	}
+	conf.RootCAs = pool
+	return conf, nil
+}
+
+type webhookWriter struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+func newWebhookWriter(opts *WebhookOptions) (*webhookWriter, error) {
+	conf, err := tlsConfig(opts.CAFile, opts.InsecureSkipVerify)
+	if err != nil {
+		return nil, err
+	}
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = defaultWebhookTimeout
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = conf
+	return &webhookWriter{
+		url:     opts.URL,
+		headers: opts.Headers,
+		client:  &http.Client{Transport: transport, Timeout: timeout},
+	}, nil
+}
+
+func (w *webhookWriter) write(events []auditv1.Event) error {
+	list := auditv1.EventList{Items: events}
+	list.APIVersion = auditv1.SchemeGroupVersion.String()
+	list.Kind = "EventList"
+	body, err := json.Marshal(&list)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range w.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %s", resp.Status)
+	}
+	return nil
+}
+
+type fileWriter struct {
+	out io.Writer
+}
+
+func newFileWriter(opts *FileOptions) *fileWriter {
+	return &fileWriter{out: &lumberjack.Logger{
+		Filename:   opts.Path,
+		MaxSize:    opts.MaxSizeMB,
+		MaxBackups: opts.MaxBackups,
+		MaxAge:     opts.MaxAgeDays,
+		Compress:   opts.Compress,
+		LocalTime:  true,
+	}}
+}
+
+func (w *fileWriter) write(events []auditv1.Event) error {
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	for i := range events {
+		if err := enc.Encode(&events[i]); err != nil {
+			return err
+		}
+	}
+	_, err := w.out.Write(buf.Bytes())
+	return err
+}
+
+const (
+	syslogSeverityWarning = 4
+	syslogSeverityNotice  = 5
+	defaultSyslogFacility = 16 // local0
+	defaultSyslogAppName  = "kubeclipper"
+)
+
+type syslogWriter struct {
+	network  string
+	address  string
+	tls      *tls.Config
+	facility int
+	appName  string
+	hostname string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func newSyslogWriter(opts *SyslogOptions) (*syslogWriter, error) {
+	w := &syslogWriter{
+		network:  opts.Network,
+		address:  opts.Address,
+		facility: opts.Facility,
+		appName:  opts.AppName,
+	}
+	if w.network == "" {
+		w.network = "udp"
+	}
+	if w.facility == 0 {
+		w.facility = defaultSyslogFacility
+	}
+	if w.appName == "" {
+		w.appName = defaultSyslogAppName
+	}
+	if w.network == "tls" {
+		conf, err := tlsConfig(opts.CAFile, opts.InsecureSkipVerify)
+		if err != nil {
+			return nil, err
+		}
+		w.tls = conf
+	}
+	w.hostname, _ = os.Hostname()
+	if w.hostname == "" {
+		w.hostname = "-"
+	}
+	return w, nil
+}
+
+func (w *syslogWriter) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: defaultWebhookTimeout}
+	if w.network == "tls" {
+		return tls.DialWithDialer(dialer, "tcp", w.address, w.tls)
+	}
+	return dialer.Dial(w.network, w.address)
+}
+
+func (w *syslogWriter) write(events []auditv1.Event) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		conn, err := w.dial()
+		if err != nil {
+			return err
+		}
+		w.conn = conn
+	}
+	for i := range events {
+		msg, err := w.format(&events[i])
+		if err != nil {
+			return err
+		}
+		if w.network != "udp" {
+			// octet counting framing of RFC 6587
+			msg = append([]byte(fmt.Sprintf("%d ", len(msg))), msg...)
+		}
+		if _, err = w.conn.Write(msg); err != nil {
+			// reconnect on the next write
+			_ = w.conn.Close()
+			w.conn = nil
+			return err
+		}
+	}
+	return nil
+}
+
+// format formats the event as RFC 5424 message, the json event is the message body.
+func (w *syslogWriter) format(e *auditv1.Event) ([]byte, error) {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	severity := syslogSeverityNotice
+	if e.ResponseStatus != nil && e.ResponseStatus.Code >= http.StatusBadRequest {
+		severity = syslogSeverityWarning
+	}
+	ts := e.StageTimestamp.Time
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	header := fmt.Sprintf("<%d>1 %s %s %s %d audit - ", w.facility*8+severity,
+		ts.Format(time.RFC3339Nano), w.hostname, w.appName, os.Getpid())
+	return append([]byte(header), body...), nil
+}
@@ -0,0 +1,200 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package auditing
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+)
+
+func testV1Events(t *testing.T, n int) []auditv1.Event {
+	var events []auditv1.Event
+	for i := 0; i < n; i++ {
+		e := newTestEvent(i)
+		out, err := convertEvent(&e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, *out)
+	}
+	return events
+}
+
+func TestWebhookWriter(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "accepted", status: http.StatusAccepted},
+		{name: "server error", status: http.StatusServiceUnavailable, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got auditv1.EventList
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "Bearer secret" || r.Header.Get("Content-Type") != "application/json" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+			w, err := newWebhookWriter(&WebhookOptions{URL: srv.URL, Headers: map[string]string{"Authorization": "Bearer secret"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = w.write(testV1Events(t, 2))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("write() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.Kind != "EventList" || len(got.Items) != 2 || got.Items[1].AuditID != "audit-1" {
+				t.Errorf("unexpected event list %+v", got)
+			}
+		})
+	}
+}
+
+func TestFileWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	w := newFileWriter(&FileOptions{Path: path})
+	if err := w.write(testV1Events(t, 2)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.write(testV1Events(t, 1)); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3", len(lines))
+	}
+	for i, want := range []string{"audit-0", "audit-1", "audit-0"} {
+		var e auditv1.Event
+		if err = json.Unmarshal([]byte(lines[i]), &e); err != nil {
+			t.Fatalf("line %d is not json: %v", i, err)
+		}
+		if string(e.AuditID) != want {
+			t.Errorf("line %d audit id = %s, want %s", i, e.AuditID, want)
+		}
+	}
+}
+
+var rfc5424 = regexp.MustCompile(`^<(\d+)>1 (\S+) (\S+) kubeclipper (\d+) audit - (\{.*\})$`)
+
+func TestSyslogWriter_format(t *testing.T) {
+	w, err := newSyslogWriter(&SyslogOptions{Address: "127.0.0.1:514"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		code    int32
+		wantPRI string
+	}{
+		{name: "success is notice", code: http.StatusOK, wantPRI: "133"},
+		{name: "failure is warning", code: http.StatusForbidden, wantPRI: "132"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := testV1Events(t, 1)[0]
+			e.ResponseStatus = &metav1.Status{Code: tt.code}
+			msg, err := w.format(&e)
+			if err != nil {
+				t.Fatal(err)
+			}
+			m := rfc5424.FindStringSubmatch(string(msg))
+			if m == nil {
+				t.Fatalf("message %q is not RFC 5424", msg)
+			}
+			if m[1] != tt.wantPRI {
+				t.Errorf("PRI = %s, want %s", m[1], tt.wantPRI)
+			}
+		})
+	}
+}
+
+func TestSyslogWriter_tcp(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		var msgs []string
+		for i := 0; i < 2; i++ {
+			// octet counting framing: MSG-LEN SP SYSLOG-MSG
+			l, err := r.ReadString(' ')
+			if err != nil {
+				break
+			}
+			n, _ := strconv.Atoi(strings.TrimSpace(l))
+			buf := make([]byte, n)
+			if _, err = io.ReadFull(r, buf); err != nil {
+				break
+			}
+			msgs = append(msgs, string(buf))
+		}
+		received <- msgs
+	}()
+	w, err := newSyslogWriter(&SyslogOptions{Network: "tcp", Address: ln.Addr().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = w.write(testV1Events(t, 2)); err != nil {
+		t.Fatal(err)
+	}
+	msgs := <-received
+	if len(msgs) != 2 {
+		t.Fatalf("received %d messages, want 2", len(msgs))
+	}
+	for i, msg := range msgs {
+		if m := rfc5424.FindStringSubmatch(msg); m == nil || !strings.Contains(m[5], fmt.Sprintf(`"auditID":"audit-%d"`, i)) {
+			t.Errorf("unexpected message %q", msg)
+		}
+	}
+}
@@ -28,12 +28,15 @@ import (
 	"github.com/kubeclipper/kubeclipper/pkg/utils/hashutil"
 
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	"github.com/kubeclipper/kubeclipper/pkg/query"
 	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
 
 	authuser "k8s.io/apiserver/pkg/authentication/user"
 
+	"github.com/kubeclipper/kubeclipper/pkg/authentication/identityprovider"
+	"github.com/kubeclipper/kubeclipper/pkg/authentication/oauth"
 	authoptions "github.com/kubeclipper/kubeclipper/pkg/authentication/options"
 	"github.com/kubeclipper/kubeclipper/pkg/models/iam"
 )
@@ -90,9 +93,75 @@ func (p *passwordAuthenticator) Authenticate(username, password string) (authuse
 		}, "", nil
 	}
 
+	// the user without password is authenticated by the password identity providers
+	return p.authenticateByProviders(username, password)
+}
+
+// authenticateByProviders tries the password identity providers in order, the user is created
+// when it logins for the first time, and the groups of the identity are synced to the user groups
+// on every login, the token authenticator reads the groups from the user.
+func (p *passwordAuthenticator) authenticateByProviders(username, password string) (authuser.Info, string, error) {
+	if p.authOptions == nil || p.authOptions.OAuthOptions == nil {
+		return nil, "", ErrUserNotExist
+	}
+	for _, providerOptions := range p.authOptions.OAuthOptions.IdentityProviders {
+		idp, err := identityprovider.GetPasswordProvider(providerOptions.Name)
+		if err != nil {
+			continue
+		}
+		authenticated, err := idp.Authenticate(username, password)
+		if err != nil {
+			if err == identityprovider.ErrUserNotFound {
+				continue
+			}
+			if err == identityprovider.ErrInvalidCredentials {
+				return nil, providerOptions.Name, ErrIncorrectPassword
+			}
+			return nil, providerOptions.Name, err
+		}
+		mappedUser, err := findMappedUser(p.iamOperator, providerOptions.Name, authenticated.GetUserID())
+		if mappedUser == nil {
+			if providerOptions.MappingMethod != oauth.MappingMethodAuto {
+				return nil, providerOptions.Name, fmt.Errorf("provider %s not support mapping method %s yet", providerOptions.Name, providerOptions.MappingMethod)
+			}
+			if mappedUser, err = p.iamOperator.CreateUser(context.TODO(), convertUser(authenticated, providerOptions.Name)); err != nil {
+				return nil, providerOptions.Name, err
+			}
+		}
+		if mappedUser.Status.State == nil || *mappedUser.Status.State != v12.UserActive {
+			return nil, providerOptions.Name, ErrAccountIsNotActive
+		}
+		identity, ok := authenticated.(identityprovider.GroupIdentity)
+		if ok {
+			if mappedUser, err = syncUserGroups(p.iamOperator, mappedUser, identity.GetGroups()); err != nil {
+				return nil, providerOptions.Name, err
+			}
+		}
+		info := &authuser.DefaultInfo{
+			Name: mappedUser.Name,
+			Extra: map[string][]string{
+				"phone": {mappedUser.Spec.Phone},
+				"email": {mappedUser.Spec.Email},
+			},
+		}
+		if ok {
+			info.Groups = mappedUser.Spec.Groups
+		}
+		return info, providerOptions.Name, nil
+	}
 	return nil, "", ErrUserNotExist
 }
 
+// syncUserGroups updates the groups of the user to the groups of its identity when they differ.
+func syncUserGroups(operator iam.Operator, u *v12.User, groups []string) (*v12.User, error) {
+	if sets.NewString(u.Spec.Groups...).Equal(sets.NewString(groups...)) {
+		return u, nil
+	}
+	updated := u.DeepCopy()
+	updated.Spec.Groups = groups
+	return operator.UpdateUser(context.TODO(), updated)
+}
+
 func (p *passwordAuthenticator) findUser(username string) (*v12.User, error) {
 	if _, err := mail.ParseAddress(username); err != nil {
 		return p.iamOperator.GetUserEx(context.TODO(), username, "0", false, false)
@@ -19,6 +19,7 @@
 package auth
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"testing"
@@ -26,11 +27,19 @@ import (
 	"github.com/kubeclipper/kubeclipper/pkg/utils/hashutil"
 
 	"github.com/golang/mock/gomock"
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apiserver/pkg/authentication/user"
 	authuser "k8s.io/apiserver/pkg/authentication/user"
 
+	"github.com/kubeclipper/kubeclipper/pkg/authentication/identityprovider"
+	"github.com/kubeclipper/kubeclipper/pkg/authentication/oauth"
+	authoptions "github.com/kubeclipper/kubeclipper/pkg/authentication/options"
+	"github.com/kubeclipper/kubeclipper/pkg/authentication/token"
+	"github.com/kubeclipper/kubeclipper/pkg/authorization/authorizer"
+	"github.com/kubeclipper/kubeclipper/pkg/authorization/rbac"
 	iammock "github.com/kubeclipper/kubeclipper/pkg/models/iam/mock"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
 	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/iam/v1"
 )
 
@@ -138,3 +147,207 @@ func Test_passwordAuthenticator_Authenticate(t *testing.T) {
 		})
 	}
 }
+
+type fakeIdentity struct {
+	name   string
+	groups []string
+}
+
+func (f *fakeIdentity) GetUserID() string   { return f.name }
+func (f *fakeIdentity) GetUsername() string { return f.name }
+func (f *fakeIdentity) GetEmail() string    { return f.name + "@example.org" }
+func (f *fakeIdentity) GetGroups() []string { return f.groups }
+
+type fakePasswordProvider struct{}
+
+func (f *fakePasswordProvider) Authenticate(username string, password string) (identityprovider.Identity, error) {
+	if username != "ldap-user" {
+		return nil, identityprovider.ErrUserNotFound
+	}
+	if password != "ldap-password" {
+		return nil, identityprovider.ErrInvalidCredentials
+	}
+	return &fakeIdentity{name: username, groups: []string{"developers"}}, nil
+}
+
+type fakePasswordProviderFactory struct{}
+
+func (f *fakePasswordProviderFactory) Type() string { return "FakePassword" }
+
+func (f *fakePasswordProviderFactory) Create(options oauth.DynamicOptions) (identityprovider.PasswordProvider, error) {
+	return &fakePasswordProvider{}, nil
+}
+
+func Test_passwordAuthenticator_authenticateByProviders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	identityprovider.RegisterPasswordProvider(&fakePasswordProviderFactory{})
+	providers := []oauth.IdentityProviderOptions{
+		{Name: "fake-ldap", Type: "FakePassword", MappingMethod: oauth.MappingMethodAuto},
+	}
+	if err := identityprovider.SetupWithOptions(providers); err != nil {
+		t.Fatal(err)
+	}
+	options := authoptions.NewAuthenticateOptions()
+	options.OAuthOptions.IdentityProviders = providers
+
+	state := v1.UserActive
+	iamMockOpera := iammock.NewMockOperator(ctrl)
+	iamMockOpera.EXPECT().ListUsers(gomock.Any(), gomock.Any()).Return(&v1.UserList{}, nil).AnyTimes()
+	iamMockOpera.EXPECT().CreateUser(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, u *v1.User) (*v1.User, error) {
+		if u.Labels[common.LabelIDP] != "fake-ldap" || u.Labels[common.LabelOriginUID] != "ldap-user" {
+			t.Errorf("unexpected labels of provisioned user: %v", u.Labels)
+		}
+		u.Status.State = &state
+		return u, nil
+	}).Times(1)
+
+	pwdAuth := &passwordAuthenticator{
+		iamOperator: iamMockOpera,
+		authOptions: options,
+	}
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		want     user.Info
+		wantErr  error
+	}{
+		{
+			name:     "user not found",
+			username: "other",
+			password: "ldap-password",
+			wantErr:  ErrUserNotExist,
+		},
+		{
+			name:     "incorrect password",
+			username: "ldap-user",
+			password: "wrong",
+			wantErr:  ErrIncorrectPassword,
+		},
+		{
+			name:     "provision user with groups",
+			username: "ldap-user",
+			password: "ldap-password",
+			want: &authuser.DefaultInfo{
+				Name:   "ldap-user",
+				Groups: []string{"developers"},
+				Extra: map[string][]string{
+					"phone": {""},
+					"email": {"ldap-user@example.org"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := pwdAuth.authenticateByProviders(tt.username, tt.password)
+			if err != tt.wantErr {
+				t.Fatalf("authenticateByProviders() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("authenticateByProviders() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ldapLoginGroupAuthorization(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	identityprovider.RegisterPasswordProvider(&fakePasswordProviderFactory{})
+	providers := []oauth.IdentityProviderOptions{
+		{Name: "fake-ldap-groups", Type: "FakePassword", MappingMethod: oauth.MappingMethodAuto},
+	}
+	if err := identityprovider.SetupWithOptions(providers); err != nil {
+		t.Fatal(err)
+	}
+	options := authoptions.NewAuthenticateOptions()
+	options.OAuthOptions.IdentityProviders = providers
+
+	// the user was provisioned before its ldap groups changed
+	state := v1.UserActive
+	stored := &v1.User{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "ldap-user",
+			Labels: map[string]string{
+				common.LabelIDP:       "fake-ldap-groups",
+				common.LabelOriginUID: "ldap-user",
+			},
+		},
+		Spec:   v1.UserSpec{Groups: []string{"staff"}},
+		Status: v1.UserStatus{State: &state},
+	}
+	iamMockOpera := iammock.NewMockOperator(ctrl)
+	iamMockOpera.EXPECT().ListUsers(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, _ interface{}) (*v1.UserList, error) {
+		return &v1.UserList{Items: []v1.User{*stored.DeepCopy()}}, nil
+	}).AnyTimes()
+	iamMockOpera.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, u *v1.User) (*v1.User, error) {
+		stored = u.DeepCopy()
+		return u, nil
+	}).Times(1)
+	iamMockOpera.EXPECT().GetUserEx(gomock.Any(), gomock.Eq("ldap-user"), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ interface{}, _ string, _ string, _ bool, _ bool) (*v1.User, error) {
+			return stored.DeepCopy(), nil
+		}).AnyTimes()
+	issueToTestPrepare(iamMockOpera)
+	verifyTestPrepare(iamMockOpera)
+	iamMockOpera.EXPECT().ListRoleBindings(gomock.Any(), gomock.Any()).Return(&v1.GlobalRoleBindingList{
+		Items: []v1.GlobalRoleBinding{{
+			ObjectMeta: metav1.ObjectMeta{Name: "developers-view"},
+			RoleRef:    rbacv1.RoleRef{APIGroup: "core.kubeclipper.io", Kind: "GlobalRole", Name: "cluster-view"},
+			Subjects:   []rbacv1.Subject{{APIGroup: rbacv1.GroupName, Kind: rbacv1.GroupKind, Name: "developers"}},
+		}},
+	}, nil).AnyTimes()
+	iamMockOpera.EXPECT().GetRoleEx(gomock.Any(), gomock.Eq("cluster-view"), gomock.Any()).Return(&v1.GlobalRole{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-view"},
+		Rules: []rbacv1.PolicyRule{{
+			APIGroups: []string{"core.kubeclipper.io"},
+			Resources: []string{"clusters"},
+			Verbs:     []string{"get", "list"},
+		}},
+	}, nil).AnyTimes()
+
+	pwdAuth := &passwordAuthenticator{iamOperator: iamMockOpera, authOptions: options}
+	info, _, err := pwdAuth.authenticateByProviders("ldap-user", "ldap-password")
+	if err != nil {
+		t.Fatalf("authenticateByProviders() error = %v", err)
+	}
+	if !reflect.DeepEqual(stored.Spec.Groups, []string{"developers"}) {
+		t.Fatalf("user groups = %v, want the ldap groups", stored.Spec.Groups)
+	}
+	tokenOpera := newTokenOpera(iamMockOpera)
+	tokenOpera.issuer = token.NewTokenIssuer(tokenOpera.options.JwtSecret, 0)
+	issued, err := tokenOpera.IssueTo(info)
+	if err != nil {
+		t.Fatalf("IssueTo() error = %v", err)
+	}
+	resp, ok, err := NewTokenAuthenticator(iamMockOpera, tokenOpera).AuthenticateToken(context.TODO(), issued.AccessToken)
+	if err != nil || !ok {
+		t.Fatalf("AuthenticateToken() = %v, %v", ok, err)
+	}
+
+	authz := rbac.NewAuthorizer(iamMockOpera)
+	for verb, want := range map[string]authorizer.Decision{
+		"list":   authorizer.DecisionAllow,
+		"delete": authorizer.DecisionNoOpinion,
+	} {
+		got, _, err := authz.Authorize(&authorizer.AttributesRecord{
+			User:            resp.User,
+			Verb:            verb,
+			APIGroup:        "core.kubeclipper.io",
+			APIVersion:      "v1",
+			Resource:        "clusters",
+			ResourceRequest: true,
+		})
+		if err != nil {
+			t.Fatalf("Authorize() error = %v", err)
+		}
+		if got != want {
+			t.Errorf("Authorize() %s clusters = %v, want %v", verb, got, want)
+		}
+	}
+}
@@ -54,5 +54,6 @@ type MFAAuthenticator interface {
 	Enabled() bool
 	Providers(info user.Info) (UserMFAProviders, error)
 	ProviderRequest(provider mfa.UserMFAProvider) error
+	Enroll(provider mfa.UserMFAProvider) (*mfa.Enrollment, error)
 	Authenticate(provider string, token string, req url.Values) (user.Info, error)
 }
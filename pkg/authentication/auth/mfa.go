@@ -20,6 +20,7 @@ package auth
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"strconv"
@@ -67,7 +68,7 @@ func (m *mfaAuthenticator) Providers(info user.Info) (UserMFAProviders, error) {
 	}
 	token := uuid.New().String()
 	if err := m.cache.Set(fmt.Sprintf("mfa-%s", token),
-		marshalTokenValue(info.GetName(), 0), 15*time.Minute); err != nil {
+		marshalTokenValue(info.GetName(), 0, info.GetGroups()), 15*time.Minute); err != nil {
 		return result, err
 	}
 	for _, providerType := range m.opts.MFAProviders {
@@ -89,7 +90,7 @@ func (m *mfaAuthenticator) ProviderRequest(provider mfa.UserMFAProvider) error {
 	if err != nil {
 		return err
 	}
-	usrName, _, err := parseTokenValue(value)
+	usrName, _, _, err := parseTokenValue(value)
 	if err != nil {
 		return err
 	}
@@ -108,6 +109,26 @@ func (m *mfaAuthenticator) ProviderRequest(provider mfa.UserMFAProvider) error {
 	})
 }
 
+func (m *mfaAuthenticator) Enroll(provider mfa.UserMFAProvider) (*mfa.Enrollment, error) {
+	value, err := m.cache.Get(fmt.Sprintf("mfa-%s", provider.Token))
+	if err != nil {
+		return nil, err
+	}
+	p, err := mfa.GetProvider(provider.Type)
+	if err != nil {
+		return nil, err
+	}
+	enroller, ok := p.(mfa.Enroller)
+	if !ok {
+		return nil, fmt.Errorf("mfa provider %s does not support enrollment", provider.Type)
+	}
+	usrName, _, _, err := parseTokenValue(value)
+	if err != nil {
+		return nil, err
+	}
+	return enroller.Enroll(&user.DefaultInfo{Name: usrName})
+}
+
 func (m *mfaAuthenticator) Authenticate(provider string, token string, values url.Values) (user.Info, error) {
 	tokenKey := fmt.Sprintf("mfa-%s", token)
 	value, err := m.cache.Get(tokenKey)
@@ -119,7 +140,7 @@ func (m *mfaAuthenticator) Authenticate(provider string, token string, values ur
 		return nil, err
 	}
 
-	usrName, failures, err := parseTokenValue(value)
+	usrName, failures, groups, err := parseTokenValue(value)
 	if err != nil {
 		return nil, err
 	}
@@ -130,7 +151,7 @@ func (m *mfaAuthenticator) Authenticate(provider string, token string, values ur
 	info := &user.DefaultInfo{
 		Name:   usrName,
 		UID:    "",
-		Groups: nil,
+		Groups: groups,
 		Extra: map[string][]string{
 			"phone": {usr.Spec.Phone},
 			"email": {usr.Spec.Email},
@@ -143,7 +164,7 @@ func (m *mfaAuthenticator) Authenticate(provider string, token string, values ur
 				logger.Errorf("cache remove key:%s", err)
 			}
 		} else {
-			if e := m.cache.Update(tokenKey, marshalTokenValue(usrName, failures)); e != nil {
+			if e := m.cache.Update(tokenKey, marshalTokenValue(usrName, failures, groups)); e != nil {
 				logger.Errorf("cache Update key:%s", err)
 			}
 		}
@@ -152,19 +173,30 @@ func (m *mfaAuthenticator) Authenticate(provider string, token string, values ur
 	return info, nil
 }
 
-func marshalTokenValue(username string, count int) string {
-	return fmt.Sprintf("%s:%d", username, count)
-
+// marshalTokenValue returns the value of the mfa token, the groups of the identity provider are kept in the
+// value to issue the token after the verification.
+func marshalTokenValue(username string, count int, groups []string) string {
+	value := fmt.Sprintf("%s:%d", username, count)
+	if len(groups) == 0 {
+		return value
+	}
+	data, _ := json.Marshal(groups)
+	return fmt.Sprintf("%s:%s", value, data)
 }
 
-func parseTokenValue(value string) (username string, count int, err error) {
-	parts := strings.Split(value, ":")
+func parseTokenValue(value string) (username string, count int, groups []string, err error) {
+	parts := strings.SplitN(value, ":", 3)
 	if len(parts) < 2 {
-		return "", 0, fmt.Errorf("invalid token value")
+		return "", 0, nil, fmt.Errorf("invalid token value")
 	}
 	count, err = strconv.Atoi(parts[1])
 	if err != nil {
-		return "", 0, fmt.Errorf("invalid token value: %w", err)
+		return "", 0, nil, fmt.Errorf("invalid token value: %w", err)
+	}
+	if len(parts) == 3 {
+		if err = json.Unmarshal([]byte(parts[2]), &groups); err != nil {
+			return "", 0, nil, fmt.Errorf("invalid token value: %w", err)
+		}
 	}
-	return parts[0], count, nil
+	return parts[0], count, groups, nil
 }
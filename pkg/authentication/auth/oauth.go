@@ -71,7 +71,7 @@ func (o *oauthAuthenticator) Authenticate(provider string, req *http.Request) (u
 	if err != nil {
 		return nil, "", err
 	}
-	mappedUser, err := o.findMappedUser(providerOptions.Name, authenticated.GetUserID())
+	mappedUser, err := findMappedUser(o.iamOperator, providerOptions.Name, authenticated.GetUserID())
 	if mappedUser == nil && providerOptions.MappingMethod == oauth.MappingMethodAuto {
 		mappedUser, err = o.iamOperator.CreateUser(context.TODO(), convertUser(authenticated, providerOptions.Name))
 		if err != nil {
@@ -86,8 +86,8 @@ func (o *oauthAuthenticator) Authenticate(provider string, req *http.Request) (u
 	return nil, "", fmt.Errorf("user auto mapping failed, wrap err %v", err)
 }
 
-func (o *oauthAuthenticator) findMappedUser(name string, id string) (*v1.User, error) {
-	users, err := o.iamOperator.ListUsers(context.TODO(), &query.Query{
+func findMappedUser(operator iam.Operator, name string, id string) (*v1.User, error) {
+	users, err := operator.ListUsers(context.TODO(), &query.Query{
 		ResourceVersion:      "0",
 		LabelSelector:        fmt.Sprintf("%s=%s,%s=%s", common.LabelIDP, name, common.LabelOriginUID, id),
 		ResourceVersionMatch: query.ResourceVersionMatchNotOlderThan,
@@ -103,7 +103,7 @@ func (o *oauthAuthenticator) findMappedUser(name string, id string) (*v1.User, e
 
 func convertUser(identity identityprovider.Identity, idp string) *v1.User {
 	stateActive := v1.UserActive
-	return &v1.User{
+	u := &v1.User{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: strings.ToLower(identity.GetUsername()),
 			Labels: map[string]string{
@@ -119,4 +119,8 @@ func convertUser(identity identityprovider.Identity, idp string) *v1.User {
 			State: &stateActive,
 		},
 	}
+	if identity, ok := identity.(identityprovider.GroupIdentity); ok {
+		u.Spec.Groups = identity.GetGroups()
+	}
+	return u
 }
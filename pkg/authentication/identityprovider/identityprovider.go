@@ -26,23 +26,37 @@ import (
 )
 
 var (
-	oauthProviderFactories = make(map[string]OAuthProviderFactory)
-	oauthProviders         = make(map[string]OAuthProvider)
+	oauthProviderFactories    = make(map[string]OAuthProviderFactory)
+	oauthProviders            = make(map[string]OAuthProvider)
+	passwordProviderFactories = make(map[string]PasswordProviderFactory)
+	passwordProviders         = make(map[string]PasswordProvider)
 )
 
 var (
 	errIdentityProviderNotFound = errors.New("identity provider not found")
+	// ErrUserNotFound is returned by PasswordProvider when the user does not exist in the identity provider
+	ErrUserNotFound = errors.New("user not found in identity provider")
+	// ErrInvalidCredentials is returned by PasswordProvider when the password is incorrect
+	ErrInvalidCredentials = errors.New("invalid credentials")
 )
 
 // SetupWithOptions will verify the configuration and initialize the identityProviders
 func SetupWithOptions(options []oauth.IdentityProviderOptions) error {
 	for _, o := range options {
-		if oauthProviders[o.Name] != nil {
+		if oauthProviders[o.Name] != nil || passwordProviders[o.Name] != nil {
 			return fmt.Errorf("duplicate identity provider found: %s", o.Name)
 		}
-		if oauthProviderFactories[o.Type] == nil {
+		if oauthProviderFactories[o.Type] == nil && passwordProviderFactories[o.Type] == nil {
 			return fmt.Errorf("identity provider %s with type %s is not supported", o.Name, o.Type)
 		}
+		if factory, ok := passwordProviderFactories[o.Type]; ok {
+			if provider, err := factory.Create(o.Provider); err != nil {
+				logger.Errorf("failed to create identity provider %s: %s", o.Name, err)
+			} else {
+				passwordProviders[o.Name] = provider
+				logger.Debugf("create identity provider %s successfully", o.Name)
+			}
+		}
 		if factory, ok := oauthProviderFactories[o.Type]; ok {
 			if provider, err := factory.Create(o.Provider); err != nil {
 				// don’t return errors, decoupling external dependencies
@@ -68,3 +82,16 @@ func GetOAuthProvider(providerName string) (OAuthProvider, error) {
 func RegisterOAuthProvider(factory OAuthProviderFactory) {
 	oauthProviderFactories[factory.Type()] = factory
 }
+
+// GetPasswordProvider returns PasswordProvider with given name
+func GetPasswordProvider(providerName string) (PasswordProvider, error) {
+	if provider, ok := passwordProviders[providerName]; ok {
+		return provider, nil
+	}
+	return nil, errIdentityProviderNotFound
+}
+
+// RegisterPasswordProvider register PasswordProviderFactory with the specified type
+func RegisterPasswordProvider(factory PasswordProviderFactory) {
+	passwordProviderFactories[factory.Type()] = factory
+}
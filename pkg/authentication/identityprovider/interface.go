@@ -38,3 +38,20 @@ type OAuthProviderFactory interface {
 	Type() string
 	Create(options oauth.DynamicOptions) (OAuthProvider, error)
 }
+
+// GroupIdentity is an Identity which belongs to groups of the identity provider.
+type GroupIdentity interface {
+	Identity
+	GetGroups() []string
+}
+
+// PasswordProvider authenticates the username and password with the identity provider,
+// it returns ErrUserNotFound if the user does not exist and ErrInvalidCredentials if the password is incorrect.
+type PasswordProvider interface {
+	Authenticate(username string, password string) (Identity, error)
+}
+
+type PasswordProviderFactory interface {
+	Type() string
+	Create(options oauth.DynamicOptions) (PasswordProvider, error)
+}
@@ -0,0 +1,304 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package ldap
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/kubeclipper/kubeclipper/pkg/authentication/identityprovider"
+	"github.com/kubeclipper/kubeclipper/pkg/authentication/oauth"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+)
+
+func init() {
+	identityprovider.RegisterPasswordProvider(&ldapProviderFactory{})
+}
+
+const (
+	defaultReadTimeout          = 15 * time.Second
+	defaultLoginAttribute       = "uid"
+	defaultMailAttribute        = "mail"
+	defaultUserSearchFilter     = "(objectClass=person)"
+	defaultGroupSearchFilter    = "(objectClass=groupOfNames)"
+	defaultGroupMemberAttribute = "member"
+	defaultGroupNameAttribute   = "cn"
+)
+
+var _ identityprovider.PasswordProvider = (*ldapProvider)(nil)
+
+type ldapProvider struct {
+	// URL of the LDAP server, e.g. ldap://ldap.example.com:389 or ldaps://ad.example.com:636
+	URL string `json:"url" yaml:"url"`
+
+	// StartTLS upgrades the plain connection to TLS
+	StartTLS bool `json:"startTLS" yaml:"startTLS"`
+
+	// Used to turn off TLS certificate checks
+	InsecureSkipVerify bool `json:"insecureSkipVerify" yaml:"insecureSkipVerify"`
+
+	// Path to a trusted root certificate file
+	RootCA string `json:"rootCA" yaml:"rootCA"`
+
+	// Base64 encoded PEM data of the trusted root certificates
+	RootCAData string `json:"rootCAData" yaml:"rootCAData"`
+
+	// Timeout of the LDAP requests, defaults to 15s
+	ReadTimeout time.Duration `json:"readTimeout" yaml:"readTimeout"`
+
+	// The DN and password used to search users and groups, anonymous bind is used if it is empty
+	BindDN       string `json:"bindDN" yaml:"bindDN"`
+	BindPassword string `json:"-" yaml:"bindPassword"`
+
+	// Base DN and filter of the user search, the filter is joined with the login attribute.
+	// e.g. (&(objectClass=person)(uid=<username>))
+	UserSearchBase   string `json:"userSearchBase" yaml:"userSearchBase"`
+	UserSearchFilter string `json:"userSearchFilter" yaml:"userSearchFilter"`
+
+	// Attribute of the username, e.g. uid for OpenLDAP and sAMAccountName for Active Directory
+	LoginAttribute string `json:"loginAttribute" yaml:"loginAttribute"`
+
+	// Attribute of the user email
+	MailAttribute string `json:"mailAttribute" yaml:"mailAttribute"`
+
+	// Base DN and filter of the group search, the groups are not searched if the base DN is empty.
+	// The filter is joined with the member attribute, e.g. (&(objectClass=groupOfNames)(member=<user dn>))
+	GroupSearchBase   string `json:"groupSearchBase" yaml:"groupSearchBase"`
+	GroupSearchFilter string `json:"groupSearchFilter" yaml:"groupSearchFilter"`
+
+	// Attribute of the group which contains the member DN
+	GroupMemberAttribute string `json:"groupMemberAttribute" yaml:"groupMemberAttribute"`
+
+	// Attribute of the group name, the names are used as the group subjects of the role bindings
+	GroupNameAttribute string `json:"groupNameAttribute" yaml:"groupNameAttribute"`
+
+	tlsConfig *tls.Config
+}
+
+type ldapIdentity struct {
+	Username string
+	Email    string
+	Groups   []string
+}
+
+func (l *ldapIdentity) GetUserID() string {
+	return l.Username
+}
+
+func (l *ldapIdentity) GetUsername() string {
+	return l.Username
+}
+
+func (l *ldapIdentity) GetEmail() string {
+	return l.Email
+}
+
+func (l *ldapIdentity) GetGroups() []string {
+	return l.Groups
+}
+
+type ldapProviderFactory struct {
+}
+
+func (f *ldapProviderFactory) Type() string {
+	return "LDAP"
+}
+
+func (f *ldapProviderFactory) Create(options oauth.DynamicOptions) (identityprovider.PasswordProvider, error) {
+	var idp ldapProvider
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.StringToTimeDurationHookFunc(),
+		Result:     &idp,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = decoder.Decode(options); err != nil {
+		return nil, err
+	}
+	if idp.URL == "" {
+		return nil, fmt.Errorf("ldap url is required")
+	}
+	if idp.UserSearchBase == "" {
+		return nil, fmt.Errorf("ldap user search base is required")
+	}
+	if idp.ReadTimeout <= 0 {
+		idp.ReadTimeout = defaultReadTimeout
+	}
+	if idp.LoginAttribute == "" {
+		idp.LoginAttribute = defaultLoginAttribute
+	}
+	if idp.MailAttribute == "" {
+		idp.MailAttribute = defaultMailAttribute
+	}
+	if idp.UserSearchFilter == "" {
+		idp.UserSearchFilter = defaultUserSearchFilter
+	}
+	if idp.GroupSearchFilter == "" {
+		idp.GroupSearchFilter = defaultGroupSearchFilter
+	}
+	if idp.GroupMemberAttribute == "" {
+		idp.GroupMemberAttribute = defaultGroupMemberAttribute
+	}
+	if idp.GroupNameAttribute == "" {
+		idp.GroupNameAttribute = defaultGroupNameAttribute
+	}
+	tlsConfig, err := idp.newTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	idp.tlsConfig = tlsConfig
+	return &idp, nil
+}
+
+func (l *ldapProvider) newTLSConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: l.InsecureSkipVerify}
+	if l.RootCA == "" && l.RootCAData == "" {
+		return tlsConfig, nil
+	}
+	var data []byte
+	var err error
+	if l.RootCA != "" {
+		if data, err = os.ReadFile(l.RootCA); err != nil {
+			return nil, fmt.Errorf("read ldap root ca failed: %v", err)
+		}
+	} else if data, err = base64.StdEncoding.DecodeString(l.RootCAData); err != nil {
+		return nil, fmt.Errorf("decode ldap root ca data failed: %v", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificate found in ldap root ca")
+	}
+	tlsConfig.RootCAs = pool
+	return tlsConfig, nil
+}
+
+func (l *ldapProvider) Authenticate(username string, password string) (identityprovider.Identity, error) {
+	// an empty password is an unauthenticated bind, which always succeeds
+	if username == "" || password == "" {
+		return nil, identityprovider.ErrInvalidCredentials
+	}
+	conn, err := l.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err = l.bind(conn); err != nil {
+		return nil, err
+	}
+	entry, err := l.searchUser(conn, username)
+	if err != nil {
+		return nil, err
+	}
+	if err = conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, identityprovider.ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("ldap: bind user %s failed: %v", entry.DN, err)
+	}
+	identity := &ldapIdentity{
+		Username: entry.GetAttributeValue(l.LoginAttribute),
+		Email:    entry.GetAttributeValue(l.MailAttribute),
+	}
+	if l.GroupSearchBase == "" {
+		return identity, nil
+	}
+	// search groups as the bind DN, the user may not have permission to read groups
+	if err = l.bind(conn); err != nil {
+		return nil, err
+	}
+	if identity.Groups, err = l.searchGroups(conn, entry.DN); err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+func (l *ldapProvider) dial() (*ldap.Conn, error) {
+	conn, err := ldap.DialURL(l.URL, ldap.DialWithTLSConfig(l.tlsConfig))
+	if err != nil {
+		return nil, fmt.Errorf("ldap: dial %s failed: %v", l.URL, err)
+	}
+	conn.SetTimeout(l.ReadTimeout)
+	if l.StartTLS {
+		if err = conn.StartTLS(l.tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("ldap: start tls failed: %v", err)
+		}
+	}
+	return conn, nil
+}
+
+func (l *ldapProvider) bind(conn *ldap.Conn) error {
+	var err error
+	if l.BindDN == "" {
+		err = conn.UnauthenticatedBind("")
+	} else {
+		err = conn.Bind(l.BindDN, l.BindPassword)
+	}
+	if err != nil {
+		return fmt.Errorf("ldap: bind %s failed: %v", l.BindDN, err)
+	}
+	return nil
+}
+
+func (l *ldapProvider) searchUser(conn *ldap.Conn, username string) (*ldap.Entry, error) {
+	filter := fmt.Sprintf("(&%s(%s=%s))", l.UserSearchFilter, l.LoginAttribute, ldap.EscapeFilter(username))
+	result, err := conn.Search(ldap.NewSearchRequest(l.UserSearchBase, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		0, 0, false, filter, []string{l.LoginAttribute, l.MailAttribute}, nil))
+	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+			return nil, identityprovider.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("ldap: search user %s failed: %v", username, err)
+	}
+	switch len(result.Entries) {
+	case 0:
+		return nil, identityprovider.ErrUserNotFound
+	case 1:
+		return result.Entries[0], nil
+	default:
+		logger.Warnf("ldap: found %d users with filter %s", len(result.Entries), filter)
+		return nil, errors.New("ldap: username is not unique")
+	}
+}
+
+func (l *ldapProvider) searchGroups(conn *ldap.Conn, userDN string) ([]string, error) {
+	filter := fmt.Sprintf("(&%s(%s=%s))", l.GroupSearchFilter, l.GroupMemberAttribute, ldap.EscapeFilter(userDN))
+	result, err := conn.Search(ldap.NewSearchRequest(l.GroupSearchBase, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		0, 0, false, filter, []string{l.GroupNameAttribute}, nil))
+	if err != nil {
+		return nil, fmt.Errorf("ldap: search groups of %s failed: %v", userDN, err)
+	}
+	groups := make([]string, 0, len(result.Entries))
+	for _, entry := range result.Entries {
+		if name := entry.GetAttributeValue(l.GroupNameAttribute); name != "" {
+			groups = append(groups, name)
+		}
+	}
+	return groups, nil
+}
@@ -0,0 +1,311 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package ldap
+
+import (
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+
+	"github.com/kubeclipper/kubeclipper/pkg/authentication/identityprovider"
+	"github.com/kubeclipper/kubeclipper/pkg/authentication/oauth"
+)
+
+type entry struct {
+	dn    string
+	attrs map[string][]string
+}
+
+// fakeServer is an in-process LDAP server which supports simple bind and search with and, or, not, equality and present filters.
+type fakeServer struct {
+	listener net.Listener
+	entries  []entry
+}
+
+func newFakeServer(t *testing.T, entries []entry) *fakeServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeServer{listener: l, entries: entries}
+	go s.serve()
+	t.Cleanup(func() { _ = l.Close() })
+	return s
+}
+
+func (s *fakeServer) url() string {
+	return "ldap://" + s.listener.Addr().String()
+}
+
+func (s *fakeServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeServer) handle(conn net.Conn) {
+	defer conn.Close()
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+		id := packet.Children[0].Value.(int64)
+		op := packet.Children[1]
+		var responses []*ber.Packet
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			dn := op.Children[1].Value.(string)
+			password := string(op.Children[2].Data.Bytes())
+			responses = append(responses, result(ldap.ApplicationBindResponse, s.bind(dn, password)))
+		case ldap.ApplicationSearchRequest:
+			base := strings.ToLower(op.Children[0].Value.(string))
+			for _, e := range s.entries {
+				if strings.HasSuffix(strings.ToLower(e.dn), base) && match(op.Children[6], e) {
+					responses = append(responses, searchEntry(e))
+				}
+			}
+			responses = append(responses, result(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess))
+		default:
+			return
+		}
+		for _, resp := range responses {
+			msg := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+			msg.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, "MessageID"))
+			msg.AppendChild(resp)
+			if _, err = conn.Write(msg.Bytes()); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *fakeServer) bind(dn, password string) uint16 {
+	if dn == "" && password == "" {
+		return ldap.LDAPResultSuccess
+	}
+	for _, e := range s.entries {
+		if strings.EqualFold(e.dn, dn) {
+			if len(e.attrs["userPassword"]) == 1 && e.attrs["userPassword"][0] == password {
+				return ldap.LDAPResultSuccess
+			}
+			break
+		}
+	}
+	return ldap.LDAPResultInvalidCredentials
+}
+
+func result(tag ber.Tag, code uint16) *ber.Packet {
+	resp := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Result")
+	resp.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), "resultCode"))
+	resp.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "matchedDN"))
+	resp.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "diagnosticMessage"))
+	return resp
+}
+
+func searchEntry(e entry) *ber.Packet {
+	resp := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+	resp.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, e.dn, "objectName"))
+	attrs := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "attributes")
+	for name, values := range e.attrs {
+		attr := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "attribute")
+		attr.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "type"))
+		vals := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "vals")
+		for _, v := range values {
+			vals.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, v, "val"))
+		}
+		attr.AppendChild(vals)
+		attrs.AppendChild(attr)
+	}
+	resp.AppendChild(attrs)
+	return resp
+}
+
+func match(filter *ber.Packet, e entry) bool {
+	switch filter.Tag {
+	case ldap.FilterAnd:
+		for _, child := range filter.Children {
+			if !match(child, e) {
+				return false
+			}
+		}
+		return true
+	case ldap.FilterOr:
+		for _, child := range filter.Children {
+			if match(child, e) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterNot:
+		return !match(filter.Children[0], e)
+	case ldap.FilterEqualityMatch:
+		for _, v := range attrValues(e, filter.Children[0].Value.(string)) {
+			if strings.EqualFold(v, filter.Children[1].Value.(string)) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterPresent:
+		return len(attrValues(e, string(filter.Data.Bytes()))) > 0
+	default:
+		return false
+	}
+}
+
+func attrValues(e entry, name string) []string {
+	for k, v := range e.attrs {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return nil
+}
+
+var testEntries = []entry{
+	{
+		dn:    "cn=admin,dc=example,dc=org",
+		attrs: map[string][]string{"cn": {"admin"}, "userPassword": {"admin-password"}},
+	},
+	{
+		dn: "uid=alice,ou=users,dc=example,dc=org",
+		attrs: map[string][]string{"objectClass": {"top", "person"}, "uid": {"alice"},
+			"mail": {"alice@example.org"}, "userPassword": {"alice-password"}},
+	},
+	{
+		dn: "uid=bob,ou=users,dc=example,dc=org",
+		attrs: map[string][]string{"objectClass": {"top", "person"}, "uid": {"bob"},
+			"mail": {"bob@example.org"}, "userPassword": {"bob-password"}},
+	},
+	{
+		dn: "cn=admins,ou=groups,dc=example,dc=org",
+		attrs: map[string][]string{"objectClass": {"groupOfNames"}, "cn": {"admins"},
+			"member": {"uid=alice,ou=users,dc=example,dc=org"}},
+	},
+	{
+		dn: "cn=developers,ou=groups,dc=example,dc=org",
+		attrs: map[string][]string{"objectClass": {"groupOfNames"}, "cn": {"developers"},
+			"member": {"uid=alice,ou=users,dc=example,dc=org", "uid=bob,ou=users,dc=example,dc=org"}},
+	},
+}
+
+func TestLdapProvider_Authenticate(t *testing.T) {
+	server := newFakeServer(t, testEntries)
+	options := oauth.DynamicOptions{
+		"url":             server.url(),
+		"bindDN":          "cn=admin,dc=example,dc=org",
+		"bindPassword":    "admin-password",
+		"userSearchBase":  "ou=users,dc=example,dc=org",
+		"groupSearchBase": "ou=groups,dc=example,dc=org",
+		"readTimeout":     "5s",
+	}
+	tests := []struct {
+		name     string
+		options  map[string]interface{}
+		username string
+		password string
+		want     identityprovider.Identity
+		wantErr  error
+	}{
+		{
+			name:     "user with groups",
+			username: "alice",
+			password: "alice-password",
+			want:     &ldapIdentity{Username: "alice", Email: "alice@example.org", Groups: []string{"admins", "developers"}},
+		},
+		{
+			name:     "user without group search",
+			options:  map[string]interface{}{"groupSearchBase": ""},
+			username: "bob",
+			password: "bob-password",
+			want:     &ldapIdentity{Username: "bob", Email: "bob@example.org"},
+		},
+		{
+			name:     "incorrect password",
+			username: "alice",
+			password: "bob-password",
+			wantErr:  identityprovider.ErrInvalidCredentials,
+		},
+		{
+			name:     "empty password",
+			username: "alice",
+			password: "",
+			wantErr:  identityprovider.ErrInvalidCredentials,
+		},
+		{
+			name:     "user not found",
+			username: "carol",
+			password: "carol-password",
+			wantErr:  identityprovider.ErrUserNotFound,
+		},
+		{
+			name:     "filter injection",
+			username: "*",
+			password: "alice-password",
+			wantErr:  identityprovider.ErrUserNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := oauth.DynamicOptions{}
+			for k, v := range options {
+				o[k] = v
+			}
+			for k, v := range tt.options {
+				o[k] = v
+			}
+			provider, err := (&ldapProviderFactory{}).Create(o)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := provider.Authenticate(tt.username, tt.password)
+			if err != tt.wantErr {
+				t.Fatalf("Authenticate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Authenticate() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLdapProvider_BindFailed(t *testing.T) {
+	server := newFakeServer(t, testEntries)
+	provider, err := (&ldapProviderFactory{}).Create(oauth.DynamicOptions{
+		"url":            server.url(),
+		"bindDN":         "cn=admin,dc=example,dc=org",
+		"bindPassword":   "wrong-password",
+		"userSearchBase": "ou=users,dc=example,dc=org",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = provider.Authenticate("alice", "alice-password")
+	if err == nil || err == identityprovider.ErrInvalidCredentials {
+		t.Errorf("Authenticate() error = %v, want bind error", err)
+	}
+}
@@ -32,6 +32,8 @@ import (
 
 var (
 	ErrProviderNotFound = fmt.Errorf("provider not found")
+	// ErrAlreadyEnrolled is returned by Enroller when the user has enrolled the provider
+	ErrAlreadyEnrolled = fmt.Errorf("mfa provider is already enrolled")
 )
 
 type Provider interface {
@@ -40,6 +42,25 @@ type Provider interface {
 	UserProviderConfig(info user.Info, token string) UserMFAProvider
 }
 
+// Enroller is implemented by the providers which need enrollment before the verification,
+// e.g. the authenticator apps which share a secret with the server.
+type Enroller interface {
+	Enroll(info user.Info) (*Enrollment, error)
+}
+
+// Enrollment is returned to the user when the user enrolls the provider, it is only returned once.
+type Enrollment struct {
+	Type string `json:"type"`
+	// Secret is the shared secret, the user can input it when the QR code can not be scanned.
+	Secret string `json:"secret,omitempty"`
+	// URI is the provisioning uri of the authenticator apps.
+	URI string `json:"uri,omitempty"`
+	// QRCode is the data url of the QR code png of the uri.
+	QRCode string `json:"qrCode,omitempty"`
+	// RecoveryCodes can be used once instead of the verification code.
+	RecoveryCodes []string `json:"recoveryCodes,omitempty"`
+}
+
 type ProviderFactory interface {
 	Type() string
 	Create(cache cache.Interface, options oauth.DynamicOptions) (Provider, error)
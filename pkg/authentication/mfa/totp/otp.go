@@ -0,0 +1,101 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// secretSize is the size of the shared secret recommended by RFC 4226
+	secretSize = 20
+)
+
+var secretEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// hotp returns the HMAC-based one-time password of the counter as defined in RFC 4226.
+func hotp(secret []byte, counter uint64, digits int) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, counter)
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	code := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint32(1)
+	for i := 0; i < digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, code%mod)
+}
+
+// counterAt returns the time step of RFC 6238 at t.
+func counterAt(t time.Time, period time.Duration) uint64 {
+	return uint64(t.Unix() / int64(period/time.Second))
+}
+
+// validate checks the code in the window of skew steps around t, it returns the matched time step.
+// The steps not after lastCounter are skipped, so a code can only be used once.
+func validate(secret []byte, code string, t time.Time, period time.Duration, digits, skew int, lastCounter uint64) (uint64, bool) {
+	if len(code) != digits {
+		return 0, false
+	}
+	current := counterAt(t, period)
+	for i := -skew; i <= skew; i++ {
+		counter := current + uint64(i)
+		if i < 0 && current < uint64(-i) || counter <= lastCounter {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(hotp(secret, counter, digits)), []byte(code)) == 1 {
+			return counter, true
+		}
+	}
+	return 0, false
+}
+
+// provisioningURI returns the key uri of the authenticator apps, see also
+// https://github.com/google/google-authenticator/wiki/Key-Uri-Format
+func provisioningURI(issuer, account string, secret []byte, period time.Duration, digits int) string {
+	v := url.Values{}
+	v.Set("secret", secretEncoding.EncodeToString(secret))
+	v.Set("issuer", issuer)
+	v.Set("algorithm", "SHA1")
+	v.Set("digits", fmt.Sprintf("%d", digits))
+	v.Set("period", fmt.Sprintf("%d", int64(period/time.Second)))
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + account,
+		RawQuery: v.Encode(),
+	}
+	return u.String()
+}
+
+// normalizeCode removes the spaces and dashes the users may type.
+func normalizeCode(code string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(code))
+}
@@ -0,0 +1,111 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// test vectors of RFC 6238 Appendix B with SHA1
+func TestHOTPRFC6238(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "94287082"},
+		{unix: 1111111109, want: "07081804"},
+		{unix: 1111111111, want: "14050471"},
+		{unix: 1234567890, want: "89005924"},
+		{unix: 2000000000, want: "69279037"},
+		{unix: 20000000000, want: "65353130"},
+	}
+	for _, tt := range tests {
+		if got := hotp(secret, counterAt(time.Unix(tt.unix, 0), period), 8); got != tt.want {
+			t.Errorf("hotp() at %d = %v, want %v", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	now := time.Unix(1111111111, 0)
+	current := counterAt(now, period)
+	tests := []struct {
+		name        string
+		code        string
+		skew        int
+		lastCounter uint64
+		wantCounter uint64
+		wantOK      bool
+	}{
+		{
+			name:        "current step",
+			code:        hotp(secret, current, digits),
+			skew:        1,
+			wantCounter: current,
+			wantOK:      true,
+		},
+		{
+			name:        "previous step in window",
+			code:        hotp(secret, current-1, digits),
+			skew:        1,
+			wantCounter: current - 1,
+			wantOK:      true,
+		},
+		{
+			name: "previous step without skew",
+			code: hotp(secret, current-1, digits),
+			skew: 0,
+		},
+		{
+			name: "out of window",
+			code: hotp(secret, current+2, digits),
+			skew: 1,
+		},
+		{
+			name:        "replayed code",
+			code:        hotp(secret, current, digits),
+			skew:        1,
+			lastCounter: current,
+		},
+		{
+			name: "invalid length",
+			code: "12345",
+			skew: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter, ok := validate(secret, tt.code, now, period, digits, tt.skew, tt.lastCounter)
+			if ok != tt.wantOK || counter != tt.wantCounter {
+				t.Errorf("validate() = %v, %v, want %v, %v", counter, ok, tt.wantCounter, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	got := provisioningURI("KubeClipper", "admin", []byte("12345678901234567890"), period, digits)
+	want := "otpauth://totp/KubeClipper:admin?algorithm=SHA1&digits=6&issuer=KubeClipper&period=30&secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	if got != want {
+		t.Errorf("provisioningURI() = %v, want %v", got, want)
+	}
+}
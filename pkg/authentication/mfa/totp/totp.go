@@ -0,0 +1,316 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package totp
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/skip2/go-qrcode"
+	"k8s.io/apiserver/pkg/authentication/user"
+
+	"github.com/kubeclipper/kubeclipper/pkg/authentication/mfa"
+	"github.com/kubeclipper/kubeclipper/pkg/authentication/oauth"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/cache"
+)
+
+func init() {
+	mfa.RegisterProviderFactory(&totpProviderFactory{})
+}
+
+const (
+	ProviderType = "totp"
+	// ValueEnrolled is the value of the user provider config when the user has enrolled the authenticator app.
+	ValueEnrolled = "enrolled"
+
+	period                   = 30 * time.Second
+	digits                   = 6
+	defaultIssuer            = "KubeClipper"
+	defaultSkew              = 1
+	defaultRecoveryCodes     = 10
+	recoveryCodeLength       = 10
+	recoveryCodeAlphabet     = "abcdefghjkmnpqrstuvwxyz23456789"
+	qrCodeSize               = 256
+	recoveryCodeGroupingSize = 5
+)
+
+var (
+	ErrNotEnrolled         = errors.New("totp is not enrolled")
+	ErrIncorrectCode       = errors.New("incorrect verification code")
+	ErrRequestNotSupported = errors.New("totp does not send verification code, use the code of the authenticator app")
+)
+
+var (
+	_ mfa.Provider = (*totpProvider)(nil)
+	_ mfa.Enroller = (*totpProvider)(nil)
+)
+
+type totpProviderFactory struct {
+}
+
+func (f *totpProviderFactory) Type() string {
+	return ProviderType
+}
+
+func (f *totpProviderFactory) Create(cache cache.Interface, options oauth.DynamicOptions) (mfa.Provider, error) {
+	p := &totpProvider{
+		Issuer:        defaultIssuer,
+		Skew:          defaultSkew,
+		RecoveryCodes: defaultRecoveryCodes,
+		cache:         cache,
+		now:           time.Now,
+	}
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		WeaklyTypedInput: true,
+		Result:           p,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = decoder.Decode(options); err != nil {
+		return nil, err
+	}
+	if p.EncryptionKey == "" {
+		return nil, fmt.Errorf("totp encryption key is required")
+	}
+	if p.Skew < 0 {
+		return nil, fmt.Errorf("totp skew must not be negative")
+	}
+	key := sha256.Sum256([]byte(p.EncryptionKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	if p.aead, err = cipher.NewGCM(block); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+type totpProvider struct {
+	// Issuer shown in the authenticator apps
+	Issuer string `json:"issuer" yaml:"issuer"`
+	// EncryptionKey encrypts the secrets of the users
+	EncryptionKey string `json:"-" yaml:"encryptionKey"`
+	// Skew is the number of time steps before and after the current one which are accepted
+	Skew int `json:"skew" yaml:"skew"`
+	// RecoveryCodes is the number of recovery codes issued to the user
+	RecoveryCodes int `json:"recoveryCodes" yaml:"recoveryCodes"`
+
+	cache cache.Interface
+	aead  cipher.AEAD
+	now   func() time.Time
+}
+
+// record is the totp enrollment of a user, it is saved in the cache without expiration.
+type record struct {
+	// Secret is the encrypted shared secret
+	Secret string `json:"secret"`
+	// Enrolled is set after the first code is verified
+	Enrolled bool `json:"enrolled"`
+	// RecoveryCodes are the sha256 hashes of the unused recovery codes
+	RecoveryCodes []string `json:"recoveryCodes,omitempty"`
+	// LastCounter is the time step of the last verified code
+	LastCounter uint64 `json:"lastCounter,omitempty"`
+}
+
+func (p *totpProvider) Verify(req url.Values, info user.Info) error {
+	key := cacheKey(info.GetName())
+	rec, err := p.load(key)
+	if err != nil {
+		return err
+	}
+	if rec == nil {
+		return ErrNotEnrolled
+	}
+	secret, err := p.decrypt(rec.Secret, info.GetName())
+	if err != nil {
+		return err
+	}
+	code := normalizeCode(req.Get("code"))
+	if counter, ok := validate(secret, code, p.now(), period, digits, p.Skew, rec.LastCounter); ok {
+		rec.Enrolled = true
+		rec.LastCounter = counter
+		return p.save(key, rec)
+	}
+	// the recovery codes can only be used after the enrollment
+	if rec.Enrolled {
+		hash := hashRecoveryCode(code)
+		for i, v := range rec.RecoveryCodes {
+			if subtle.ConstantTimeCompare([]byte(v), []byte(hash)) == 1 {
+				rec.RecoveryCodes = append(rec.RecoveryCodes[:i], rec.RecoveryCodes[i+1:]...)
+				return p.save(key, rec)
+			}
+		}
+	}
+	return ErrIncorrectCode
+}
+
+func (p *totpProvider) Request(info user.Info) error {
+	return ErrRequestNotSupported
+}
+
+func (p *totpProvider) UserProviderConfig(info user.Info, token string) mfa.UserMFAProvider {
+	config := mfa.UserMFAProvider{
+		Type:  ProviderType,
+		Token: token,
+	}
+	if rec, err := p.load(cacheKey(info.GetName())); err == nil && rec != nil && rec.Enrolled {
+		config.Value = ValueEnrolled
+	}
+	return config
+}
+
+// Enroll generates a new secret and recovery codes for the user, the enrollment takes effect after the first code is verified.
+// The user who has enrolled can not enroll again, otherwise the password is enough to replace the authenticator app.
+func (p *totpProvider) Enroll(info user.Info) (*mfa.Enrollment, error) {
+	key := cacheKey(info.GetName())
+	rec, err := p.load(key)
+	if err != nil {
+		return nil, err
+	}
+	if rec != nil && rec.Enrolled {
+		return nil, mfa.ErrAlreadyEnrolled
+	}
+	secret := make([]byte, secretSize)
+	if _, err = rand.Read(secret); err != nil {
+		return nil, err
+	}
+	encrypted, err := p.encrypt(secret, info.GetName())
+	if err != nil {
+		return nil, err
+	}
+	codes, err := generateRecoveryCodes(p.RecoveryCodes)
+	if err != nil {
+		return nil, err
+	}
+	rec = &record{Secret: encrypted}
+	for _, code := range codes {
+		rec.RecoveryCodes = append(rec.RecoveryCodes, hashRecoveryCode(normalizeCode(code)))
+	}
+	if err = p.save(key, rec); err != nil {
+		return nil, err
+	}
+	uri := provisioningURI(p.Issuer, info.GetName(), secret, period, digits)
+	png, err := qrcode.Encode(uri, qrcode.Medium, qrCodeSize)
+	if err != nil {
+		return nil, err
+	}
+	return &mfa.Enrollment{
+		Type:          ProviderType,
+		Secret:        secretEncoding.EncodeToString(secret),
+		URI:           uri,
+		QRCode:        "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+		RecoveryCodes: codes,
+	}, nil
+}
+
+func (p *totpProvider) load(key string) (*record, error) {
+	value, err := p.cache.Get(key)
+	if err != nil {
+		if cache.IsNotExists(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	rec := &record{}
+	if err = json.Unmarshal([]byte(value), rec); err != nil {
+		return nil, fmt.Errorf("invalid totp record: %w", err)
+	}
+	return rec, nil
+}
+
+func (p *totpProvider) save(key string, rec *record) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	exist, err := p.cache.Exist(key)
+	if err != nil {
+		return err
+	}
+	if exist {
+		return p.cache.Update(key, string(data))
+	}
+	return p.cache.Set(key, string(data), cache.NoExpiration)
+}
+
+// encrypt seals the secret with AES-GCM, the username is the additional data so the secret can not be moved to another user.
+func (p *totpProvider) encrypt(secret []byte, username string) (string, error) {
+	nonce := make([]byte, p.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(p.aead.Seal(nonce, nonce, secret, []byte(username))), nil
+}
+
+func (p *totpProvider) decrypt(encrypted string, username string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < p.aead.NonceSize() {
+		return nil, fmt.Errorf("invalid totp secret")
+	}
+	secret, err := p.aead.Open(nil, data[:p.aead.NonceSize()], data[p.aead.NonceSize():], []byte(username))
+	if err != nil {
+		return nil, fmt.Errorf("decrypt totp secret failed: %w", err)
+	}
+	return secret, nil
+}
+
+func cacheKey(username string) string {
+	return fmt.Sprintf("%s-%s", ProviderType, username)
+}
+
+func generateRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, 0, n)
+	buf := make([]byte, recoveryCodeLength)
+	for i := 0; i < n; i++ {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		var sb strings.Builder
+		for j, b := range buf {
+			if j > 0 && j%recoveryCodeGroupingSize == 0 {
+				sb.WriteByte('-')
+			}
+			sb.WriteByte(recoveryCodeAlphabet[int(b)%len(recoveryCodeAlphabet)])
+		}
+		codes = append(codes, sb.String())
+	}
+	return codes, nil
+}
+
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(code)))
+	return hex.EncodeToString(sum[:])
+}
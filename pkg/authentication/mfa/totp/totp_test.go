@@ -0,0 +1,97 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package totp
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apiserver/pkg/authentication/user"
+
+	"github.com/kubeclipper/kubeclipper/pkg/authentication/mfa"
+	"github.com/kubeclipper/kubeclipper/pkg/authentication/oauth"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/cache"
+)
+
+func codeValues(code string) url.Values {
+	v := url.Values{}
+	v.Set("code", code)
+	return v
+}
+
+func TestTOTPProvider(t *testing.T) {
+	kv, err := cache.NewMemory()
+	require.NoError(t, err)
+	_, err = (&totpProviderFactory{}).Create(kv, oauth.DynamicOptions{})
+	require.Error(t, err, "encryption key is required")
+
+	provider, err := (&totpProviderFactory{}).Create(kv, oauth.DynamicOptions{"encryptionKey": "test-key", "skew": "1", "recoveryCodes": 2})
+	require.NoError(t, err)
+	p := provider.(*totpProvider)
+	now := time.Unix(1700000000, 0)
+	p.now = func() time.Time { return now }
+
+	info := &user.DefaultInfo{Name: "admin"}
+	require.Equal(t, "", p.UserProviderConfig(info, "token").Value)
+	require.ErrorIs(t, p.Verify(codeValues("123456"), info), ErrNotEnrolled)
+	require.ErrorIs(t, p.Request(info), ErrRequestNotSupported)
+
+	enrollment, err := p.Enroll(info)
+	require.NoError(t, err)
+	require.Equal(t, ProviderType, enrollment.Type)
+	require.Len(t, enrollment.RecoveryCodes, 2)
+	require.True(t, strings.HasPrefix(enrollment.URI, "otpauth://totp/KubeClipper:admin?"))
+	require.True(t, strings.HasPrefix(enrollment.QRCode, "data:image/png;base64,"))
+	secret, err := secretEncoding.DecodeString(enrollment.Secret)
+	require.NoError(t, err)
+
+	// the secret is encrypted in the cache
+	value, err := kv.Get(cacheKey("admin"))
+	require.NoError(t, err)
+	require.NotContains(t, value, enrollment.Secret)
+
+	// recovery codes can not be used before the enrollment is verified
+	require.ErrorIs(t, p.Verify(codeValues(enrollment.RecoveryCodes[0]), info), ErrIncorrectCode)
+	require.Equal(t, "", p.UserProviderConfig(info, "token").Value)
+
+	// the previous step is accepted in the skew window
+	code := hotp(secret, counterAt(now, period)-1, digits)
+	require.NoError(t, p.Verify(codeValues(code), info))
+	require.Equal(t, ValueEnrolled, p.UserProviderConfig(info, "token").Value)
+	// a code can only be used once
+	require.ErrorIs(t, p.Verify(codeValues(code), info), ErrIncorrectCode)
+	require.NoError(t, p.Verify(codeValues(hotp(secret, counterAt(now, period), digits)), info))
+
+	// recovery codes can be used once, case and dashes are ignored
+	recovery := strings.ToUpper(strings.ReplaceAll(enrollment.RecoveryCodes[0], "-", ""))
+	require.NoError(t, p.Verify(codeValues(recovery), info))
+	require.ErrorIs(t, p.Verify(codeValues(recovery), info), ErrIncorrectCode)
+	require.NoError(t, p.Verify(codeValues(enrollment.RecoveryCodes[1]), info))
+
+	_, err = p.Enroll(info)
+	require.ErrorIs(t, err, mfa.ErrAlreadyEnrolled)
+
+	// the secret is bound to the user
+	other, err := (&totpProviderFactory{}).Create(kv, oauth.DynamicOptions{"encryptionKey": "other-key"})
+	require.NoError(t, err)
+	require.Error(t, other.Verify(codeValues(hotp(secret, counterAt(time.Now(), period), digits)), info))
+}
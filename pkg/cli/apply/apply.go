@@ -0,0 +1,285 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package apply
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/printer"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/utils"
+	apierror "github.com/kubeclipper/kubeclipper/pkg/errors"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/kc"
+)
+
+/*
+kubeclipper apply
+
+Usage:
+  kcctl apply (--filename | -f <FILE-NAME>) [flags]
+  kcctl diff (--filename | -f <FILE-NAME>) [flags]
+
+Examples:
+  kcctl apply -f cluster.yaml
+  kcctl apply -f cluster.yaml --dry-run
+  kcctl diff -f cluster.yaml
+
+Flags:
+  -h, --help                   help for apply
+*/
+
+const (
+	applyLongDescription = `
+  Apply the cluster described in the file.
+
+  The cluster is created when it does not exist, otherwise the file is compared with the live
+  cluster and the changes (workers added or removed, addons installed, uninstalled or reconfigured
+  and kubernetes version upgraded) are applied one after another with the existing operations.
+  Masters, container runtime and cni can not be changed.`
+	applyExample = `
+  # Apply the cluster described in the file
+  kcctl apply -f cluster.yaml
+
+  # Show the operations the apply would run without running them
+  kcctl apply -f cluster.yaml --dry-run
+
+  Please read 'kcctl apply -h' get more apply flags.`
+	diffLongDescription = `
+  Show the changes and the operation steps which bring the live cluster to the one described in
+  the file, nothing is applied. A reconfigured addon is uninstalled and installed again, both
+  changes are marked as a disruptive reconfigure.`
+	diffExample = `
+  # Show the changes between the file and the live cluster
+  kcctl diff -f cluster.yaml
+
+  Please read 'kcctl diff -h' get more diff flags.`
+
+	defaultApplyTimeout = 30 * time.Minute
+	pollInterval        = 5 * time.Second
+)
+
+type ApplyOptions struct {
+	PrintFlags *printer.PrintFlags
+	CliOpts    *options.CliOptions
+	options.IOStreams
+	Client   *kc.Client
+	Filename string
+	DryRun   bool
+	Timeout  time.Duration
+}
+
+func NewApplyOptions(streams options.IOStreams) *ApplyOptions {
+	return &ApplyOptions{
+		PrintFlags: printer.NewPrintFlags(),
+		CliOpts:    options.NewCliOptions(),
+		IOStreams:  streams,
+		Timeout:    defaultApplyTimeout,
+	}
+}
+
+func NewCmdApply(streams options.IOStreams) *cobra.Command {
+	o := NewApplyOptions(streams)
+	cmd := &cobra.Command{
+		Use:                   "apply (--filename | -f <FILE-NAME>) [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "apply the cluster described in the file",
+		Long:                  applyLongDescription,
+		Example:               applyExample,
+		Args:                  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			utils.CheckErr(o.Complete(o.CliOpts))
+			utils.CheckErr(o.ValidateArgs(cmd))
+			utils.CheckErr(o.RunApply())
+		},
+	}
+	cmd.Flags().StringVarP(&o.Filename, "filename", "f", o.Filename, "file of the cluster to apply")
+	cmd.Flags().BoolVar(&o.DryRun, "dry-run", o.DryRun, "only show the operations the apply would run")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", o.Timeout, "time to wait for the cluster to be running between the operations")
+	o.CliOpts.AddFlags(cmd.Flags())
+	o.PrintFlags.AddFlags(cmd)
+	return cmd
+}
+
+func NewCmdDiff(streams options.IOStreams) *cobra.Command {
+	o := NewApplyOptions(streams)
+	cmd := &cobra.Command{
+		Use:                   "diff (--filename | -f <FILE-NAME>) [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "show the changes between the file and the live cluster",
+		Long:                  diffLongDescription,
+		Example:               diffExample,
+		Args:                  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			utils.CheckErr(o.Complete(o.CliOpts))
+			utils.CheckErr(o.ValidateArgs(cmd))
+			utils.CheckErr(o.RunDiff())
+		},
+	}
+	cmd.Flags().StringVarP(&o.Filename, "filename", "f", o.Filename, "file of the cluster to compare")
+	o.CliOpts.AddFlags(cmd.Flags())
+	o.PrintFlags.AddFlags(cmd)
+	return cmd
+}
+
+func (o *ApplyOptions) Complete(opts *options.CliOptions) error {
+	if err := opts.Complete(); err != nil {
+		return err
+	}
+	c, err := opts.ToRawConfig().ToKcClient()
+	if err != nil {
+		return err
+	}
+	o.Client = c
+	return nil
+}
+
+func (o *ApplyOptions) ValidateArgs(cmd *cobra.Command) error {
+	if o.Filename == "" {
+		return utils.UsageErrorf(cmd, "You must specify the file of the cluster")
+	}
+	if o.Timeout <= 0 {
+		return utils.UsageErrorf(cmd, "timeout must be greater than 0")
+	}
+	return nil
+}
+
+func (o *ApplyOptions) RunDiff() error {
+	desired, err := o.readCluster()
+	if err != nil {
+		return err
+	}
+	plan, err := o.Client.PlanCluster(context.TODO(), desired)
+	if err != nil {
+		return err
+	}
+	return o.PrintFlags.Print(plan, o.IOStreams.Out)
+}
+
+func (o *ApplyOptions) RunApply() error {
+	desired, err := o.readCluster()
+	if err != nil {
+		return err
+	}
+	_, err = o.Client.DescribeCluster(context.TODO(), desired.Name)
+	if apierror.IsNotFound(err) {
+		if o.DryRun {
+			_, err = fmt.Fprintf(o.IOStreams.Out, "cluster %s will be created\n", desired.Name)
+			return err
+		}
+		c, err := o.Client.CreateCluster(context.TODO(), desired)
+		if err != nil {
+			return err
+		}
+		return o.PrintFlags.Print(c, o.IOStreams.Out)
+	}
+	if err != nil {
+		return err
+	}
+	plan, err := o.Client.PlanCluster(context.TODO(), desired)
+	if err != nil {
+		return err
+	}
+	if len(plan.Changes) == 0 {
+		_, err = fmt.Fprintf(o.IOStreams.Out, "cluster %s is up to date\n", desired.Name)
+		return err
+	}
+	if o.DryRun {
+		return o.PrintFlags.Print(plan, o.IOStreams.Out)
+	}
+	// the operations of a cluster can't run concurrently, each change waits for the previous one
+	for _, change := range plan.Changes {
+		if err = o.waitClusterRunning(desired.Name); err != nil {
+			return err
+		}
+		if err = o.applyChange(desired.Name, change); err != nil {
+			return fmt.Errorf("%s: %w", change.Action, err)
+		}
+		if _, err = fmt.Fprintf(o.IOStreams.Out, "cluster %s: %s started\n", desired.Name, change.Action); err != nil {
+			return err
+		}
+	}
+	if err = o.waitClusterRunning(desired.Name); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(o.IOStreams.Out, "cluster %s applied\n", desired.Name)
+	return err
+}
+
+func (o *ApplyOptions) applyChange(name string, change kc.ClusterChange) error {
+	var err error
+	switch {
+	case change.Nodes != nil:
+		_, err = o.Client.PatchClusterNodes(context.TODO(), name, change.Nodes)
+	case change.Components != nil:
+		_, err = o.Client.PatchClusterComponents(context.TODO(), name, change.Components)
+	case change.Upgrade != nil:
+		err = o.Client.UpgradeCluster(context.TODO(), name, change.Upgrade)
+	default:
+		err = fmt.Errorf("unsupported cluster change %s", change.Action)
+	}
+	return err
+}
+
+// waitClusterRunning waits for the running operation of the cluster to finish.
+func (o *ApplyOptions) waitClusterRunning(name string) error {
+	deadline := time.Now().Add(o.Timeout)
+	for {
+		c, err := o.Client.DescribeCluster(context.TODO(), name)
+		if err != nil {
+			return err
+		}
+		if len(c.Items) == 0 {
+			return fmt.Errorf("cluster %s not found", name)
+		}
+		phase := c.Items[0].Status.Phase
+		if phase == v1.ClusterRunning {
+			return nil
+		}
+		if strings.HasSuffix(string(phase), "Failed") {
+			return fmt.Errorf("cluster %s is %s, use 'kcctl get operation' to find the failed operation", name, phase)
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for cluster %s to be running, it is %s", name, phase)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+func (o *ApplyOptions) readCluster() (*v1.Cluster, error) {
+	data, err := os.ReadFile(o.Filename)
+	if err != nil {
+		return nil, err
+	}
+	c := &v1.Cluster{}
+	if err = yaml.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("parse cluster from %s: %w", o.Filename, err)
+	}
+	if c.Name == "" {
+		return nil, fmt.Errorf("the name of the cluster in %s is required", o.Filename)
+	}
+	return c, nil
+}
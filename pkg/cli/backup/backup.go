@@ -0,0 +1,228 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package backup
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/printer"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/utils"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/kc"
+)
+
+/*
+kubeclipper backup
+
+Usage:
+  kcctl backup verify
+  kcctl backup scan
+  kcctl backup enable-cron
+  kcctl backup disable-cron
+
+Examples:
+  kcctl backup verify 'BACKUP-NAME' --cluster 'CLUSTER-NAME'
+  kcctl backup scan 'BACKUP-POINT-NAME'
+
+Flags:
+  -h, --help                   help for backup
+*/
+
+const (
+	longDescription = `
+  Manage the backups of clusters.
+
+  Use 'kcctl create backup' and 'kcctl create cronbackup' to back up the clusters,
+  and 'kcctl create recovery' to restore the cluster from its backup.`
+	backupExample = `
+  # Verify the backup of the cluster
+  kcctl backup verify 'BACKUP-NAME' --cluster 'CLUSTER-NAME'
+
+  # Import the backups found in the backup point
+  kcctl backup scan 'BACKUP-POINT-NAME'
+
+  # Disable the cron backup
+  kcctl backup disable-cron 'CRON-BACKUP-NAME'
+
+  Please read 'kcctl backup -h' get more backup flags.`
+	verifyLongDescription = `
+  Verify the backup by restoring its snapshot in a scratch directory.
+
+  The verification is run asynchronously, use 'kcctl get backup' to watch its status.`
+	scanLongDescription = `
+  Import the backups found in the storage of the backup point.
+
+  The backups already known by kubeclipper are skipped. The fs backup points
+  are not supported, their backups are saved on the master nodes of the clusters.`
+)
+
+type BackupOptions struct {
+	PrintFlags *printer.PrintFlags
+	CliOpts    *options.CliOptions
+	options.IOStreams
+	Client  *kc.Client
+	Cluster string
+	name    string
+}
+
+func NewBackupOptions(streams options.IOStreams) *BackupOptions {
+	return &BackupOptions{
+		PrintFlags: printer.NewPrintFlags(),
+		CliOpts:    options.NewCliOptions(),
+		IOStreams:  streams,
+	}
+}
+
+func NewCmdBackup(streams options.IOStreams) *cobra.Command {
+	o := NewBackupOptions(streams)
+	cmd := &cobra.Command{
+		Use:                   "backup",
+		DisableFlagsInUseLine: true,
+		Short:                 "cluster backup",
+		Long:                  longDescription,
+		Example:               backupExample,
+		Args:                  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(NewCmdBackupVerify(o))
+	cmd.AddCommand(NewCmdBackupScan(o))
+	cmd.AddCommand(NewCmdBackupCron(o, "enable-cron", "enable the cron backup", o.RunEnableCron))
+	cmd.AddCommand(NewCmdBackupCron(o, "disable-cron", "disable the cron backup", o.RunDisableCron))
+
+	return cmd
+}
+
+func NewCmdBackupVerify(o *BackupOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "verify <name> (--cluster) [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "verify the backup of the cluster",
+		Long:                  verifyLongDescription,
+		Example: `
+  # Verify the backup of the cluster
+  kcctl backup verify 'BACKUP-NAME' --cluster 'CLUSTER-NAME'`,
+		Run: func(cmd *cobra.Command, args []string) {
+			utils.CheckErr(o.Complete(o.CliOpts))
+			utils.CheckErr(o.ValidateArgs(cmd, args))
+			if o.Cluster == "" {
+				utils.CheckErr(utils.UsageErrorf(cmd, "cluster must be specified"))
+			}
+			utils.CheckErr(o.RunVerify())
+		},
+	}
+	cmd.Flags().StringVar(&o.Cluster, "cluster", o.Cluster, "cluster of the backup")
+	o.CliOpts.AddFlags(cmd.Flags())
+	o.PrintFlags.AddFlags(cmd)
+	return cmd
+}
+
+func NewCmdBackupScan(o *BackupOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "scan <backup-point> [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "import the backups found in the backup point",
+		Long:                  scanLongDescription,
+		Example: `
+  # Import the backups found in the backup point
+  kcctl backup scan 'BACKUP-POINT-NAME'`,
+		Run: func(cmd *cobra.Command, args []string) {
+			utils.CheckErr(o.Complete(o.CliOpts))
+			utils.CheckErr(o.ValidateArgs(cmd, args))
+			utils.CheckErr(o.RunScan())
+		},
+	}
+	o.CliOpts.AddFlags(cmd.Flags())
+	o.PrintFlags.AddFlags(cmd)
+	return cmd
+}
+
+func NewCmdBackupCron(o *BackupOptions, use, short string, run func() error) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   use + " <name> [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 short,
+		Long:                  "\n  " + short + ".",
+		Example:               "\n  kcctl backup " + use + " 'CRON-BACKUP-NAME'",
+		Run: func(cmd *cobra.Command, args []string) {
+			utils.CheckErr(o.Complete(o.CliOpts))
+			utils.CheckErr(o.ValidateArgs(cmd, args))
+			utils.CheckErr(run())
+		},
+	}
+	o.CliOpts.AddFlags(cmd.Flags())
+	o.PrintFlags.AddFlags(cmd)
+	return cmd
+}
+
+func (o *BackupOptions) Complete(opts *options.CliOptions) error {
+	if err := opts.Complete(); err != nil {
+		return err
+	}
+	c, err := opts.ToRawConfig().ToKcClient()
+	if err != nil {
+		return err
+	}
+	o.Client = c
+	return nil
+}
+
+func (o *BackupOptions) ValidateArgs(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return utils.UsageErrorf(cmd, "You must specify the name of the resource to %s", cmd.Name())
+	}
+	o.name = args[0]
+	return nil
+}
+
+func (o *BackupOptions) RunVerify() error {
+	b, err := o.Client.VerifyBackup(context.TODO(), o.Cluster, o.name)
+	if err != nil {
+		return err
+	}
+	return o.PrintFlags.Print(b, o.IOStreams.Out)
+}
+
+func (o *BackupOptions) RunScan() error {
+	backups, err := o.Client.ScanBackupPoint(context.TODO(), o.name)
+	if err != nil {
+		return err
+	}
+	return o.PrintFlags.Print(backups, o.IOStreams.Out)
+}
+
+func (o *BackupOptions) RunEnableCron() error {
+	cb, err := o.Client.EnableCronBackup(context.TODO(), o.name)
+	if err != nil {
+		return err
+	}
+	return o.PrintFlags.Print(cb, o.IOStreams.Out)
+}
+
+func (o *BackupOptions) RunDisableCron() error {
+	cb, err := o.Client.DisableCronBackup(context.TODO(), o.name)
+	if err != nil {
+		return err
+	}
+	return o.PrintFlags.Print(cb, o.IOStreams.Out)
+}
@@ -0,0 +1,249 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/printer"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/utils"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/kc"
+)
+
+/*
+kubeclipper cluster
+
+Usage:
+  kcctl cluster add-nodes
+  kcctl cluster remove-nodes
+  kcctl cluster upgrade
+  kcctl cluster renew-cert
+
+Examples:
+  kcctl cluster add-nodes 'CLUSTER-NAME' --node 'NODE-ID'
+  kcctl cluster upgrade 'CLUSTER-NAME' --version v1.23.6
+
+Flags:
+  -h, --help                   help for cluster
+*/
+
+const (
+	longDescription = `
+  Manage the day-2 operations of clusters.
+
+  The operation is run asynchronously, use 'kcctl get operation' to watch its status.`
+	clusterExample = `
+  # Add worker nodes to the cluster
+  kcctl cluster add-nodes 'CLUSTER-NAME' --node 'NODE-ID'
+
+  # Upgrade the cluster
+  kcctl cluster upgrade 'CLUSTER-NAME' --version v1.23.6
+
+  Please read 'kcctl cluster -h' get more cluster flags.`
+	addNodesExample = `
+  # Add worker nodes to the cluster
+  kcctl cluster add-nodes 'CLUSTER-NAME' --node 'NODE-ID-1' --node 'NODE-ID-2'
+
+  Please read 'kcctl cluster add-nodes -h' get more cluster add-nodes flags.`
+	removeNodesExample = `
+  # Remove worker nodes from the cluster
+  kcctl cluster remove-nodes 'CLUSTER-NAME' --node 'NODE-ID'
+
+  Please read 'kcctl cluster remove-nodes -h' get more cluster remove-nodes flags.`
+	upgradeExample = `
+  # Upgrade the cluster online
+  kcctl cluster upgrade 'CLUSTER-NAME' --version v1.23.6
+
+  # Upgrade the cluster with the offline package and local registry
+  kcctl cluster upgrade 'CLUSTER-NAME' --version v1.23.6 --offline --local-registry 10.0.0.1:5000
+
+  Please read 'kcctl cluster upgrade -h' get more cluster upgrade flags.`
+	renewCertExample = `
+  # Renew the certificates of the cluster
+  kcctl cluster renew-cert 'CLUSTER-NAME'
+
+  Please read 'kcctl cluster renew-cert -h' get more cluster renew-cert flags.`
+)
+
+type ClusterOptions struct {
+	PrintFlags *printer.PrintFlags
+	CliOpts    *options.CliOptions
+	options.IOStreams
+	Client        *kc.Client
+	Nodes         []string
+	Version       string
+	Offline       bool
+	LocalRegistry string
+	name          string
+}
+
+func NewClusterOptions(streams options.IOStreams) *ClusterOptions {
+	return &ClusterOptions{
+		PrintFlags: printer.NewPrintFlags(),
+		CliOpts:    options.NewCliOptions(),
+		IOStreams:  streams,
+	}
+}
+
+func NewCmdCluster(streams options.IOStreams) *cobra.Command {
+	o := NewClusterOptions(streams)
+	cmd := &cobra.Command{
+		Use:                   "cluster",
+		DisableFlagsInUseLine: true,
+		Short:                 "cluster day-2 operation",
+		Long:                  longDescription,
+		Example:               clusterExample,
+		Args:                  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(NewCmdClusterNodes(o, kc.NodesOperationAdd, "add-nodes", "add worker nodes to the cluster", addNodesExample))
+	cmd.AddCommand(NewCmdClusterNodes(o, kc.NodesOperationRemove, "remove-nodes", "remove worker nodes from the cluster", removeNodesExample))
+	cmd.AddCommand(NewCmdClusterUpgrade(o))
+	cmd.AddCommand(NewCmdClusterRenewCert(o))
+
+	return cmd
+}
+
+func NewCmdClusterNodes(o *ClusterOptions, operation, use, short, example string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   use + " <name> (--node) [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 short,
+		Long:                  "\n  " + short + ".",
+		Example:               example,
+		Run: func(cmd *cobra.Command, args []string) {
+			utils.CheckErr(o.Complete(o.CliOpts))
+			utils.CheckErr(o.ValidateArgs(cmd, args))
+			if len(o.Nodes) == 0 {
+				utils.CheckErr(utils.UsageErrorf(cmd, "at least one node must be specified"))
+			}
+			utils.CheckErr(o.RunPatchNodes(operation))
+		},
+	}
+	cmd.Flags().StringSliceVar(&o.Nodes, "node", o.Nodes, "id of the worker node")
+	o.CliOpts.AddFlags(cmd.Flags())
+	o.PrintFlags.AddFlags(cmd)
+	return cmd
+}
+
+func NewCmdClusterUpgrade(o *ClusterOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "upgrade <name> (--version) [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "upgrade the kubernetes version of the cluster",
+		Long:                  "\n  Upgrade the kubernetes version of the cluster.",
+		Example:               upgradeExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			utils.CheckErr(o.Complete(o.CliOpts))
+			utils.CheckErr(o.ValidateArgs(cmd, args))
+			if o.Version == "" {
+				utils.CheckErr(utils.UsageErrorf(cmd, "version must be specified"))
+			}
+			utils.CheckErr(o.RunUpgrade())
+		},
+	}
+	cmd.Flags().StringVar(&o.Version, "version", o.Version, "kubernetes version to upgrade to")
+	cmd.Flags().BoolVar(&o.Offline, "offline", o.Offline, "upgrade with the offline package")
+	cmd.Flags().StringVar(&o.LocalRegistry, "local-registry", o.LocalRegistry, "local registry of the images")
+	o.CliOpts.AddFlags(cmd.Flags())
+	return cmd
+}
+
+func NewCmdClusterRenewCert(o *ClusterOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "renew-cert <name> [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "renew the certificates of the cluster",
+		Long:                  "\n  Renew the certificates of the cluster.",
+		Example:               renewCertExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			utils.CheckErr(o.Complete(o.CliOpts))
+			utils.CheckErr(o.ValidateArgs(cmd, args))
+			utils.CheckErr(o.RunRenewCert())
+		},
+	}
+	o.CliOpts.AddFlags(cmd.Flags())
+	o.PrintFlags.AddFlags(cmd)
+	return cmd
+}
+
+func (o *ClusterOptions) Complete(opts *options.CliOptions) error {
+	if err := opts.Complete(); err != nil {
+		return err
+	}
+	c, err := opts.ToRawConfig().ToKcClient()
+	if err != nil {
+		return err
+	}
+	o.Client = c
+	return nil
+}
+
+func (o *ClusterOptions) ValidateArgs(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return utils.UsageErrorf(cmd, "You must specify the name of the cluster")
+	}
+	o.name = args[0]
+	return nil
+}
+
+func (o *ClusterOptions) RunPatchNodes(operation string) error {
+	patch := &kc.PatchNodes{
+		Operation: operation,
+		Role:      common.NodeRoleWorker,
+	}
+	for _, node := range o.Nodes {
+		patch.Nodes = append(patch.Nodes, v1.WorkerNode{ID: node})
+	}
+	c, err := o.Client.PatchClusterNodes(context.TODO(), o.name, patch)
+	if err != nil {
+		return err
+	}
+	return o.PrintFlags.Print(c, o.IOStreams.Out)
+}
+
+func (o *ClusterOptions) RunUpgrade() error {
+	err := o.Client.UpgradeCluster(context.TODO(), o.name, &kc.ClusterUpgrade{
+		Version:       o.Version,
+		Offline:       o.Offline,
+		LocalRegistry: o.LocalRegistry,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(o.IOStreams.Out, "cluster %s is upgrading to %s\n", o.name, o.Version)
+	return err
+}
+
+func (o *ClusterOptions) RunRenewCert() error {
+	c, err := o.Client.UpdateClusterCertification(context.TODO(), o.name)
+	if err != nil {
+		return err
+	}
+	return o.PrintFlags.Print(c, o.IOStreams.Out)
+}
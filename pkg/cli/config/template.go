@@ -95,7 +95,7 @@ ExecStart=/usr/local/bin/etcd --name {{.NodeName}} \
 --initial-advertise-peer-urls=https://{{.PeerAddress}} \
 --initial-cluster={{.InitialCluster}} \
 --initial-cluster-token={{.ClusterToken}} \
---initial-cluster-state=new \
+--initial-cluster-state={{.InitialClusterState}} \
 --key-file={{.ServerCertKeyPath}} \
 --listen-client-urls={{.ClientURLs}} \
 --listen-metrics-urls={{.MetricsURLs}} \
@@ -224,6 +224,12 @@ mq:
       host: {{.MQServerAddress}}
       port: {{.MQClusterPort}}
       leaderHost: {{.LeaderHost}}
+{{- if .MQRoutes}}
+      routes:
+      {{range .MQRoutes -}}
+      - {{.}}
+      {{end -}}
+{{- end }}
 {{- if .MQTLS}}
     tls: {{.MQTLS}}
     tlsCaPath: {{.MQCaPath}}
@@ -245,6 +251,7 @@ metadata:
 {{- end}}
 registerNode: true
 nodeStatusUpdateFrequency: 1m
+metricsAddress: 0.0.0.0:9891
 downloader:
   address: {{.StaticServerAddress}}
   tlsCertFile: ""
@@ -38,7 +38,13 @@ Examples:
 TODO..
 
 Available Commands:
+  backup      create kubeclipper backup resource
+  backuppoint create kubeclipper backup point resource
   cluster     create kubeclipper cluster resource
+  cronbackup  create kubeclipper cron backup resource
+  domain      create kubeclipper domain resource
+  record      create kubeclipper domain record resource
+  recovery    create kubeclipper recovery resource
   role        create kubeclipper role resource
   user        create kubeclipper role resource
 
@@ -55,7 +61,8 @@ const (
 	longDescription = `
   Create specified resource
 
-  Using the create command to create cluster, user, or role resources.
+  Using the create command to create cluster, user, role, backup point, backup, cron backup,
+  recovery, domain or record resources.
   Or you can choose to create those directly from a file.`
 	createExample = `
   # Using config file to create resource
@@ -105,6 +112,12 @@ func NewCmdCreate(streams options.IOStreams) *cobra.Command {
 	cmd.AddCommand(NewCmdCreateCluster(streams))
 	cmd.AddCommand(NewCmdCreateRole(streams))
 	cmd.AddCommand(NewCmdCreateUser(streams))
+	cmd.AddCommand(NewCmdCreateBackupPoint(streams))
+	cmd.AddCommand(NewCmdCreateBackup(streams))
+	cmd.AddCommand(NewCmdCreateCronBackup(streams))
+	cmd.AddCommand(NewCmdCreateRecovery(streams))
+	cmd.AddCommand(NewCmdCreateDomain(streams))
+	cmd.AddCommand(NewCmdCreateRecord(streams))
 	return cmd
 }
 
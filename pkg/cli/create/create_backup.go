@@ -0,0 +1,143 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package create
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/printer"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/utils"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+/*
+create kubeclipper backup resource
+
+Usage:
+  kcctl create backup (--cluster) (--name) [flags]
+
+Flags:
+      --cluster string          cluster to back up
+  -c, --config string           Path to the config file to use for CLI requests.
+  -h, --help                    help for backup
+      --name string             backup name
+  -o, --output string           Output format either: json,yaml,table (default "table")
+      --preferred-node string   master node to run the backup on
+*/
+
+const (
+	backupLongDescription = `
+  Create etcd backup of the cluster using command line
+
+  The backup is stored in the backup point of the cluster.`
+	createBackupExample = `
+  # Create backup of the cluster
+  kcctl create backup --cluster demo --name before-upgrade
+
+  # Create backup of the cluster on the specified master node
+  kcctl create backup --cluster demo --name before-upgrade --preferred-node 'NODE-ID'
+
+  Please read 'kcctl create backup -h' get more create backup flags.`
+)
+
+type CreateBackupOptions struct {
+	BaseOptions
+	Cluster       string
+	Name          string
+	PreferredNode string
+}
+
+func NewCreateBackupOptions(streams options.IOStreams) *CreateBackupOptions {
+	return &CreateBackupOptions{
+		BaseOptions: BaseOptions{
+			PrintFlags: printer.NewPrintFlags(),
+			CliOpts:    options.NewCliOptions(),
+			IOStreams:  streams,
+		},
+	}
+}
+
+func NewCmdCreateBackup(streams options.IOStreams) *cobra.Command {
+	o := NewCreateBackupOptions(streams)
+	cmd := &cobra.Command{
+		Use:                   "backup (--cluster) (--name) [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "create kubeclipper backup resource",
+		Long:                  backupLongDescription,
+		Example:               createBackupExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			utils.CheckErr(o.ValidateArgs(cmd))
+			utils.CheckErr(o.Complete(o.CliOpts))
+			utils.CheckErr(o.RunCreate())
+		},
+	}
+	cmd.Flags().StringVar(&o.Cluster, "cluster", "", "cluster to back up")
+	cmd.Flags().StringVar(&o.Name, "name", "", "backup name")
+	cmd.Flags().StringVar(&o.PreferredNode, "preferred-node", "", "master node to run the backup on")
+	o.CliOpts.AddFlags(cmd.Flags())
+	o.PrintFlags.AddFlags(cmd)
+
+	_ = cmd.MarkFlagRequired("cluster")
+	_ = cmd.MarkFlagRequired("name")
+	return cmd
+}
+
+func (l *CreateBackupOptions) Complete(opts *options.CliOptions) error {
+	if err := opts.Complete(); err != nil {
+		return err
+	}
+	c, err := opts.ToRawConfig().ToKcClient()
+	if err != nil {
+		return err
+	}
+	l.Client = c
+	return nil
+}
+
+func (l *CreateBackupOptions) ValidateArgs(cmd *cobra.Command) error {
+	if l.Cluster == "" {
+		return utils.UsageErrorf(cmd, "cluster must be specified")
+	}
+	if l.Name == "" {
+		return utils.UsageErrorf(cmd, "backup name must be specified")
+	}
+	return nil
+}
+
+func (l *CreateBackupOptions) RunCreate() error {
+	backup := &v1.Backup{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Backup",
+			APIVersion: "core.kubeclipper.io/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: l.Name,
+		},
+		PreferredNode: l.PreferredNode,
+	}
+	resp, err := l.Client.CreateBackup(context.TODO(), l.Cluster, backup)
+	if err != nil {
+		return err
+	}
+	return l.PrintFlags.Print(resp, l.IOStreams.Out)
+}
@@ -0,0 +1,245 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package create
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/printer"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/utils"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	bs "github.com/kubeclipper/kubeclipper/pkg/simple/backupstore"
+)
+
+/*
+create kubeclipper backup point resource
+
+Usage:
+  kcctl create backuppoint (--name) (--type) [flags]
+
+Flags:
+      --access-key-id string       access key id of the s3 storage
+      --access-key-secret string   access key secret of the s3 storage
+      --bucket string              bucket of the s3 storage
+  -c, --config string              Path to the config file to use for CLI requests.
+      --description string         backup point description
+      --endpoint string            endpoint of the s3 or webdav storage
+  -h, --help                       help for backuppoint
+      --host string                host or host:port of the sftp server
+      --name string                backup point name
+  -o, --output string              Output format either: json,yaml,table (default "table")
+      --password string            password of the sftp or webdav storage
+      --pk-file string             private key file of the sftp server
+      --root-dir string            root directory of the fs or sftp storage
+      --type string                storage type, fs, s3, sftp or webdav
+      --username string            username of the sftp or webdav storage
+*/
+
+const (
+	backupPointLongDescription = `
+  Create backup point using command line`
+	createBackupPointExample = `
+  # Create fs backup point
+  kcctl create backuppoint --name fs-bp --type fs --root-dir /opt/kc/backups
+
+  # Create s3 backup point
+  kcctl create backuppoint --name s3-bp --type s3 --endpoint 10.0.0.1:9000 --bucket backups --access-key-id 'ID' --access-key-secret 'SECRET'
+
+  # Create sftp backup point with a private key
+  kcctl create backuppoint --name sftp-bp --type sftp --host 10.0.0.1:22 --username backup --pk-file ~/.ssh/id_rsa --root-dir /data/backups
+
+  # Create webdav backup point
+  kcctl create backuppoint --name dav-bp --type webdav --endpoint https://dav.example.com/backups --username backup --password 'PWD'
+
+  Please read 'kcctl create backuppoint -h' get more create backup point flags.`
+)
+
+type CreateBackupPointOptions struct {
+	BaseOptions
+	Name            string
+	StorageType     string
+	Description     string
+	RootDir         string
+	Endpoint        string
+	Bucket          string
+	AccessKeyID     string
+	AccessKeySecret string
+	Host            string
+	Username        string
+	Password        string
+	PkFile          string
+}
+
+func NewCreateBackupPointOptions(streams options.IOStreams) *CreateBackupPointOptions {
+	return &CreateBackupPointOptions{
+		BaseOptions: BaseOptions{
+			PrintFlags: printer.NewPrintFlags(),
+			CliOpts:    options.NewCliOptions(),
+			IOStreams:  streams,
+		},
+	}
+}
+
+func NewCmdCreateBackupPoint(streams options.IOStreams) *cobra.Command {
+	o := NewCreateBackupPointOptions(streams)
+	cmd := &cobra.Command{
+		Use:                   "backuppoint (--name) (--type) [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "create kubeclipper backup point resource",
+		Long:                  backupPointLongDescription,
+		Example:               createBackupPointExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			utils.CheckErr(o.ValidateArgs(cmd))
+			utils.CheckErr(o.Complete(o.CliOpts))
+			utils.CheckErr(o.RunCreate())
+		},
+	}
+	cmd.Flags().StringVar(&o.Name, "name", "", "backup point name")
+	cmd.Flags().StringVar(&o.StorageType, "type", "", "storage type, fs, s3, sftp or webdav")
+	cmd.Flags().StringVar(&o.Description, "description", "", "backup point description")
+	cmd.Flags().StringVar(&o.RootDir, "root-dir", "", "root directory of the fs or sftp storage")
+	cmd.Flags().StringVar(&o.Endpoint, "endpoint", "", "endpoint of the s3 or webdav storage")
+	cmd.Flags().StringVar(&o.Bucket, "bucket", "", "bucket of the s3 storage")
+	cmd.Flags().StringVar(&o.AccessKeyID, "access-key-id", "", "access key id of the s3 storage")
+	cmd.Flags().StringVar(&o.AccessKeySecret, "access-key-secret", "", "access key secret of the s3 storage")
+	cmd.Flags().StringVar(&o.Host, "host", "", "host or host:port of the sftp server")
+	cmd.Flags().StringVar(&o.Username, "username", "", "username of the sftp or webdav storage")
+	cmd.Flags().StringVar(&o.Password, "password", "", "password of the sftp or webdav storage")
+	cmd.Flags().StringVar(&o.PkFile, "pk-file", "", "private key file of the sftp server")
+	o.CliOpts.AddFlags(cmd.Flags())
+	o.PrintFlags.AddFlags(cmd)
+
+	utils.CheckErr(cmd.RegisterFlagCompletionFunc("type", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{bs.FSStorage, bs.S3Storage, bs.SFTPStorage, bs.WebDAVStorage}, cobra.ShellCompDirectiveNoFileComp
+	}))
+	_ = cmd.MarkFlagRequired("name")
+	_ = cmd.MarkFlagRequired("type")
+	return cmd
+}
+
+func (l *CreateBackupPointOptions) Complete(opts *options.CliOptions) error {
+	if err := opts.Complete(); err != nil {
+		return err
+	}
+	c, err := opts.ToRawConfig().ToKcClient()
+	if err != nil {
+		return err
+	}
+	l.Client = c
+	return nil
+}
+
+func (l *CreateBackupPointOptions) ValidateArgs(cmd *cobra.Command) error {
+	if l.Name == "" {
+		return utils.UsageErrorf(cmd, "backup point name must be specified")
+	}
+	l.StorageType = strings.ToLower(l.StorageType)
+	switch l.StorageType {
+	case bs.FSStorage:
+		if l.RootDir == "" {
+			return utils.UsageErrorf(cmd, "root dir must be specified for fs storage")
+		}
+	case bs.S3Storage:
+		if l.Endpoint == "" || l.Bucket == "" {
+			return utils.UsageErrorf(cmd, "endpoint and bucket must be specified for s3 storage")
+		}
+	case bs.SFTPStorage:
+		if l.Host == "" || l.Username == "" {
+			return utils.UsageErrorf(cmd, "host and username must be specified for sftp storage")
+		}
+		if l.Password == "" && l.PkFile == "" {
+			return utils.UsageErrorf(cmd, "password or pk-file must be specified for sftp storage")
+		}
+	case bs.WebDAVStorage:
+		if l.Endpoint == "" {
+			return utils.UsageErrorf(cmd, "endpoint must be specified for webdav storage")
+		}
+	default:
+		return utils.UsageErrorf(cmd, "unsupported storage type %q, support fs, s3, sftp and webdav", l.StorageType)
+	}
+	return nil
+}
+
+func (l *CreateBackupPointOptions) RunCreate() error {
+	bp, err := l.newBackupPoint()
+	if err != nil {
+		return err
+	}
+	resp, err := l.Client.CreateBackupPoint(context.TODO(), bp)
+	if err != nil {
+		return err
+	}
+	return l.PrintFlags.Print(resp, l.IOStreams.Out)
+}
+
+func (l *CreateBackupPointOptions) newBackupPoint() (*v1.BackupPoint, error) {
+	bp := &v1.BackupPoint{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "BackupPoint",
+			APIVersion: "core.kubeclipper.io/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: l.Name,
+		},
+		StorageType: l.StorageType,
+	}
+	switch l.StorageType {
+	case bs.FSStorage:
+		bp.FsConfig = &v1.FsConfig{
+			BackupRootDir: l.RootDir,
+			Description:   l.Description,
+		}
+	case bs.S3Storage:
+		bp.S3Config = &v1.S3Config{
+			Bucket:          l.Bucket,
+			Endpoint:        l.Endpoint,
+			AccessKeyID:     l.AccessKeyID,
+			AccessKeySecret: l.AccessKeySecret,
+		}
+	case bs.SFTPStorage:
+		bp.SftpConfig = &v1.SftpConfig{
+			Host:          l.Host,
+			Username:      l.Username,
+			Password:      l.Password,
+			BackupRootDir: l.RootDir,
+			Description:   l.Description,
+		}
+		if l.PkFile != "" {
+			pk, err := os.ReadFile(l.PkFile)
+			if err != nil {
+				return nil, err
+			}
+			bp.SftpConfig.PrivateKey = string(pk)
+		}
+	case bs.WebDAVStorage:
+		bp.WebDAVConfig = &v1.WebDAVConfig{
+			Endpoint:    l.Endpoint,
+			Username:    l.Username,
+			Password:    l.Password,
+			Description: l.Description,
+		}
+	}
+	return bp, nil
+}
@@ -54,7 +54,7 @@ const (
   The offline flag is 'online' by default.
   The untaint-master flag is FALSE by default.
   The cri flag is 'containerd' by defualt.
-  The cni flag is 'calico' by default.`
+  The cni flag is 'calico' by default, 'cilium' and 'flannel' are supported too.`
 	createClusterExample = `
   # Create cluster offline. The default value of offline is true, so it can be omitted.
   kcctl create cluster --name demo --master 192.168.10.123
@@ -65,6 +65,9 @@ const (
   # Create cluster with taint manage
   kcctl create cluster --name demo --master 192.168.10.123 --untaint-master true
 
+  # Create cluster with cilium cni
+  kcctl create cluster --name demo --master 192.168.10.123 --cni cilium
+
   Please read 'kcctl create cluster -h' get more create cluster flags.`
 )
 
@@ -85,7 +88,13 @@ type CreateClusterOptions struct {
 
 var (
 	allowedCRI = sets.NewString("containerd", "docker")
-	allowedCNI = sets.NewString("calico")
+	allowedCNI = v1.AllowedCNI
+	// cniVersions are the default cni versions, the server prefers the versions matching the k8s version.
+	cniVersions = map[string]string{
+		"calico":  "v3.21.2",
+		"cilium":  "v1.12.4",
+		"flannel": "v0.20.2",
+	}
 )
 
 func NewCreateClusterOptions(streams options.IOStreams) *CreateClusterOptions {
@@ -106,7 +115,7 @@ func NewCreateClusterOptions(streams options.IOStreams) *CreateClusterOptions {
 func NewCmdCreateCluster(streams options.IOStreams) *cobra.Command {
 	o := NewCreateClusterOptions(streams)
 	cmd := &cobra.Command{
-		Use:                   "cluster (--name) <name> (-m|--master) <id or ip> [(--offline <online> | <offline>)] [(--cri <docker> | <containerd>)] [(--cni <calico> | <others> )] [flags]",
+		Use:                   "cluster (--name) <name> (-m|--master) <id or ip> [(--offline <online> | <offline>)] [(--cri <docker> | <containerd>)] [(--cni <calico> | <cilium> | <flannel>)] [flags]",
 		DisableFlagsInUseLine: true,
 		Short:                 "create kubeclipper cluster resource",
 		Long:                  clusterLongDescription,
@@ -128,7 +137,7 @@ func NewCmdCreateCluster(streams options.IOStreams) *cobra.Command {
 	cmd.Flags().StringVar(&o.CRI, "cri", o.CRI, "k8s cri type, docker or containerd")
 	cmd.Flags().StringVar(&o.CRIVersion, "cri-version", o.CRIVersion, "k8s cri version")
 	cmd.Flags().StringVar(&o.K8sVersion, "k8s-version", o.K8sVersion, "k8s version")
-	cmd.Flags().StringVar(&o.CNI, "cni", o.CNI, "k8s cni type, calico or others")
+	cmd.Flags().StringVar(&o.CNI, "cni", o.CNI, "k8s cni type, calico, cilium or flannel")
 	o.CliOpts.AddFlags(cmd.Flags())
 	o.PrintFlags.AddFlags(cmd)
 
@@ -317,18 +326,30 @@ func (l *CreateClusterOptions) newCluster() *v1.Cluster {
 		CNI: v1.CNI{
 			LocalRegistry: l.LocalRegistry,
 			Type:          l.CNI,
-			Version:       "v3.21.2",
-			Calico: &v1.Calico{
-				IPv4AutoDetection: "first-found",
-				IPv6AutoDetection: "first-found",
-				Mode:              "Overlay-Vxlan-All",
-				IPManger:          true,
-				MTU:               1440,
-			},
+			Version:       cniVersions[l.CNI],
 		},
 
 		Status: v1.ClusterStatus{},
 	}
+	switch l.CNI {
+	case "calico":
+		c.CNI.Calico = &v1.Calico{
+			IPv4AutoDetection: "first-found",
+			IPv6AutoDetection: "first-found",
+			Mode:              "Overlay-Vxlan-All",
+			IPManger:          true,
+			MTU:               1440,
+		}
+	case "cilium":
+		c.CNI.Cilium = &v1.Cilium{
+			TunnelMode:           "vxlan",
+			KubeProxyReplacement: "disabled",
+		}
+	case "flannel":
+		c.CNI.Flannel = &v1.Flannel{
+			Backend: "vxlan",
+		}
+	}
 
 	masters := make([]v1.WorkerNode, 0)
 	for _, n := range l.Masters {
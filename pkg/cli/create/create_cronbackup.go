@@ -0,0 +1,198 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package create
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/printer"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/utils"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+/*
+create kubeclipper cron backup resource
+
+Usage:
+  kcctl create cronbackup (--name) (--cluster) (--schedule | --run-at) [flags]
+
+Flags:
+      --cluster string       cluster to back up
+  -c, --config string        Path to the config file to use for CLI requests.
+  -h, --help                 help for cronbackup
+      --keep-daily int       number of daily backups to keep
+      --keep-hourly int      number of hourly backups to keep
+      --keep-monthly int     number of monthly backups to keep
+      --keep-weekly int      number of weekly backups to keep
+      --max-backup-num int   maximum number of backups to keep
+      --name string          cron backup name
+  -o, --output string        Output format either: json,yaml,table (default "table")
+      --run-at string        run the backup once at the time, in RFC3339 format
+      --schedule string      schedule of the backups in cron format
+      --verify               verify each backup by restoring it in a scratch directory
+*/
+
+const (
+	cronBackupLongDescription = `
+  Create cron backup using command line
+
+  The cron backup backs up the cluster periodically by the schedule,
+  or once at the specified time.`
+	createCronBackupExample = `
+  # Back up the cluster at 1 am every day and keep 7 backups
+  kcctl create cronbackup --name daily --cluster demo --schedule '0 1 * * *' --max-backup-num 7
+
+  # Back up the cluster hourly, keep 24 hourly and 7 daily backups
+  kcctl create cronbackup --name hourly --cluster demo --schedule '0 * * * *' --keep-hourly 24 --keep-daily 7
+
+  # Back up the cluster once
+  kcctl create cronbackup --name once --cluster demo --run-at 2026-01-01T01:00:00+08:00
+
+  Please read 'kcctl create cronbackup -h' get more create cron backup flags.`
+)
+
+type CreateCronBackupOptions struct {
+	BaseOptions
+	Name         string
+	Cluster      string
+	Schedule     string
+	RunAt        string
+	MaxBackupNum int
+	Verify       bool
+	Retention    v1.RetentionPolicy
+	runAt        time.Time
+}
+
+func NewCreateCronBackupOptions(streams options.IOStreams) *CreateCronBackupOptions {
+	return &CreateCronBackupOptions{
+		BaseOptions: BaseOptions{
+			PrintFlags: printer.NewPrintFlags(),
+			CliOpts:    options.NewCliOptions(),
+			IOStreams:  streams,
+		},
+	}
+}
+
+func NewCmdCreateCronBackup(streams options.IOStreams) *cobra.Command {
+	o := NewCreateCronBackupOptions(streams)
+	cmd := &cobra.Command{
+		Use:                   "cronbackup (--name) (--cluster) (--schedule | --run-at) [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "create kubeclipper cron backup resource",
+		Long:                  cronBackupLongDescription,
+		Example:               createCronBackupExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			utils.CheckErr(o.ValidateArgs(cmd))
+			utils.CheckErr(o.Complete(o.CliOpts))
+			utils.CheckErr(o.RunCreate())
+		},
+	}
+	cmd.Flags().StringVar(&o.Name, "name", "", "cron backup name")
+	cmd.Flags().StringVar(&o.Cluster, "cluster", "", "cluster to back up")
+	cmd.Flags().StringVar(&o.Schedule, "schedule", "", "schedule of the backups in cron format")
+	cmd.Flags().StringVar(&o.RunAt, "run-at", "", "run the backup once at the time, in RFC3339 format")
+	cmd.Flags().IntVar(&o.MaxBackupNum, "max-backup-num", 0, "maximum number of backups to keep")
+	cmd.Flags().BoolVar(&o.Verify, "verify", false, "verify each backup by restoring it in a scratch directory")
+	cmd.Flags().IntVar(&o.Retention.Hourly, "keep-hourly", 0, "number of hourly backups to keep")
+	cmd.Flags().IntVar(&o.Retention.Daily, "keep-daily", 0, "number of daily backups to keep")
+	cmd.Flags().IntVar(&o.Retention.Weekly, "keep-weekly", 0, "number of weekly backups to keep")
+	cmd.Flags().IntVar(&o.Retention.Monthly, "keep-monthly", 0, "number of monthly backups to keep")
+	o.CliOpts.AddFlags(cmd.Flags())
+	o.PrintFlags.AddFlags(cmd)
+
+	_ = cmd.MarkFlagRequired("name")
+	_ = cmd.MarkFlagRequired("cluster")
+	return cmd
+}
+
+func (l *CreateCronBackupOptions) Complete(opts *options.CliOptions) error {
+	if err := opts.Complete(); err != nil {
+		return err
+	}
+	c, err := opts.ToRawConfig().ToKcClient()
+	if err != nil {
+		return err
+	}
+	l.Client = c
+	return nil
+}
+
+func (l *CreateCronBackupOptions) ValidateArgs(cmd *cobra.Command) error {
+	if l.Name == "" || l.Cluster == "" {
+		return utils.UsageErrorf(cmd, "cron backup name and cluster must be specified")
+	}
+	if (l.Schedule == "") == (l.RunAt == "") {
+		return utils.UsageErrorf(cmd, "one of schedule and run-at must be specified")
+	}
+	if l.RunAt != "" {
+		t, err := time.Parse(time.RFC3339, l.RunAt)
+		if err != nil {
+			return utils.UsageErrorf(cmd, "invalid run-at %q: %v", l.RunAt, err)
+		}
+		l.runAt = t
+	}
+	if l.MaxBackupNum < 0 {
+		return utils.UsageErrorf(cmd, "max-backup-num must not be negative")
+	}
+	return nil
+}
+
+func (l *CreateCronBackupOptions) RunCreate() error {
+	resp, err := l.Client.CreateCronBackup(context.TODO(), l.newCronBackup())
+	if err != nil {
+		return err
+	}
+	return l.PrintFlags.Print(resp, l.IOStreams.Out)
+}
+
+func (l *CreateCronBackupOptions) newCronBackup() *v1.CronBackup {
+	cb := &v1.CronBackup{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "CronBackup",
+			APIVersion: "core.kubeclipper.io/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: l.Name,
+			Labels: map[string]string{
+				common.LabelClusterName: l.Cluster,
+			},
+		},
+		Spec: v1.CronBackupSpec{
+			ClusterName:  l.Cluster,
+			Schedule:     l.Schedule,
+			MaxBackupNum: l.MaxBackupNum,
+			Verify:       l.Verify,
+		},
+	}
+	if l.RunAt != "" {
+		runAt := metav1.NewTime(l.runAt)
+		cb.Spec.RunAt = &runAt
+	}
+	if l.Retention != (v1.RetentionPolicy{}) {
+		retention := l.Retention
+		cb.Spec.RetentionPolicy = &retention
+	}
+	return cb
+}
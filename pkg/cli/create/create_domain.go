@@ -0,0 +1,164 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package create
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/printer"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/utils"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+/*
+create kubeclipper domain resource
+
+Usage:
+  kcctl create domain (--name) [flags]
+
+Flags:
+  -c, --config string            Path to the config file to use for CLI requests.
+      --description string       domain description
+      --forward-policy string    policy to select the upstream, random, round_robin or sequential
+      --forwarder strings        upstream dns server to forward the names without record to, ip or ip:port
+  -h, --help                     help for domain
+      --name string              domain name
+  -o, --output string            Output format either: json,yaml,table (default "table")
+      --sync-cluster strings     cluster to sync the domain to
+      --tls-server-name string   server name to verify the upstreams with dns over tls
+*/
+
+const (
+	domainLongDescription = `
+  Create domain using command line
+
+  The records of the domain are resolved by the coredns of the synced clusters.`
+	createDomainExample = `
+  # Create domain synced to cluster demo
+  kcctl create domain --name example.com --sync-cluster demo
+
+  # Create domain which forwards the names without record to the corporate dns
+  kcctl create domain --name corp.example --sync-cluster demo --forwarder 10.0.0.53 --forwarder 10.0.0.54
+
+  Please read 'kcctl create domain -h' get more create domain flags.`
+)
+
+type CreateDomainOptions struct {
+	BaseOptions
+	Name          string
+	Description   string
+	SyncClusters  []string
+	Forwarders    []string
+	ForwardPolicy string
+	TLSServerName string
+}
+
+func NewCreateDomainOptions(streams options.IOStreams) *CreateDomainOptions {
+	return &CreateDomainOptions{
+		BaseOptions: BaseOptions{
+			PrintFlags: printer.NewPrintFlags(),
+			CliOpts:    options.NewCliOptions(),
+			IOStreams:  streams,
+		},
+	}
+}
+
+func NewCmdCreateDomain(streams options.IOStreams) *cobra.Command {
+	o := NewCreateDomainOptions(streams)
+	cmd := &cobra.Command{
+		Use:                   "domain (--name) [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "create kubeclipper domain resource",
+		Long:                  domainLongDescription,
+		Example:               createDomainExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			utils.CheckErr(o.ValidateArgs(cmd))
+			utils.CheckErr(o.Complete(o.CliOpts))
+			utils.CheckErr(o.RunCreate())
+		},
+	}
+	cmd.Flags().StringVar(&o.Name, "name", "", "domain name")
+	cmd.Flags().StringVar(&o.Description, "description", "", "domain description")
+	cmd.Flags().StringSliceVar(&o.SyncClusters, "sync-cluster", nil, "cluster to sync the domain to")
+	cmd.Flags().StringSliceVar(&o.Forwarders, "forwarder", nil, "upstream dns server to forward the names without record to, ip or ip:port")
+	cmd.Flags().StringVar(&o.ForwardPolicy, "forward-policy", "", "policy to select the upstream, random, round_robin or sequential")
+	cmd.Flags().StringVar(&o.TLSServerName, "tls-server-name", "", "server name to verify the upstreams with dns over tls")
+	o.CliOpts.AddFlags(cmd.Flags())
+	o.PrintFlags.AddFlags(cmd)
+
+	utils.CheckErr(cmd.RegisterFlagCompletionFunc("forward-policy", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{v1.ForwardPolicyRandom, v1.ForwardPolicyRoundRobin, v1.ForwardPolicySequential}, cobra.ShellCompDirectiveNoFileComp
+	}))
+	_ = cmd.MarkFlagRequired("name")
+	return cmd
+}
+
+func (l *CreateDomainOptions) Complete(opts *options.CliOptions) error {
+	if err := opts.Complete(); err != nil {
+		return err
+	}
+	c, err := opts.ToRawConfig().ToKcClient()
+	if err != nil {
+		return err
+	}
+	l.Client = c
+	return nil
+}
+
+func (l *CreateDomainOptions) ValidateArgs(cmd *cobra.Command) error {
+	if l.Name == "" {
+		return utils.UsageErrorf(cmd, "domain name must be specified")
+	}
+	if len(l.Forwarders) == 0 && (l.ForwardPolicy != "" || l.TLSServerName != "") {
+		return utils.UsageErrorf(cmd, "forward-policy and tls-server-name require forwarder")
+	}
+	return nil
+}
+
+func (l *CreateDomainOptions) RunCreate() error {
+	domain := &v1.Domain{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Domain",
+			APIVersion: "core.kubeclipper.io/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: l.Name,
+		},
+		Spec: v1.DomainSpec{
+			Description: l.Description,
+			SyncCluster: l.SyncClusters,
+		},
+	}
+	if len(l.Forwarders) > 0 {
+		domain.Spec.Forwarders = &v1.Forwarders{
+			Upstreams:     l.Forwarders,
+			Policy:        l.ForwardPolicy,
+			TLSServerName: l.TLSServerName,
+		}
+	}
+	resp, err := l.Client.CreateDomain(context.TODO(), domain)
+	if err != nil {
+		return err
+	}
+	return l.PrintFlags.Print(resp, l.IOStreams.Out)
+}
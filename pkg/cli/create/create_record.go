@@ -0,0 +1,205 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package create
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/printer"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/utils"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+/*
+create kubeclipper domain record resource
+
+Usage:
+  kcctl create record (--domain) (--rr) (--value) [flags]
+
+Flags:
+  -c, --config string        Path to the config file to use for CLI requests.
+      --description string   record description
+      --domain string        domain of the record
+  -h, --help                 help for record
+  -o, --output string        Output format either: json,yaml,table (default "table")
+      --rr string            resource record, e.g. www, @ for the domain itself, * for wildcard
+      --ttl int32            ttl of the record in seconds, 60 if not set
+      --type string          record type, A, AAAA, CNAME, SRV or TXT (default "A")
+      --value strings        record value, ip of A/AAAA, target of CNAME, 'priority weight port target' of SRV or text of TXT
+*/
+
+const (
+	recordLongDescription = `
+  Create domain record using command line
+
+  A record may have several values of the same type, e.g. ips of A record or targets of SRV record.`
+	createRecordExample = `
+  # Create A record www.example.com resolved to 2 ips
+  kcctl create record --domain example.com --rr www --value 10.0.0.1 --value 10.0.0.2
+
+  # Create CNAME record
+  kcctl create record --domain example.com --rr docs --type CNAME --value example.github.io --ttl 600
+
+  # Create SRV record
+  kcctl create record --domain example.com --rr _ldap._tcp --type SRV --value '10 60 389 ldap1.example.com'
+
+  Please read 'kcctl create record -h' get more create record flags.`
+)
+
+type CreateRecordOptions struct {
+	BaseOptions
+	Domain      string
+	RR          string
+	Type        string
+	Values      []string
+	TTL         int32
+	Description string
+}
+
+func NewCreateRecordOptions(streams options.IOStreams) *CreateRecordOptions {
+	return &CreateRecordOptions{
+		BaseOptions: BaseOptions{
+			PrintFlags: printer.NewPrintFlags(),
+			CliOpts:    options.NewCliOptions(),
+			IOStreams:  streams,
+		},
+		Type: v1.RecordTypeA,
+	}
+}
+
+func NewCmdCreateRecord(streams options.IOStreams) *cobra.Command {
+	o := NewCreateRecordOptions(streams)
+	cmd := &cobra.Command{
+		Use:                   "record (--domain) (--rr) (--value) [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "create kubeclipper domain record resource",
+		Long:                  recordLongDescription,
+		Example:               createRecordExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			utils.CheckErr(o.ValidateArgs(cmd))
+			utils.CheckErr(o.Complete(o.CliOpts))
+			utils.CheckErr(o.RunCreate())
+		},
+	}
+	cmd.Flags().StringVar(&o.Domain, "domain", "", "domain of the record")
+	cmd.Flags().StringVar(&o.RR, "rr", "", "resource record, e.g. www, @ for the domain itself, * for wildcard")
+	cmd.Flags().StringVar(&o.Type, "type", o.Type, "record type, A, AAAA, CNAME, SRV or TXT")
+	cmd.Flags().StringSliceVar(&o.Values, "value", nil, "record value, ip of A/AAAA, target of CNAME, 'priority weight port target' of SRV or text of TXT")
+	cmd.Flags().Int32Var(&o.TTL, "ttl", 0, "ttl of the record in seconds, 60 if not set")
+	cmd.Flags().StringVar(&o.Description, "description", "", "record description")
+	o.CliOpts.AddFlags(cmd.Flags())
+	o.PrintFlags.AddFlags(cmd)
+
+	utils.CheckErr(cmd.RegisterFlagCompletionFunc("type", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{v1.RecordTypeA, v1.RecordTypeAAAA, v1.RecordTypeCNAME, v1.RecordTypeSRV, v1.RecordTypeTXT}, cobra.ShellCompDirectiveNoFileComp
+	}))
+	_ = cmd.MarkFlagRequired("domain")
+	_ = cmd.MarkFlagRequired("rr")
+	_ = cmd.MarkFlagRequired("value")
+	return cmd
+}
+
+func (l *CreateRecordOptions) Complete(opts *options.CliOptions) error {
+	if err := opts.Complete(); err != nil {
+		return err
+	}
+	c, err := opts.ToRawConfig().ToKcClient()
+	if err != nil {
+		return err
+	}
+	l.Client = c
+	return nil
+}
+
+func (l *CreateRecordOptions) ValidateArgs(cmd *cobra.Command) error {
+	if l.Domain == "" || l.RR == "" {
+		return utils.UsageErrorf(cmd, "domain and rr must be specified")
+	}
+	if len(l.Values) == 0 {
+		return utils.UsageErrorf(cmd, "at least one value must be specified")
+	}
+	l.Type = strings.ToUpper(l.Type)
+	return nil
+}
+
+func (l *CreateRecordOptions) RunCreate() error {
+	record, err := l.newRecord()
+	if err != nil {
+		return err
+	}
+	resp, err := l.Client.CreateRecord(context.TODO(), l.Domain, record)
+	if err != nil {
+		return err
+	}
+	return l.PrintFlags.Print(resp, l.IOStreams.Out)
+}
+
+func (l *CreateRecordOptions) newRecord() (*v1.Record, error) {
+	record := &v1.Record{
+		Domain:      l.Domain,
+		RR:          l.RR,
+		Description: l.Description,
+		TTL:         l.TTL,
+	}
+	for _, value := range l.Values {
+		pr, err := parseRecordValue(l.Type, value)
+		if err != nil {
+			return nil, err
+		}
+		record.ParseRecord = append(record.ParseRecord, pr)
+	}
+	return record, nil
+}
+
+// parseRecordValue parses the value of the record type, the SRV value is
+// 'priority weight port target' as it is in the zone file.
+func parseRecordValue(typ, value string) (v1.ParseRecord, error) {
+	pr := v1.ParseRecord{Type: typ}
+	switch typ {
+	case v1.RecordTypeA, v1.RecordTypeAAAA:
+		pr.IP = value
+	case v1.RecordTypeCNAME:
+		pr.Target = value
+	case v1.RecordTypeTXT:
+		pr.Text = value
+	case v1.RecordTypeSRV:
+		fields := strings.Fields(value)
+		if len(fields) != 4 {
+			return pr, fmt.Errorf("invalid SRV value %q, must be 'priority weight port target'", value)
+		}
+		nums := make([]uint16, 3)
+		for i, f := range fields[:3] {
+			n, err := strconv.ParseUint(f, 10, 16)
+			if err != nil {
+				return pr, fmt.Errorf("invalid SRV value %q: %v", value, err)
+			}
+			nums[i] = uint16(n)
+		}
+		pr.Priority, pr.Weight, pr.Port, pr.Target = nums[0], nums[1], nums[2], fields[3]
+	default:
+		return pr, fmt.Errorf("unsupported record type %q, support A, AAAA, CNAME, SRV and TXT", typ)
+	}
+	return pr, nil
+}
@@ -0,0 +1,90 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package create
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func TestParseRecordValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		typ     string
+		value   string
+		want    v1.ParseRecord
+		wantErr bool
+	}{
+		{
+			name:  "a",
+			typ:   v1.RecordTypeA,
+			value: "10.0.0.1",
+			want:  v1.ParseRecord{Type: v1.RecordTypeA, IP: "10.0.0.1"},
+		},
+		{
+			name:  "cname",
+			typ:   v1.RecordTypeCNAME,
+			value: "example.github.io",
+			want:  v1.ParseRecord{Type: v1.RecordTypeCNAME, Target: "example.github.io"},
+		},
+		{
+			name:  "srv",
+			typ:   v1.RecordTypeSRV,
+			value: "10 60  389 ldap1.example.com",
+			want:  v1.ParseRecord{Type: v1.RecordTypeSRV, Priority: 10, Weight: 60, Port: 389, Target: "ldap1.example.com"},
+		},
+		{
+			name:  "txt with spaces",
+			typ:   v1.RecordTypeTXT,
+			value: "v=spf1 -all",
+			want:  v1.ParseRecord{Type: v1.RecordTypeTXT, Text: "v=spf1 -all"},
+		},
+		{
+			name:    "srv without target",
+			typ:     v1.RecordTypeSRV,
+			value:   "10 60 389",
+			wantErr: true,
+		},
+		{
+			name:    "srv port overflow",
+			typ:     v1.RecordTypeSRV,
+			value:   "10 60 65536 ldap1.example.com",
+			wantErr: true,
+		},
+		{
+			name:    "unsupported type",
+			typ:     "MX",
+			value:   "10 mail.example.com",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRecordValue(tt.typ, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRecordValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseRecordValue() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
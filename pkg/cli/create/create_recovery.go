@@ -0,0 +1,138 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package create
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/printer"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/utils"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+/*
+create kubeclipper recovery resource
+
+Usage:
+  kcctl create recovery (--cluster) (--backup) [flags]
+
+Flags:
+      --backup string        backup to restore
+      --cluster string       cluster to restore
+  -c, --config string        Path to the config file to use for CLI requests.
+      --description string   recovery description
+  -h, --help                 help for recovery
+  -o, --output string        Output format either: json,yaml,table (default "table")
+*/
+
+const (
+	recoveryLongDescription = `
+  Restore the cluster from its backup using command line
+
+  The etcd data of the cluster is replaced by the snapshot of the backup.`
+	createRecoveryExample = `
+  # Restore the cluster from the backup
+  kcctl create recovery --cluster demo --backup 'BACKUP-NAME'
+
+  Please read 'kcctl create recovery -h' get more create recovery flags.`
+)
+
+type CreateRecoveryOptions struct {
+	BaseOptions
+	Cluster     string
+	Backup      string
+	Description string
+}
+
+func NewCreateRecoveryOptions(streams options.IOStreams) *CreateRecoveryOptions {
+	return &CreateRecoveryOptions{
+		BaseOptions: BaseOptions{
+			PrintFlags: printer.NewPrintFlags(),
+			CliOpts:    options.NewCliOptions(),
+			IOStreams:  streams,
+		},
+	}
+}
+
+func NewCmdCreateRecovery(streams options.IOStreams) *cobra.Command {
+	o := NewCreateRecoveryOptions(streams)
+	cmd := &cobra.Command{
+		Use:                   "recovery (--cluster) (--backup) [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "create kubeclipper recovery resource",
+		Long:                  recoveryLongDescription,
+		Example:               createRecoveryExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			utils.CheckErr(o.ValidateArgs(cmd))
+			utils.CheckErr(o.Complete(o.CliOpts))
+			utils.CheckErr(o.RunCreate())
+		},
+	}
+	cmd.Flags().StringVar(&o.Cluster, "cluster", "", "cluster to restore")
+	cmd.Flags().StringVar(&o.Backup, "backup", "", "backup to restore")
+	cmd.Flags().StringVar(&o.Description, "description", "", "recovery description")
+	o.CliOpts.AddFlags(cmd.Flags())
+	o.PrintFlags.AddFlags(cmd)
+
+	_ = cmd.MarkFlagRequired("cluster")
+	_ = cmd.MarkFlagRequired("backup")
+	return cmd
+}
+
+func (l *CreateRecoveryOptions) Complete(opts *options.CliOptions) error {
+	if err := opts.Complete(); err != nil {
+		return err
+	}
+	c, err := opts.ToRawConfig().ToKcClient()
+	if err != nil {
+		return err
+	}
+	l.Client = c
+	return nil
+}
+
+func (l *CreateRecoveryOptions) ValidateArgs(cmd *cobra.Command) error {
+	if l.Cluster == "" {
+		return utils.UsageErrorf(cmd, "cluster must be specified")
+	}
+	if l.Backup == "" {
+		return utils.UsageErrorf(cmd, "backup must be specified")
+	}
+	return nil
+}
+
+func (l *CreateRecoveryOptions) RunCreate() error {
+	recovery := &v1.Recovery{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Recovery",
+			APIVersion: "core.kubeclipper.io/v1",
+		},
+		UseBackupName: l.Backup,
+		Description:   l.Description,
+	}
+	resp, err := l.Client.CreateRecovery(context.TODO(), l.Cluster, recovery)
+	if err != nil {
+		return err
+	}
+	return l.PrintFlags.Print(resp, l.IOStreams.Out)
+}
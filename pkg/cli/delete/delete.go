@@ -49,6 +49,8 @@ Available Commands:
   user        delete kubeclipper user resource
 
 Flags:
+      --cluster string    cluster of the backup
+      --domain string     domain of the record
   -h, --help              help for delete
 
 Use "kcctl delete [command] --help" for more information about a command.
@@ -58,7 +60,8 @@ const (
 	longDescription = `
   Delete kubeclipper resources.
 
-  Currently,only clusters, users and roles resources can be supported.`
+  Currently, clusters, users, roles, backups, backup points, cron backups, domains, records
+  and templates can be deleted.`
 	deleteExample = `
   # Delete kubeclipper cluster
   kcctl delete cluster 'CLUSTER-NAME'
@@ -69,6 +72,12 @@ const (
   # Delete kubeclipper role
   kcctl delete role 'ROLE-NAME'
 
+  # Delete backup of the cluster
+  kcctl delete backup 'BACKUP-NAME' --cluster 'CLUSTER-NAME'
+
+  # Delete the www record of domain example.com
+  kcctl delete record www --domain example.com
+
   Please read 'kcctl delete -h' get more delete flags.`
 )
 
@@ -81,18 +90,22 @@ type BaseOptions struct {
 
 type DeleteOptions struct {
 	BaseOptions
+	Cluster  string
+	Domain   string
 	resource string
 	name     string
 }
 
 var (
-	allowedResource = sets.NewString(options.ResourceUser, options.ResourceRole, options.ResourceCluster)
+	allowedResource = sets.NewString(options.ResourceUser, options.ResourceRole, options.ResourceCluster,
+		options.ResourceBackup, options.ResourceBackupPoint, options.ResourceCronBackup, options.ResourceDomain,
+		options.ResourceRecord, options.ResourceTemplate)
 )
 
 func NewCmdDelete(streams options.IOStreams) *cobra.Command {
 	o := NewDeleteOptions(streams)
 	cmd := &cobra.Command{
-		Use:                   "delete (<cluster> | <user> | <role>) [flags]",
+		Use:                   "delete (<cluster> | <user> | <role> | <backup> | <backuppoint> | <cronbackup> | <domain> | <record> | <template>) [flags]",
 		DisableFlagsInUseLine: true,
 		Short:                 "delete kubeclipper resource",
 		Long:                  longDescription,
@@ -104,6 +117,8 @@ func NewCmdDelete(streams options.IOStreams) *cobra.Command {
 		},
 		ValidArgsFunction: ValidArgsFunction(o),
 	}
+	cmd.Flags().StringVar(&o.Cluster, "cluster", "", "cluster of the backup")
+	cmd.Flags().StringVar(&o.Domain, "domain", "", "domain of the record")
 
 	return cmd
 }
@@ -145,6 +160,12 @@ func (l *DeleteOptions) ValidateArgs(cmd *cobra.Command, args []string) error {
 	if len(args) > 0 {
 		l.name = args[0]
 	}
+	if l.resource == options.ResourceBackup && l.Cluster == "" {
+		return utils.UsageErrorf(cmd, "You must specify the cluster of the backup with --cluster")
+	}
+	if l.resource == options.ResourceRecord && l.Domain == "" {
+		return utils.UsageErrorf(cmd, "You must specify the domain of the record with --domain")
+	}
 	return nil
 }
 
@@ -166,6 +187,36 @@ func (l *DeleteOptions) RunDelete() error {
 		if err != nil {
 			return err
 		}
+	case options.ResourceBackup:
+		err = l.Client.DeleteBackup(context.TODO(), l.Cluster, l.name)
+		if err != nil {
+			return err
+		}
+	case options.ResourceBackupPoint:
+		err = l.Client.DeleteBackupPoint(context.TODO(), l.name)
+		if err != nil {
+			return err
+		}
+	case options.ResourceCronBackup:
+		err = l.Client.DeleteCronBackup(context.TODO(), l.name)
+		if err != nil {
+			return err
+		}
+	case options.ResourceDomain:
+		err = l.Client.DeleteDomain(context.TODO(), l.name)
+		if err != nil {
+			return err
+		}
+	case options.ResourceRecord:
+		err = l.Client.DeleteRecord(context.TODO(), l.Domain, l.name)
+		if err != nil {
+			return err
+		}
+	case options.ResourceTemplate:
+		err = l.Client.DeleteTemplate(context.TODO(), l.name)
+		if err != nil {
+			return err
+		}
 	default:
 		return fmt.Errorf("unsupported resource")
 	}
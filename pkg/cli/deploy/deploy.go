@@ -24,7 +24,6 @@ import (
 	"crypto/x509"
 	"fmt"
 	"math"
-	"net"
 	"path"
 	"path/filepath"
 	"strconv"
@@ -437,7 +436,7 @@ func (d DeployOptions) generateAndSendCerts() error {
 	for _, name := range d.servers {
 		altNames = append(altNames, name)
 	}
-	cas := caList()
+	cas := join.CaList()
 	certs := make([]certutils.Config, 0)
 
 	etcdCommonNameUsages := make(map[string][]x509.ExtKeyUsage)
@@ -446,7 +445,7 @@ func (d DeployOptions) generateAndSendCerts() error {
 	etcdCommonNameUsages[options.EtcdKcClient] = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
 	etcdCommonNameUsages[options.EtcdHealthCheck] = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
 
-	etcdCert := certList(options.DefaultEtcdPKIPath, options.Ca, append(altNames, d.deployConfig.ServerIPs...), etcdCommonNameUsages)
+	etcdCert := join.CertList(options.DefaultEtcdPKIPath, options.Ca, append(altNames, d.deployConfig.ServerIPs...), etcdCommonNameUsages)
 	certs = append(certs, etcdCert...)
 
 	var natsCert []certutils.Config
@@ -454,7 +453,7 @@ func (d DeployOptions) generateAndSendCerts() error {
 		natsCommonNameUsages := make(map[string][]x509.ExtKeyUsage)
 		natsCommonNameUsages[options.NatsIOClient] = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
 		natsCommonNameUsages[options.NatsIOServer] = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
-		natsCert = certList(options.DefaultNatsPKIPath, options.Ca, append(altNames, d.deployConfig.ServerIPs...), natsCommonNameUsages)
+		natsCert = join.CertList(options.DefaultNatsPKIPath, options.Ca, append(altNames, d.deployConfig.ServerIPs...), natsCommonNameUsages)
 		certs = append(certs, natsCert...)
 	}
 
@@ -578,6 +577,7 @@ func (d *DeployOptions) getEtcdTemplateContent(ip string) string {
 	data["DataDIR"] = d.deployConfig.EtcdConfig.DataDir
 	data["PeerAddress"] = fmt.Sprintf("%s:%d", ip, d.deployConfig.EtcdConfig.PeerPort)
 	data["InitialCluster"] = strings.Join(initialCluster, ",")
+	data["InitialClusterState"] = "new"
 	data["ClusterToken"] = "kc-etcd-cluster"
 	data["ServerCertKeyPath"] = filepath.Join(options.DefaultKcServerConfigPath, options.DefaultEtcdPKIPath, fmt.Sprintf("%s.key", options.EtcdServer))
 	data["ClientURLs"] = fmt.Sprintf("https://127.0.0.1:%d,https://%s:%d", d.deployConfig.EtcdConfig.ClientPort, ip, d.deployConfig.EtcdConfig.ClientPort)
@@ -593,80 +593,6 @@ func (d *DeployOptions) getEtcdTemplateContent(ip string) string {
 	return buffer.String()
 }
 
-func (d *DeployOptions) getKcConsoleTemplateContent() string {
-	tmpl, err := template.New("text").Parse(config.KcCaddyTmpl)
-	if err != nil {
-		logger.Fatalf("template parse failed: %s", err.Error())
-	}
-	var serverUpstream string
-	for k := range d.servers {
-		serverUpstream = serverUpstream + fmt.Sprintf(" http://%s:%d", k, d.deployConfig.ServerPort)
-	}
-	var data = make(map[string]interface{})
-	data["ConsolePort"] = d.deployConfig.ConsolePort
-	data["ServerUpstream"] = serverUpstream
-	var buffer bytes.Buffer
-	if err := tmpl.Execute(&buffer, data); err != nil {
-		logger.Fatalf("template execute failed: %s", err.Error())
-	}
-	return buffer.String()
-}
-
-func (d *DeployOptions) getKcServerConfigTemplateContent(ip string) string {
-	tmpl, err := template.New("text").Parse(config.KcServerConfigTmpl)
-	if err != nil {
-		logger.Fatalf("template parse failed: %s", err.Error())
-	}
-	var mqServerEndpoints []string
-	for _, v := range d.deployConfig.MQ.IPs {
-		mqServerEndpoints = append(mqServerEndpoints, fmt.Sprintf("%s:%d", v, d.deployConfig.MQ.Port))
-	}
-	etcdEndpoints := []string{fmt.Sprintf("%s:%d", ip, d.deployConfig.EtcdConfig.ClientPort)}
-	var data = make(map[string]interface{})
-	data["ServerAddress"] = ip
-	data["ServerPort"] = d.deployConfig.ServerPort
-	// TODO: make auto generate
-	data["JwtSecret"] = d.deployConfig.JWTSecret
-	data["StaticServerPort"] = d.deployConfig.StaticServerPort
-	data["StaticServerPath"] = d.deployConfig.StaticServerPath
-	if d.deployConfig.Debug {
-		data["LogLevel"] = "debug"
-	} else {
-		data["LogLevel"] = "info"
-	}
-	data["EtcdEndpoints"] = etcdEndpoints
-	data["EtcdCaPath"] = filepath.Join(options.DefaultKcServerConfigPath, options.DefaultCaPath, fmt.Sprintf("%s.crt", options.Ca))
-	data["EtcdCertPath"] = filepath.Join(options.DefaultKcServerConfigPath, options.DefaultEtcdPKIPath, fmt.Sprintf("%s.crt", options.EtcdKcClient))
-	data["EtcdKeyPath"] = filepath.Join(options.DefaultKcServerConfigPath, options.DefaultEtcdPKIPath, fmt.Sprintf("%s.key", options.EtcdKcClient))
-
-	data["MQExternal"] = d.deployConfig.MQ.External
-	data["MQUser"] = d.deployConfig.MQ.User
-	data["MQAuthToken"] = d.deployConfig.MQ.Secret
-	data["MQServerEndpoints"] = mqServerEndpoints
-	data["MQTLS"] = d.deployConfig.MQ.TLS
-	if !d.deployConfig.MQ.External {
-		data["MQServerAddress"] = ip
-		data["MQServerPort"] = d.deployConfig.MQ.Port
-		data["MQClusterPort"] = d.deployConfig.MQ.ClusterPort
-		data["LeaderHost"] = fmt.Sprintf("%s:%d", d.deployConfig.ServerIPs[0], d.deployConfig.MQ.ClusterPort)
-		if d.deployConfig.MQ.TLS {
-			data["MQServerCertPath"] = filepath.Join(options.DefaultKcServerConfigPath, options.DefaultNatsPKIPath, fmt.Sprintf("%s.crt", options.NatsIOServer))
-			data["MQServerKeyPath"] = filepath.Join(options.DefaultKcServerConfigPath, options.DefaultNatsPKIPath, fmt.Sprintf("%s.key", options.NatsIOServer))
-		}
-	}
-
-	if d.deployConfig.MQ.TLS {
-		data["MQCaPath"] = d.deployConfig.MQ.CA
-		data["MQClientCertPath"] = d.deployConfig.MQ.ClientCert
-		data["MQClientKeyPath"] = d.deployConfig.MQ.ClientKey
-	}
-	var buffer bytes.Buffer
-	if err := tmpl.Execute(&buffer, data); err != nil {
-		logger.Fatalf("template execute failed: %s", err.Error())
-	}
-	return buffer.String()
-}
-
 func (d *DeployOptions) getKcAgentConfigTemplateContent(metadata options.Metadata) string {
 	tmpl, err := template.New("text").Parse(config.KcAgentConfigTmpl)
 	if err != nil {
@@ -724,7 +650,7 @@ func (d *DeployOptions) deployKcServer() {
 	}
 
 	for _, host := range d.deployConfig.ServerIPs {
-		data := d.getKcServerConfigTemplateContent(host)
+		data := join.ServerConfigContent(d.deployConfig, host)
 		cmd := sshutils.WrapEcho(data, "/etc/kubeclipper-server/kubeclipper-server.yaml") +
 			"&& systemctl daemon-reload && systemctl enable kc-server --now"
 		ret, err := sshutils.SSHCmdWithSudo(d.deployConfig.SSHConfig, host, cmd)
@@ -740,7 +666,7 @@ func (d *DeployOptions) deployKcServer() {
 }
 
 func (d *DeployOptions) deployKcConsole() {
-	data := d.getKcConsoleTemplateContent()
+	data := join.ConsoleConfigContent(d.deployConfig)
 
 	cmdList := []string{
 		fmt.Sprintf("mkdir -pv /etc/kc-console && cp -rf %s/kc/kc-console /etc/kc-console/dist", config.DefaultPkgPath),
@@ -842,54 +768,3 @@ func (d *DeployOptions) sendAgentCertAndKey(contents []certutils.Config, pki str
 	}
 	return nil
 }
-
-func caList() []certutils.Config {
-	certPath := filepath.Join(options.HomeDIR, options.DefaultPath, options.DefaultCaPath)
-	return []certutils.Config{
-		{
-			Path:         certPath,
-			BaseName:     options.Ca,
-			CommonName:   options.Ca,
-			Organization: []string{"kubeclipper.io"},
-			Year:         100,
-			AltNames:     certutils.AltNames{},
-			Usages:       []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
-		},
-	}
-}
-
-func certList(pki, caName string, altNames []string, commonNameUsage map[string][]x509.ExtKeyUsage) []certutils.Config {
-	certPath := filepath.Join(options.HomeDIR, options.DefaultPath, pki)
-	alt := certutils.AltNames{
-		DNSNames: map[string]string{
-			"localhost": "localhost",
-		},
-		IPs: map[string]net.IP{
-			"127.0.0.1":               net.IPv4(127, 0, 0, 1),
-			net.IPv6loopback.String(): net.IPv6loopback,
-		},
-	}
-	for _, altName := range altNames {
-		if ip := net.ParseIP(altName); ip != nil {
-			alt.IPs[ip.String()] = ip
-			continue
-		}
-		alt.DNSNames[altName] = altName
-	}
-	logger.V(2).Infof("Etcd alt DNS : [%v], Etcd alt IPs: [%v]", alt.DNSNames, alt.IPs)
-	certConfig := make([]certutils.Config, 0)
-	for commonName, usages := range commonNameUsage {
-		conf := certutils.Config{
-			Path:         certPath,
-			BaseName:     commonName,
-			CAName:       caName,
-			CommonName:   commonName,
-			Organization: []string{"kubeclipper.io"},
-			Year:         100,
-			AltNames:     alt,
-			Usages:       usages,
-		}
-		certConfig = append(certConfig, conf)
-	}
-	return certConfig
-}
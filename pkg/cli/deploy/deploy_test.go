@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/join"
 )
 
 func TestDeployOptions_getEtcdTemplateContent(t *testing.T) {
@@ -48,7 +49,7 @@ func TestDeployOptions_getKcServerConfigTemplateContent(t *testing.T) {
 	}
 
 	for _, s := range d.deployConfig.ServerIPs {
-		t.Log(d.getKcServerConfigTemplateContent(s))
+		t.Log(join.ServerConfigContent(d.deployConfig, s))
 	}
 }
 
@@ -78,5 +79,5 @@ func TestDeployOptions_getKcConsoleTemplateContent(t *testing.T) {
 		"192.168.234.5": "master3",
 	}
 
-	t.Log(d.getKcConsoleTemplateContent())
+	t.Log(join.ConsoleConfigContent(d.deployConfig))
 }
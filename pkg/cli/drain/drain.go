@@ -27,9 +27,11 @@ import (
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 
+	"github.com/kubeclipper/kubeclipper/pkg/cli/join"
 	"github.com/kubeclipper/kubeclipper/pkg/cli/utils"
 	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
 	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/sliceutil"
 	"github.com/kubeclipper/kubeclipper/pkg/utils/sshutils"
 
 	"github.com/kubeclipper/kubeclipper/pkg/query"
@@ -46,7 +48,10 @@ const (
 	longDescription = `
   Drain the Kubeclipper service or agent node from the cluster.
 
-  Now only support drain kc-agent node, so the --agent parameter must be valid.`
+  Draining a server node removes its kc-etcd member and mq route first, then
+  cleans the node. At least one server must be left and the number of the
+  remaining servers must be odd. The agents are pointed at the remaining
+  servers, and restarted if the first server is drained.`
 	drainExample = `
   # Drain kc-agent from kubeclipper cluster use default deploy-config(~/.kc/deploy-config.yaml) and config(~/.kc/config).
   kcctl drain --agent 192.168.10.19
@@ -57,6 +62,9 @@ const (
   # Force drain kc-agent which is in used from kubeclipper cluster
   kcctl drain  --force --agent=192.168.10.123
 
+  # Drain two kc-server from a three servers kubeclipper platform.
+  kcctl drain --server 192.168.10.11,192.168.10.12
+
   Please read 'kcctl drain -h' get more drain flags.`
 )
 
@@ -82,7 +90,7 @@ func NewDrainOptions(streams options.IOStreams) *DrainOptions {
 func NewCmdDrain(streams options.IOStreams) *cobra.Command {
 	o := NewDrainOptions(streams)
 	cmd := &cobra.Command{
-		Use:                   "drain (--agent <agentIps>) [flags]",
+		Use:                   "drain (--agent <agentIps> | --server <serverIps>) [flags]",
 		DisableFlagsInUseLine: true,
 		Short:                 "drain kubeclipper server or agent",
 		Long:                  longDescription,
@@ -99,6 +107,7 @@ func NewCmdDrain(streams options.IOStreams) *cobra.Command {
 
 	o.cliOpts.AddFlags(cmd.Flags())
 	cmd.Flags().StringSliceVar(&o.agents, "agent", o.agents, "drain agent node ip.")
+	cmd.Flags().StringSliceVar(&o.servers, "server", o.servers, "drain server node ip.")
 	cmd.Flags().StringVar(&o.deployConfig.Config, "deploy-config", options.DefaultDeployConfigPath, "kcctl deploy config path")
 	cmd.Flags().BoolVarP(&o.force, "force", "F", o.force, "force delete in used node.")
 
@@ -106,7 +115,6 @@ func NewCmdDrain(streams options.IOStreams) *cobra.Command {
 		return o.listNode(toComplete), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
 	}))
 
-	utils.CheckErr(cmd.MarkFlagRequired("agent"))
 	return cmd
 }
 
@@ -155,14 +163,15 @@ func (c *DrainOptions) ValidateArgs() error {
 	if c.deployConfig.Config == "" {
 		return errors.New("deploy config path cannot be empty")
 	}
-	if len(c.agents) == 0 {
-		return errors.New("--agent is required")
+	if len(c.agents) == 0 && len(c.servers) == 0 {
+		return errors.New("one of --agent or --server is required")
 	}
 	return nil
 }
 
 func (c *DrainOptions) preCheck() bool {
 	c.agents = sets.NewString(c.agents...).List()
+	c.servers = sets.NewString(c.servers...).List()
 
 	for _, agent := range c.agents {
 		if !c.deployConfig.Agents.Exists(agent) {
@@ -170,6 +179,24 @@ func (c *DrainOptions) preCheck() bool {
 			return false
 		}
 	}
+	if len(c.servers) > 0 {
+		deployed := sets.NewString(c.deployConfig.ServerIPs...)
+		for _, server := range c.servers {
+			if !deployed.Has(server) {
+				logger.Errorf("server %s is not in server nodes", server)
+				return false
+			}
+		}
+		remaining := deployed.Difference(sets.NewString(c.servers...)).Len()
+		if remaining == 0 {
+			logger.Error("at least one server node must be left")
+			return false
+		}
+		if remaining%2 == 0 {
+			logger.Errorf("the number of servers must be odd after drain, %d servers would be left", remaining)
+			return false
+		}
+	}
 
 	if c.force {
 		_, _ = c.IOStreams.Out.Write([]byte("force delete node which is in used maybe cause data inconsistency." +
@@ -259,13 +286,79 @@ func (c *DrainOptions) agentFilesAndData(node *v1.Node) error {
 }
 
 func (c *DrainOptions) runDrainServerNode() error {
+	if len(c.servers) == 0 {
+		return nil
+	}
+	// run etcdctl on a server which stays in the cluster
+	drained := sets.NewString(c.servers...)
+	var host string
+	for _, ip := range c.deployConfig.ServerIPs {
+		if !drained.Has(ip) {
+			host = ip
+			break
+		}
+	}
+	firstServer := c.deployConfig.ServerIPs[0]
+	for _, node := range c.servers {
+		if err := join.RemoveEtcdMember(c.deployConfig, host, node); err != nil {
+			return err
+		}
+		c.serverFiles(node)
+
+		// rewrite deploy config
+		c.deployConfig.ServerIPs = sliceutil.RemoveString(c.deployConfig.ServerIPs, func(item string) bool {
+			return item == node
+		})
+		if !c.deployConfig.MQ.External {
+			c.deployConfig.MQ.IPs = c.deployConfig.ServerIPs
+		}
+		if err := c.deployConfig.Write(); err != nil {
+			return errors.WithMessage(err, "rewrite deploy config")
+		}
+	}
+	// drop the mq routes to the drained servers and, if it was drained, move
+	// the mq leader to the first remaining server.
+	if err := join.SyncServerConfig(c.deployConfig, c.deployConfig.ServerIPs, true); err != nil {
+		return err
+	}
+	// the agents download packages from the first server, they are restarted
+	// when it is drained so that they stop using it.
+	if err := join.SyncAgentConfig(c.deployConfig, c.deployConfig.ServerIPs[0] != firstServer); err != nil {
+		return err
+	}
+	logger.Info("server node drain completed.")
 	return nil
 }
 
+// serverFiles stops the server services and removes their files. The member
+// is already out of the cluster, so an unreachable node is only reported.
+func (c *DrainOptions) serverFiles(node string) {
+	cmdList := []string{
+		"systemctl disable kc-console kc-server kc-etcd --now",
+		"rm -rf /usr/lib/systemd/system/kc-console.service /usr/lib/systemd/system/kc-server.service /usr/lib/systemd/system/kc-etcd.service " +
+			"/etc/kc-console /etc/kubeclipper-server " + safeDir(c.deployConfig.EtcdConfig.DataDir) + " " + safeDir(c.deployConfig.StaticServerPath),
+		"systemctl daemon-reload",
+	}
+	for _, v := range cmdList {
+		ret, err := sshutils.SSHCmdWithSudo(c.deployConfig.SSHConfig, node, v)
+		if err == nil {
+			err = ret.Error()
+		}
+		if err != nil {
+			logger.Warnf("run cmd %s on %s failed: %s", v, node, err.Error())
+		}
+	}
+}
+
 // checkOplogDir return oplog dir, avoid removing illegal folders
 func (c *DrainOptions) checkOplogDir() string {
-	if !filepath.IsAbs(c.deployConfig.OpLog.Dir) || c.deployConfig.OpLog.Dir == "/" {
+	return safeDir(c.deployConfig.OpLog.Dir)
+}
+
+// safeDir returns dir if it is safe to remove recursively.
+func safeDir(dir string) string {
+	if !filepath.IsAbs(dir) || dir == "/" {
 		return ""
 	}
-	return c.deployConfig.OpLog.Dir
+	return dir
 }
@@ -60,7 +60,13 @@ const (
   kcctl get user admin -o yaml
 
   # List other resource
-  kcctl get [role,cluster,node]
+  kcctl get [role,cluster,node,operation,backup,backuppoint,cronbackup,region,domain,template,token]
+
+  # List the records of domain example.com
+  kcctl get record --domain example.com
+
+  # Describe the www record of domain example.com
+  kcctl get record www --domain example.com -o yaml
 
   Please read 'kcctl get -h' get more get flags`
 )
@@ -71,6 +77,7 @@ type GetOptions struct {
 	options.IOStreams
 	LabelSelector string
 	FieldSelector string
+	Domain        string
 	Watch         bool
 	client        *kc.Client
 	resource      string
@@ -78,7 +85,9 @@ type GetOptions struct {
 }
 
 var (
-	allowedResource = sets.NewString(options.ResourceUser, options.ResourceRole, options.ResourceNode, options.ResourceCluster)
+	allowedResource = sets.NewString(options.ResourceUser, options.ResourceRole, options.ResourceNode, options.ResourceCluster,
+		options.ResourceOperation, options.ResourceBackup, options.ResourceBackupPoint, options.ResourceCronBackup,
+		options.ResourceRegion, options.ResourceDomain, options.ResourceRecord, options.ResourceTemplate, options.ResourceToken)
 )
 
 func NewGetOptions(streams options.IOStreams) *GetOptions {
@@ -107,6 +116,7 @@ func NewCmdGet(streams options.IOStreams) *cobra.Command {
 	o.cliOpts.AddFlags(cmd.Flags())
 	cmd.Flags().BoolVarP(&o.Watch, "watch", "w", o.Watch, "After listing/getting the requested object, watch for changes.")
 	cmd.Flags().StringVarP(&o.LabelSelector, "selector", "l", o.LabelSelector, "Selector (label query) to filter on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2)")
+	cmd.Flags().StringVar(&o.Domain, "domain", o.Domain, "Domain of the records, required when getting records.")
 	cmd.Flags().StringVar(&o.FieldSelector, "field-selector", o.FieldSelector, "Selector (field query) to filter on, supports '=', '==', and '!='.(e.g. --field-selector key1=value1,key2=value2). The server only supports a limited number of field queries per type.")
 	o.PrintFlags.AddFlags(cmd)
 	return cmd
@@ -124,6 +134,9 @@ func (l *GetOptions) ValidateArgs(cmd *cobra.Command, args []string) error {
 	if !allowedResource.Has(l.resource) {
 		return utils.UsageErrorf(cmd, "unsupported resource type,support %v now", allowedResource.List())
 	}
+	if l.resource == options.ResourceRecord && l.Domain == "" {
+		return utils.UsageErrorf(cmd, "You must specify the domain of the records with --domain")
+	}
 	return nil
 }
 
@@ -163,6 +176,24 @@ func (l *GetOptions) list() error {
 		result, err = l.client.ListRoles(context.TODO(), kc.Queries(*q))
 	case options.ResourceCluster:
 		result, err = l.client.ListClusters(context.TODO(), kc.Queries(*q))
+	case options.ResourceOperation:
+		result, err = l.client.ListOperations(context.TODO(), kc.Queries(*q))
+	case options.ResourceBackup:
+		result, err = l.client.ListBackups(context.TODO(), kc.Queries(*q))
+	case options.ResourceBackupPoint:
+		result, err = l.client.ListBackupPoints(context.TODO(), kc.Queries(*q))
+	case options.ResourceCronBackup:
+		result, err = l.client.ListCronBackups(context.TODO(), kc.Queries(*q))
+	case options.ResourceRegion:
+		result, err = l.client.ListRegions(context.TODO(), kc.Queries(*q))
+	case options.ResourceDomain:
+		result, err = l.client.ListDomains(context.TODO(), kc.Queries(*q))
+	case options.ResourceRecord:
+		result, err = l.client.ListRecords(context.TODO(), l.Domain, kc.Queries(*q))
+	case options.ResourceTemplate:
+		result, err = l.client.ListTemplates(context.TODO(), kc.Queries(*q))
+	case options.ResourceToken:
+		result, err = l.client.ListTokens(context.TODO(), kc.Queries(*q))
 	default:
 		return fmt.Errorf("unsupported resource")
 	}
@@ -187,6 +218,24 @@ func (l *GetOptions) describe() error {
 		result, err = l.client.DescribeRole(context.TODO(), l.name)
 	case options.ResourceCluster:
 		result, err = l.client.DescribeCluster(context.TODO(), l.name)
+	case options.ResourceOperation:
+		result, err = l.client.DescribeOperation(context.TODO(), l.name)
+	case options.ResourceBackup:
+		result, err = l.client.DescribeBackup(context.TODO(), l.name)
+	case options.ResourceBackupPoint:
+		result, err = l.client.DescribeBackupPoint(context.TODO(), l.name)
+	case options.ResourceCronBackup:
+		result, err = l.client.DescribeCronBackup(context.TODO(), l.name)
+	case options.ResourceRegion:
+		result, err = l.client.DescribeRegion(context.TODO(), l.name)
+	case options.ResourceDomain:
+		result, err = l.client.DescribeDomain(context.TODO(), l.name)
+	case options.ResourceRecord:
+		result, err = l.client.DescribeRecord(context.TODO(), l.Domain, l.name)
+	case options.ResourceTemplate:
+		result, err = l.client.DescribeTemplate(context.TODO(), l.name)
+	case options.ResourceToken:
+		result, err = l.client.DescribeToken(context.TODO(), l.name)
 	default:
 		return fmt.Errorf("unsupported resource")
 	}
@@ -212,6 +261,14 @@ func ValidArgsFunction(o *GetOptions) func(cmd *cobra.Command, args []string, to
 			return o.listNode(toComplete), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
 		case options.ResourceCluster:
 			return o.listCluster(toComplete), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+		case options.ResourceBackup:
+			return o.listBackup(toComplete), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+		case options.ResourceBackupPoint:
+			return o.listBackupPoint(toComplete), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+		case options.ResourceCronBackup:
+			return o.listCronBackup(toComplete), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+		case options.ResourceDomain:
+			return o.listDomain(toComplete), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
 		}
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
@@ -284,3 +341,71 @@ func (l *GetOptions) listCluster(toComplete string) []string {
 	}
 	return list
 }
+
+func (l *GetOptions) listBackup(toComplete string) []string {
+	list := make([]string, 0)
+	q := query.New()
+	q.LabelSelector = l.LabelSelector
+	q.FieldSelector = l.FieldSelector
+	data, err := l.client.ListBackups(context.TODO(), kc.Queries(*q))
+	if err != nil {
+		return nil
+	}
+	for _, v := range data.Items {
+		if strings.HasPrefix(v.Name, toComplete) {
+			list = append(list, v.Name)
+		}
+	}
+	return list
+}
+
+func (l *GetOptions) listBackupPoint(toComplete string) []string {
+	list := make([]string, 0)
+	q := query.New()
+	q.LabelSelector = l.LabelSelector
+	q.FieldSelector = l.FieldSelector
+	data, err := l.client.ListBackupPoints(context.TODO(), kc.Queries(*q))
+	if err != nil {
+		return nil
+	}
+	for _, v := range data.Items {
+		if strings.HasPrefix(v.Name, toComplete) {
+			list = append(list, v.Name)
+		}
+	}
+	return list
+}
+
+func (l *GetOptions) listCronBackup(toComplete string) []string {
+	list := make([]string, 0)
+	q := query.New()
+	q.LabelSelector = l.LabelSelector
+	q.FieldSelector = l.FieldSelector
+	data, err := l.client.ListCronBackups(context.TODO(), kc.Queries(*q))
+	if err != nil {
+		return nil
+	}
+	for _, v := range data.Items {
+		if strings.HasPrefix(v.Name, toComplete) {
+			list = append(list, v.Name)
+		}
+	}
+	return list
+}
+
+func (l *GetOptions) listDomain(toComplete string) []string {
+	list := make([]string, 0)
+	q := query.New()
+	q.LabelSelector = l.LabelSelector
+	q.FieldSelector = l.FieldSelector
+	data, err := l.client.ListDomains(context.TODO(), kc.Queries(*q))
+	if err != nil {
+		return nil
+	}
+	for _, v := range data.Items {
+		if strings.HasPrefix(v.Name, toComplete) {
+			list = append(list, v.Name)
+		}
+	}
+	return list
+}
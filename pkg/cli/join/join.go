@@ -50,6 +50,7 @@ Usage:
 Examples:
   kcctl join --agent=1.1.1.1 --deploy-config=~/.kc/deploy-config.yaml
   kcctl join --agent=1.1.1.1 --agent=2.2.2.2 --deploy-config=~/.kc/deploy-config.yaml
+  kcctl join --server=3.3.3.3,4.4.4.4 --deploy-config=~/.kc/deploy-config.yaml
 
 Flags:
       --deploy-config string   kcctl deploy config path (default "~/.kc/deploy-config.yaml")
@@ -61,6 +62,9 @@ const (
   Add Server and Agents nodes on kubeclipper platform.
 
   At least one Server node must be installed before adding an Agents node.
+  Server nodes join the kc-etcd and mq cluster one by one, the number of
+  servers must stay odd after join. The config of the agents is updated to
+  list the new servers.
   deploy-config.yaml file is used to check whether a node can be added correctly.`
 	joinExample = `
   # Add multiple agent nodes use default config.
@@ -85,6 +89,9 @@ const (
   # this will add 10 agent,1.1.1.1, 1.1.1.2, ... 1.1.1.10.
   kcctl join --agent us-west-1:1.1.1.1-1.1.1.10
 
+  # Add two server nodes, a single server platform becomes a three servers HA platform.
+  kcctl join --server 192.168.10.11,192.168.10.12
+
   # Add multiple agent nodes and config fip.
   kcctl join --agent 192.168.10.123,192.168.10.124 --fip 192.168.10.123:172.20.149.199 --fip 192.168.10.124:172.20.149.200
 
@@ -130,9 +137,9 @@ func NewCmdJoin(streams options.IOStreams) *cobra.Command {
 	}
 	cmd.Flags().StringVar(&o.ipDetect, "ip-detect", o.ipDetect, "Kc ip detect method.")
 	cmd.Flags().StringArrayVar(&o.agents, "agent", o.agents, "join agent node.")
+	cmd.Flags().StringSliceVar(&o.servers, "server", o.servers, "join server node.")
 	cmd.Flags().StringArrayVar(&o.floatIPs, "float-ip", o.floatIPs, "Kc agent ip and float ip.")
 	cmd.Flags().StringVar(&o.deployConfig.Config, "deploy-config", options.DefaultDeployConfigPath, "kcctl deploy config path")
-	utils.CheckErr(cmd.MarkFlagRequired("agent"))
 	return cmd
 }
 
@@ -140,6 +147,13 @@ func (c *JoinOptions) preCheck() bool {
 	if !sudo.PreCheck("sudo", c.deployConfig.SSHConfig, c.IOStreams, append(c.parseAgent.ListIP(), c.servers...)) {
 		return false
 	}
+	// check if the server can be added
+	for _, server := range c.servers {
+		if err := c.checkServerNode(server); err != nil {
+			logger.Error(err)
+			return false
+		}
+	}
 	// check if the node is already added
 	for _, agent := range c.parseAgent.ListIP() {
 		if !c.preCheckKcAgent(agent) {
@@ -170,8 +184,14 @@ func (c *JoinOptions) ValidateArgs() error {
 	if c.ipDetect != "" && !autodetection.CheckMethod(c.ipDetect) {
 		return fmt.Errorf("invalid ip detect method,suppot [first-found,interface=xxx,cidr=xxx] now")
 	}
-	if len(c.agents) == 0 {
-		return fmt.Errorf("must specified at least one agent node")
+	if len(c.agents) == 0 && len(c.servers) == 0 {
+		return fmt.Errorf("must specified at least one agent or server node")
+	}
+	if len(c.servers) > 0 && (len(c.deployConfig.ServerIPs)+len(c.servers))%2 == 0 {
+		return fmt.Errorf("the number of servers must be odd after join, now %d servers are deployed", len(c.deployConfig.ServerIPs))
+	}
+	if len(c.servers) > 0 && c.deployConfig.Pkg == "" {
+		return fmt.Errorf("the pkg of deploy config cannot be empty when join server node")
 	}
 	if len(c.deployConfig.ServerIPs) == 0 {
 		logger.Error("join an agent node requires specifying at least one server node")
@@ -285,23 +305,22 @@ func (c *JoinOptions) enableAgent(node string, metadata options.Metadata) error
 }
 
 func (c *JoinOptions) runJoinServerNode() error {
-	for _, node := range c.deployConfig.ServerIPs {
-		if err := c.checkServerNode(node); err != nil {
+	if len(c.servers) == 0 {
+		return nil
+	}
+	for _, node := range c.servers {
+		name := utils.GetRemoteHostName(c.deployConfig.SSHConfig, node)
+		if name == "" {
+			return fmt.Errorf("get remote hostname of %s failed", node)
+		}
+		if err := c.serverNodeFiles(node); err != nil {
+			return err
+		}
+		if err := c.enableServerService(node, name); err != nil {
 			return err
 		}
 	}
-	return nil
-}
-
-func (c *JoinOptions) checkServerNode(node string) error {
-	return nil
-}
-
-func (c *JoinOptions) serverNodeFiles() error {
-	return nil
-}
-
-func (c *JoinOptions) enableServerService() error {
+	logger.Info("server node join completed. show command: 'kcctl get node'")
 	return nil
 }
 
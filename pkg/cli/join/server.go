@@ -0,0 +1,606 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package join
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/config"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/utils"
+	certutils "github.com/kubeclipper/kubeclipper/pkg/utils/certs"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/sshutils"
+)
+
+const (
+	etcdClusterToken = "kc-etcd-cluster"
+	// etcdHealthRetries * etcdHealthInterval bounds the wait for a new member to catch up.
+	etcdHealthRetries  = 30
+	etcdHealthInterval = 2 * time.Second
+)
+
+func (c *JoinOptions) checkServerNode(node string) error {
+	if sets.NewString(c.deployConfig.ServerIPs...).Has(node) {
+		return fmt.Errorf("node %s is already deployed", node)
+	}
+	if c.deployConfig.Agents.Exists(node) {
+		// kc-etcd listens on the same ports as the etcd of a kubernetes cluster
+		// which may be installed on the agent later.
+		logger.Warnf("node %s is also an agent node, make sure etcd ports do not conflict", node)
+	}
+	for _, svc := range []string{"kc-etcd", "kc-server"} {
+		ret, err := sshutils.SSHCmdWithSudo(c.deployConfig.SSHConfig, node, fmt.Sprintf("systemctl --all --type service | grep -Fq %s", svc))
+		logger.V(2).Info(ret.String())
+		if err != nil {
+			return errors.WithMessagef(err, "check node %s", node)
+		}
+		if ret.ExitCode == 0 {
+			return fmt.Errorf("%s service exist on %s, please clean old environment", svc, node)
+		}
+	}
+	return nil
+}
+
+func (c *JoinOptions) serverNodeFiles(node string) error {
+	// send server binaries
+	tar := fmt.Sprintf("rm -rf %s && tar -xvf %s -C %s", filepath.Join(config.DefaultPkgPath, "kc"),
+		filepath.Join(config.DefaultPkgPath, path.Base(c.deployConfig.Pkg)), config.DefaultPkgPath)
+	cp := sshutils.WrapSh(fmt.Sprintf("cp -rf %s /usr/local/bin/", filepath.Join(config.DefaultPkgPath, "kc/bin/*")))
+	hook := sshutils.Combine([]string{tar, cp})
+	logger.V(3).Info("join server node hook:", hook)
+	if err := utils.SendPackageV2(c.deployConfig.SSHConfig, c.deployConfig.Pkg, []string{node}, config.DefaultPkgPath, nil, &hook); err != nil {
+		return errors.Wrap(err, "SendPackageV2")
+	}
+	if err := c.sendServerCerts(node); err != nil {
+		return errors.WithMessage(err, "send server certs")
+	}
+	cmdList := []string{
+		"mkdir -pv /etc/kubeclipper-server",
+		sshutils.WrapEcho(config.KcServerService, "/usr/lib/systemd/system/kc-server.service"),
+		fmt.Sprintf("mkdir -pv %s ", c.deployConfig.StaticServerPath),
+		sshutils.WrapSh(fmt.Sprintf("cp -rf %s/kc/resource/* %s/", config.DefaultPkgPath, c.deployConfig.StaticServerPath)),
+		fmt.Sprintf("mkdir -pv /etc/kc-console && cp -rf %s/kc/kc-console /etc/kc-console/dist", config.DefaultPkgPath),
+		sshutils.WrapEcho(config.KcConsoleServiceTmpl, "/usr/lib/systemd/system/kc-console.service"),
+		fmt.Sprintf("rm -rf %s/kc", config.DefaultPkgPath),
+	}
+	for _, cmd := range cmdList {
+		ret, err := sshutils.SSHCmdWithSudo(c.deployConfig.SSHConfig, node, cmd)
+		if err != nil {
+			return err
+		}
+		if err = ret.Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// enableServerService adds node to the kc-etcd cluster, starts the services
+// on it and points the existing servers at the new member. A failure before
+// the services are up removes the member again, an unstarted member counts
+// against the quorum and takes a single server platform down.
+func (c *JoinOptions) enableServerService(node, name string) (err error) {
+	existing := append([]string(nil), c.deployConfig.ServerIPs...)
+
+	// 1. register the member first, the new etcd must start with state existing.
+	peerURL := fmt.Sprintf("https://%s:%d", node, c.deployConfig.EtcdConfig.PeerPort)
+	ret, err := sshutils.SSHCmdWithSudo(c.deployConfig.SSHConfig, existing[0],
+		EtcdCtlCmd(c.deployConfig, "member", "add", name, "--peer-urls="+peerURL))
+	if err != nil {
+		return errors.WithMessage(err, "add etcd member")
+	}
+	if err = ret.Error(); err != nil {
+		return errors.WithMessage(err, "add etcd member")
+	}
+	recorded, joined := false, false
+	defer func() {
+		if err != nil && !joined {
+			c.rollbackServer(existing, node, recorded)
+		}
+	}()
+	initialCluster, err := parseInitialCluster(ret.Stdout)
+	if err != nil {
+		return err
+	}
+
+	// 2. start etcd and wait for it to catch up.
+	data := c.getEtcdTemplateContent(node, name, initialCluster)
+	cmd := sshutils.WrapEcho(data, "/usr/lib/systemd/system/kc-etcd.service") +
+		" && systemctl daemon-reload && systemctl enable kc-etcd --now"
+	if err = runCmdWithSudo(c.deployConfig.SSHConfig, node, cmd); err != nil {
+		return errors.WithMessage(err, "enable kc-etcd")
+	}
+	if err = waitEtcdHealthy(c.deployConfig, node); err != nil {
+		return err
+	}
+
+	// 3. the member is live, record it before anything else can fail.
+	c.deployConfig.ServerIPs = append(c.deployConfig.ServerIPs, node)
+	if !c.deployConfig.MQ.External {
+		c.deployConfig.MQ.IPs = c.deployConfig.ServerIPs
+	}
+	recorded = true
+	if err = c.deployConfig.Write(); err != nil {
+		return err
+	}
+
+	// 4. start kc-server and kc-console on the new node.
+	cmd = sshutils.WrapEcho(ServerConfigContent(c.deployConfig, node), "/etc/kubeclipper-server/kubeclipper-server.yaml") +
+		" && systemctl daemon-reload && systemctl enable kc-server --now"
+	if err = runCmdWithSudo(c.deployConfig.SSHConfig, node, cmd); err != nil {
+		return errors.WithMessage(err, "enable kc-server")
+	}
+	cmd = sshutils.WrapEcho(ConsoleConfigContent(c.deployConfig), "/etc/kc-console/Caddyfile") +
+		" && systemctl daemon-reload && systemctl enable kc-console --now"
+	if err = runCmdWithSudo(c.deployConfig.SSHConfig, node, cmd); err != nil {
+		return errors.WithMessage(err, "enable kc-console")
+	}
+
+	// 5. the new mq node routes to every server, gossip propagates it to the
+	// running ones. Rewrite their config so the route survives a restart. The
+	// new server is up, so a failure here is reported but not rolled back.
+	joined = true
+	if err = SyncServerConfig(c.deployConfig, existing, false); err != nil {
+		return errors.WithMessage(err, "the server joined, but the config of the existing servers is not updated")
+	}
+	// agents pick the new server up from the mq cluster, the config is for their next start.
+	return SyncAgentConfig(c.deployConfig, false)
+}
+
+// rollbackServer removes the etcd member of node added by a failed join and
+// stops the services started on it, the deploy config is restored if the
+// node was recorded in it.
+func (c *JoinOptions) rollbackServer(existing []string, node string, recorded bool) {
+	if err := RemoveEtcdMember(c.deployConfig, existing[0], node); err != nil {
+		logger.Errorf("remove etcd member of %s failed, remove it with 'etcdctl member remove': %s", node, err.Error())
+	}
+	cmdList := []string{
+		"systemctl disable kc-console kc-server kc-etcd --now",
+		"rm -f /usr/lib/systemd/system/kc-etcd.service",
+		"systemctl daemon-reload",
+	}
+	if dir := c.deployConfig.EtcdConfig.DataDir; filepath.IsAbs(dir) && dir != "/" {
+		cmdList = append(cmdList, "rm -rf "+dir)
+	}
+	for _, cmd := range cmdList {
+		if err := runCmdWithSudo(c.deployConfig.SSHConfig, node, cmd); err != nil {
+			logger.Warnf("[%s]%s failed: %s", node, cmd, err.Error())
+		}
+	}
+	if !recorded {
+		return
+	}
+	c.deployConfig.ServerIPs = existing
+	if !c.deployConfig.MQ.External {
+		c.deployConfig.MQ.IPs = c.deployConfig.ServerIPs
+	}
+	if err := c.deployConfig.Write(); err != nil {
+		logger.Errorf("restore deploy config failed: %s", err.Error())
+	}
+}
+
+func (c *JoinOptions) sendServerCerts(node string) error {
+	cas := CaList()
+	caCert, caKey, err := c.loadCA(cas[0])
+	if err != nil {
+		return err
+	}
+	altNames := append(append([]string(nil), c.deployConfig.ServerIPs...), node)
+
+	etcdCerts := CertList(options.DefaultEtcdPKIPath, options.Ca, altNames, map[string][]x509.ExtKeyUsage{
+		options.EtcdServer:      {x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		options.EtcdPeer:        {x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		options.EtcdKcClient:    {x509.ExtKeyUsageClientAuth},
+		options.EtcdHealthCheck: {x509.ExtKeyUsageClientAuth},
+	})
+	var natsCerts []certutils.Config
+	if !c.deployConfig.MQ.External && c.deployConfig.MQ.TLS {
+		natsCerts = CertList(options.DefaultNatsPKIPath, options.Ca, altNames, map[string][]x509.ExtKeyUsage{
+			options.NatsIOClient: {x509.ExtKeyUsageClientAuth},
+			options.NatsIOServer: {x509.ExtKeyUsageServerAuth},
+		})
+	}
+	// the existing servers keep their certs, they are signed by the same ca.
+	for _, cert := range append(etcdCerts, natsCerts...) {
+		crt, key, err := certutils.NewCaCertAndKeyFromRoot(cert, caCert, caKey)
+		if err != nil {
+			return err
+		}
+		if err = certutils.WriteCertAndKey(cert.Path, cert.BaseName, crt, key); err != nil {
+			return err
+		}
+	}
+
+	if err = sendCertAndKey(c.deployConfig.SSHConfig, node, cas, options.DefaultCaPath); err != nil {
+		return err
+	}
+	if err = sendCertAndKey(c.deployConfig.SSHConfig, node, etcdCerts, options.DefaultEtcdPKIPath); err != nil {
+		return err
+	}
+	if len(natsCerts) > 0 {
+		return sendCertAndKey(c.deployConfig.SSHConfig, node, natsCerts, options.DefaultNatsPKIPath)
+	}
+	if c.deployConfig.MQ.External && c.deployConfig.MQ.TLS {
+		for _, file := range []string{c.deployConfig.MQ.CA, c.deployConfig.MQ.ClientCert, c.deployConfig.MQ.ClientKey} {
+			if err = utils.SendPackageV2(c.deployConfig.SSHConfig, file, []string{node}, filepath.Dir(file), nil, nil); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// loadCA loads the platform ca generated by deploy, downloading it from the
+// first server when kcctl runs on another host.
+func (c *JoinOptions) loadCA(ca certutils.Config) (*x509.Certificate, crypto.Signer, error) {
+	for _, ext := range []string{".crt", ".key"} {
+		local := filepath.Join(ca.Path, ca.BaseName+ext)
+		if utils.FileExist(local) {
+			continue
+		}
+		remote := filepath.Join(options.DefaultKcServerConfigPath, options.DefaultCaPath, ca.BaseName+ext)
+		if err := c.deployConfig.SSHConfig.DownloadSudo(c.deployConfig.ServerIPs[0], local, remote); err != nil {
+			return nil, nil, errors.WithMessage(err, "download ca from server")
+		}
+	}
+	return certutils.LoadCaCertAndKeyFromDisk(ca)
+}
+
+func (c *JoinOptions) getEtcdTemplateContent(ip, name, initialCluster string) string {
+	tmpl, err := template.New("text").Parse(config.EtcdServiceTmpl)
+	if err != nil {
+		logger.Fatalf("template parse failed: %s", err.Error())
+	}
+	etcd := c.deployConfig.EtcdConfig
+	var data = make(map[string]interface{})
+	data["NodeName"] = name
+	data["AdvertiseAddress"] = fmt.Sprintf("%s:%d", ip, etcd.ClientPort)
+	data["ServerCertPath"] = filepath.Join(options.DefaultKcServerConfigPath, options.DefaultEtcdPKIPath, fmt.Sprintf("%s.crt", options.EtcdServer))
+	data["DataDIR"] = etcd.DataDir
+	data["PeerAddress"] = fmt.Sprintf("%s:%d", ip, etcd.PeerPort)
+	data["InitialCluster"] = initialCluster
+	data["InitialClusterState"] = "existing"
+	data["ClusterToken"] = etcdClusterToken
+	data["ServerCertKeyPath"] = filepath.Join(options.DefaultKcServerConfigPath, options.DefaultEtcdPKIPath, fmt.Sprintf("%s.key", options.EtcdServer))
+	data["ClientURLs"] = fmt.Sprintf("https://127.0.0.1:%d,https://%s:%d", etcd.ClientPort, ip, etcd.ClientPort)
+	data["MetricsURLs"] = fmt.Sprintf("http://127.0.0.1:%d", etcd.MetricsPort)
+	data["PeerURLs"] = fmt.Sprintf("https://%s:%d", ip, etcd.PeerPort)
+	data["PeerCertPath"] = filepath.Join(options.DefaultKcServerConfigPath, options.DefaultEtcdPKIPath, fmt.Sprintf("%s.crt", options.EtcdPeer))
+	data["PeerCertKeyPath"] = filepath.Join(options.DefaultKcServerConfigPath, options.DefaultEtcdPKIPath, fmt.Sprintf("%s.key", options.EtcdPeer))
+	data["CaPath"] = filepath.Join(options.DefaultKcServerConfigPath, options.DefaultCaPath, fmt.Sprintf("%s.crt", options.Ca))
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, data); err != nil {
+		logger.Fatalf("template execute failed: %s", err.Error())
+	}
+	return buffer.String()
+}
+
+// parseInitialCluster returns the ETCD_INITIAL_CLUSTER printed by etcdctl member add.
+func parseInitialCluster(out string) (string, error) {
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "ETCD_INITIAL_CLUSTER=") {
+			continue
+		}
+		if v := strings.Trim(strings.TrimPrefix(line, "ETCD_INITIAL_CLUSTER="), `"`); v != "" {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("ETCD_INITIAL_CLUSTER not found in etcdctl output: %s", out)
+}
+
+func waitEtcdHealthy(dc *options.DeployConfig, node string) error {
+	cmd := EtcdCtlCmd(dc, "endpoint", "health")
+	var lastErr error
+	for i := 0; i < etcdHealthRetries; i++ {
+		if lastErr = runCmdWithSudo(dc.SSHConfig, node, cmd); lastErr == nil {
+			return nil
+		}
+		time.Sleep(etcdHealthInterval)
+	}
+	return errors.WithMessagef(lastErr, "kc-etcd on %s is not healthy", node)
+}
+
+// RemoveEtcdMember removes the kc-etcd member of node through the member on host.
+func RemoveEtcdMember(dc *options.DeployConfig, host, node string) error {
+	ret, err := sshutils.SSHCmdWithSudo(dc.SSHConfig, host, EtcdCtlCmd(dc, "member", "list"))
+	if err != nil {
+		return errors.WithMessage(err, "list etcd member")
+	}
+	if err = ret.Error(); err != nil {
+		return errors.WithMessage(err, "list etcd member")
+	}
+	peerURL := fmt.Sprintf("https://%s:%d", node, dc.EtcdConfig.PeerPort)
+	id := EtcdMemberID(ret.Stdout, peerURL)
+	if id == "" {
+		logger.Warnf("etcd member of %s not found, skip removing it", node)
+		return nil
+	}
+	ret, err = sshutils.SSHCmdWithSudo(dc.SSHConfig, host, EtcdCtlCmd(dc, "member", "remove", id))
+	if err != nil {
+		return errors.WithMessagef(err, "remove etcd member %s", id)
+	}
+	return errors.WithMessagef(ret.Error(), "remove etcd member %s", id)
+}
+
+// EtcdMemberID returns the id of the member advertising peerURL in the
+// output of etcdctl member list.
+func EtcdMemberID(list, peerURL string) string {
+	for _, line := range strings.Split(list, "\n") {
+		fields := strings.Split(line, ",")
+		if len(fields) < 4 {
+			continue
+		}
+		for _, u := range strings.Split(fields[3], " ") {
+			if strings.TrimSpace(u) == peerURL {
+				return strings.TrimSpace(fields[0])
+			}
+		}
+	}
+	return ""
+}
+
+// EtcdCtlCmd returns an etcdctl command against the local kc-etcd member of a server.
+func EtcdCtlCmd(dc *options.DeployConfig, args ...string) string {
+	pki := filepath.Join(options.DefaultKcServerConfigPath, options.DefaultEtcdPKIPath)
+	return fmt.Sprintf("ETCDCTL_API=3 etcdctl --endpoints=https://127.0.0.1:%d --cacert=%s --cert=%s --key=%s %s",
+		dc.EtcdConfig.ClientPort,
+		filepath.Join(options.DefaultKcServerConfigPath, options.DefaultCaPath, fmt.Sprintf("%s.crt", options.Ca)),
+		filepath.Join(pki, fmt.Sprintf("%s.crt", options.EtcdHealthCheck)),
+		filepath.Join(pki, fmt.Sprintf("%s.key", options.EtcdHealthCheck)),
+		strings.Join(args, " "))
+}
+
+// ServerConfigContent renders kubeclipper-server.yaml of the server ip
+// from the deploy config.
+func ServerConfigContent(dc *options.DeployConfig, ip string) string {
+	tmpl, err := template.New("text").Parse(config.KcServerConfigTmpl)
+	if err != nil {
+		logger.Fatalf("template parse failed: %s", err.Error())
+	}
+	var mqServerEndpoints []string
+	for _, v := range dc.MQ.IPs {
+		mqServerEndpoints = append(mqServerEndpoints, fmt.Sprintf("%s:%d", v, dc.MQ.Port))
+	}
+	var data = make(map[string]interface{})
+	data["ServerAddress"] = ip
+	data["ServerPort"] = dc.ServerPort
+	data["JwtSecret"] = dc.JWTSecret
+	data["StaticServerPort"] = dc.StaticServerPort
+	data["StaticServerPath"] = dc.StaticServerPath
+	if dc.Debug {
+		data["LogLevel"] = "debug"
+	} else {
+		data["LogLevel"] = "info"
+	}
+	data["EtcdEndpoints"] = []string{fmt.Sprintf("%s:%d", ip, dc.EtcdConfig.ClientPort)}
+	data["EtcdCaPath"] = filepath.Join(options.DefaultKcServerConfigPath, options.DefaultCaPath, fmt.Sprintf("%s.crt", options.Ca))
+	data["EtcdCertPath"] = filepath.Join(options.DefaultKcServerConfigPath, options.DefaultEtcdPKIPath, fmt.Sprintf("%s.crt", options.EtcdKcClient))
+	data["EtcdKeyPath"] = filepath.Join(options.DefaultKcServerConfigPath, options.DefaultEtcdPKIPath, fmt.Sprintf("%s.key", options.EtcdKcClient))
+
+	data["MQExternal"] = dc.MQ.External
+	data["MQUser"] = dc.MQ.User
+	data["MQAuthToken"] = dc.MQ.Secret
+	data["MQServerEndpoints"] = mqServerEndpoints
+	data["MQTLS"] = dc.MQ.TLS
+	if !dc.MQ.External {
+		data["MQServerAddress"] = ip
+		data["MQServerPort"] = dc.MQ.Port
+		data["MQClusterPort"] = dc.MQ.ClusterPort
+		data["LeaderHost"] = fmt.Sprintf("%s:%d", dc.ServerIPs[0], dc.MQ.ClusterPort)
+		data["MQRoutes"] = MQRoutes(dc)
+		if dc.MQ.TLS {
+			data["MQServerCertPath"] = filepath.Join(options.DefaultKcServerConfigPath, options.DefaultNatsPKIPath, fmt.Sprintf("%s.crt", options.NatsIOServer))
+			data["MQServerKeyPath"] = filepath.Join(options.DefaultKcServerConfigPath, options.DefaultNatsPKIPath, fmt.Sprintf("%s.key", options.NatsIOServer))
+		}
+	}
+	if dc.MQ.TLS {
+		data["MQCaPath"] = dc.MQ.CA
+		data["MQClientCertPath"] = dc.MQ.ClientCert
+		data["MQClientKeyPath"] = dc.MQ.ClientKey
+	}
+	var buffer bytes.Buffer
+	if err = tmpl.Execute(&buffer, data); err != nil {
+		logger.Fatalf("template execute failed: %s", err.Error())
+	}
+	return buffer.String()
+}
+
+// MQRoutes returns the cluster address of every built-in mq server.
+func MQRoutes(dc *options.DeployConfig) []string {
+	routes := make([]string, 0, len(dc.ServerIPs))
+	for _, ip := range dc.ServerIPs {
+		routes = append(routes, net.JoinHostPort(ip, strconv.Itoa(dc.MQ.ClusterPort)))
+	}
+	return routes
+}
+
+// ConsoleConfigContent renders the kc-console Caddyfile proxying to every server.
+func ConsoleConfigContent(dc *options.DeployConfig) string {
+	tmpl, err := template.New("text").Parse(config.KcCaddyTmpl)
+	if err != nil {
+		logger.Fatalf("template parse failed: %s", err.Error())
+	}
+	var serverUpstream string
+	for _, ip := range dc.ServerIPs {
+		serverUpstream = serverUpstream + fmt.Sprintf(" http://%s:%d", ip, dc.ServerPort)
+	}
+	var data = make(map[string]interface{})
+	data["ConsolePort"] = dc.ConsolePort
+	data["ServerUpstream"] = serverUpstream
+	var buffer bytes.Buffer
+	if err = tmpl.Execute(&buffer, data); err != nil {
+		logger.Fatalf("template execute failed: %s", err.Error())
+	}
+	return buffer.String()
+}
+
+// SyncServerConfig rewrites kubeclipper-server.yaml and the console Caddyfile
+// on servers after the server list changed. kc-console is always restarted,
+// kc-server only when restartServer is set, one node at a time so the
+// platform stays available.
+func SyncServerConfig(dc *options.DeployConfig, servers []string, restartServer bool) error {
+	for _, host := range servers {
+		cmd := sshutils.WrapEcho(ServerConfigContent(dc, host), "/etc/kubeclipper-server/kubeclipper-server.yaml")
+		if restartServer {
+			cmd += " && systemctl restart kc-server"
+		}
+		if err := runCmdWithSudo(dc.SSHConfig, host, cmd); err != nil {
+			return errors.WithMessagef(err, "[%s]update kc-server config", host)
+		}
+		cmd = sshutils.WrapEcho(ConsoleConfigContent(dc), "/etc/kc-console/Caddyfile") + " && systemctl restart kc-console"
+		if err := runCmdWithSudo(dc.SSHConfig, host, cmd); err != nil {
+			return errors.WithMessagef(err, "[%s]update kc-console config", host)
+		}
+		if restartServer {
+			// TODO: check server healthz endpoint instead of time sleep
+			time.Sleep(5 * time.Second)
+		}
+	}
+	return nil
+}
+
+// SyncAgentConfig points kubeclipper-agent.yaml of every agent at the current
+// servers. Running agents reach the new servers through the mq cluster, the
+// config only matters when kc-agent restarts, so it is restarted only when
+// restartAgent is set. An unreachable agent is reported and skipped.
+func SyncAgentConfig(dc *options.DeployConfig, restartAgent bool) error {
+	cmd := AgentConfigSyncCmd(dc)
+	if restartAgent {
+		cmd += " && systemctl restart kc-agent"
+	}
+	var failed []string
+	for _, ip := range dc.Agents.ListIP() {
+		if err := runCmdWithSudo(dc.SSHConfig, ip, cmd); err != nil {
+			logger.Warnf("[%s]update kc-agent config failed: %s", ip, err.Error())
+			failed = append(failed, ip)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("update kc-agent config of %s failed, run the command again once they are reachable", strings.Join(failed, ","))
+	}
+	return nil
+}
+
+// AgentConfigSyncCmd rewrites the mq server list and the downloader address of
+// the kubeclipper-agent.yaml rendered from config.KcAgentConfigTmpl, the other
+// settings of the agent, such as its id, are kept.
+func AgentConfigSyncCmd(dc *options.DeployConfig) string {
+	var mqServerEndpoints []string
+	for _, v := range dc.MQ.IPs {
+		mqServerEndpoints = append(mqServerEndpoints, fmt.Sprintf("%s:%d", v, dc.MQ.Port))
+	}
+	staticServerAddress := fmt.Sprintf("http://%s:%d", dc.ServerIPs[0], dc.StaticServerPort)
+	file := "/etc/kubeclipper-agent/kubeclipper-agent.yaml"
+	script := `/^    serverAddress:/ {print; n=split(eps,e,","); for(i=1;i<=n;i++) print "    - " e[i]; skip=1; next} ` +
+		`/^    - / {if (skip) next} {skip=0} /^  address:/ {print "  address: " static; next} {print}`
+	awk := fmt.Sprintf("awk -v eps=%s -v static=%s '%s' %s > %s.tmp",
+		strings.Join(mqServerEndpoints, ","), staticServerAddress, script, file, file)
+	return sshutils.WrapSh(awk) + fmt.Sprintf(" && mv %s.tmp %s", file, file)
+}
+
+func runCmdWithSudo(sshConfig *sshutils.SSH, host, cmd string) error {
+	ret, err := sshutils.SSHCmdWithSudo(sshConfig, host, cmd)
+	if err != nil {
+		return err
+	}
+	return ret.Error()
+}
+
+func sendCertAndKey(sshConfig *sshutils.SSH, node string, contents []certutils.Config, pki string) error {
+	for _, content := range contents {
+		for _, ext := range []string{".key", ".crt"} {
+			err := utils.SendPackageV2(sshConfig,
+				path.Join(content.Path, content.BaseName+ext),
+				[]string{node},
+				filepath.Join(options.DefaultKcServerConfigPath, pki), nil, nil)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// CaList returns the root ca of the platform, kept under ~/.kc/pki.
+func CaList() []certutils.Config {
+	certPath := filepath.Join(options.HomeDIR, options.DefaultPath, options.DefaultCaPath)
+	return []certutils.Config{
+		{
+			Path:         certPath,
+			BaseName:     options.Ca,
+			CommonName:   options.Ca,
+			Organization: []string{"kubeclipper.io"},
+			Year:         100,
+			AltNames:     certutils.AltNames{},
+			Usages:       []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		},
+	}
+}
+
+// CertList returns the configs of the certs signed by caName for every common name.
+func CertList(pki, caName string, altNames []string, commonNameUsage map[string][]x509.ExtKeyUsage) []certutils.Config {
+	certPath := filepath.Join(options.HomeDIR, options.DefaultPath, pki)
+	alt := certutils.AltNames{
+		DNSNames: map[string]string{
+			"localhost": "localhost",
+		},
+		IPs: map[string]net.IP{
+			"127.0.0.1":               net.IPv4(127, 0, 0, 1),
+			net.IPv6loopback.String(): net.IPv6loopback,
+		},
+	}
+	for _, altName := range altNames {
+		if ip := net.ParseIP(altName); ip != nil {
+			alt.IPs[ip.String()] = ip
+			continue
+		}
+		alt.DNSNames[altName] = altName
+	}
+	logger.V(2).Infof("Etcd alt DNS : [%v], Etcd alt IPs: [%v]", alt.DNSNames, alt.IPs)
+	certConfig := make([]certutils.Config, 0)
+	for commonName, usages := range commonNameUsage {
+		conf := certutils.Config{
+			Path:         certPath,
+			BaseName:     commonName,
+			CAName:       caName,
+			CommonName:   commonName,
+			Organization: []string{"kubeclipper.io"},
+			Year:         100,
+			AltNames:     alt,
+			Usages:       usages,
+		}
+		certConfig = append(certConfig, conf)
+	}
+	return certConfig
+}
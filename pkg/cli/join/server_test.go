@@ -0,0 +1,114 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package join
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/natsio"
+)
+
+func TestParseInitialCluster(t *testing.T) {
+	tests := []struct {
+		name    string
+		out     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "member add output",
+			out: `Member ced000fda4d05edf added to cluster 8c4281cc65c7b112
+
+ETCD_NAME="node2"
+ETCD_INITIAL_CLUSTER="node1=https://10.0.0.1:2380,node2=https://10.0.0.2:2380"
+ETCD_INITIAL_ADVERTISE_PEER_URLS="https://10.0.0.2:2380"
+ETCD_INITIAL_CLUSTER_STATE="existing"
+`,
+			want: "node1=https://10.0.0.1:2380,node2=https://10.0.0.2:2380",
+		},
+		{
+			name:    "missing",
+			out:     "Error: etcdserver: unhealthy cluster",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseInitialCluster(tt.out)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseInitialCluster() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseInitialCluster() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEtcdMemberID(t *testing.T) {
+	list := `8e9e05c52164694d, started, node1, https://10.0.0.1:2380, https://10.0.0.1:2379, false
+91bc3c398fb3c146, started, node2, https://10.0.0.2:2380, https://10.0.0.2:2379, false
+fd422379fda50e48, unstarted, , https://10.0.0.3:2380, , false
+`
+	tests := []struct {
+		name    string
+		peerURL string
+		want    string
+	}{
+		{"started", "https://10.0.0.2:2380", "91bc3c398fb3c146"},
+		{"unstarted", "https://10.0.0.3:2380", "fd422379fda50e48"},
+		{"not found", "https://10.0.0.4:2380", ""},
+		{"client url", "https://10.0.0.1:2379", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EtcdMemberID(list, tt.peerURL); got != tt.want {
+				t.Errorf("EtcdMemberID() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServerConfigContent(t *testing.T) {
+	dc := options.NewDeployOptions()
+	dc.ServerIPs = []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}
+	dc.MQ.IPs = dc.ServerIPs
+
+	var cfg struct {
+		MQ natsio.NatsOptions `yaml:"mq"`
+	}
+	if err := yaml.Unmarshal([]byte(ServerConfigContent(dc, "10.0.0.2")), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	cluster := cfg.MQ.Server.Cluster
+	if cluster.Host != "10.0.0.2" || cluster.LeaderHost != "10.0.0.1:9890" {
+		t.Errorf("unexpected cluster host %s leader %s", cluster.Host, cluster.LeaderHost)
+	}
+	want := []string{"10.0.0.1:9890", "10.0.0.2:9890", "10.0.0.3:9890"}
+	if !reflect.DeepEqual(cluster.Routes, want) {
+		t.Errorf("routes got = %v, want %v", cluster.Routes, want)
+	}
+	if !reflect.DeepEqual(cfg.MQ.Client.ServerAddress, []string{"10.0.0.1:9889", "10.0.0.2:9889", "10.0.0.3:9889"}) {
+		t.Errorf("unexpected client server address %v", cfg.MQ.Client.ServerAddress)
+	}
+}
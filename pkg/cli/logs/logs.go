@@ -0,0 +1,282 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package logs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/utils"
+	"github.com/kubeclipper/kubeclipper/pkg/oplog"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/kc"
+)
+
+/*
+print the step logs of operation
+
+Usage:
+  kcctl logs <operation> [flags]
+
+Examples:
+  kcctl logs 'OPERATION-NAME' -f
+
+Flags:
+  -f, --follow        Specify if the logs should be streamed.
+  -h, --help          help for logs
+      --step string   Only print the logs of the step.
+*/
+
+const (
+	longDescription = `
+  Print the step logs of the operation on all nodes.
+
+  Each line is prefixed with the node and step it comes from.
+  With --follow, the logs appended later are printed until the operation is done.`
+	logsExample = `
+  # Print the logs of the operation
+  kcctl logs 'OPERATION-NAME'
+
+  # Print the logs of a step of the operation
+  kcctl logs 'OPERATION-NAME' --step 'STEP-ID'
+
+  # Print the logs of the operation and follow them until the operation is done
+  kcctl logs 'OPERATION-NAME' -f
+
+  Please read 'kcctl logs -h' get more logs flags.`
+)
+
+type LogsOptions struct {
+	cliOpts *options.CliOptions
+	options.IOStreams
+	client    *kc.Client
+	operation string
+	Step      string
+	Follow    bool
+}
+
+func NewLogsOptions(streams options.IOStreams) *LogsOptions {
+	return &LogsOptions{
+		cliOpts:   options.NewCliOptions(),
+		IOStreams: streams,
+	}
+}
+
+func NewCmdLogs(streams options.IOStreams) *cobra.Command {
+	o := NewLogsOptions(streams)
+	cmd := &cobra.Command{
+		Use:                   "logs <operation> [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Print the step logs of operation",
+		Long:                  longDescription,
+		Example:               logsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			utils.CheckErr(o.Complete(o.cliOpts))
+			utils.CheckErr(o.ValidateArgs(cmd, args))
+			utils.CheckErr(o.RunLogs())
+		},
+	}
+	o.cliOpts.AddFlags(cmd.Flags())
+	cmd.Flags().BoolVarP(&o.Follow, "follow", "f", o.Follow, "Specify if the logs should be streamed.")
+	cmd.Flags().StringVar(&o.Step, "step", o.Step, "Only print the logs of the step.")
+	return cmd
+}
+
+func (o *LogsOptions) Complete(opts *options.CliOptions) error {
+	if err := opts.Complete(); err != nil {
+		return err
+	}
+	c, err := opts.ToRawConfig().ToKcClient()
+	if err != nil {
+		return err
+	}
+	o.client = c
+	return nil
+}
+
+func (o *LogsOptions) ValidateArgs(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return utils.UsageErrorf(cmd, "You must specify the name of the operation")
+	}
+	o.operation = args[0]
+	return nil
+}
+
+func (o *LogsOptions) RunLogs() error {
+	ctx := context.TODO()
+	ops, err := o.client.DescribeOperation(ctx, o.operation)
+	if err != nil {
+		return err
+	}
+	op := &ops.Items[0]
+	var steps []v1.Step
+	for _, step := range op.Steps {
+		if o.Step == "" || step.ID == o.Step {
+			steps = append(steps, step)
+		}
+	}
+	if len(steps) == 0 {
+		return fmt.Errorf("step %s not found in operation %s", o.Step, o.operation)
+	}
+
+	// open the stream before reading the logs, so that nothing appended in between is missed.
+	var stream *kc.LogStream
+	if o.Follow && op.Status.Status == v1.OperationStatusRunning {
+		if stream, err = o.client.StreamOperationLog(ctx, o.operation, o.Step); err != nil {
+			return err
+		}
+		defer stream.Close()
+	}
+
+	p := newLogPrinter(o.IOStreams.Out, steps)
+	for _, step := range steps {
+		for _, node := range step.Nodes {
+			if err = o.printStepLog(ctx, p, step, node.ID); err != nil {
+				return err
+			}
+		}
+	}
+	if stream == nil {
+		p.flush()
+		return nil
+	}
+	for {
+		chunk, err := stream.Next()
+		if err != nil {
+			p.flush()
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		p.writeChunk(chunk)
+	}
+}
+
+func (o *LogsOptions) printStepLog(ctx context.Context, p *logPrinter, step v1.Step, node string) error {
+	var offset int64
+	for {
+		log, err := o.client.GetStepLog(ctx, o.operation, step.ID, node, offset)
+		if err != nil {
+			// the step may not run on the node yet
+			return nil
+		}
+		if log.DeliverySize == 0 {
+			return nil
+		}
+		p.write(node, stepKey(step), offset, []byte(log.Content))
+		offset += log.DeliverySize
+		if offset >= log.LogSize {
+			return nil
+		}
+	}
+}
+
+func stepKey(step v1.Step) string {
+	return fmt.Sprintf("%s-%s", step.ID, step.Name)
+}
+
+// logPrinter prints the step logs line by line with the node and step prefix, and drops
+// the streamed logs which have been printed already.
+type logPrinter struct {
+	out      io.Writer
+	prefixes map[string]string
+	// printed is the printed size of the log of node and step.
+	printed map[string]int64
+	// partial is the last line without line break of node and step.
+	partial map[string]*bytes.Buffer
+	keys    []string
+}
+
+func newLogPrinter(out io.Writer, steps []v1.Step) *logPrinter {
+	p := &logPrinter{
+		out:      out,
+		prefixes: make(map[string]string),
+		printed:  make(map[string]int64),
+		partial:  make(map[string]*bytes.Buffer),
+	}
+	for _, step := range steps {
+		for _, node := range step.Nodes {
+			name := node.Hostname
+			if name == "" {
+				name = node.ID
+			}
+			p.prefixes[node.ID+"/"+stepKey(step)] = fmt.Sprintf("[%s %s] ", name, step.Name)
+		}
+	}
+	return p
+}
+
+func (p *logPrinter) writeChunk(chunk *oplog.LogStreamChunk) {
+	p.write(chunk.Node, chunk.StepID, chunk.Offset, []byte(chunk.Content))
+}
+
+// write prints data at offset of the log of node and step, offset is -1 if unknown.
+func (p *logPrinter) write(node, step string, offset int64, data []byte) {
+	key := node + "/" + step
+	if offset >= 0 {
+		printed := p.printed[key]
+		if end := offset + int64(len(data)); end <= printed {
+			return
+		} else if offset < printed {
+			data = data[printed-offset:]
+		}
+		p.printed[key] = offset + int64(len(data))
+	}
+	buf, ok := p.partial[key]
+	if !ok {
+		buf = &bytes.Buffer{}
+		p.partial[key] = buf
+		p.keys = append(p.keys, key)
+	}
+	buf.Write(data)
+	for {
+		line, err := buf.ReadString('\n')
+		if err != nil {
+			// keep the partial line until the rest arrives
+			buf.Reset()
+			buf.WriteString(line)
+			return
+		}
+		p.printLine(key, line)
+	}
+}
+
+func (p *logPrinter) flush() {
+	for _, key := range p.keys {
+		if buf := p.partial[key]; buf.Len() > 0 {
+			p.printLine(key, buf.String()+"\n")
+			buf.Reset()
+		}
+	}
+}
+
+func (p *logPrinter) printLine(key, line string) {
+	prefix, ok := p.prefixes[key]
+	if !ok {
+		prefix = fmt.Sprintf("[%s] ", strings.Replace(key, "/", " ", 1))
+	}
+	_, _ = fmt.Fprint(p.out, prefix+line)
+}
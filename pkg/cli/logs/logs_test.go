@@ -0,0 +1,73 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package logs
+
+import (
+	"bytes"
+	"testing"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func TestLogPrinter_write(t *testing.T) {
+	steps := []v1.Step{{ID: "s1", Name: "install", Nodes: []v1.StepNode{{ID: "n1", Hostname: "node1"}}}}
+	type chunk struct {
+		offset int64
+		data   string
+	}
+	tests := []struct {
+		name   string
+		chunks []chunk
+		want   string
+	}{
+		{
+			name:   "print complete lines",
+			chunks: []chunk{{0, "a\nb\n"}},
+			want:   "[node1 install] a\n[node1 install] b\n",
+		},
+		{
+			name:   "join partial lines",
+			chunks: []chunk{{0, "a"}, {1, "b\nc"}},
+			want:   "[node1 install] ab\n[node1 install] c\n",
+		},
+		{
+			name:   "drop printed logs",
+			chunks: []chunk{{0, "a\nb\n"}, {2, "b\n"}, {2, "b\nc\n"}},
+			want:   "[node1 install] a\n[node1 install] b\n[node1 install] c\n",
+		},
+		{
+			name:   "print chunks with unknown offset",
+			chunks: []chunk{{0, "a\n"}, {-1, "a\n"}},
+			want:   "[node1 install] a\n[node1 install] a\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			p := newLogPrinter(out, steps)
+			for _, c := range tt.chunks {
+				p.write("n1", "s1-install", c.offset, []byte(c.data))
+			}
+			p.flush()
+			if got := out.String(); got != tt.want {
+				t.Errorf("write() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
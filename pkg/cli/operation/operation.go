@@ -0,0 +1,200 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package operation
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/printer"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/utils"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/kc"
+)
+
+/*
+kubeclipper operation
+
+Usage:
+  kcctl operation cancel
+  kcctl operation retry
+
+Examples:
+  kcctl operation cancel 'OPERATION-NAME'
+  kcctl operation retry 'OPERATION-NAME' --from-step 'STEP-ID'
+
+Flags:
+  -h, --help                   help for operation
+*/
+
+const (
+	longDescription = `
+  Manage the operations of clusters.
+
+  The running operation can be cancelled, and the failed or cancelled operation can be retried.
+  Use 'kcctl get operation' to list the operations and 'kcctl logs' to view the step logs.`
+	operationExample = `
+  # Cancel the running operation
+  kcctl operation cancel 'OPERATION-NAME'
+
+  # Retry the failed operation
+  kcctl operation retry 'OPERATION-NAME'
+
+  Please read 'kcctl operation -h' get more operation flags.`
+	cancelLongDescription = `
+  Cancel the running operation.
+
+  The steps running on the nodes are killed and no more step runs,
+  the cancelled operation can be retried later.`
+	cancelExample = `
+  # Cancel the running operation
+  kcctl operation cancel 'OPERATION-NAME'
+
+  # Cancel the running operation and print it in yaml
+  kcctl operation cancel 'OPERATION-NAME' -o yaml
+
+  Please read 'kcctl operation cancel -h' get more operation cancel flags.`
+	retryLongDescription = `
+  Retry the failed or cancelled operation.
+
+  The operation is resumed from the failed step by default,
+  or from the step specified by --from-step.`
+	retryExample = `
+  # Retry the failed operation from the failed step
+  kcctl operation retry 'OPERATION-NAME'
+
+  # Retry the failed operation from the specified step
+  kcctl operation retry 'OPERATION-NAME' --from-step 'STEP-ID'
+
+  Please read 'kcctl operation retry -h' get more operation retry flags.`
+)
+
+type OperationOptions struct {
+	PrintFlags *printer.PrintFlags
+	CliOpts    *options.CliOptions
+	options.IOStreams
+	Client   *kc.Client
+	FromStep string
+	name     string
+}
+
+func NewOperationOptions(streams options.IOStreams) *OperationOptions {
+	return &OperationOptions{
+		PrintFlags: printer.NewPrintFlags(),
+		CliOpts:    options.NewCliOptions(),
+		IOStreams:  streams,
+	}
+}
+
+func NewCmdOperation(streams options.IOStreams) *cobra.Command {
+	o := NewOperationOptions(streams)
+	cmd := &cobra.Command{
+		Use:                   "operation",
+		DisableFlagsInUseLine: true,
+		Short:                 "cluster operation",
+		Long:                  longDescription,
+		Example:               operationExample,
+		Args:                  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(NewCmdOperationCancel(o))
+	cmd.AddCommand(NewCmdOperationRetry(o))
+
+	return cmd
+}
+
+func NewCmdOperationCancel(o *OperationOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "cancel <name> [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "cancel the running operation",
+		Long:                  cancelLongDescription,
+		Example:               cancelExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			utils.CheckErr(o.Complete(o.CliOpts))
+			utils.CheckErr(o.ValidateArgs(cmd, args))
+			utils.CheckErr(o.RunCancel())
+		},
+	}
+	o.CliOpts.AddFlags(cmd.Flags())
+	o.PrintFlags.AddFlags(cmd)
+	return cmd
+}
+
+func NewCmdOperationRetry(o *OperationOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "retry <name> [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "retry the failed operation",
+		Long:                  retryLongDescription,
+		Example:               retryExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			utils.CheckErr(o.Complete(o.CliOpts))
+			utils.CheckErr(o.ValidateArgs(cmd, args))
+			utils.CheckErr(o.RunRetry())
+		},
+	}
+	cmd.Flags().StringVar(&o.FromStep, "from-step", o.FromStep, "id of the step to resume from, defaults to the failed step")
+	o.CliOpts.AddFlags(cmd.Flags())
+	o.PrintFlags.AddFlags(cmd)
+	return cmd
+}
+
+func (o *OperationOptions) Complete(opts *options.CliOptions) error {
+	if err := opts.Complete(); err != nil {
+		return err
+	}
+	c, err := opts.ToRawConfig().ToKcClient()
+	if err != nil {
+		return err
+	}
+	o.Client = c
+	return nil
+}
+
+func (o *OperationOptions) ValidateArgs(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return utils.UsageErrorf(cmd, "You must specify the name of the operation to %s", cmd.Name())
+	}
+	o.name = args[0]
+	return nil
+}
+
+func (o *OperationOptions) RunCancel() error {
+	op, err := o.Client.CancelOperation(context.TODO(), o.name)
+	if err != nil {
+		return err
+	}
+	return o.PrintFlags.Print(op, o.IOStreams.Out)
+}
+
+func (o *OperationOptions) RunRetry() error {
+	if err := o.Client.RetryOperation(context.TODO(), o.name, o.FromStep); err != nil {
+		return err
+	}
+	op, err := o.Client.DescribeOperation(context.TODO(), o.name)
+	if err != nil {
+		return err
+	}
+	return o.PrintFlags.Print(op, o.IOStreams.Out)
+}
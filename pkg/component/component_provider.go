@@ -0,0 +1,50 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package component
+
+import (
+	"errors"
+	"sync"
+)
+
+var (
+	ErrProviderExist = errors.New("cluster provider already exist")
+)
+
+var (
+	providerLock sync.RWMutex
+	providers    = make(map[string]ClusterProvider)
+)
+
+func RegisterClusterProvider(p ClusterProvider) error {
+	providerLock.Lock()
+	defer providerLock.Unlock()
+	if _, exist := providers[p.Name()]; exist {
+		return ErrProviderExist
+	}
+	providers[p.Name()] = p
+	return nil
+}
+
+func LoadClusterProvider(name string) (ClusterProvider, bool) {
+	providerLock.RLock()
+	defer providerLock.RUnlock()
+	p, exist := providers[name]
+	return p, exist
+}
@@ -24,6 +24,7 @@ import (
 
 type (
 	extraKey     struct{}
+	repliesKey   struct{}
 	metaKey      struct{}
 	operationKey struct{}
 	stepKey      struct{}
@@ -45,6 +46,7 @@ type ExtraMetadata struct {
 	CRI           string
 	ClusterName   string
 	KubeVersion   string
+	CNIVersion    string
 	OperationType string
 }
 
@@ -140,6 +142,18 @@ func GetExtraData(ctx context.Context) []byte {
 	return nil
 }
 
+// WithStepReplies sets the last replies of the resumed steps keyed by the step ID.
+func WithStepReplies(ctx context.Context, replies map[string][]byte) context.Context {
+	return context.WithValue(ctx, repliesKey{}, replies)
+}
+
+func GetStepReplies(ctx context.Context) map[string][]byte {
+	if v := ctx.Value(repliesKey{}); v != nil {
+		return v.(map[string][]byte)
+	}
+	return nil
+}
+
 func WithExtraMetadata(ctx context.Context, metadata ExtraMetadata) context.Context {
 	return context.WithValue(ctx, metaKey{}, metadata)
 }
@@ -20,7 +20,7 @@ package component
 
 import (
 	"context"
-	"os"
+	"io"
 
 	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
 )
@@ -152,11 +152,25 @@ type Runnable interface {
 
 type FuncIndex func() (key, value []byte, err error)
 
+// ClusterProvider generates the steps managing clusters of a kubernetes distribution,
+// it is registered with the name used in the cluster provider spec.
+// The extra metadata of the cluster is carried by ctx.
+type ClusterProvider interface {
+	Name() string
+	Validate(c *v1.Cluster) error
+	InstallSteps(ctx context.Context, c *v1.Cluster) ([]v1.Step, error)
+	UninstallSteps(ctx context.Context, c *v1.Cluster) ([]v1.Step, error)
+	// UpgradeSteps upgrades the cluster to the kube version in the extra metadata.
+	UpgradeSteps(ctx context.Context, c *v1.Cluster) ([]v1.Step, error)
+	AddNodeSteps(ctx context.Context, c *v1.Cluster, nodes []v1.StepNode, role string) ([]v1.Step, error)
+	RemoveNodeSteps(ctx context.Context, c *v1.Cluster, nodes []v1.StepNode, role string) ([]v1.Step, error)
+}
+
 type OperationLogFile interface {
 	GetRootDir() string
 	CreateOperationDir(opID string) error
 	GetOperationDir(opID string) (path string, err error)
-	CreateStepLogFile(opID, stepID string) (file *os.File, err error)
+	CreateStepLogFile(opID, stepID string) (file io.WriteCloser, err error)
 	GetStepLogFile(opID, stepID string) (path string, err error)
 	GetStepLogContent(opID, stepID string, offset int64, length int) (content []byte, deliverySize int64, logSize int64, err error)
 	CreateStepLogFileAndAppend(opID, stepID string, data []byte) error
@@ -26,6 +26,7 @@ import (
 
 	"go.uber.org/zap"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 
@@ -111,6 +112,20 @@ func (r *BackupReconciler) updateBackupStatus(ctx context.Context, log logger.Lo
 		return err
 	}
 
+	// when the verification is done, record the result in the backup conditions
+	if name := b.Labels[common.LabelVerifyOperationName]; name != "" {
+		vo, err := r.OperationLister.Get(name)
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		if vo != nil && vo.Status.Status != v1.OperationStatusRunning && setVerification(b, vo) {
+			if b, err = r.BackupWriter.UpdateBackup(context.TODO(), b); err != nil {
+				log.Warnf("backup(%s) sync verification failed: %s", b.Name, err.Error())
+				return err
+			}
+		}
+	}
+
 	// if operation not exist, backup change to error
 	if oErr != nil && errors.IsNotFound(oErr) {
 		b.Status.ClusterBackupStatus = v1.ClusterBackupError
@@ -166,17 +181,32 @@ func (r *BackupReconciler) updateBackupStatus(ctx context.Context, log logger.Lo
 		if checkFile.BackupFileSize != int64(0) && checkFile.BackupFileMD5 != "" {
 			b.Status.BackupFileSize = checkFile.BackupFileSize
 			b.Status.BackupFileMD5 = checkFile.BackupFileMD5
+			b.Status.Algorithm = checkFile.Algorithm
+			b.Status.Checksum = checkFile.Checksum
 		} else {
 			log.Warnf("backup file size is %s, and backup md5 is %s, reconcile again", checkFile.BackupFileSize, checkFile.BackupFileMD5)
 			return fmt.Errorf("backup file size is %d, and backup md5 is %s", checkFile.BackupFileSize, checkFile.BackupFileMD5)
 		}
 		b.Status.ClusterBackupStatus = v1.ClusterBackupAvailable
+		// the backup created by the cronBackup may be verified in the same operation
+		setVerification(b, o)
 		_, err := r.BackupWriter.UpdateBackup(context.TODO(), b)
 		if err != nil {
 			log.Warnf("backup(%s) sync status failed: %s", b.Name, err.Error())
 		}
 	}
 
+	// backups verified before the verify operation label was introduced
+	if o != nil && o.Labels[common.LabelOperationAction] == v1.OperationVerifyBackup && o.Status.Status != v1.OperationStatusRunning {
+		if setVerification(b, o) {
+			_, err := r.BackupWriter.UpdateBackup(context.TODO(), b)
+			if err != nil {
+				log.Warnf("backup(%s) sync verification failed: %s", b.Name, err.Error())
+			}
+		}
+		return nil
+	}
+
 	// when the backup is restoring and operation is successful, set the backup status to available
 	if b.Status.ClusterBackupStatus == v1.ClusterBackupRestoring && o != nil && o.Status.Status == v1.OperationStatusSuccessful && o.Labels[common.LabelOperationAction] == v1.OperationRecoverCluster {
 		b.Status.ClusterBackupStatus = v1.ClusterBackupAvailable
@@ -186,8 +216,8 @@ func (r *BackupReconciler) updateBackupStatus(ctx context.Context, log logger.Lo
 		}
 	}
 
-	// when the operation status is failed, set the backup status to error
-	if o != nil && o.Status.Status == v1.OperationStatusFailed {
+	// when the operation status is failed or cancelled, set the backup status to error
+	if o != nil && (o.Status.Status == v1.OperationStatusFailed || o.Status.Status == v1.OperationStatusCancelled) {
 		if c.Status.Phase == v1.ClusterRestoreFailed {
 			b.Status.ClusterBackupStatus = v1.ClusterBackupAvailable
 		} else {
@@ -210,7 +240,7 @@ func (r *BackupReconciler) findObjectsForOperation(clu client.Object) []reconcil
 	}
 	var requests []reconcile.Request
 	for _, backup := range backups {
-		if backup.Labels[common.LabelOperationName] == clu.GetName() {
+		if backup.Labels[common.LabelOperationName] == clu.GetName() || backup.Labels[common.LabelVerifyOperationName] == clu.GetName() {
 			requests = append(requests, reconcile.Request{
 				NamespacedName: types.NamespacedName{
 					Name: backup.Name,
@@ -221,6 +251,62 @@ func (r *BackupReconciler) findObjectsForOperation(clu client.Object) []reconcil
 	return requests
 }
 
+// setVerification sets the verification condition of the backup by the verify step of the operation,
+// it reports whether the backup is changed.
+func setVerification(b *v1.Backup, o *v1.Operation) bool {
+	var stepID string
+	for _, step := range o.Steps {
+		if step.Name == k8s.VerifyBackupStep {
+			stepID = step.ID
+			break
+		}
+	}
+	if stepID == "" {
+		return false
+	}
+	cond := v1.BackupCondition{
+		Type:    v1.BackupVerificationFailed,
+		Status:  v1.ConditionTrue,
+		Reason:  string(o.Status.Status),
+		Message: fmt.Sprintf("operation %s is %s", o.Name, o.Status.Status),
+	}
+	var keyCount int64
+	for _, c := range o.Status.Conditions {
+		if c.StepID != stepID || len(c.Status) == 0 {
+			continue
+		}
+		// the last record wins when the step is retried
+		status := c.Status[len(c.Status)-1]
+		if status.Status != v1.StepStatusSuccessful {
+			cond.Reason = status.Reason
+			cond.Message = fmt.Sprintf("operation %s: %s", o.Name, status.Message)
+			break
+		}
+		verification := k8s.BackupVerification{}
+		if err := json.Unmarshal(status.Response, &verification); err != nil {
+			cond.Reason = "InvalidResponse"
+			cond.Message = fmt.Sprintf("operation %s: %s", o.Name, err.Error())
+			break
+		}
+		keyCount = verification.KeyCount
+		cond = v1.BackupCondition{
+			Type:    v1.BackupVerified,
+			Status:  v1.ConditionTrue,
+			Reason:  "SnapshotRestored",
+			Message: fmt.Sprintf("verified by operation %s", o.Name),
+		}
+	}
+	if cur := b.Status.GetCondition(cond.Type); cur != nil && cur.Message == cond.Message {
+		return false
+	}
+	cond.LastTransitionTime = metav1.Now()
+	b.Status.SetVerification(cond)
+	if cond.Type == v1.BackupVerified {
+		b.Status.KeyCount = keyCount
+	}
+	return true
+}
+
 func checkBackupTimeout(log logger.Logging, b *v1.Backup) bool {
 	if b.Labels[common.LabelTimeoutSeconds] == "" {
 		log.Warn("unexpected error, backup should always has a timeout label. will be considered as timeout")
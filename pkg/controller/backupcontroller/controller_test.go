@@ -0,0 +1,99 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package backupcontroller
+
+import (
+	"encoding/json"
+	"testing"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1/k8s"
+)
+
+func TestSetVerification(t *testing.T) {
+	response, _ := json.Marshal(&k8s.BackupVerification{KeyCount: 1024, Revision: 10})
+	steps := []v1.Step{{ID: "backup", Name: "createBackup"}, {ID: "verify", Name: k8s.VerifyBackupStep}}
+	tests := []struct {
+		name         string
+		op           *v1.Operation
+		wantChanged  bool
+		wantType     v1.BackupConditionType
+		wantKeyCount int64
+	}{
+		{
+			name: "no verify step",
+			op:   &v1.Operation{Steps: steps[:1]},
+		},
+		{
+			name: "verified",
+			op: &v1.Operation{Steps: steps, Status: v1.OperationStatus{
+				Status: v1.OperationStatusSuccessful,
+				Conditions: []v1.OperationCondition{
+					{StepID: "verify", Status: []v1.StepStatus{
+						{Node: "node1", Status: v1.StepStatusFailed},
+						{Node: "node1", Status: v1.StepStatusSuccessful, Response: response},
+					}},
+				},
+			}},
+			wantChanged:  true,
+			wantType:     v1.BackupVerified,
+			wantKeyCount: 1024,
+		},
+		{
+			name: "verification failed",
+			op: &v1.Operation{Steps: steps, Status: v1.OperationStatus{
+				Status: v1.OperationStatusSuccessful,
+				Conditions: []v1.OperationCondition{
+					{StepID: "verify", Status: []v1.StepStatus{{Node: "node1", Status: v1.StepStatusFailed, Message: "md5 mismatch"}}},
+				},
+			}},
+			wantChanged: true,
+			wantType:    v1.BackupVerificationFailed,
+		},
+		{
+			name:        "operation cancelled",
+			op:          &v1.Operation{Steps: steps, Status: v1.OperationStatus{Status: v1.OperationStatusCancelled}},
+			wantChanged: true,
+			wantType:    v1.BackupVerificationFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.op.Name = "op"
+			b := &v1.Backup{}
+			b.Status.SetVerification(v1.BackupCondition{Type: v1.BackupVerificationFailed, Message: "previous"})
+			if got := setVerification(b, tt.op); got != tt.wantChanged {
+				t.Fatalf("setVerification() = %v, want %v", got, tt.wantChanged)
+			}
+			if !tt.wantChanged {
+				return
+			}
+			if len(b.Status.Conditions) != 1 || b.Status.Conditions[0].Type != tt.wantType {
+				t.Errorf("setVerification() conditions = %+v, want %s", b.Status.Conditions, tt.wantType)
+			}
+			if b.Status.KeyCount != tt.wantKeyCount {
+				t.Errorf("setVerification() key count = %d, want %d", b.Status.KeyCount, tt.wantKeyCount)
+			}
+			// the result of the same operation is recorded once
+			if setVerification(b, tt.op) {
+				t.Errorf("setVerification() changed the backup twice")
+			}
+		})
+	}
+}
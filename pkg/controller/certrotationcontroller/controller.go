@@ -0,0 +1,223 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package certrotationcontroller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/client/informers"
+	listerv1 "github.com/kubeclipper/kubeclipper/pkg/client/lister/core/v1"
+	ctrl "github.com/kubeclipper/kubeclipper/pkg/controller-runtime"
+	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/controller"
+	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/handler"
+	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/manager"
+	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/source"
+	"github.com/kubeclipper/kubeclipper/pkg/errors"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/models/cluster"
+	"github.com/kubeclipper/kubeclipper/pkg/models/operation"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1/k8s"
+	"github.com/kubeclipper/kubeclipper/pkg/service"
+)
+
+const (
+	// retry interval when the cluster is busy at the rotation time
+	busyRequeueInterval = time.Minute
+	// the certifications are collected by the cluster status monitor, check again later if they are not collected yet
+	certCheckInterval = time.Hour
+	// the certifications are collected again after the rotation, do not rotate again before that
+	minRotationInterval = 24 * time.Hour
+)
+
+// CertRotationReconciler renews the certificates of the clusters before they expire according to the rotation policy.
+type CertRotationReconciler struct {
+	ClusterLister   listerv1.ClusterLister
+	NodeLister      listerv1.NodeLister
+	ClusterWriter   cluster.ClusterWriter
+	OperationWriter operation.Writer
+	CmdDelivery     service.CmdDelivery
+	now             func() time.Time
+}
+
+func (r *CertRotationReconciler) SetupWithManager(mgr manager.Manager, cache informers.InformerCache) error {
+	c, err := controller.NewUnmanaged("certrotation", controller.Options{
+		MaxConcurrentReconciles: 2,
+		Reconciler:              r,
+		Log:                     mgr.GetLogger().WithName("certrotation-controller"),
+		RecoverPanic:            true,
+	})
+	if err != nil {
+		return err
+	}
+	if err = c.Watch(source.NewKindWithCache(&v1.Cluster{}, cache), &handler.EnqueueRequestForObject{}); err != nil {
+		return err
+	}
+	mgr.AddRunnable(c)
+	return nil
+}
+
+func (r *CertRotationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logger.FromContext(ctx)
+	c, err := r.ClusterLister.Get(req.Name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		log.Error("Failed to get cluster with name", zap.Error(err))
+		return ctrl.Result{}, err
+	}
+	if c.CertRotationPolicy == nil || !c.DeletionTimestamp.IsZero() {
+		if c.Status.CertRotation.NextRotationTime == nil {
+			return ctrl.Result{}, nil
+		}
+		// the policy is removed
+		c = c.DeepCopy()
+		c.Status.CertRotation.NextRotationTime = nil
+		_, err = r.ClusterWriter.UpdateCluster(ctx, c)
+		return ctrl.Result{}, err
+	}
+	if len(c.Status.Certifications) == 0 {
+		return ctrl.Result{RequeueAfter: certCheckInterval}, nil
+	}
+
+	now := r.clock()
+	next, err := nextRotationTime(c, now)
+	if err != nil {
+		log.Error("invalid certificate rotation policy", zap.Error(err))
+		return ctrl.Result{}, nil
+	}
+	if next.After(now) {
+		if err = r.updateNextRotationTime(ctx, c, next); err != nil {
+			log.Error("Failed to update cluster", zap.Error(err))
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: next.Sub(now)}, nil
+	}
+	if c.Status.Phase != v1.ClusterRunning {
+		log.Warnf("the cluster is %v, renew certificates later", c.Status.Phase)
+		return ctrl.Result{RequeueAfter: busyRequeueInterval}, nil
+	}
+
+	c = c.DeepCopy()
+	op, err := r.makeOperation(c)
+	if err != nil {
+		log.Error("Failed to make certificate rotation operation", zap.Error(err))
+		return ctrl.Result{}, err
+	}
+	rotateAt := metav1.NewTime(now)
+	c.Status.Phase = v1.ClusterUpdating
+	c.Status.CertRotation.LastRotationTime = &rotateAt
+	c.Status.CertRotation.NextRotationTime = nil
+	if op, err = r.OperationWriter.CreateOperation(ctx, op); err != nil {
+		log.Error("Failed to create operation", zap.Error(err))
+		return ctrl.Result{}, err
+	}
+	if _, err = r.ClusterWriter.UpdateCluster(ctx, c); err != nil {
+		log.Error("Failed to update cluster", zap.Error(err))
+		return ctrl.Result{}, err
+	}
+	log.Info("renew cluster certificates", zap.String("cluster", c.Name), zap.String("operation", op.Name))
+	go func() {
+		if err := r.CmdDelivery.DeliverTaskOperation(context.TODO(), op, &service.Options{DryRun: false}); err != nil {
+			log.Error("Failed to delivery operation", zap.Error(err))
+		}
+	}()
+	return ctrl.Result{RequeueAfter: minRotationInterval}, nil
+}
+
+func (r *CertRotationReconciler) clock() time.Time {
+	if r.now != nil {
+		return r.now()
+	}
+	return time.Now()
+}
+
+func (r *CertRotationReconciler) updateNextRotationTime(ctx context.Context, c *v1.Cluster, next time.Time) error {
+	if t := c.Status.CertRotation.NextRotationTime; t != nil && t.Time.Equal(next) {
+		return nil
+	}
+	c = c.DeepCopy()
+	nextRotationAt := metav1.NewTime(next)
+	c.Status.CertRotation.NextRotationTime = &nextRotationAt
+	_, err := r.ClusterWriter.UpdateCluster(ctx, c)
+	return err
+}
+
+// nextRotationTime returns the time the certificates of the cluster should be renewed, it is
+// RenewBefore the first certificate expires, delayed to the maintenance window and not earlier
+// than minRotationInterval after the last rotation.
+func nextRotationTime(c *v1.Cluster, now time.Time) (time.Time, error) {
+	var expiry time.Time
+	for _, cert := range c.Status.Certifications {
+		if expiry.IsZero() || cert.ExpirationTime.Time.Before(expiry) {
+			expiry = cert.ExpirationTime.Time
+		}
+	}
+	next := expiry.Add(-c.CertRotationPolicy.RenewBefore())
+	if last := c.Status.CertRotation.LastRotationTime; last != nil && next.Before(last.Add(minRotationInterval)) {
+		next = last.Add(minRotationInterval)
+	}
+	if next.Before(now) {
+		next = now
+	}
+	if w := c.CertRotationPolicy.MaintenanceWindow; w != nil {
+		return w.Next(next)
+	}
+	return next, nil
+}
+
+func (r *CertRotationReconciler) makeOperation(c *v1.Cluster) (*v1.Operation, error) {
+	if c.Provider.Name != "" && c.Provider.Name != v1.ClusterKubeadm {
+		return nil, fmt.Errorf("certificate rotation is not supported by %s cluster", c.Provider.Name)
+	}
+	var masters []v1.StepNode
+	for _, m := range c.Masters {
+		n, err := r.NodeLister.Get(m.ID)
+		if err != nil {
+			return nil, err
+		}
+		masters = append(masters, v1.StepNode{
+			ID:       n.Name,
+			IPv4:     n.Status.Ipv4DefaultIP,
+			Hostname: n.Labels[common.LabelHostname],
+		})
+	}
+	steps, err := (&k8s.Certification{}).InstallSteps(masters)
+	if err != nil {
+		return nil, err
+	}
+	op := &v1.Operation{Steps: steps}
+	op.Name = uuid.New().String()
+	op.Labels = map[string]string{
+		common.LabelClusterName:     c.Name,
+		common.LabelTimeoutSeconds:  v1.DefaultOperationTimeoutSecs,
+		common.LabelOperationAction: v1.OperationUpdateCertification,
+		common.LabelTopologyRegion:  c.Masters[0].Labels[common.LabelTopologyRegion],
+	}
+	op.Status.Status = v1.OperationStatusRunning
+	return op, nil
+}
@@ -0,0 +1,120 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package certrotationcontroller
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func Test_nextRotationTime(t *testing.T) {
+	// Thursday
+	now := time.Date(2022, 6, 30, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	newTime := func(t time.Time) *metav1.Time {
+		mt := metav1.NewTime(t)
+		return &mt
+	}
+	tests := []struct {
+		name    string
+		expiry  []time.Duration
+		policy  v1.CertRotationPolicy
+		last    *metav1.Time
+		want    time.Time
+		wantErr bool
+	}{
+		{
+			name:   "renew before the first certificate expires",
+			expiry: []time.Duration{200 * day, 100 * day},
+			policy: v1.CertRotationPolicy{},
+			want:   now.Add(70 * day),
+		},
+		{
+			name:   "renew now",
+			expiry: []time.Duration{10 * day},
+			policy: v1.CertRotationPolicy{RenewBeforeDays: 15},
+			want:   now,
+		},
+		{
+			name:   "in the maintenance window",
+			expiry: []time.Duration{10 * day},
+			policy: v1.CertRotationPolicy{MaintenanceWindow: &v1.MaintenanceWindow{Start: "11:00", End: "13:00"}},
+			want:   now,
+		},
+		{
+			name:   "wait for the window today",
+			expiry: []time.Duration{10 * day},
+			policy: v1.CertRotationPolicy{MaintenanceWindow: &v1.MaintenanceWindow{Start: "22:00", End: "02:00"}},
+			want:   time.Date(2022, 6, 30, 22, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "wait for the window tomorrow",
+			expiry: []time.Duration{10 * day},
+			policy: v1.CertRotationPolicy{MaintenanceWindow: &v1.MaintenanceWindow{Start: "02:00", End: "04:00"}},
+			want:   time.Date(2022, 7, 1, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "window in time zone",
+			expiry: []time.Duration{10 * day},
+			// 12:00 UTC is 20:00 in Shanghai
+			policy: v1.CertRotationPolicy{MaintenanceWindow: &v1.MaintenanceWindow{Start: "21:30", End: "23:00", TimeZone: "Asia/Shanghai"}},
+			want:   time.Date(2022, 6, 30, 13, 30, 0, 0, time.UTC),
+		},
+		{
+			name:   "window after the renew time",
+			expiry: []time.Duration{40 * day},
+			policy: v1.CertRotationPolicy{MaintenanceWindow: &v1.MaintenanceWindow{Start: "01:00", End: "03:00"}},
+			want:   time.Date(2022, 7, 11, 1, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "rotated recently",
+			expiry: []time.Duration{10 * day},
+			policy: v1.CertRotationPolicy{},
+			last:   newTime(now.Add(-time.Hour)),
+			want:   now.Add(23 * time.Hour),
+		},
+		{
+			name:    "invalid window",
+			expiry:  []time.Duration{10 * day},
+			policy:  v1.CertRotationPolicy{MaintenanceWindow: &v1.MaintenanceWindow{Start: "1am", End: "3am"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := tt.policy
+			c := &v1.Cluster{CertRotationPolicy: &policy}
+			for _, e := range tt.expiry {
+				c.Status.Certifications = append(c.Status.Certifications, v1.Certification{ExpirationTime: metav1.NewTime(now.Add(e))})
+			}
+			c.Status.CertRotation.LastRotationTime = tt.last
+			got, err := nextRotationTime(c, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("nextRotationTime() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !got.Equal(tt.want) {
+				t.Errorf("nextRotationTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
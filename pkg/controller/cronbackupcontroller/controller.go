@@ -43,6 +43,7 @@ import (
 	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/controller"
 	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/handler"
 	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/manager"
+	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/reconcile"
 	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/source"
 	"github.com/kubeclipper/kubeclipper/pkg/errors"
 	"github.com/kubeclipper/kubeclipper/pkg/logger"
@@ -143,36 +144,9 @@ func (r *CronBackupReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 				log.Error("Failed to update cronBackup", zap.Error(err))
 			}
 
-			// keep the backup num within the maxNum
-			backupList, err := r.BackupLister.List(labels.Everything())
-			if err != nil {
-				log.Error("Failed to list backups of the cronBackup", zap.Error(err))
+			if err = r.pruneBackups(ctx, log, cronBackup); err != nil {
 				return ctrl.Result{}, err
 			}
-			backupsByCb := GroupBackupsByParent(backupList)
-			if len(backupsByCb[cronBackup.UID]) > cronBackup.Spec.MaxBackupNum {
-				// sort the backups by creation time
-				sort.Slice(backupsByCb[cronBackup.UID], func(i, j int) bool {
-					return backupsByCb[cronBackup.UID][i].CreationTimestamp.Time.Before(backupsByCb[cronBackup.UID][j].CreationTimestamp.Time)
-				})
-				sub := len(backupsByCb[cronBackup.UID]) - cronBackup.Spec.MaxBackupNum
-				for del := 0; del < sub; del++ {
-					if backupsByCb[cronBackup.UID][del].Status.ClusterBackupStatus != v1.ClusterBackupAvailable {
-						continue
-					}
-					err = r.BackupWriter.DeleteBackup(ctx, backupsByCb[cronBackup.UID][del].Name)
-					if err != nil {
-						log.Error("Failed to delete backup", zap.Error(err))
-						return ctrl.Result{}, err
-					}
-					// delivery the create backup operation
-					err = r.deleteBackup(log, backupsByCb[cronBackup.UID][del].Labels[common.LabelClusterName], backupsByCb[cronBackup.UID][del])
-					if err != nil {
-						log.Error("Failed to delivery operation to delete backup", zap.Error(err))
-						return ctrl.Result{}, err
-					}
-				}
-			}
 		}
 		sub := cronBackup.Status.NextScheduleTime.Sub(now.Time)
 		return ctrl.Result{
@@ -183,6 +157,48 @@ func (r *CronBackupReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	return ctrl.Result{}, nil
 }
 
+// pruneBackups deletes the backups of the cronBackup which are expired by the retention policy,
+// or exceed the MaxBackupNum when no retention policy is set.
+func (r *CronBackupReconciler) pruneBackups(ctx context.Context, log logger.Logging, cronBackup *v1.CronBackup) error {
+	backupList, err := r.BackupLister.List(labels.Everything())
+	if err != nil {
+		log.Error("Failed to list backups of the cronBackup", zap.Error(err))
+		return err
+	}
+	backups := GroupBackupsByParent(backupList)[cronBackup.UID]
+
+	var expired []*v1.Backup
+	if cronBackup.Spec.RetentionPolicy != nil {
+		expired = ExpiredBackups(backups, cronBackup.Spec.RetentionPolicy, time.Now())
+	} else if len(backups) > cronBackup.Spec.MaxBackupNum {
+		// keep the backup num within the maxNum
+		sort.Slice(backups, func(i, j int) bool {
+			return backups[i].CreationTimestamp.Time.Before(backups[j].CreationTimestamp.Time)
+		})
+		for _, b := range backups[:len(backups)-cronBackup.Spec.MaxBackupNum] {
+			if b.Status.ClusterBackupStatus == v1.ClusterBackupAvailable {
+				expired = append(expired, b)
+			}
+		}
+	}
+	return r.deleteBackups(ctx, log, expired)
+}
+
+// deleteBackups delivers the delete backup operation for each backup, then deletes the backup.
+func (r *CronBackupReconciler) deleteBackups(ctx context.Context, log logger.Logging, backups []*v1.Backup) error {
+	for _, b := range backups {
+		if err := r.deleteBackup(log, b.Labels[common.LabelClusterName], b); err != nil {
+			log.Error("Failed to delivery operation to delete backup", zap.Error(err))
+			return err
+		}
+		if err := r.BackupWriter.DeleteBackup(ctx, b.Name); err != nil {
+			log.Error("Failed to delete backup", zap.Error(err))
+			return err
+		}
+	}
+	return nil
+}
+
 func (r *CronBackupReconciler) SetupWithManager(mgr manager.Manager, cache informers.InformerCache) error {
 	c, err := controller.NewUnmanaged("cronbackup", controller.Options{
 		MaxConcurrentReconciles: 2,
@@ -197,6 +213,24 @@ func (r *CronBackupReconciler) SetupWithManager(mgr manager.Manager, cache infor
 		return err
 	}
 	mgr.AddRunnable(c)
+
+	// the backups which are not created by a cronBackup are pruned by the retention policy of their backup point
+	rc, err := controller.NewUnmanaged("backupretention", controller.Options{
+		MaxConcurrentReconciles: 1,
+		Reconciler:              reconcile.Func(r.reconcileRetention),
+		Log:                     mgr.GetLogger().WithName("backupretention-controller"),
+		RecoverPanic:            true,
+	})
+	if err != nil {
+		return err
+	}
+	if err = rc.Watch(source.NewKindWithCache(&v1.BackupPoint{}, cache), &handler.EnqueueRequestForObject{}); err != nil {
+		return err
+	}
+	if err = rc.Watch(source.NewKindWithCache(&v1.Backup{}, cache), handler.EnqueueRequestsFromMapFunc(r.findBackupPointForBackup)); err != nil {
+		return err
+	}
+	mgr.AddRunnable(rc)
 	return nil
 }
 
@@ -334,6 +368,18 @@ func (r *CronBackupReconciler) createBackup(log logger.Logging, cronBackup *v1.C
 			BackupFileName:     backup.Status.FileName,
 			BackupPointRootDir: bp.FsConfig.BackupRootDir,
 		}
+	case bs.SFTPStorage, bs.WebDAVStorage:
+		actBackup = &k8s.ActBackup{
+			StoreType:      bp.StorageType,
+			BackupFileName: backup.Status.FileName,
+			SftpConfig:     bp.SftpConfig,
+			WebDAVConfig:   bp.WebDAVConfig,
+		}
+	}
+	actBackup.Metadata = k8s.NewBackupMetadata(backup)
+	if actBackup.Codec, err = k8s.NewBackupCodec(bp, backup); err != nil {
+		log.Error("Failed to init backup codec", zap.Error(err))
+		return err
 	}
 
 	if err = actBackup.InitSteps(ctx); err != nil {
@@ -361,6 +407,20 @@ func (r *CronBackupReconciler) createBackup(log logger.Logging, cronBackup *v1.C
 
 	actBackupStep := actBackup.GetStep(v1.ActionInstall)
 	op.Steps = append(steps, actBackupStep...)
+	if cronBackup.Spec.Verify {
+		// the failed verification does not fail the backup, it is recorded in the backup conditions
+		verify, err := k8s.NewVerifyBackup(bp, backup)
+		if err != nil {
+			log.Error("Failed to init verify steps", zap.Error(err))
+			return err
+		}
+		verifySteps, err := verify.InstallSteps(actBackupStep[0].Nodes[0], true)
+		if err != nil {
+			log.Error("Failed to init verify steps", zap.Error(err))
+			return err
+		}
+		op.Steps = append(op.Steps, verifySteps...)
+	}
 	op, err = r.OperationWriter.CreateOperation(ctx, op)
 	if err != nil {
 		log.Error("Failed to create operation", zap.Error(err))
@@ -424,7 +484,12 @@ func (r *CronBackupReconciler) deleteBackup(log logger.Logging, clusterName stri
 		return err
 	}
 
-	bp, err := r.BackupPointLister.Get(c.Labels[common.LabelBackupPoint])
+	// the backup file is stored in the backup point of the backup, which may not be the current one of the cluster
+	bpName := backup.BackupPointName
+	if bpName == "" {
+		bpName = c.Labels[common.LabelBackupPoint]
+	}
+	bp, err := r.BackupPointLister.Get(bpName)
 	if err != nil {
 		if apimachineryErrors.IsNotFound(err) {
 			log.Error("backupPoint is not found", zap.Error(err))
@@ -491,6 +556,13 @@ func (r *CronBackupReconciler) deleteBackup(log logger.Logging, clusterName stri
 			BackupFileName:     backup.Status.FileName,
 			BackupPointRootDir: bp.FsConfig.BackupRootDir,
 		}
+	case bs.SFTPStorage, bs.WebDAVStorage:
+		actBackup = &k8s.ActBackup{
+			StoreType:      bp.StorageType,
+			BackupFileName: backup.Status.FileName,
+			SftpConfig:     bp.SftpConfig,
+			WebDAVConfig:   bp.WebDAVConfig,
+		}
 	}
 
 	if err = actBackup.InitSteps(ctx); err != nil {
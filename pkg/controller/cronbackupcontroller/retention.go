@@ -0,0 +1,167 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package cronbackupcontroller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+
+	ctrl "github.com/kubeclipper/kubeclipper/pkg/controller-runtime"
+	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/client"
+	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/reconcile"
+	"github.com/kubeclipper/kubeclipper/pkg/errors"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+// period returns the key of the retention period a time belongs to
+type period func(t time.Time) string
+
+var (
+	hourly  period = func(t time.Time) string { return t.Format("2006-01-02T15") }
+	daily   period = func(t time.Time) string { return t.Format("2006-01-02") }
+	monthly period = func(t time.Time) string { return t.Format("2006-01") }
+	weekly  period = func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	}
+)
+
+// ExpiredBackups returns the available backups which are not kept by the retention policy.
+// Backups of each level are kept from newest to oldest, one backup per period,
+// until the number of periods of the level is reached.
+func ExpiredBackups(backups []*v1.Backup, policy *v1.RetentionPolicy, now time.Time) []*v1.Backup {
+	if policy == nil {
+		return nil
+	}
+	available := make([]*v1.Backup, 0, len(backups))
+	for _, b := range backups {
+		if b.Status.ClusterBackupStatus == v1.ClusterBackupAvailable {
+			available = append(available, b)
+		}
+	}
+	sort.SliceStable(available, func(i, j int) bool {
+		return available[i].CreationTimestamp.After(available[j].CreationTimestamp.Time)
+	})
+
+	kept := make(map[string]struct{})
+	levels := []struct {
+		count  int
+		period period
+	}{
+		{policy.Hourly, hourly},
+		{policy.Daily, daily},
+		{policy.Weekly, weekly},
+		{policy.Monthly, monthly},
+	}
+	for _, level := range levels {
+		seen := make(map[string]struct{})
+		for _, b := range available {
+			if len(seen) >= level.count {
+				break
+			}
+			key := level.period(b.CreationTimestamp.Time)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			kept[b.Name] = struct{}{}
+		}
+	}
+
+	var expired []*v1.Backup
+	for _, b := range available {
+		if _, ok := kept[b.Name]; ok {
+			continue
+		}
+		if now.Sub(b.CreationTimestamp.Time) < policy.MinAge.Duration {
+			continue
+		}
+		expired = append(expired, b)
+	}
+	return expired
+}
+
+// interval of evaluating the retention policy of a backup point
+const retentionInterval = time.Hour
+
+// reconcileRetention prunes the backups of a backup point which are not created by a cronBackup,
+// the retention policy is evaluated for each cluster separately.
+func (r *CronBackupReconciler) reconcileRetention(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logger.FromContext(ctx)
+	bp, err := r.BackupPointLister.Get(req.Name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		log.Error("Failed to get backupPoint with name", zap.Error(err))
+		return ctrl.Result{}, err
+	}
+	if bp.RetentionPolicy == nil {
+		return ctrl.Result{}, nil
+	}
+
+	backupList, err := r.BackupLister.List(labels.Everything())
+	if err != nil {
+		log.Error("Failed to list backups of the backupPoint", zap.Error(err))
+		return ctrl.Result{}, err
+	}
+	backupsByCluster := make(map[string][]*v1.Backup)
+	for _, b := range backupList {
+		if b.BackupPointName != bp.Name || metav1.GetControllerOf(b) != nil {
+			continue
+		}
+		clusterName := b.Labels[common.LabelClusterName]
+		backupsByCluster[clusterName] = append(backupsByCluster[clusterName], b)
+	}
+	now := time.Now()
+	for clusterName, backups := range backupsByCluster {
+		if _, err = r.ClusterLister.Get(clusterName); errors.IsNotFound(err) {
+			// the delete backup operation can not be delivered without the cluster
+			continue
+		}
+		if err = r.deleteBackups(ctx, log, ExpiredBackups(backups, bp.RetentionPolicy, now)); err != nil {
+			log.Error("Failed to prune backups of the cluster", zap.String("cluster", clusterName), zap.Error(err))
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{RequeueAfter: retentionInterval}, nil
+}
+
+func (r *CronBackupReconciler) findBackupPointForBackup(obj client.Object) []reconcile.Request {
+	b, ok := obj.(*v1.Backup)
+	if !ok || b.BackupPointName == "" {
+		return []reconcile.Request{}
+	}
+	return []reconcile.Request{
+		{
+			NamespacedName: types.NamespacedName{
+				Name: b.BackupPointName,
+			},
+		},
+	}
+}
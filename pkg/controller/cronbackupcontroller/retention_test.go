@@ -0,0 +1,93 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package cronbackupcontroller
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func newBackup(name string, created time.Time, status v1.ClusterBackupStatus) *v1.Backup {
+	b := &v1.Backup{}
+	b.Name = name
+	b.CreationTimestamp = metav1.NewTime(created)
+	b.Status.ClusterBackupStatus = status
+	return b
+}
+
+func TestExpiredBackups(t *testing.T) {
+	now := time.Date(2022, 3, 15, 12, 30, 0, 0, time.UTC)
+	backups := []*v1.Backup{
+		newBackup("h0", now.Add(-10*time.Minute), v1.ClusterBackupAvailable),
+		newBackup("h0-old", now.Add(-20*time.Minute), v1.ClusterBackupAvailable),
+		newBackup("h1", now.Add(-70*time.Minute), v1.ClusterBackupAvailable),
+		newBackup("d1", now.Add(-24*time.Hour), v1.ClusterBackupAvailable),
+		newBackup("d2", now.Add(-48*time.Hour), v1.ClusterBackupAvailable),
+		newBackup("w1", now.Add(-8*24*time.Hour), v1.ClusterBackupAvailable),
+		newBackup("m1", now.Add(-40*24*time.Hour), v1.ClusterBackupAvailable),
+		newBackup("m1-error", now.Add(-41*24*time.Hour), v1.ClusterBackupError),
+	}
+	tests := []struct {
+		name   string
+		policy *v1.RetentionPolicy
+		want   []string
+	}{
+		{
+			name:   "no policy",
+			policy: nil,
+			want:   nil,
+		},
+		{
+			name:   "hourly",
+			policy: &v1.RetentionPolicy{Hourly: 2},
+			want:   []string{"h0-old", "d1", "d2", "w1", "m1"},
+		},
+		{
+			name:   "daily",
+			policy: &v1.RetentionPolicy{Daily: 2},
+			want:   []string{"h0-old", "h1", "d2", "w1", "m1"},
+		},
+		{
+			name:   "grandfather-father-son",
+			policy: &v1.RetentionPolicy{Daily: 1, Weekly: 2, Monthly: 3},
+			want:   []string{"h0-old", "h1", "d1", "w1"},
+		},
+		{
+			name:   "min age",
+			policy: &v1.RetentionPolicy{Hourly: 1, MinAge: metav1.Duration{Duration: 36 * time.Hour}},
+			want:   []string{"d2", "w1", "m1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, b := range ExpiredBackups(backups, tt.policy, now) {
+				got = append(got, b.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExpiredBackups() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
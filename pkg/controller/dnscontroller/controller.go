@@ -33,6 +33,7 @@ import (
 
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -42,8 +43,10 @@ import (
 	listerv1 "github.com/kubeclipper/kubeclipper/pkg/client/lister/core/v1"
 	ctrl "github.com/kubeclipper/kubeclipper/pkg/controller-runtime"
 	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/controller"
+	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/event"
 	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/handler"
 	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/manager"
+	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/predicate"
 	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/source"
 	"github.com/kubeclipper/kubeclipper/pkg/logger"
 	"github.com/kubeclipper/kubeclipper/pkg/models/cluster"
@@ -51,6 +54,10 @@ import (
 	"github.com/kubeclipper/kubeclipper/pkg/utils/hashutil"
 )
 
+// upstreamRequestNamespace marks the requests enqueued by a cluster whose upstream dns changed,
+// the request name is the cluster name rather than a domain name.
+const upstreamRequestNamespace = "cluster-upstream"
+
 type DNSReconciler struct {
 	DomainLister  listerv1.DomainLister
 	DomainWriter  cluster.DNSWriter
@@ -61,6 +68,10 @@ type DNSReconciler struct {
 func (r *DNSReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := logger.FromContext(ctx)
 
+	if req.Namespace == upstreamRequestNamespace {
+		return ctrl.Result{}, r.syncClusterConfigMap(ctx, log, req.Name)
+	}
+
 	domain, err := r.DomainLister.Get(req.Name)
 	if err != nil {
 		// domain not found, possibly been deleted
@@ -105,6 +116,15 @@ func (r *DNSReconciler) SetupWithManager(mgr manager.Manager, cache informers.In
 	if err = c.Watch(source.NewKindWithCache(&v1.Cluster{}, cache), handler.EnqueueRequestsFromMapFunc(r.findObjectsForCluster)); err != nil {
 		return err
 	}
+	if err = c.Watch(source.NewKindWithCache(&v1.Cluster{}, cache), handler.EnqueueRequestsFromMapFunc(r.findUpstreamForCluster),
+		predicate.Funcs{
+			CreateFunc:  func(event.CreateEvent) bool { return false },
+			DeleteFunc:  func(event.DeleteEvent) bool { return false },
+			GenericFunc: func(event.GenericEvent) bool { return false },
+			UpdateFunc:  upstreamChanged,
+		}); err != nil {
+		return err
+	}
 	r.mgr = mgr
 	mgr.AddRunnable(c)
 	return nil
@@ -147,50 +167,101 @@ func (r *DNSReconciler) syncConfigMap(ctx context.Context, log logger.Logging) e
 		return err
 	}
 	for _, clu := range clusters {
-		cc, ok := r.mgr.GetClusterClientSet(clu.Name)
-		if !ok {
-			return fmt.Errorf("get cluster client failed")
-		}
-		cli := cc.Kubernetes()
-		svc, err := cli.CoreV1().Services("kube-system").Get(context.TODO(), "kube-dns", metav1.GetOptions{})
-		if err != nil {
-			return fmt.Errorf("get coredns svc failed: %s", err.Error())
-		}
-		domains := m[clu.Name]
-		corefile, err := renderCorefile(domains, svc.Spec.ClusterIP, clu.Networking.DNSDomain)
-		if err != nil {
-			return fmt.Errorf("failed to render corefile: %s", err.Error())
+		if err = r.syncCorefile(ctx, log, clu, m[clu.Name]); err != nil {
+			return err
 		}
-		log.Debugf("cluster name:%s new corefile:%v", clu.Name, corefile)
+	}
+	return nil
+}
 
-		configmap, err := cli.CoreV1().ConfigMaps("kube-system").Get(context.TODO(), "coredns", metav1.GetOptions{})
-		if err != nil {
-			return fmt.Errorf("get coredns configmap failed: %s", err.Error())
+// syncClusterConfigMap syncs the coredns configmap of a single cluster.
+func (r *DNSReconciler) syncClusterConfigMap(ctx context.Context, log logger.Logging, name string) error {
+	clu, err := r.ClusterLister.Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
 		}
+		log.Error("failed to get cluster", zap.String("cluster", name), zap.Error(err))
+		return err
+	}
+	originDomains, err := r.DomainLister.List(labels.Everything())
+	if err != nil {
+		log.Error("failed to list domains", zap.Error(err))
+		return err
+	}
+	return r.syncCorefile(ctx, log, clu, buildDomain(originDomains)[clu.Name])
+}
 
-		if equal(configmap.Data["Corefile"], corefile) {
-			log.Debugf("cluster name:%s corefile not changed,skip update", clu.Name)
-			continue
-		}
-		configmap.Data["Corefile"] = corefile
-		_, err = cli.CoreV1().ConfigMaps("kube-system").Update(context.TODO(), configmap, metav1.UpdateOptions{})
-		if err != nil {
-			return fmt.Errorf("update coredns configmap failed: %s", err.Error())
-		}
+func (r *DNSReconciler) syncCorefile(ctx context.Context, log logger.Logging, clu *v1.Cluster, domains []*v1.Domain) error {
+	cc, ok := r.mgr.GetClusterClientSet(clu.Name)
+	if !ok {
+		return fmt.Errorf("get cluster client failed")
+	}
+	cli := cc.Kubernetes()
+	svc, err := cli.CoreV1().Services("kube-system").Get(ctx, "kube-dns", metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("get coredns svc failed: %s", err.Error())
+	}
+	corefile, err := renderCorefile(domains, svc.Spec.ClusterIP, clu.Networking.DNSDomain, clu.Networking.UpstreamDNS)
+	if err != nil {
+		return fmt.Errorf("failed to render corefile: %s", err.Error())
+	}
+	log.Debugf("cluster name:%s new corefile:%v", clu.Name, corefile)
 
-		addonPort := addonPorts(svc.Spec.Ports)
-		if len(addonPort) != len(svc.Spec.Ports) {
-			svc.Spec.Ports = addonPort
-			log.Debugf("update coredns svc cluster:%s svc ports:%+v", clu.Name, svc.Spec.Ports)
-			_, err = cli.CoreV1().Services("kube-system").Update(context.TODO(), svc, metav1.UpdateOptions{})
-			if err != nil {
-				return fmt.Errorf("update coredns svc failed: %s", err.Error())
-			}
+	configmap, err := cli.CoreV1().ConfigMaps("kube-system").Get(ctx, "coredns", metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("get coredns configmap failed: %s", err.Error())
+	}
+
+	if equal(configmap.Data["Corefile"], corefile) {
+		log.Debugf("cluster name:%s corefile not changed,skip update", clu.Name)
+		return nil
+	}
+	configmap.Data["Corefile"] = corefile
+	_, err = cli.CoreV1().ConfigMaps("kube-system").Update(ctx, configmap, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("update coredns configmap failed: %s", err.Error())
+	}
+
+	addonPort := addonPorts(svc.Spec.Ports)
+	if len(addonPort) != len(svc.Spec.Ports) {
+		svc.Spec.Ports = addonPort
+		log.Debugf("update coredns svc cluster:%s svc ports:%+v", clu.Name, svc.Spec.Ports)
+		_, err = cli.CoreV1().Services("kube-system").Update(ctx, svc, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("update coredns svc failed: %s", err.Error())
 		}
 	}
 	return nil
 }
 
+// upstreamChanged filters the cluster updates which need the corefile re-rendered:
+// the upstream dns was modified, or the cluster just became running.
+func upstreamChanged(e event.UpdateEvent) bool {
+	oldClu, ok := e.ObjectOld.(*v1.Cluster)
+	if !ok {
+		return false
+	}
+	newClu, ok := e.ObjectNew.(*v1.Cluster)
+	if !ok || !newClu.DeletionTimestamp.IsZero() {
+		return false
+	}
+	if !apiequality.Semantic.DeepEqual(oldClu.Networking.UpstreamDNS, newClu.Networking.UpstreamDNS) {
+		return true
+	}
+	return newClu.Networking.UpstreamDNS != nil &&
+		oldClu.Status.Phase != v1.ClusterRunning && newClu.Status.Phase == v1.ClusterRunning
+}
+
+func (r *DNSReconciler) findUpstreamForCluster(clu client.Object) []reconcile.Request {
+	return []reconcile.Request{{
+		NamespacedName: types.NamespacedName{
+			Namespace: upstreamRequestNamespace,
+			Name:      clu.GetName(),
+		},
+	}}
+}
+
 func (r *DNSReconciler) findObjectsForCluster(clu client.Object) []reconcile.Request {
 	if clu.GetDeletionTimestamp() == nil || clu.GetDeletionTimestamp().IsZero() {
 		return []reconcile.Request{}
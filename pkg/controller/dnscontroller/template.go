@@ -20,6 +20,8 @@ package dnscontroller
 
 import (
 	"bytes"
+	"fmt"
+	"regexp"
 	"sort"
 	"strings"
 
@@ -39,92 +41,140 @@ const corednsCorefile = `.:53 {
 	   ttl 30
 	}
 	prometheus :9153
-	forward . /etc/resolv.conf
+	{{- template "forward" .Upstream}}
 	cache 10
 	loop
 	reload 10s
 	loadbalance
 }
+{{- define "forward"}}
+	forward . {{ join " " .To }}
+	{{- if or .Policy .TLSServerName}} {
+	    {{- if .Policy}}
+	    policy {{.Policy}}
+	    {{- end}}
+	    {{- if .TLSServerName}}
+	    tls_servername {{.TLSServerName}}
+	    {{- end}}
+	}
+	{{- end}}
+{{- end}}
+{{- /* names without record are forwarded if the domain has forwarders */}}
+{{- define "fallback"}}
+    {{- if .Domain.Forward}}
+    {{- template "forward" .Domain.Forward}}
+    {{- else}}
+    {{- template "templates" dict "Name" .Domain.Name "Templates" .NXDomain}}
+    {{- end}}
+{{- end}}
+{{- define "templates"}}
+    {{- range $t := .Templates}}
+    template IN {{$t.Type}} {{$.Name}} {
+        match "{{$t.Match}}"
+        {{- range $answer := $t.Answers}}
+        answer "{{$answer}}"
+        {{- end}}
+        {{- if $t.Rcode}}
+        rcode {{$t.Rcode}}
+        {{- end}}
+        {{- if $t.Upstream}}
+        upstream
+        {{- end}}
+        fallthrough
+    }
+    {{- end}}
+{{- end}}
 {{- range $domain := .Domains}}
-    {{- $n := len $domain.A}}
-    {{- if gt $n 0}}
-{{/* hosts use 53 port with default*/}}
+{{/* exact records use 53 port with default*/}}
 {{$domain.Name}}:53 {
     errors
-    cache 10
 	loadbalance
-    {{- $n := len $domain.A}}
-    {{- if gt $n 0}}
-    hosts {
-        {{- range $record := $domain.A}}
-        {{$record.IP}} {{$record.RR}}
-        {{- end}}
-        fallthrough
-    }
-	{{- /* forward to 5300 resolved by template */}}
+    {{- /* no cache, it would cap the ttl of the records */}}
+    {{- template "templates" dict "Name" $domain.Name "Templates" $domain.Records}}
+    {{- if $domain.Extensive}}
+	{{- /* forward to 5300 resolved by wildcard template */}}
 	forward . {{ $.CoreDNSVIP }}:5300
+    {{- else}}
+    {{- template "fallback" dict "Domain" $domain "NXDomain" $.NXDomain}}
     {{- end}}
 }
-{{- end}}
-{{- $n := len $domain.Extensive}}
-    {{- if gt $n 0}}
+    {{- if $domain.Extensive}}
 {{$domain.Name}}:5300 {
     errors
-    cache 10
 	loadbalance
- {{- range $record := $domain.Extensive}}
-    template IN A {{$record.Name}} {
-        match .{{ $record.RR | replace "." "\\." }}
-        {{- range $RIP := $record.IPs}}
-        answer "{{ "e3sgLk5hbWUgfX0=" | b64dec }} 60 IN A {{$RIP}}"
-        {{- end}}
-        fallthrough
-    }
-    {{- end}}
+    {{- template "templates" dict "Name" $domain.Name "Templates" $domain.Extensive}}
+    {{- template "fallback" dict "Domain" $domain "NXDomain" $.NXDomain}}
 }
     {{- end}}
 {{- end}}
 `
 
+// nameAnswer is rendered by the coredns template plugin as the query name.
+const nameAnswer = "{{ .Name }}"
+
 type DNS struct {
-	Domains    []Domain `json:"domains"`
-	DNSDomain  string   `json:"dnsDomain"`
-	CoreDNSVIP string   `json:"coreDNSVIP"` // coredns service ip
+	Domains    []Domain   `json:"domains"`
+	DNSDomain  string     `json:"dnsDomain"`
+	CoreDNSVIP string     `json:"coreDNSVIP"` // coredns service ip
+	Upstream   Forward    `json:"upstream"`   // upstream of the root zone
+	NXDomain   []Template `json:"nxDomain"`   // answers the names without any record
 }
 
 type Domain struct {
-	Name      string            `json:"name"`
-	A         []Record          `json:"a"`
-	Extensive []ExtensiveRecord `json:"extensive"`
+	Name      string     `json:"name"`
+	Records   []Template `json:"records"`   // exact records, served on port 53
+	Extensive []Template `json:"extensive"` // wildcard records, served on port 5300
+	Forward   *Forward   `json:"forward"`   // resolves the names without any record
 }
 
-type ExtensiveRecord struct {
-	ExRecord `json:",inline"`
-	Name     string `json:"name"`
+// Forward is a coredns forward plugin.
+type Forward struct {
+	To            []string `json:"to"`
+	Policy        string   `json:"policy"`
+	TLSServerName string   `json:"tlsServerName"`
 }
 
-type Record struct {
-	RR string `json:"rr"`
-	IP string `json:"ip"`
-}
+var defaultUpstream = Forward{To: []string{"/etc/resolv.conf"}}
 
-type ExRecord struct {
-	RR  string   `json:"rr"`
-	IPs []string `json:"ip"`
+func newForward(f *v1.Forwarders) *Forward {
+	if f == nil || len(f.Upstreams) == 0 {
+		return nil
+	}
+	forward := &Forward{
+		To:            make([]string, 0, len(f.Upstreams)),
+		Policy:        f.Policy,
+		TLSServerName: f.TLSServerName,
+	}
+	for _, upstream := range f.Upstreams {
+		if f.TLSServerName != "" {
+			upstream = "tls://" + upstream
+		}
+		forward.To = append(forward.To, upstream)
+	}
+	return forward
 }
 
-type exList []ExtensiveRecord
-
-func (l exList) Len() int      { return len(l) }
-func (l exList) Swap(i, j int) { l[i], l[j] = l[j], l[i] }
-func (l exList) Less(i, j int) bool {
-	return len(l[i].RR) > len(l[j].RR)
+// Template is a block of the coredns template plugin answering one query type.
+// Templates are evaluated in order, the first one matching the query wins.
+type Template struct {
+	Type     string   `json:"type"`
+	Match    string   `json:"match"`
+	Answers  []string `json:"answers"`
+	Rcode    string   `json:"rcode"`
+	Upstream bool     `json:"upstream"` // resolve the CNAME target
 }
 
-func renderCorefile(data []*v1.Domain, vip, dnsDomain string) (string, error) {
+var nxDomain = []Template{{Type: "ANY", Match: ".*", Rcode: "NXDOMAIN"}}
+
+func renderCorefile(data []*v1.Domain, vip, dnsDomain string, upstream *v1.Forwarders) (string, error) {
 	dns := transformer(data)
 	dns.DNSDomain = dnsDomain
 	dns.CoreDNSVIP = vip
+	dns.NXDomain = nxDomain
+	dns.Upstream = defaultUpstream
+	if f := newForward(upstream); f != nil {
+		dns.Upstream = *f
+	}
 	buffer := bytes.NewBuffer(nil)
 	at := tmplutil.New()
 	_, err := at.RenderTo(buffer, corednsCorefile, dns)
@@ -134,43 +184,56 @@ func renderCorefile(data []*v1.Domain, vip, dnsDomain string) (string, error) {
 	return buffer.String(), nil
 }
 
+type namedRecord struct {
+	key    string
+	record v1.Record
+}
+
+// byPriority puts the longer names first, so a more specific wildcard
+// takes precedence over a shorter one.
+type byPriority []namedRecord
+
+func (l byPriority) Len() int      { return len(l) }
+func (l byPriority) Swap(i, j int) { l[i], l[j] = l[j], l[i] }
+func (l byPriority) Less(i, j int) bool {
+	if len(l[i].key) != len(l[j].key) {
+		return len(l[i].key) > len(l[j].key)
+	}
+	return l[i].key < l[j].key
+}
+
 func transformer(data []*v1.Domain) DNS {
 	var (
 		domainList = make([]Domain, 0, len(data))
 	)
 	for _, domain := range data {
-		a := make([]Record, 0)
-		ex := make([]ExtensiveRecord, 0)
+		if len(domain.Spec.Records) == 0 && domain.Spec.Forwarders == nil {
+			continue
+		}
+		var exact, wildcard []namedRecord
 		for _, record := range domain.Spec.Records {
 			key := record.RR + "." + record.Domain
 			if record.RR == "@" { // @ is special record
 				key = record.Domain
 			}
+			key = strings.ToLower(key)
 			if isGenericRecord(record.RR) {
-				item := ExtensiveRecord{
-					ExRecord: ExRecord{
-						RR: key,
-					},
-					Name: record.Domain,
-				}
-				for _, parseRecord := range record.ParseRecord {
-					item.IPs = append(item.IPs, parseRecord.IP)
-				}
-				ex = append(ex, item)
+				wildcard = append(wildcard, namedRecord{key: key, record: record})
 			} else {
-				for _, parseRecord := range record.ParseRecord {
-					a = append(a, Record{
-						RR: key,
-						IP: parseRecord.IP,
-					})
-				}
+				exact = append(exact, namedRecord{key: key, record: record})
 			}
 		}
-		sort.Sort(exList(ex)) // sort by length of RR to change record priority
+		sort.Sort(byPriority(exact)) // exact names do not overlap, sort keeps the output stable
+		sort.Sort(byPriority(wildcard))
 		d := Domain{
-			Name:      domain.Name,
-			A:         a,
-			Extensive: ex,
+			Name:    domain.Name,
+			Forward: newForward(domain.Spec.Forwarders),
+		}
+		for _, r := range exact {
+			d.Records = append(d.Records, templates(exactMatch(r.key), r.record)...)
+		}
+		for _, r := range wildcard {
+			d.Extensive = append(d.Extensive, templates(wildcardMatch(r.key), r.record)...)
 		}
 		domainList = append(domainList, d)
 	}
@@ -179,6 +242,72 @@ func transformer(data []*v1.Domain) DNS {
 	}
 }
 
+// templates returns the template blocks answering the record, one per type,
+// followed by an empty answer for the other types of the name.
+func templates(match string, record v1.Record) []Template {
+	ttl := record.TTLSeconds()
+	byType := make(map[string]*Template)
+	var types []string
+	for _, p := range record.ParseRecord {
+		typ := p.RecordType()
+		t, ok := byType[typ]
+		if !ok {
+			t = &Template{Type: typ, Match: match}
+			if typ == v1.RecordTypeCNAME {
+				// answer every query type of the name with the alias
+				t.Type = "ANY"
+				t.Upstream = true
+			}
+			byType[typ] = t
+			types = append(types, typ)
+		}
+		t.Answers = append(t.Answers, answer(ttl, p))
+	}
+	sort.Strings(types)
+	list := make([]Template, 0, len(types)+1)
+	for _, typ := range types {
+		list = append(list, *byType[typ])
+	}
+	if _, ok := byType[v1.RecordTypeCNAME]; !ok {
+		list = append(list, Template{Type: "ANY", Match: match, Rcode: "NOERROR"})
+	}
+	return list
+}
+
+// templateEscaper escapes the delimiters of the Go template, coredns parses the answer as a template.
+var templateEscaper = strings.NewReplacer("{{", "{{`{{`}}", "}}", "{{`}}`}}")
+
+func answer(ttl int32, p v1.ParseRecord) string {
+	typ := p.RecordType()
+	switch typ {
+	case v1.RecordTypeCNAME:
+		return fmt.Sprintf("%s %d IN %s %s", nameAnswer, ttl, typ, fqdn(p.Target))
+	case v1.RecordTypeSRV:
+		return fmt.Sprintf("%s %d IN %s %d %d %d %s", nameAnswer, ttl, typ, p.Priority, p.Weight, p.Port, fqdn(p.Target))
+	case v1.RecordTypeTXT:
+		return fmt.Sprintf(`%s %d IN %s \"%s\"`, nameAnswer, ttl, typ, templateEscaper.Replace(p.Text))
+	default:
+		return fmt.Sprintf("%s %d IN %s %s", nameAnswer, ttl, typ, p.IP)
+	}
+}
+
+func fqdn(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
+
+// exactMatch returns the regex matching the name only.
+func exactMatch(name string) string {
+	return "^" + regexp.QuoteMeta(name) + `\.$`
+}
+
+// wildcardMatch returns the regex matching any name below the wildcard parent.
+func wildcardMatch(name string) string {
+	return "^.*" + regexp.QuoteMeta(strings.TrimPrefix(name, "*")) + `\.$`
+}
+
 // 判断是否为泛解析 *.x 或者 * 格式
 func isGenericRecord(rr string) bool {
 	if rr == "*" {
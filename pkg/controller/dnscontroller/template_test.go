@@ -0,0 +1,205 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package dnscontroller
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+var update = flag.Bool("update", false, "update the golden files of the Corefile")
+
+func newDomain(name string, records ...v1.Record) *v1.Domain {
+	d := &v1.Domain{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       v1.DomainSpec{Records: make(map[string]v1.Record)},
+	}
+	for _, r := range records {
+		r.Domain = name
+		d.Spec.Records[r.RR+"."+name] = r
+	}
+	return d
+}
+
+func TestRenderCorefile(t *testing.T) {
+	tests := []struct {
+		name     string
+		domains  []*v1.Domain
+		upstream *v1.Forwarders
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "address",
+			domains: []*v1.Domain{
+				newDomain("example.com",
+					v1.Record{RR: "@", ParseRecord: []v1.ParseRecord{{Type: "A", IP: "10.0.0.1"}}},
+					v1.Record{RR: "www", ParseRecord: []v1.ParseRecord{
+						{Type: "A", IP: "10.0.0.2"},
+						{Type: "A", IP: "10.0.0.3"},
+						{Type: "AAAA", IP: "fd00::2"},
+					}},
+					// stored before type was required, the family decides
+					v1.Record{RR: "v6", ParseRecord: []v1.ParseRecord{{IP: "fd00::6"}}},
+				),
+			},
+		},
+		{
+			name: "wildcard",
+			domains: []*v1.Domain{
+				newDomain("example.com",
+					v1.Record{RR: "www", ParseRecord: []v1.ParseRecord{{Type: "A", IP: "10.0.0.2"}}},
+					v1.Record{RR: "*", ParseRecord: []v1.ParseRecord{{Type: "A", IP: "10.0.1.1"}}},
+					v1.Record{RR: "*.dev", TTL: 30, ParseRecord: []v1.ParseRecord{
+						{Type: "A", IP: "10.0.2.1"},
+						{Type: "AAAA", IP: "fd00::21"},
+					}},
+				),
+				newDomain("wildcard.io",
+					v1.Record{RR: "*", ParseRecord: []v1.ParseRecord{{Type: "AAAA", IP: "fd00::1"}}},
+				),
+			},
+		},
+		{
+			name: "types",
+			domains: []*v1.Domain{
+				newDomain("example.com",
+					v1.Record{RR: "docs", TTL: 600, ParseRecord: []v1.ParseRecord{{Type: "CNAME", Target: "example.github.io"}}},
+					v1.Record{RR: "_ldap._tcp", TTL: 3600, ParseRecord: []v1.ParseRecord{
+						{Type: "SRV", Priority: 10, Weight: 60, Port: 389, Target: "ldap1.example.com."},
+						{Type: "SRV", Priority: 10, Weight: 40, Port: 389, Target: "ldap2.example.com."},
+					}},
+					v1.Record{RR: "@", ParseRecord: []v1.ParseRecord{
+						{Type: "TXT", Text: "v=spf1 -all"},
+						{Type: "A", IP: "10.0.0.1"},
+					}},
+				),
+			},
+		},
+		{
+			name: "txt",
+			domains: []*v1.Domain{
+				newDomain("example.com",
+					v1.Record{RR: "token", ParseRecord: []v1.ParseRecord{
+						{Type: "TXT", Text: "token={{ .Name }}"},
+						{Type: "TXT", Text: "}}{{"},
+					}},
+				),
+			},
+		},
+		{
+			name: "forwarders",
+			domains: []*v1.Domain{
+				func() *v1.Domain {
+					d := newDomain("corp.example")
+					d.Spec.Forwarders = &v1.Forwarders{Upstreams: []string{"10.1.0.53", "10.1.0.54:5353"}, Policy: v1.ForwardPolicySequential}
+					return d
+				}(),
+				func() *v1.Domain {
+					d := newDomain("example.com",
+						v1.Record{RR: "www", ParseRecord: []v1.ParseRecord{{Type: "A", IP: "10.0.0.2"}}},
+					)
+					d.Spec.Forwarders = &v1.Forwarders{Upstreams: []string{"10.2.0.53"}}
+					return d
+				}(),
+				func() *v1.Domain {
+					d := newDomain("wildcard.io",
+						v1.Record{RR: "*.dev", ParseRecord: []v1.ParseRecord{{Type: "A", IP: "10.0.2.1"}}},
+					)
+					d.Spec.Forwarders = &v1.Forwarders{Upstreams: []string{"10.3.0.53"}}
+					return d
+				}(),
+			},
+		},
+		{
+			name: "upstream",
+			upstream: &v1.Forwarders{
+				Upstreams:     []string{"1.1.1.1", "1.0.0.1"},
+				Policy:        v1.ForwardPolicyRoundRobin,
+				TLSServerName: "cloudflare-dns.com",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderCorefile(tt.domains, "10.96.0.10", "cluster.local", tt.upstream)
+			if err != nil {
+				t.Fatal(err)
+			}
+			golden := filepath.Join("testdata", tt.name+".golden")
+			if *update {
+				if err = os.WriteFile(golden, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != string(want) {
+				t.Errorf("renderCorefile() mismatch %s, got:\n%s", golden, got)
+			}
+		})
+	}
+}
+
+func Test_answer(t *testing.T) {
+	tests := []struct {
+		name   string
+		record v1.ParseRecord
+		want   string
+	}{
+		{
+			name:   "txt",
+			record: v1.ParseRecord{Type: "TXT", Text: "v=spf1 -all"},
+			want:   `token.example.com. 60 IN TXT "v=spf1 -all"`,
+		},
+		{
+			name:   "txt with template delimiters",
+			record: v1.ParseRecord{Type: "TXT", Text: "token={{ .Name }}}}{{"},
+			want:   `token.example.com. 60 IN TXT "token={{ .Name }}}}{{"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// coredns unquotes the answer and executes it as a template
+			tmpl, err := template.New("answer").Parse(strings.ReplaceAll(answer(60, tt.record), `\"`, `"`))
+			if err != nil {
+				t.Fatalf("parse answer error = %v", err)
+			}
+			var got bytes.Buffer
+			if err = tmpl.Execute(&got, struct{ Name string }{Name: "token.example.com."}); err != nil {
+				t.Fatalf("execute answer error = %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("answer() = %s, want %s", got.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package etcdmaintenancecontroller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/client/informers"
+	listerv1 "github.com/kubeclipper/kubeclipper/pkg/client/lister/core/v1"
+	ctrl "github.com/kubeclipper/kubeclipper/pkg/controller-runtime"
+	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/controller"
+	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/handler"
+	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/manager"
+	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/source"
+	"github.com/kubeclipper/kubeclipper/pkg/errors"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/models/cluster"
+	"github.com/kubeclipper/kubeclipper/pkg/models/operation"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1/k8s"
+	"github.com/kubeclipper/kubeclipper/pkg/service"
+)
+
+// retry interval when the cluster is busy at the scheduled time
+const busyRequeueInterval = time.Minute
+
+// EtcdMaintenanceReconciler delivers the etcd maintenance operation of the clusters on their schedule.
+type EtcdMaintenanceReconciler struct {
+	ClusterLister   listerv1.ClusterLister
+	NodeLister      listerv1.NodeLister
+	ClusterWriter   cluster.ClusterWriter
+	OperationWriter operation.Writer
+	CmdDelivery     service.CmdDelivery
+}
+
+func (r *EtcdMaintenanceReconciler) SetupWithManager(mgr manager.Manager, cache informers.InformerCache) error {
+	c, err := controller.NewUnmanaged("etcdmaintenance", controller.Options{
+		MaxConcurrentReconciles: 2,
+		Reconciler:              r,
+		Log:                     mgr.GetLogger().WithName("etcdmaintenance-controller"),
+		RecoverPanic:            true,
+	})
+	if err != nil {
+		return err
+	}
+	if err = c.Watch(source.NewKindWithCache(&v1.Cluster{}, cache), &handler.EnqueueRequestForObject{}); err != nil {
+		return err
+	}
+	mgr.AddRunnable(c)
+	return nil
+}
+
+func (r *EtcdMaintenanceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logger.FromContext(ctx)
+	c, err := r.ClusterLister.Get(req.Name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		log.Error("Failed to get cluster with name", zap.Error(err))
+		return ctrl.Result{}, err
+	}
+	if c.Etcd.Maintenance == nil || c.Etcd.Maintenance.Schedule == "" {
+		if c.Status.Etcd.NextMaintenanceTime == nil {
+			return ctrl.Result{}, nil
+		}
+		// the schedule is removed
+		c = c.DeepCopy()
+		c.Status.Etcd.NextMaintenanceTime = nil
+		_, err = r.ClusterWriter.UpdateCluster(ctx, c)
+		return ctrl.Result{}, err
+	}
+	s, err := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow).Parse(c.Etcd.Maintenance.Schedule)
+	if err != nil {
+		log.Error("invalid etcd maintenance schedule", zap.String("schedule", c.Etcd.Maintenance.Schedule), zap.Error(err))
+		return ctrl.Result{}, nil
+	}
+
+	now := metav1.Now()
+	next := c.Status.Etcd.NextMaintenanceTime
+	if next == nil {
+		c = c.DeepCopy()
+		nextRunAt := metav1.NewTime(s.Next(now.Time))
+		c.Status.Etcd.NextMaintenanceTime = &nextRunAt
+		if _, err = r.ClusterWriter.UpdateCluster(ctx, c); err != nil {
+			log.Error("Failed to update cluster", zap.Error(err))
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: nextRunAt.Sub(now.Time)}, nil
+	}
+	if now.Before(next) {
+		return ctrl.Result{RequeueAfter: next.Sub(now.Time)}, nil
+	}
+	if c.Status.Phase != v1.ClusterRunning {
+		log.Warnf("the cluster is %v, run etcd maintenance later", c.Status.Phase)
+		return ctrl.Result{RequeueAfter: busyRequeueInterval}, nil
+	}
+
+	c = c.DeepCopy()
+	op, err := r.makeOperation(c)
+	if err != nil {
+		log.Error("Failed to make etcd maintenance operation", zap.Error(err))
+		return ctrl.Result{}, err
+	}
+	nextRunAt := metav1.NewTime(s.Next(now.Time))
+	c.Status.Phase = v1.ClusterUpdating
+	c.Status.Etcd.LastMaintenanceTime = &now
+	c.Status.Etcd.NextMaintenanceTime = &nextRunAt
+	if op, err = r.OperationWriter.CreateOperation(ctx, op); err != nil {
+		log.Error("Failed to create operation", zap.Error(err))
+		return ctrl.Result{}, err
+	}
+	if _, err = r.ClusterWriter.UpdateCluster(ctx, c); err != nil {
+		log.Error("Failed to update cluster", zap.Error(err))
+		return ctrl.Result{}, err
+	}
+	go func() {
+		if err := r.CmdDelivery.DeliverTaskOperation(context.TODO(), op, &service.Options{DryRun: false}); err != nil {
+			log.Error("Failed to delivery operation", zap.Error(err))
+		}
+	}()
+	return ctrl.Result{RequeueAfter: nextRunAt.Sub(now.Time)}, nil
+}
+
+func (r *EtcdMaintenanceReconciler) makeOperation(c *v1.Cluster) (*v1.Operation, error) {
+	if c.Provider.Name != "" && c.Provider.Name != v1.ClusterKubeadm {
+		return nil, fmt.Errorf("etcd maintenance is not supported by %s cluster", c.Provider.Name)
+	}
+	var masters []v1.StepNode
+	for _, m := range c.Masters {
+		n, err := r.NodeLister.Get(m.ID)
+		if err != nil {
+			return nil, err
+		}
+		masters = append(masters, v1.StepNode{
+			ID:       n.Name,
+			IPv4:     n.Status.Ipv4DefaultIP,
+			Hostname: n.Labels[common.LabelHostname],
+		})
+	}
+	steps, err := (&k8s.EtcdMaintenance{}).InstallSteps(masters)
+	if err != nil {
+		return nil, err
+	}
+	op := &v1.Operation{Steps: steps}
+	op.Name = uuid.New().String()
+	op.Labels = map[string]string{
+		common.LabelClusterName:     c.Name,
+		common.LabelTimeoutSeconds:  v1.DefaultOperationTimeoutSecs,
+		common.LabelOperationAction: v1.OperationEtcdMaintenance,
+		common.LabelTopologyRegion:  c.Masters[0].Labels[common.LabelTopologyRegion],
+	}
+	op.Status.Status = v1.OperationStatusRunning
+	return op, nil
+}
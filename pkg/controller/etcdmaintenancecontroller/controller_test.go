@@ -0,0 +1,247 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package etcdmaintenancecontroller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+
+	listerv1 "github.com/kubeclipper/kubeclipper/pkg/client/lister/core/v1"
+	ctrl "github.com/kubeclipper/kubeclipper/pkg/controller-runtime"
+	mock_cluster "github.com/kubeclipper/kubeclipper/pkg/models/cluster/mock"
+	mock_operation "github.com/kubeclipper/kubeclipper/pkg/models/operation/mock"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/service"
+)
+
+// fakeDelivery sends the delivered operations to the channel.
+type fakeDelivery struct {
+	service.CmdDelivery
+	delivered chan *v1.Operation
+}
+
+func (f *fakeDelivery) DeliverTaskOperation(ctx context.Context, op *v1.Operation, opts *service.Options) error {
+	f.delivered <- op
+	return nil
+}
+
+func TestEtcdMaintenanceReconciler_Reconcile(t *testing.T) {
+	now := time.Now()
+	newTime := func(d time.Duration) *metav1.Time {
+		mt := metav1.NewTime(now.Add(d))
+		return &mt
+	}
+	schedule := &v1.EtcdMaintenance{Schedule: "0 3 * * *"}
+	tests := []struct {
+		name          string
+		maintenance   *v1.EtcdMaintenance
+		next          *metav1.Time
+		phase         v1.ClusterPhase
+		provider      string
+		wantUpdate    bool
+		wantNext      bool
+		wantOperation bool
+		wantRequeue   time.Duration
+		wantErr       bool
+	}{
+		{
+			name: "no schedule",
+		},
+		{
+			name:       "schedule removed",
+			next:       newTime(time.Hour),
+			wantUpdate: true,
+		},
+		{
+			name:        "schedule the next maintenance",
+			maintenance: schedule,
+			wantUpdate:  true,
+			wantNext:    true,
+			wantRequeue: -1,
+		},
+		{
+			name:        "not due",
+			maintenance: schedule,
+			next:        newTime(time.Hour),
+			wantRequeue: time.Hour,
+		},
+		{
+			name:        "due but cluster is busy",
+			maintenance: schedule,
+			next:        newTime(-time.Minute),
+			phase:       v1.ClusterUpdating,
+			wantRequeue: busyRequeueInterval,
+		},
+		{
+			name:          "due",
+			maintenance:   schedule,
+			next:          newTime(-time.Minute),
+			wantUpdate:    true,
+			wantNext:      true,
+			wantOperation: true,
+			wantRequeue:   -1,
+		},
+		{
+			name:        "due for unsupported provider",
+			maintenance: schedule,
+			next:        newTime(-time.Minute),
+			provider:    "k3s",
+			wantErr:     true,
+		},
+		{
+			name:        "invalid schedule",
+			maintenance: &v1.EtcdMaintenance{Schedule: "every day"},
+			next:        newTime(-time.Minute),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			c := &v1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster1"},
+				Masters: v1.WorkerNodeList{
+					{ID: "node1", Labels: map[string]string{common.LabelTopologyRegion: "default"}},
+					{ID: "node2"},
+				},
+			}
+			c.Provider.Name = tt.provider
+			c.Etcd.Maintenance = tt.maintenance
+			c.Status.Phase = v1.ClusterRunning
+			if tt.phase != "" {
+				c.Status.Phase = tt.phase
+			}
+			c.Status.Etcd.NextMaintenanceTime = tt.next
+			clusterIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			nodeIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			if err := clusterIndexer.Add(c); err != nil {
+				t.Fatal(err)
+			}
+			for i, name := range []string{"node1", "node2"} {
+				n := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{common.LabelHostname: "master" + name[4:]}}}
+				n.Status.Ipv4DefaultIP = []string{"10.0.0.1", "10.0.0.2"}[i]
+				if err := nodeIndexer.Add(n); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			clusterWriter := mock_cluster.NewMockClusterWriter(mockCtrl)
+			operationWriter := mock_operation.NewMockWriter(mockCtrl)
+			var (
+				updated *v1.Cluster
+				created *v1.Operation
+			)
+			if tt.wantUpdate {
+				clusterWriter.EXPECT().UpdateCluster(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, c *v1.Cluster) (*v1.Cluster, error) {
+						updated = c
+						return c, nil
+					})
+			}
+			if tt.wantOperation {
+				operationWriter.EXPECT().CreateOperation(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, op *v1.Operation) (*v1.Operation, error) {
+						created = op
+						return op, nil
+					})
+			}
+			delivery := &fakeDelivery{delivered: make(chan *v1.Operation, 1)}
+			r := &EtcdMaintenanceReconciler{
+				ClusterLister:   listerv1.NewClusterLister(clusterIndexer),
+				NodeLister:      listerv1.NewNodeLister(nodeIndexer),
+				ClusterWriter:   clusterWriter,
+				OperationWriter: operationWriter,
+				CmdDelivery:     delivery,
+			}
+
+			got, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "cluster1"}})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Reconcile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			switch {
+			case tt.wantRequeue < 0:
+				if got.RequeueAfter <= 0 {
+					t.Errorf("Reconcile() requeue after %v, want the next maintenance", got.RequeueAfter)
+				}
+			case tt.wantRequeue == 0:
+				if got.RequeueAfter != 0 {
+					t.Errorf("Reconcile() requeue after %v, want no requeue", got.RequeueAfter)
+				}
+			default:
+				if d := got.RequeueAfter - tt.wantRequeue; d > time.Second || d < -time.Second {
+					t.Errorf("Reconcile() requeue after %v, want %v", got.RequeueAfter, tt.wantRequeue)
+				}
+			}
+			if tt.wantUpdate && (updated.Status.Etcd.NextMaintenanceTime != nil) != tt.wantNext {
+				t.Errorf("Reconcile() next maintenance time = %v, want set %v", updated.Status.Etcd.NextMaintenanceTime, tt.wantNext)
+			}
+			if tt.wantNext && !updated.Status.Etcd.NextMaintenanceTime.After(now) {
+				t.Errorf("Reconcile() next maintenance time %v is not in the future", updated.Status.Etcd.NextMaintenanceTime)
+			}
+			if !tt.wantOperation {
+				return
+			}
+			if updated.Status.Phase != v1.ClusterUpdating || updated.Status.Etcd.LastMaintenanceTime == nil {
+				t.Errorf("Reconcile() cluster phase = %s, last maintenance time = %v", updated.Status.Phase, updated.Status.Etcd.LastMaintenanceTime)
+			}
+			if created.Labels[common.LabelOperationAction] != v1.OperationEtcdMaintenance || created.Labels[common.LabelClusterName] != "cluster1" {
+				t.Errorf("Reconcile() operation labels = %v", created.Labels)
+			}
+			type step struct {
+				name  string
+				nodes []string
+			}
+			var steps []step
+			for _, s := range created.Steps {
+				var nodes []string
+				for _, n := range s.Nodes {
+					nodes = append(nodes, n.ID+"/"+n.IPv4)
+				}
+				steps = append(steps, step{s.Name, nodes})
+			}
+			want := []step{
+				{"etcdCompact", []string{"node1/10.0.0.1"}},
+				{"etcdDefrag-master1", []string{"node1/10.0.0.1"}},
+				{"etcdDefrag-master2", []string{"node2/10.0.0.2"}},
+				{"etcdAlarmDisarm", []string{"node1/10.0.0.1"}},
+				{"etcdStatus", []string{"node1/10.0.0.1", "node2/10.0.0.2"}},
+			}
+			if !reflect.DeepEqual(steps, want) {
+				t.Errorf("Reconcile() operation steps = %v, want %v", steps, want)
+			}
+			select {
+			case op := <-delivery.delivered:
+				if op.Name != created.Name {
+					t.Errorf("Reconcile() delivered operation %s, want %s", op.Name, created.Name)
+				}
+			case <-time.After(5 * time.Second):
+				t.Error("Reconcile() did not deliver the operation")
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/manager"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/models/platform"
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+const (
+	eventTTLMonitorPeriod = time.Hour
+)
+
+// EventTTLMon prunes the audit events which are older than the TTL.
+type EventTTLMon struct {
+	EventReader platform.EventReader
+	EventWriter platform.EventWriter
+	TTL         time.Duration
+	log         logger.Logging
+	now         func() time.Time
+}
+
+func (s *EventTTLMon) SetupWithManager(mgr manager.Manager) {
+	s.log = mgr.GetLogger().WithName("event-ttl-monitor")
+	if s.now == nil {
+		s.now = time.Now
+	}
+	mgr.AddWorkerLoop(s.pruneEvents, eventTTLMonitorPeriod)
+}
+
+func (s *EventTTLMon) pruneEvents() {
+	if s.TTL <= 0 {
+		return
+	}
+	events, err := s.EventReader.ListEvents(context.TODO(), query.New())
+	if err != nil {
+		s.log.Error("list events failed, prune events next period", zap.Error(err))
+		return
+	}
+	deadline := s.now().Add(-s.TTL)
+	pruned := 0
+	for i := range events.Items {
+		if !eventCreatedAt(&events.Items[i]).Before(deadline) {
+			continue
+		}
+		if err = s.EventWriter.DeleteEvent(context.TODO(), events.Items[i].Name); err != nil {
+			s.log.Warn("delete expired event failed", zap.String("event", events.Items[i].Name), zap.Error(err))
+			continue
+		}
+		pruned++
+	}
+	if pruned > 0 {
+		s.log.Info("expired events pruned", zap.Int("count", pruned), zap.Duration("ttl", s.TTL))
+	}
+}
+
+// eventCreatedAt prefers the time the request was received, the event is created asynchronously.
+func eventCreatedAt(e *v1.Event) time.Time {
+	if !e.RequestReceivedTimestamp.IsZero() {
+		return e.RequestReceivedTimestamp.Time
+	}
+	return e.CreationTimestamp.Time
+}
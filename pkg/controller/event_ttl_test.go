@@ -0,0 +1,109 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	mockplatform "github.com/kubeclipper/kubeclipper/pkg/models/platform/mock"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func TestEventTTLMon_pruneEvents(t *testing.T) {
+	now := time.Date(2022, 6, 30, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	newEvent := func(name string, created, received time.Time) v1.Event {
+		e := v1.Event{ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(created)}}
+		if !received.IsZero() {
+			e.RequestReceivedTimestamp = metav1.NewMicroTime(received)
+		}
+		return e
+	}
+	events := &v1.EventList{Items: []v1.Event{
+		newEvent("fresh", now.Add(-day), time.Time{}),
+		newEvent("expired", now.Add(-10*day), time.Time{}),
+		newEvent("expired-received", now, now.Add(-8*day)),
+		newEvent("delete-failed", now.Add(-9*day), time.Time{}),
+	}}
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		listErr error
+		want    []string
+	}{
+		{
+			name: "prune expired events",
+			ttl:  7 * day,
+			want: []string{"delete-failed", "expired", "expired-received"},
+		},
+		{
+			name: "ttl longer than events",
+			ttl:  30 * day,
+		},
+		{
+			name: "disabled",
+			ttl:  0,
+		},
+		{
+			name:    "list failed",
+			ttl:     7 * day,
+			listErr: fmt.Errorf("etcd unavailable"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			reader := mockplatform.NewMockEventReader(ctrl)
+			writer := mockplatform.NewMockEventWriter(ctrl)
+			if tt.ttl > 0 {
+				reader.EXPECT().ListEvents(gomock.Any(), gomock.Any()).Return(events, tt.listErr)
+			}
+			var deleted []string
+			writer.EXPECT().DeleteEvent(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, name string) error {
+				deleted = append(deleted, name)
+				if name == "delete-failed" {
+					return fmt.Errorf("conflict")
+				}
+				return nil
+			}).AnyTimes()
+			s := &EventTTLMon{
+				EventReader: reader,
+				EventWriter: writer,
+				TTL:         tt.ttl,
+				log:         logger.WithName("event-ttl-monitor"),
+				now:         func() time.Time { return now },
+			}
+			s.pruneEvents()
+			sort.Strings(deleted)
+			if !reflect.DeepEqual(deleted, tt.want) {
+				t.Errorf("pruneEvents() deleted = %v, want %v", deleted, tt.want)
+			}
+		})
+	}
+}
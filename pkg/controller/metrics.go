@@ -0,0 +1,146 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/labels"
+	compbasemetrics "k8s.io/component-base/metrics"
+
+	listerv1 "github.com/kubeclipper/kubeclipper/pkg/client/lister/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/manager"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/metrics"
+)
+
+const (
+	metricsMonitorPeriod = 15 * time.Second
+)
+
+var (
+	nodeHeartbeatAge = compbasemetrics.NewGaugeVec(
+		&compbasemetrics.GaugeOpts{
+			Name:           "kc_node_heartbeat_age_seconds",
+			Help:           "Seconds since the agent of the node renewed its lease last time.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"node"},
+	)
+
+	clusterPhase = compbasemetrics.NewGaugeVec(
+		&compbasemetrics.GaugeOpts{
+			Name:           "kc_cluster_phase",
+			Help:           "The phase of the cluster, 1 for the current phase and 0 for the others.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"cluster", "phase"},
+	)
+
+	clusterCertificateExpiryDays = compbasemetrics.NewGaugeVec(
+		&compbasemetrics.GaugeOpts{
+			Name:           "kc_cluster_certificate_expiry_days",
+			Help:           "Days until the certificate of the cluster expires, negative if it has expired.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"cluster", "certificate"},
+	)
+
+	clusterPhases = []v1.ClusterPhase{
+		v1.ClusterInstalling, v1.ClusterInstallFailed, v1.ClusterRunning,
+		v1.ClusterUpdating, v1.ClusterUpdateFailed, v1.ClusterUpgrading, v1.ClusterUpgradeFailed,
+		v1.ClusterBackingUp, v1.ClusterRestoring, v1.ClusterRestoreFailed,
+		v1.ClusterTerminating, v1.ClusterTerminateFailed,
+	}
+
+	registerMetricsOnce sync.Once
+)
+
+func registerMetrics() {
+	registerMetricsOnce.Do(func() {
+		metrics.MustRegister(nodeHeartbeatAge, clusterPhase, clusterCertificateExpiryDays)
+	})
+}
+
+// MetricsMon refreshes the gauges of the clusters and nodes periodically.
+type MetricsMon struct {
+	ClusterLister listerv1.ClusterLister
+	NodeLister    listerv1.NodeLister
+	LeaseLister   listerv1.LeaseLister
+	log           logger.Logging
+	now           func() time.Time
+}
+
+func (s *MetricsMon) SetupWithManager(mgr manager.Manager) {
+	registerMetrics()
+	s.log = mgr.GetLogger().WithName("metrics-monitor")
+	if s.now == nil {
+		s.now = time.Now
+	}
+	mgr.AddWorkerLoop(s.updateMetrics, metricsMonitorPeriod)
+}
+
+func (s *MetricsMon) updateMetrics() {
+	s.updateNodeMetrics()
+	s.updateClusterMetrics()
+}
+
+func (s *MetricsMon) updateNodeMetrics() {
+	nodes, err := s.NodeLister.List(labels.Everything())
+	if err != nil {
+		s.log.Error("list nodes failed, update node metrics next period", zap.Error(err))
+		return
+	}
+	// reset to drop the deleted nodes
+	nodeHeartbeatAge.Reset()
+	for _, node := range nodes {
+		lease, err := s.LeaseLister.Leases(namespaceNodeLease).Get(node.Name)
+		if err != nil || lease.Spec.RenewTime == nil {
+			continue
+		}
+		nodeHeartbeatAge.WithLabelValues(node.Name).Set(s.now().Sub(lease.Spec.RenewTime.Time).Seconds())
+	}
+}
+
+func (s *MetricsMon) updateClusterMetrics() {
+	clusters, err := s.ClusterLister.List(labels.Everything())
+	if err != nil {
+		s.log.Error("list clusters failed, update cluster metrics next period", zap.Error(err))
+		return
+	}
+	// reset to drop the deleted clusters and renewed certificates
+	clusterPhase.Reset()
+	clusterCertificateExpiryDays.Reset()
+	for _, clu := range clusters {
+		for _, phase := range clusterPhases {
+			value := 0.0
+			if clu.Status.Phase == phase {
+				value = 1
+			}
+			clusterPhase.WithLabelValues(clu.Name, string(phase)).Set(value)
+		}
+		for _, cert := range clu.Status.Certifications {
+			days := cert.ExpirationTime.Sub(s.now()).Hours() / 24
+			clusterCertificateExpiryDays.WithLabelValues(clu.Name, cert.Name).Set(days)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/component-base/metrics/testutil"
+
+	listerv1 "github.com/kubeclipper/kubeclipper/pkg/client/lister/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func TestMetricsMon_updateMetrics(t *testing.T) {
+	registerMetrics()
+	now := time.Date(2022, 6, 30, 0, 0, 0, 0, time.UTC)
+	newIndexer := func(objs ...interface{}) cache.Indexer {
+		indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+		for _, obj := range objs {
+			if err := indexer.Add(obj); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return indexer
+	}
+	renew := metav1.NewMicroTime(now.Add(-30 * time.Second))
+	s := &MetricsMon{
+		ClusterLister: listerv1.NewClusterLister(newIndexer(&v1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "c1"},
+			Status: v1.ClusterStatus{
+				Phase: v1.ClusterRunning,
+				Certifications: []v1.Certification{
+					{Name: "apiserver", ExpirationTime: metav1.NewTime(now.Add(10 * 24 * time.Hour))},
+					{Name: "front-proxy-client", ExpirationTime: metav1.NewTime(now.Add(-12 * time.Hour))},
+				},
+			},
+		})),
+		NodeLister: listerv1.NewNodeLister(newIndexer(
+			&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "n1"}},
+			&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "n2"}},
+		)),
+		LeaseLister: listerv1.NewLeaseLister(newIndexer(&coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{Name: "n1", Namespace: namespaceNodeLease},
+			Spec:       coordinationv1.LeaseSpec{RenewTime: &renew},
+		})),
+		log: logger.WithName("metrics-monitor"),
+		now: func() time.Time { return now },
+	}
+	s.updateMetrics()
+
+	tests := []struct {
+		name string
+		got  func() (float64, error)
+		want float64
+	}{
+		{
+			name: "heartbeat age",
+			got:  func() (float64, error) { return testutil.GetGaugeMetricValue(nodeHeartbeatAge.WithLabelValues("n1")) },
+			want: 30,
+		},
+		{
+			name: "current phase",
+			got: func() (float64, error) {
+				return testutil.GetGaugeMetricValue(clusterPhase.WithLabelValues("c1", string(v1.ClusterRunning)))
+			},
+			want: 1,
+		},
+		{
+			name: "other phase",
+			got: func() (float64, error) {
+				return testutil.GetGaugeMetricValue(clusterPhase.WithLabelValues("c1", string(v1.ClusterInstalling)))
+			},
+			want: 0,
+		},
+		{
+			name: "certificate expiry",
+			got: func() (float64, error) {
+				return testutil.GetGaugeMetricValue(clusterCertificateExpiryDays.WithLabelValues("c1", "apiserver"))
+			},
+			want: 10,
+		},
+		{
+			name: "expired certificate",
+			got: func() (float64, error) {
+				return testutil.GetGaugeMetricValue(clusterCertificateExpiryDays.WithLabelValues("c1", "front-proxy-client"))
+			},
+			want: -0.5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.got()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -21,6 +21,9 @@ package operationcontroller
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	"go.uber.org/zap"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -37,12 +40,22 @@ import (
 	"github.com/kubeclipper/kubeclipper/pkg/models/operation"
 	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
 	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/service"
 )
 
+// unclaimedGracePeriod is how long a running operation may stay without a runner before it is
+// adopted, the delivery service records itself as the runner right after the operation starts.
+const unclaimedGracePeriod = time.Minute
+
 type OperationReconciler struct {
 	ClusterLister   listerv1.ClusterLister
 	OperationLister listerv1.OperationLister
 	OperationWriter operation.Writer
+	LeaseLister     listerv1.LeaseLister
+	CmdDelivery     service.CmdDelivery
+
+	mu             sync.Mutex
+	unclaimedSince map[string]time.Time
 }
 
 func (r *OperationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -53,6 +66,7 @@ func (r *OperationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		// operation not found, possibly been deleted
 		// need to do the cleanup
 		if errors.IsNotFound(err) {
+			r.forgetUnclaimed(req.Name)
 			return ctrl.Result{}, nil
 		}
 		log.Error("Failed to get operation with name", zap.Error(err))
@@ -101,9 +115,73 @@ func (r *OperationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		log.Error("Failed to get cluster with name", zap.String("cluster", cluName), zap.Error(err))
 		return ctrl.Result{}, err
 	}
+	return r.syncRunner(ctx, op)
+}
+
+// syncRunner adopts the running operation if the server running it is gone,
+// otherwise the operation is checked again when the runner lease expires.
+func (r *OperationReconciler) syncRunner(ctx context.Context, op *v1.Operation) (ctrl.Result, error) {
+	log := logger.FromContext(ctx)
+
+	runner := op.Annotations[common.AnnotationOperationRunner]
+	if op.Status.Status != v1.OperationStatusRunning || runner != "" {
+		r.forgetUnclaimed(op.Name)
+	}
+	if op.Status.Status != v1.OperationStatusRunning || r.CmdDelivery == nil {
+		return ctrl.Result{}, nil
+	}
+	if runner == "" {
+		if remaining := r.unclaimedRemaining(op.Name); remaining > 0 {
+			return ctrl.Result{RequeueAfter: remaining}, nil
+		}
+	} else if remaining := r.runnerRemaining(runner); remaining > 0 {
+		return ctrl.Result{RequeueAfter: remaining}, nil
+	}
+	log.Info("adopt operation since its runner is gone", zap.String("operation", op.Name), zap.String("runner", runner))
+	if err := r.CmdDelivery.AdoptOperation(ctx, op.Name, runner); err != nil {
+		log.Error("Failed to adopt operation", zap.String("operation", op.Name), zap.Error(err))
+		return ctrl.Result{}, err
+	}
+	r.forgetUnclaimed(op.Name)
 	return ctrl.Result{}, nil
 }
 
+// runnerRemaining returns how long the runner lease is still valid, the runner is gone when
+// the lease is missing, expired or held by another process of the server.
+func (r *OperationReconciler) runnerRemaining(runner string) time.Duration {
+	name, holder := runner, ""
+	if i := strings.LastIndex(runner, "/"); i >= 0 {
+		name, holder = runner[:i], runner[i+1:]
+	}
+	l, err := r.LeaseLister.Leases(common.ServerLeaseNamespace).Get(name)
+	if err != nil || l.Spec.HolderIdentity == nil || *l.Spec.HolderIdentity != holder ||
+		l.Spec.RenewTime == nil || l.Spec.LeaseDurationSeconds == nil {
+		return 0
+	}
+	expire := l.Spec.RenewTime.Add(time.Duration(*l.Spec.LeaseDurationSeconds) * time.Second)
+	return time.Until(expire)
+}
+
+func (r *OperationReconciler) unclaimedRemaining(name string) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.unclaimedSince == nil {
+		r.unclaimedSince = make(map[string]time.Time)
+	}
+	since, ok := r.unclaimedSince[name]
+	if !ok {
+		since = time.Now()
+		r.unclaimedSince[name] = since
+	}
+	return time.Until(since.Add(unclaimedGracePeriod))
+}
+
+func (r *OperationReconciler) forgetUnclaimed(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.unclaimedSince, name)
+}
+
 func (r *OperationReconciler) SetupWithManager(mgr manager.Manager, cache informers.InformerCache) error {
 	c, err := controller.NewUnmanaged("operation", controller.Options{
 		MaxConcurrentReconciles: 2,
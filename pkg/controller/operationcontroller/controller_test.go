@@ -0,0 +1,148 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package operationcontroller
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	listerv1 "github.com/kubeclipper/kubeclipper/pkg/client/lister/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/service"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/pointer"
+)
+
+// fakeDelivery records the adopted operations.
+type fakeDelivery struct {
+	service.CmdDelivery
+	adopted []string
+	err     error
+}
+
+func (f *fakeDelivery) AdoptOperation(ctx context.Context, name, runner string) error {
+	f.adopted = append(f.adopted, name+"@"+runner)
+	return f.err
+}
+
+func TestOperationReconciler_syncRunner(t *testing.T) {
+	now := time.Now()
+	newLease := func(holder string, renew time.Time) *coordinationv1.Lease {
+		renewTime := metav1.NewMicroTime(renew)
+		return &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{Name: "server1", Namespace: common.ServerLeaseNamespace},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       pointer.StringPtr(holder),
+				LeaseDurationSeconds: pointer.Int32Ptr(40),
+				RenewTime:            &renewTime,
+			},
+		}
+	}
+	newOp := func(status v1.OperationStatusType, runner string) *v1.Operation {
+		op := &v1.Operation{ObjectMeta: metav1.ObjectMeta{Name: "op1"}}
+		op.Status.Status = status
+		if runner != "" {
+			op.Annotations = map[string]string{common.AnnotationOperationRunner: runner}
+		}
+		return op
+	}
+	tests := []struct {
+		name        string
+		op          *v1.Operation
+		lease       *coordinationv1.Lease
+		adoptErr    error
+		wantAdopted []string
+		wantRequeue bool
+		wantErr     bool
+	}{
+		{
+			name:        "adopt operation of expired lease",
+			op:          newOp(v1.OperationStatusRunning, "server1/holder1"),
+			lease:       newLease("holder1", now.Add(-time.Minute)),
+			wantAdopted: []string{"op1@server1/holder1"},
+		},
+		{
+			name:        "leave operation of live lease",
+			op:          newOp(v1.OperationStatusRunning, "server1/holder1"),
+			lease:       newLease("holder1", now),
+			wantRequeue: true,
+		},
+		{
+			name:        "adopt operation of restarted server",
+			op:          newOp(v1.OperationStatusRunning, "server1/holder1"),
+			lease:       newLease("holder2", now),
+			wantAdopted: []string{"op1@server1/holder1"},
+		},
+		{
+			name:        "adopt operation without lease",
+			op:          newOp(v1.OperationStatusRunning, "server1/holder1"),
+			wantAdopted: []string{"op1@server1/holder1"},
+		},
+		{
+			name:        "wait for runner of new operation",
+			op:          newOp(v1.OperationStatusRunning, ""),
+			wantRequeue: true,
+		},
+		{
+			name:  "skip finished operation",
+			op:    newOp(v1.OperationStatusFailed, "server1/holder1"),
+			lease: newLease("holder1", now.Add(-time.Minute)),
+		},
+		{
+			name:        "adoption failure",
+			op:          newOp(v1.OperationStatusRunning, "server1/holder1"),
+			lease:       newLease("holder1", now.Add(-time.Minute)),
+			adoptErr:    errors.New("operation conflict"),
+			wantAdopted: []string{"op1@server1/holder1"},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if tt.lease != nil {
+				if err := indexer.Add(tt.lease); err != nil {
+					t.Fatal(err)
+				}
+			}
+			delivery := &fakeDelivery{err: tt.adoptErr}
+			r := &OperationReconciler{
+				LeaseLister: listerv1.NewLeaseLister(indexer),
+				CmdDelivery: delivery,
+			}
+			got, err := r.syncRunner(context.TODO(), tt.op)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("syncRunner() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(delivery.adopted, tt.wantAdopted) {
+				t.Errorf("syncRunner() adopted %v, want %v", delivery.adopted, tt.wantAdopted)
+			}
+			if (got.RequeueAfter > 0) != tt.wantRequeue {
+				t.Errorf("syncRunner() requeue after %v, want requeue %v", got.RequeueAfter, tt.wantRequeue)
+			}
+		})
+	}
+}
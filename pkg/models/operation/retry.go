@@ -0,0 +1,177 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package operation
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+// SupportRetry reports whether the operation can run again from a failed step,
+// backup/recovery/upgrade does not support retries.
+func SupportRetry(op *v1.Operation) bool {
+	switch op.Labels[common.LabelOperationAction] {
+	case v1.OperationBackupCluster, v1.OperationRecoverCluster, v1.OperationUpgradeCluster, "":
+		return false
+	}
+	return true
+}
+
+// ParseRetrySteps returns the steps to run when retrying the operation from the step fromStep,
+// an empty fromStep means the failed step for installation and the first step for uninstallation.
+// When resuming an installation from the failed step, the step only runs on the nodes without a
+// successful status, and the later steps done by all nodes are skipped. The recorded conditions of
+// the operation are trimmed to the steps that will not run again. The dependencies on the steps which
+// will not run again are dropped, so the response of the first dependency of each step whose first
+// dependency is done is returned keyed by the step ID, it is passed to the step as its last reply.
+func ParseRetrySteps(op *v1.Operation, fromStep string) ([]v1.Step, map[string][]byte, error) {
+	if len(op.Steps) == 0 {
+		return nil, nil, fmt.Errorf("operation %s has no steps", op.Name)
+	}
+	conditions := make(map[string]v1.OperationCondition, len(op.Status.Conditions))
+	for _, c := range op.Status.Conditions {
+		conditions[c.StepID] = c
+	}
+	// succeeded reports whether all nodes of the step have a successful status
+	succeeded := func(step v1.Step) bool {
+		c, ok := conditions[step.ID]
+		if !ok {
+			return false
+		}
+		status := c.NodeStatus()
+		for _, node := range step.Nodes {
+			if status[node.ID] != v1.StepStatusSuccessful {
+				return false
+			}
+		}
+		return true
+	}
+	stepIndex := func(id string) int {
+		for i, step := range op.Steps {
+			if step.ID == id {
+				return i
+			}
+		}
+		return -1
+	}
+
+	// the failed step is the first step which is not done, a failed step ignoring errors is done.
+	// failedIndex is len(op.Steps) when all steps are done, no step runs again in that case.
+	failedIndex := len(op.Steps)
+	for i, step := range op.Steps {
+		if _, ok := conditions[step.ID]; ok && (step.ErrIgnore || succeeded(step)) {
+			continue
+		}
+		failedIndex = i
+		break
+	}
+	from := failedIndex
+	if op.Steps[0].Action == v1.ActionUninstall {
+		// if there is an uninstall error, start from the beginning
+		from = 0
+	}
+	if fromStep != "" {
+		if from = stepIndex(fromStep); from < 0 {
+			return nil, nil, fmt.Errorf("step %s not found in operation %s", fromStep, op.Name)
+		}
+		if from > failedIndex {
+			return nil, nil, fmt.Errorf("step %s is after the failed step %s, the failed step can not be skipped",
+				fromStep, op.Steps[failedIndex].ID)
+		}
+	}
+	// uninstall steps and steps from an explicit earlier step run again on all nodes.
+	resume := from == failedIndex && op.Steps[0].Action == v1.ActionInstall
+
+	var (
+		steps []v1.Step
+		kept  []v1.OperationCondition
+	)
+	for i := range op.Steps {
+		step := op.Steps[i].DeepCopy()
+		c, recorded := conditions[step.ID]
+		switch {
+		case i < from:
+			if recorded {
+				kept = append(kept, c)
+			}
+		case i == from && resume:
+			nodeStatus := c.NodeStatus()
+			var failedNodes []v1.StepNode
+			for _, node := range step.Nodes {
+				if nodeStatus[node.ID] != v1.StepStatusSuccessful {
+					failedNodes = append(failedNodes, node)
+				}
+			}
+			// retain the successful status, the status of failed nodes will be appended when the step runs again.
+			successStatus := make([]v1.StepStatus, 0, len(c.Status))
+			for _, status := range c.Status {
+				if nodeStatus[status.Node] == v1.StepStatusSuccessful && status.Status == v1.StepStatusSuccessful {
+					successStatus = append(successStatus, status)
+				}
+			}
+			if len(successStatus) > 0 {
+				kept = append(kept, v1.OperationCondition{StepID: step.ID, Status: successStatus})
+			}
+			step.Nodes = failedNodes
+			steps = append(steps, *step)
+		case i > from && resume && succeeded(*step):
+			// independent steps may have been done after the failed step
+			kept = append(kept, c)
+		default:
+			steps = append(steps, *step)
+		}
+	}
+	// drop the dependencies on the steps which will not run again
+	ids := sets.NewString()
+	for i := range steps {
+		var deps []string
+		for _, id := range steps[i].DependsOn {
+			if ids.Has(id) {
+				deps = append(deps, id)
+			}
+		}
+		steps[i].DependsOn = deps
+		ids.Insert(steps[i].ID)
+	}
+	// the dependencies are resolved on the original steps, a step without DependsOn depends on the previous step.
+	replies := make(map[string][]byte)
+	for i := range op.Steps {
+		if !ids.Has(op.Steps[i].ID) {
+			continue
+		}
+		deps, err := op.StepDependencies(i)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(deps) == 0 || ids.Has(op.Steps[deps[0]].ID) {
+			continue
+		}
+		var reply []byte
+		if c := conditions[op.Steps[deps[0]].ID]; len(c.Status) > 0 {
+			reply = c.Status[0].Response
+		}
+		replies[op.Steps[i].ID] = reply
+	}
+	op.Status.Conditions = kept
+	return steps, replies, nil
+}
@@ -0,0 +1,246 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package operation
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func TestParseRetrySteps(t *testing.T) {
+	newOp := func(action v1.StepAction) *v1.Operation {
+		nodes := []v1.StepNode{{ID: "node1"}, {ID: "node2"}}
+		return &v1.Operation{
+			ObjectMeta: metav1.ObjectMeta{Name: "op1"},
+			Steps: []v1.Step{
+				{ID: "s1", Action: action, Nodes: nodes},
+				{ID: "s2", Action: action, Nodes: nodes},
+				{ID: "s3", Action: action, Nodes: nodes},
+			},
+			Status: v1.OperationStatus{
+				Status: v1.OperationStatusFailed,
+				Conditions: []v1.OperationCondition{
+					{StepID: "s1", Status: []v1.StepStatus{
+						{Node: "node1", Status: v1.StepStatusSuccessful, Response: []byte("s1")},
+						{Node: "node2", Status: v1.StepStatusSuccessful},
+					}},
+					{StepID: "s2", Status: []v1.StepStatus{
+						{Node: "node1", Status: v1.StepStatusSuccessful},
+						{Node: "node2", Status: v1.StepStatusFailed},
+					}},
+				},
+			},
+		}
+	}
+	tests := []struct {
+		name           string
+		op             *v1.Operation
+		fromStep       string
+		wantErr        bool
+		wantSteps      []string
+		wantNodes      []string
+		wantConditions int
+		wantReplies    map[string]string
+	}{
+		{
+			name:           "resume install from failed step on failed nodes",
+			op:             newOp(v1.ActionInstall),
+			wantSteps:      []string{"s2", "s3"},
+			wantNodes:      []string{"node2"},
+			wantConditions: 2,
+			wantReplies:    map[string]string{"s2": "s1"},
+		},
+		{
+			name:           "resume install from earlier step on all nodes",
+			op:             newOp(v1.ActionInstall),
+			fromStep:       "s1",
+			wantSteps:      []string{"s1", "s2", "s3"},
+			wantNodes:      []string{"node1", "node2"},
+			wantConditions: 0,
+		},
+		{
+			name:     "skip failed step",
+			op:       newOp(v1.ActionInstall),
+			fromStep: "s3",
+			wantErr:  true,
+		},
+		{
+			name:     "unknown step",
+			op:       newOp(v1.ActionInstall),
+			fromStep: "s4",
+			wantErr:  true,
+		},
+		{
+			name: "resume graph from failed step and skip done steps",
+			op: func() *v1.Operation {
+				op := newOp(v1.ActionInstall)
+				op.Steps[2].DependsOn = []string{"s1"}
+				op.Status.Conditions = []v1.OperationCondition{
+					op.Status.Conditions[0],
+					{StepID: "s3", Status: []v1.StepStatus{
+						{Node: "node1", Status: v1.StepStatusSuccessful},
+						{Node: "node2", Status: v1.StepStatusFailed},
+						{Node: "node2", Status: v1.StepStatusSuccessful},
+					}},
+					op.Status.Conditions[1],
+				}
+				return op
+			}(),
+			wantSteps:      []string{"s2"},
+			wantNodes:      []string{"node2"},
+			wantConditions: 3,
+			wantReplies:    map[string]string{"s2": "s1"},
+		},
+		{
+			name: "nothing to resume when all steps are done",
+			op: func() *v1.Operation {
+				op := newOp(v1.ActionInstall)
+				op.Status.Conditions[1].Status[1].Status = v1.StepStatusSuccessful
+				op.Status.Conditions = append(op.Status.Conditions, v1.OperationCondition{StepID: "s3", Status: []v1.StepStatus{
+					{Node: "node1", Status: v1.StepStatusSuccessful},
+					{Node: "node2", Status: v1.StepStatusSuccessful},
+				}})
+				return op
+			}(),
+			wantConditions: 3,
+		},
+		{
+			name: "run all steps when no condition is recorded",
+			op: func() *v1.Operation {
+				op := newOp(v1.ActionInstall)
+				op.Status.Conditions = nil
+				return op
+			}(),
+			wantSteps:      []string{"s1", "s2", "s3"},
+			wantNodes:      []string{"node1", "node2"},
+			wantConditions: 0,
+		},
+		{
+			name: "resume from the failed step which is not the last condition",
+			op: func() *v1.Operation {
+				op := newOp(v1.ActionInstall)
+				op.Steps[2].DependsOn = []string{"s1"}
+				op.Status.Conditions = append(op.Status.Conditions, v1.OperationCondition{StepID: "s3", Status: []v1.StepStatus{
+					{Node: "node1", Status: v1.StepStatusFailed},
+				}})
+				return op
+			}(),
+			wantSteps:      []string{"s2", "s3"},
+			wantNodes:      []string{"node2"},
+			wantConditions: 2,
+			wantReplies:    map[string]string{"s2": "s1", "s3": "s1"},
+		},
+		{
+			name: "resume a step with the response of its dependency instead of the previous step",
+			op: func() *v1.Operation {
+				node1, node2 := []v1.StepNode{{ID: "node1"}}, []v1.StepNode{{ID: "node2"}}
+				return &v1.Operation{
+					ObjectMeta: metav1.ObjectMeta{Name: "op1"},
+					Steps: []v1.Step{
+						{ID: "initControlPlane", Action: v1.ActionInstall, Nodes: node1},
+						{ID: "installPackages", Action: v1.ActionInstall, Nodes: node2},
+						{ID: "joinNode", Action: v1.ActionInstall, Nodes: node2, DependsOn: []string{"initControlPlane", "installPackages"}},
+						{ID: "labelNode", Action: v1.ActionInstall, Nodes: node2},
+					},
+					Status: v1.OperationStatus{
+						Status: v1.OperationStatusFailed,
+						Conditions: []v1.OperationCondition{
+							{StepID: "initControlPlane", Status: []v1.StepStatus{
+								{Node: "node1", Status: v1.StepStatusSuccessful, Response: []byte("kubeadm join")},
+							}},
+							{StepID: "installPackages", Status: []v1.StepStatus{
+								{Node: "node2", Status: v1.StepStatusSuccessful, Response: []byte("packages")},
+							}},
+							{StepID: "joinNode", Status: []v1.StepStatus{
+								{Node: "node2", Status: v1.StepStatusFailed},
+							}},
+						},
+					},
+				}
+			}(),
+			wantSteps:      []string{"joinNode", "labelNode"},
+			wantNodes:      []string{"node2"},
+			wantConditions: 2,
+			wantReplies:    map[string]string{"joinNode": "kubeadm join"},
+		},
+		{
+			name: "resume when the previous step has no node status",
+			op: func() *v1.Operation {
+				op := newOp(v1.ActionInstall)
+				op.Steps[0].ErrIgnore = true
+				op.Status.Conditions[0].Status = nil
+				return op
+			}(),
+			wantSteps:      []string{"s2", "s3"},
+			wantNodes:      []string{"node2"},
+			wantConditions: 2,
+			wantReplies:    map[string]string{"s2": ""},
+		},
+		{
+			name:           "restart uninstall from the beginning",
+			op:             newOp(v1.ActionUninstall),
+			wantSteps:      []string{"s1", "s2", "s3"},
+			wantNodes:      []string{"node1", "node2"},
+			wantConditions: 0,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			steps, replies, err := ParseRetrySteps(test.op, test.fromStep)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("ParseRetrySteps() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+			var ids []string
+			for _, s := range steps {
+				ids = append(ids, s.ID)
+			}
+			if !reflect.DeepEqual(ids, test.wantSteps) {
+				t.Errorf("ParseRetrySteps() steps = %v, want %v", ids, test.wantSteps)
+			}
+			var nodes []string
+			if len(steps) > 0 {
+				for _, n := range steps[0].Nodes {
+					nodes = append(nodes, n.ID)
+				}
+			}
+			if !reflect.DeepEqual(nodes, test.wantNodes) {
+				t.Errorf("ParseRetrySteps() nodes = %v, want %v", nodes, test.wantNodes)
+			}
+			if len(test.op.Status.Conditions) != test.wantConditions {
+				t.Errorf("ParseRetrySteps() conditions = %d, want %d", len(test.op.Status.Conditions), test.wantConditions)
+			}
+			gotReplies := make(map[string]string, len(replies))
+			for id, reply := range replies {
+				gotReplies[id] = string(reply)
+			}
+			if len(gotReplies) != 0 || len(test.wantReplies) != 0 {
+				if !reflect.DeepEqual(gotReplies, test.wantReplies) {
+					t.Errorf("ParseRetrySteps() replies = %v, want %v", gotReplies, test.wantReplies)
+				}
+			}
+		})
+	}
+}
@@ -30,3 +30,13 @@ type LogContentResponse struct {
 	LogSize      int64  `json:"logSize"`
 	DeliverySize int64  `json:"deliverySize"`
 }
+
+// LogStreamChunk is the data appended to a step log file on a node, the agents publish
+// it to the log subject of the operation.
+type LogStreamChunk struct {
+	Node   string `json:"node"`
+	StepID string `json:"stepID"`
+	// Offset of the content in the step log file, it is -1 if unknown.
+	Offset  int64  `json:"offset"`
+	Content string `json:"content"`
+}
@@ -20,6 +20,7 @@ package oplog
 
 import (
 	"errors"
+	"io"
 	"os"
 	"path/filepath"
 
@@ -73,11 +74,15 @@ func (op *OperationLog) GetOperationDir(opID string) (path string, err error) {
 }
 
 // CreateStepLogFile create a file based on opID and stepID, open the file to return the file descriptor. You should close it.
-func (op *OperationLog) CreateStepLogFile(opID, stepID string) (*os.File, error) {
+func (op *OperationLog) CreateStepLogFile(opID, stepID string) (io.WriteCloser, error) {
 	if opID == "" || stepID == "" {
 		return nil, errors.New("opID or stepID is invalid")
 	}
-	return os.OpenFile(filepath.Join(op.cfg.Dir, opID, stepID+OperationLogSuffix), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0777)
+	f, err := os.OpenFile(filepath.Join(op.cfg.Dir, opID, stepID+OperationLogSuffix), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0777)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
 }
 
 // GetStepLogFile get step log file path
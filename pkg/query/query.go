@@ -46,6 +46,7 @@ const (
 	ParameterNode                 = "node"
 	ParameterOperation            = "operation"
 	ParameterStep                 = "step"
+	ParameterFromStep             = "fromStep"
 	ParameterOffset               = "offset"
 	OrderByParam                  = "orderBy"
 	ParamReverse                  = "reverse"
@@ -39,10 +39,15 @@ const (
 	LabelUserReference     = "iam.kubeclipper.io/user-ref"
 	LabelExternalIP        = "kubeclipper.io/externalIP"
 	LabelUpgradeVersion    = "kubeclipper.io/upgrade-version"
+	LabelUpgradeCNIVersion = "kubeclipper.io/upgrade-cni-version"
 	LabelBackupPoint       = "kubeclipper.io/backupPoint"
 	LabelCronBackupDisable = "kubeclipper.io/cronBackupDisable"
 	LabelCronBackupEnable  = "kubeclipper.io/cronBackupEnable"
 	LabelMetadataFloatIP   = "metadata.kubeclipper.io/floatIP"
+
+	// LabelVerifyOperationName is the last operation verifying the backup, the operation name label
+	// keeps tracking the operation creating or restoring the backup.
+	LabelVerifyOperationName = "kubeclipper.io/verify-operation-name"
 )
 
 const (
@@ -54,8 +59,15 @@ const (
 	RegoOverrideAnnotation     = "kubeclipper.io/rego-override"
 	RoleAnnotation             = "iam.kubeclipper.io/role"
 	AnnotationInternal         = "kubeclipper.io/internal"
+	// AnnotationOperationRunner is the server running the operation, in the format of <lease name>/<holder identity>.
+	AnnotationOperationRunner = "kubeclipper.io/operation-runner"
+	// AnnotationOperationNodes is the comma separated IDs of the nodes added or removed by the operation.
+	AnnotationOperationNodes = "kubeclipper.io/operation-nodes"
 )
 
+// ServerLeaseNamespace is the namespace of the leases renewed by every kubeclipper-server.
+const ServerLeaseNamespace = "server-lease"
+
 type NodeRole string // master/worker/ingress(worker)
 
 const (
@@ -45,6 +45,67 @@ type BackupStatus struct {
 	BackupFileSize      int64  `json:"backupFileSize"`
 	BackupFileMD5       string `json:"backupFileMD5"`
 	ClusterBackupStatus `json:"status"`
+	// compression and encryption of the backup file, e.g. zstd+aes-256-gcm,
+	// the size and md5 value are the ones of the compressed and encrypted file
+	// +optional
+	Algorithm string `json:"algorithm,omitempty"`
+	// sha256 checksum of the saved backup file
+	// +optional
+	Checksum string `json:"checksum,omitempty"`
+	// number of keys in the snapshot, it is recorded by the verification
+	// +optional
+	KeyCount int64 `json:"keyCount,omitempty"`
+	// +optional
+	Conditions []BackupCondition `json:"conditions,omitempty"`
+}
+
+type BackupConditionType string
+
+const (
+	// BackupVerified means the snapshot is downloaded and restored in a scratch directory successfully.
+	BackupVerified BackupConditionType = "Verified"
+	// BackupVerificationFailed means the snapshot can not be downloaded or restored.
+	BackupVerificationFailed BackupConditionType = "VerificationFailed"
+)
+
+// BackupCondition contains condition information for a backup.
+type BackupCondition struct {
+	// Type of backup condition.
+	Type BackupConditionType `json:"type"`
+	// Status of the condition, one of True, False, Unknown.
+	Status ConditionStatus `json:"status"`
+	// Last time the condition transit from one status to another.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// (brief) reason for the condition's last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Human readable message indicating details about last transition.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// SetVerification records the result of the latest verification,
+// the Verified and VerificationFailed conditions exclude each other.
+func (s *BackupStatus) SetVerification(cond BackupCondition) {
+	conditions := make([]BackupCondition, 0, len(s.Conditions)+1)
+	for _, c := range s.Conditions {
+		if c.Type == BackupVerified || c.Type == BackupVerificationFailed {
+			continue
+		}
+		conditions = append(conditions, c)
+	}
+	s.Conditions = append(conditions, cond)
+}
+
+// GetCondition returns the condition with the type, nil if it does not exist.
+func (s *BackupStatus) GetCondition(t BackupConditionType) *BackupCondition {
+	for i := range s.Conditions {
+		if s.Conditions[i].Type == t {
+			return &s.Conditions[i]
+		}
+	}
+	return nil
 }
 
 // ClusterBackupStatus describes the status of a cluster backup
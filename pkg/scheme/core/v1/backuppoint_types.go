@@ -18,7 +18,11 @@
 
 package v1
 
-import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
 
 // +genclient
 // +genclient:nonNamespaced
@@ -30,9 +34,17 @@ type BackupPoint struct {
 	// Standard object's metadata.
 	// +optional
 	metav1.ObjectMeta `json:"metadata,omitempty"`
-	StorageType       string    `json:"storageType,omitempty"`
-	FsConfig          *FsConfig `json:"fsConfig,omitempty"`
-	S3Config          *S3Config `json:"s3Config,omitempty"`
+	StorageType       string        `json:"storageType,omitempty"`
+	FsConfig          *FsConfig     `json:"fsConfig,omitempty"`
+	S3Config          *S3Config     `json:"s3Config,omitempty"`
+	SftpConfig        *SftpConfig   `json:"sftpConfig,omitempty"`
+	WebDAVConfig      *WebDAVConfig `json:"webdavConfig,omitempty"`
+	// retention of the backups which are not created by a cronBackup
+	RetentionPolicy *RetentionPolicy `json:"retentionPolicy,omitempty"`
+	// compression of the backup files, gzip or zstd
+	Compression string `json:"compression,omitempty"`
+	// client-side encryption of the backup files
+	Encryption *BackupEncryption `json:"encryption,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -59,3 +71,60 @@ type S3Config struct {
 	Region          string `json:"region" yaml:"region"`
 	SSL             bool   `json:"ssl" yaml:"ssl"`
 }
+
+// BackupEncryption encrypts the backup files on the node before they are saved in the backup point.
+type BackupEncryption struct {
+	// encryption algorithm, only aes-256-gcm is supported
+	Algorithm string `json:"algorithm,omitempty"`
+	// the file containing the passphrase the encryption key is derived from, it is read on the master nodes,
+	// so the passphrase is neither stored in the backup point nor sent in the operations.
+	KeyFile string `json:"keyFile,omitempty"`
+}
+
+// RetentionPolicy is a grandfather-father-son retention of backups.
+// For each level the newest backup of the latest N periods is kept,
+// a backup kept by any level is not pruned.
+type RetentionPolicy struct {
+	// number of hourly backups to keep
+	Hourly int `json:"hourly,omitempty"`
+	// number of daily backups to keep
+	Daily int `json:"daily,omitempty"`
+	// number of weekly backups to keep
+	Weekly int `json:"weekly,omitempty"`
+	// number of monthly backups to keep
+	Monthly int `json:"monthly,omitempty"`
+	// backups younger than MinAge are never pruned
+	MinAge metav1.Duration `json:"minAge,omitempty"`
+}
+
+func (p *RetentionPolicy) Validate() error {
+	if p.Hourly < 0 || p.Daily < 0 || p.Weekly < 0 || p.Monthly < 0 {
+		return fmt.Errorf("retention policy counts must not be negative")
+	}
+	if p.MinAge.Duration < 0 {
+		return fmt.Errorf("retention policy minAge must not be negative")
+	}
+	if p.Hourly+p.Daily+p.Weekly+p.Monthly == 0 {
+		return fmt.Errorf("retention policy must keep at least one backup")
+	}
+	return nil
+}
+
+type SftpConfig struct {
+	// host or host:port of the sftp server
+	Host     string `json:"host" yaml:"host"`
+	Username string `json:"username" yaml:"username"`
+	Password string `json:"password,omitempty" yaml:"password,omitempty"`
+	// content of the private key, it is used before the password
+	PrivateKey    string `json:"privateKey,omitempty" yaml:"privateKey,omitempty"`
+	BackupRootDir string `json:"backupRootDir,omitempty" yaml:"backupRootDir,omitempty"`
+	Description   string `json:"description,omitempty"`
+}
+
+type WebDAVConfig struct {
+	// URL of the directory for storing backup files
+	Endpoint    string `json:"endpoint" yaml:"endpoint"`
+	Username    string `json:"username,omitempty" yaml:"username,omitempty"`
+	Password    string `json:"password,omitempty" yaml:"password,omitempty"`
+	Description string `json:"description,omitempty"`
+}
@@ -2,9 +2,9 @@ package v1
 
 const (
 	ClusterKubeadm string = "kubeadm"
-	// TODO: other cluster type
+	ClusterK3s     string = "k3s"
 )
 
 type ProviderSpec struct {
-	Name string `json:"name"`
+	Name string `json:"name" enum:"kubeadm|k3s"`
 }
@@ -19,6 +19,9 @@
 package v1
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -52,7 +55,17 @@ type Cluster struct {
 	KubeConfig        []byte           `json:"kubeConfig,omitempty"`
 	Addons            []Addon          `json:"addons" optional:"true"`
 	Description       string           `json:"description,omitempty" optional:"true"`
-	Status            ClusterStatus    `json:"status,omitempty" optional:"true"`
+	// the backup the new cluster is restored from, it is only used when the cluster is created
+	RestoreFrom *RestoreFrom `json:"restoreFrom,omitempty" optional:"true"`
+	// renew the certificates automatically before they expire
+	CertRotationPolicy *CertRotationPolicy `json:"certRotationPolicy,omitempty" optional:"true"`
+	Status             ClusterStatus       `json:"status,omitempty" optional:"true"`
+}
+
+// RestoreFrom references the backup a new cluster is restored from.
+type RestoreFrom struct {
+	// name of the backup
+	Backup string `json:"backup"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -124,6 +137,41 @@ type ClusterStatus struct {
 	ComponentConditions []ComponentConditions `json:"componentConditions,omitempty"`
 
 	Certifications []Certification `json:"certifications,omitempty"`
+	// etcd db size and maintenance time, updated by the etcd maintenance operation
+	// +optional
+	Etcd EtcdStatus `json:"etcd,omitempty"`
+	// certificate rotation time, updated by the certificate rotation controller
+	// +optional
+	CertRotation CertRotationStatus `json:"certRotation,omitempty"`
+}
+
+type CertRotationStatus struct {
+	// the time the certificates will be renewed
+	NextRotationTime *metav1.Time `json:"nextRotationTime,omitempty"`
+	// the time the certificates were renewed last time
+	LastRotationTime *metav1.Time `json:"lastRotationTime,omitempty"`
+}
+
+type EtcdStatus struct {
+	// the db size quota of each member, etcd raises the NOSPACE alarm when it is exceeded
+	QuotaBackendBytes int64 `json:"quotaBackendBytes,omitempty"`
+	// db size of the members measured after the last maintenance
+	Members []EtcdMemberStatus `json:"members,omitempty"`
+	// next scheduled maintenance time
+	NextMaintenanceTime *metav1.Time `json:"nextMaintenanceTime,omitempty"`
+	// last scheduled maintenance time
+	LastMaintenanceTime *metav1.Time `json:"lastMaintenanceTime,omitempty"`
+}
+
+type EtcdMemberStatus struct {
+	// the node id of the member
+	Node string `json:"node"`
+	// db size in bytes, including the free pages
+	DBSize int64 `json:"dbSize"`
+	// db size in bytes actually used
+	DBSizeInUse int64 `json:"dbSizeInUse"`
+	// the time when the db size is measured
+	MeasureTime metav1.Time `json:"measureTime,omitempty"`
 }
 
 func (c *Cluster) Offline() bool {
@@ -149,7 +197,9 @@ func (c *Cluster) Complete(cniVersion string) {
 	c.CNI.LocalRegistry = c.LocalRegistry
 	c.CNI.CriType = c.ContainerRuntime.Type
 	c.CNI.Offline = c.Offline()
-	c.CNI.Version = cniVersion
+	if cniVersion != "" {
+		c.CNI.Version = cniVersion
+	}
 }
 
 type Certification struct {
@@ -215,19 +265,24 @@ type Networking struct {
 	// Defaults to "ipvs". "ebpf" disables kube-proxy and requires CNI support.
 	ProxyMode     string `json:"proxyMode"`
 	WorkerNodeVip string `json:"workerNodeVip" optional:"true"`
+	// UpstreamDNS replaces /etc/resolv.conf of the nodes as the upstreams of CoreDNS.
+	// +optional
+	UpstreamDNS *Forwarders `json:"upstreamDNS,omitempty" optional:"true"`
 }
 
 var (
-	AllowedCNI = sets.NewString("calico")
+	AllowedCNI = sets.NewString("calico", "cilium", "flannel")
 )
 
 type CNI struct {
 	LocalRegistry string `json:"localRegistry" optional:"true"`
-	Type          string `json:"type" enum:"calico"`
+	Type          string `json:"type" enum:"calico|cilium|flannel"`
 	Version       string
 	CriType       string
 	Offline       bool
-	Calico        *Calico `json:"calico" optional:"true"`
+	Calico        *Calico  `json:"calico" optional:"true"`
+	Cilium        *Cilium  `json:"cilium,omitempty" optional:"true"`
+	Flannel       *Flannel `json:"flannel,omitempty" optional:"true"`
 }
 
 type Calico struct {
@@ -238,8 +293,113 @@ type Calico struct {
 	MTU               int    `json:"mtu"`
 }
 
+type Cilium struct {
+	// TunnelMode is the encapsulation of the pod network, "disabled" routes pods natively.
+	TunnelMode string `json:"tunnelMode" enum:"vxlan|geneve|disabled"`
+	// KubeProxyReplacement lets cilium handle services by eBPF along with kube-proxy.
+	KubeProxyReplacement string `json:"kubeProxyReplacement" enum:"disabled|partial" optional:"true"`
+	MTU                  int    `json:"mtu" optional:"true"`
+}
+
+type Flannel struct {
+	Backend string `json:"backend" enum:"vxlan|host-gw"`
+}
+
 type Etcd struct {
 	DataDir string `json:"dataDir,omitempty" optional:"true"`
+	// Maintenance schedules the compaction and defragmentation of etcd.
+	Maintenance *EtcdMaintenance `json:"maintenance,omitempty" optional:"true"`
+}
+
+// EtcdMaintenance compacts the keyspace, defragments the members one by one and disarms the alarms.
+type EtcdMaintenance struct {
+	// the schedule in cron format
+	Schedule string `json:"schedule"`
+}
+
+// DefaultCertRenewBeforeDays is the default days before expiry the certificates are renewed.
+const DefaultCertRenewBeforeDays = 30
+
+// CertRotationPolicy renews the certificates of the masters one by one when any of them is about to expire.
+type CertRotationPolicy struct {
+	// renew the certificates N days before the first of them expires, defaults to 30
+	RenewBeforeDays int `json:"renewBeforeDays,omitempty" optional:"true"`
+	// the renewal only starts in the maintenance window, it starts at any time if the window is not set
+	MaintenanceWindow *MaintenanceWindow `json:"maintenanceWindow,omitempty" optional:"true"`
+}
+
+// RenewBefore returns how long before the expiry the certificates are renewed.
+func (p *CertRotationPolicy) RenewBefore() time.Duration {
+	days := p.RenewBeforeDays
+	if days <= 0 {
+		days = DefaultCertRenewBeforeDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// MaintenanceWindow is a daily time window, it spans midnight if the end is not after the start.
+type MaintenanceWindow struct {
+	// start time of the window in HH:MM
+	Start string `json:"start"`
+	// end time of the window in HH:MM
+	End string `json:"end"`
+	// IANA time zone name of the window, defaults to UTC
+	TimeZone string `json:"timeZone,omitempty" optional:"true"`
+}
+
+// Validate checks the time and time zone of the window.
+func (w *MaintenanceWindow) Validate() error {
+	_, _, _, err := w.parse()
+	return err
+}
+
+// Next returns t if t is in the window, otherwise the next time the window opens after t.
+func (w *MaintenanceWindow) Next(t time.Time) (time.Time, error) {
+	start, end, loc, err := w.parse()
+	if err != nil {
+		return time.Time{}, err
+	}
+	t = t.In(loc)
+	at := func(days int, clock time.Duration) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day()+days, int(clock/time.Hour), int(clock%time.Hour/time.Minute), 0, 0, loc)
+	}
+	now := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	if start < end {
+		if now >= start && now < end {
+			return t, nil
+		}
+	} else if now >= start || now < end {
+		// the window spans midnight
+		return t, nil
+	}
+	if now < start {
+		return at(0, start), nil
+	}
+	return at(1, start), nil
+}
+
+func (w *MaintenanceWindow) parse() (start, end time.Duration, loc *time.Location, err error) {
+	parseClock := func(s string) (time.Duration, error) {
+		c, err := time.Parse("15:04", s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid time %q of maintenance window, it must be in HH:MM", s)
+		}
+		return time.Duration(c.Hour())*time.Hour + time.Duration(c.Minute())*time.Minute, nil
+	}
+	if start, err = parseClock(w.Start); err != nil {
+		return
+	}
+	if end, err = parseClock(w.End); err != nil {
+		return
+	}
+	if start == end {
+		err = fmt.Errorf("the start and end of maintenance window must be different")
+		return
+	}
+	if loc, err = time.LoadLocation(w.TimeZone); err != nil {
+		err = fmt.Errorf("invalid time zone %q of maintenance window: %v", w.TimeZone, err)
+	}
+	return
 }
 
 type Kubelet struct {
@@ -28,6 +28,10 @@ type CronBackupSpec struct {
 	MaxBackupNum int `json:"maxBackupNum,omitempty"`
 	// specific run time
 	RunAt *metav1.Time `json:"runAt,omitempty"`
+	// verify each new backup by restoring it in a scratch directory
+	Verify bool `json:"verify,omitempty"`
+	// retention of the backups, replaces MaxBackupNum when set
+	RetentionPolicy *RetentionPolicy `json:"retentionPolicy,omitempty"`
 }
 
 // CronBackupStatus defines the status of cronBackup
@@ -19,6 +19,9 @@
 package v1
 
 import (
+	"net"
+	"strings"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -43,6 +46,29 @@ type DomainSpec struct {
 	Records map[string]Record `json:"records,omitempty"` // key: rr.domain value: record
 	// +optional
 	SyncCluster []string `json:"syncCluster,omitempty"`
+	// Forwarders resolve the names of the domain which have no record.
+	// +optional
+	Forwarders *Forwarders `json:"forwarders,omitempty"`
+}
+
+const (
+	ForwardPolicyRandom     = "random"
+	ForwardPolicyRoundRobin = "round_robin"
+	ForwardPolicySequential = "sequential"
+)
+
+// Forwarders are the upstream dns servers queries are forwarded to.
+type Forwarders struct {
+	// Upstreams are ip or ip:port of the dns servers, at most 15.
+	Upstreams []string `json:"upstreams"`
+	// Policy selects the upstream, one of random, round_robin and sequential.
+	// CoreDNS uses random if not set.
+	// +optional
+	Policy string `json:"policy,omitempty"`
+	// TLSServerName enables DNS over TLS to the upstreams, it verifies
+	// the certificate of the upstreams.
+	// +optional
+	TLSServerName string `json:"tlsServerName,omitempty"`
 }
 
 type DomainStatus struct {
@@ -61,14 +87,60 @@ type DomainList struct {
 }
 
 type Record struct {
-	Domain      string        `json:"domain,omitempty"` // domain e.g. baidu.com
-	RR          string        `json:"rr,omitempty"`     // resource record e.g. www
-	CreateTime  metav1.Time   `json:"createTime,omitempty"`
-	Description string        `json:"description,omitempty"`
+	Domain      string      `json:"domain,omitempty"` // domain e.g. baidu.com
+	RR          string      `json:"rr,omitempty"`     // resource record e.g. www
+	CreateTime  metav1.Time `json:"createTime,omitempty"`
+	Description string      `json:"description,omitempty"`
+	// TTL of the answers in seconds, DefaultRecordTTL if not set.
+	// +optional
+	TTL         int32         `json:"ttl,omitempty"`
 	ParseRecord []ParseRecord `json:"parseRecord,omitempty"`
 }
 
+const (
+	RecordTypeA     = "A"
+	RecordTypeAAAA  = "AAAA"
+	RecordTypeCNAME = "CNAME"
+	RecordTypeSRV   = "SRV"
+	RecordTypeTXT   = "TXT"
+
+	DefaultRecordTTL int32 = 60
+)
+
+// TTLSeconds returns the ttl of the record answers.
+func (r Record) TTLSeconds() int32 {
+	if r.TTL > 0 {
+		return r.TTL
+	}
+	return DefaultRecordTTL
+}
+
 type ParseRecord struct {
-	Type string `json:"type,omitempty"` // resolve record. A or AAAA
-	IP   string `json:"ip,omitempty"`   // ipv4 or ipv6
+	Type string `json:"type,omitempty"` // resolve record. A, AAAA, CNAME, SRV or TXT
+	IP   string `json:"ip,omitempty"`   // ipv4 of A or ipv6 of AAAA
+	// Target is the canonical name of CNAME or the target host of SRV.
+	// +optional
+	Target string `json:"target,omitempty"`
+	// Priority, Weight and Port of SRV.
+	// +optional
+	Priority uint16 `json:"priority,omitempty"`
+	// +optional
+	Weight uint16 `json:"weight,omitempty"`
+	// +optional
+	Port uint16 `json:"port,omitempty"`
+	// Text of TXT.
+	// +optional
+	Text string `json:"text,omitempty"`
+}
+
+// RecordType returns the upper case type, records stored before the type
+// was required are A or AAAA depending on the ip family.
+func (p ParseRecord) RecordType() string {
+	if p.Type != "" {
+		return strings.ToUpper(p.Type)
+	}
+	if ip := net.ParseIP(p.IP); ip != nil && ip.To4() == nil {
+		return RecordTypeAAAA
+	}
+	return RecordTypeA
 }
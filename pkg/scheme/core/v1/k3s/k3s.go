@@ -0,0 +1,254 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package k3s
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/downloader"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/cmdutil"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/fileutil"
+	tmplutil "github.com/kubeclipper/kubeclipper/pkg/utils/template"
+)
+
+const (
+	K3s = "k3s"
+
+	version  = "v1"
+	packages = "k3sPackage"
+	node     = "k3sNode"
+	token    = "k3sToken"
+
+	RoleServer = "server"
+	RoleAgent  = "agent"
+
+	ConfigDir       = "/etc/rancher/k3s"
+	DataDir         = "/var/lib/rancher/k3s"
+	AirgapImagesDir = "/var/lib/rancher/k3s/agent/images"
+	ServerTokenFile = "/var/lib/rancher/k3s/server/node-token"
+	SystemdDir      = "/etc/systemd/system"
+	BinaryPath      = "/usr/local/bin/k3s"
+)
+
+func init() {
+	if err := component.RegisterAgentStep(fmt.Sprintf(component.RegisterStepKeyFormat, packages, version, component.TypeStep), &Package{}); err != nil {
+		panic(err)
+	}
+	if err := component.RegisterAgentStep(fmt.Sprintf(component.RegisterStepKeyFormat, node, version, component.TypeStep), &Node{}); err != nil {
+		panic(err)
+	}
+	if err := component.RegisterAgentStep(fmt.Sprintf(component.RegisterStepKeyFormat, token, version, component.TypeStep), &Token{}); err != nil {
+		panic(err)
+	}
+}
+
+var (
+	_ component.StepRunnable = (*Package)(nil)
+	_ component.StepRunnable = (*Node)(nil)
+	_ component.StepRunnable = (*Token)(nil)
+)
+
+// Package installs the k3s binary, and the airgap images when there is no registry in offline mode.
+type Package struct {
+	Version       string `json:"version"`
+	Offline       bool   `json:"offline"`
+	LocalRegistry string `json:"localRegistry"`
+}
+
+// Node runs k3s server or agent as systemd service with the config file.
+type Node struct {
+	Role   string `json:"role"`
+	Config Config `json:"config"`
+	// Registry is the insecure registry pulled from by http.
+	Registry string `json:"registry,omitempty"`
+}
+
+// Config is the k3s config file, a node joins the cluster if Server is set,
+// and the token is received from the previous step.
+type Config struct {
+	Server                string   `json:"server,omitempty"`
+	Token                 string   `json:"token,omitempty"`
+	ClusterInit           bool     `json:"cluster-init,omitempty"`
+	ClusterCIDR           string   `json:"cluster-cidr,omitempty"`
+	ServiceCIDR           string   `json:"service-cidr,omitempty"`
+	ClusterDomain         string   `json:"cluster-domain,omitempty"`
+	FlannelBackend        string   `json:"flannel-backend,omitempty"`
+	TLSSAN                []string `json:"tls-san,omitempty"`
+	SystemDefaultRegistry string   `json:"system-default-registry,omitempty"`
+	Disable               []string `json:"disable,omitempty"`
+}
+
+// Token prints the token of the server for nodes joining.
+type Token struct{}
+
+func (stepper *Package) NewInstance() component.ObjectMeta {
+	return &Package{}
+}
+
+func (stepper *Package) Install(ctx context.Context, opts component.Options) ([]byte, error) {
+	instance, err := downloader.NewInstance(ctx, K3s, stepper.Version, runtime.GOARCH, !stepper.Offline, opts.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	if stepper.Offline && stepper.LocalRegistry == "" {
+		imageSrc, err := instance.DownloadImages()
+		if err != nil {
+			return nil, err
+		}
+		// k3s imports the image tarballs under the images dir when it starts.
+		if err = os.MkdirAll(AirgapImagesDir, 0755); err != nil {
+			return nil, err
+		}
+		if _, err = cmdutil.RunCmdWithContext(ctx, opts.DryRun, "cp", "-f", imageSrc, filepath.Join(AirgapImagesDir, "k3s-airgap-images.tar.gz")); err != nil {
+			return nil, err
+		}
+	}
+	// configs.tar.gz contains the k3s binary and the kubectl link
+	if _, err = instance.DownloadAndUnpackConfigs(); err != nil {
+		return nil, err
+	}
+	logger.Debug("k3s packages offline install successfully")
+	return nil, nil
+}
+
+func (stepper *Package) Uninstall(ctx context.Context, opts component.Options) ([]byte, error) {
+	instance, err := downloader.NewInstance(ctx, K3s, stepper.Version, runtime.GOARCH, !stepper.Offline, opts.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	if err = instance.RemoveAll(); err != nil {
+		logger.Warnf("remove k3s packages error: %s", err.Error())
+	}
+	return nil, nil
+}
+
+func (stepper *Node) NewInstance() component.ObjectMeta {
+	return &Node{}
+}
+
+func (stepper *Node) serviceName() string {
+	if stepper.Role == RoleAgent {
+		return "k3s-agent"
+	}
+	return "k3s"
+}
+
+func (stepper *Node) Install(ctx context.Context, opts component.Options) ([]byte, error) {
+	cfg := stepper.Config
+	if cfg.Server != "" {
+		v := component.GetExtraData(ctx)
+		if len(v) == 0 {
+			return nil, fmt.Errorf("no k3s token received")
+		}
+		cfg.Token = strings.TrimSpace(string(v))
+	}
+	if err := os.MkdirAll(ConfigDir, 0755); err != nil {
+		return nil, err
+	}
+	if err := fileutil.WriteFileWithContext(ctx, filepath.Join(ConfigDir, "config.yaml"), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600,
+		func(w io.Writer) error {
+			data, err := yaml.Marshal(cfg)
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(data)
+			return err
+		}, opts.DryRun); err != nil {
+		return nil, err
+	}
+	if stepper.Registry != "" {
+		if err := fileutil.WriteFileWithContext(ctx, filepath.Join(ConfigDir, "registries.yaml"), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644,
+			stepper.renderRegistriesTo, opts.DryRun); err != nil {
+			return nil, err
+		}
+	}
+	if err := fileutil.WriteFileWithContext(ctx, filepath.Join(SystemdDir, stepper.serviceName()+".service"), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644,
+		stepper.renderServiceTo, opts.DryRun); err != nil {
+		return nil, err
+	}
+	// the service of notify type is started after k3s is ready
+	if _, err := cmdutil.RunCmdWithContext(ctx, opts.DryRun, "bash", "-c",
+		fmt.Sprintf("systemctl daemon-reload && systemctl enable --now %s", stepper.serviceName())); err != nil {
+		return nil, err
+	}
+	if stepper.Role == RoleServer {
+		if _, err := cmdutil.RunCmdWithContext(ctx, opts.DryRun, "bash", "-c",
+			fmt.Sprintf("mkdir -p $HOME/.kube && cp -f %s $HOME/.kube/config", filepath.Join(ConfigDir, "k3s.yaml"))); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+func (stepper *Node) Uninstall(ctx context.Context, opts component.Options) ([]byte, error) {
+	unit := filepath.Join(SystemdDir, stepper.serviceName()+".service")
+	if _, err := cmdutil.RunCmdWithContext(ctx, opts.DryRun, "bash", "-c",
+		fmt.Sprintf("systemctl disable --now %s; rm -f %s; systemctl daemon-reload", stepper.serviceName(), unit)); err != nil {
+		logger.Warnf("stop %s service error: %s", stepper.serviceName(), err.Error())
+	}
+	// the containers are left running after the service stopped
+	if _, err := cmdutil.RunCmdWithContext(ctx, opts.DryRun, "bash", "-c", killAllScript); err != nil {
+		logger.Warnf("kill k3s containers error: %s", err.Error())
+	}
+	if _, err := cmdutil.RunCmdWithContext(ctx, opts.DryRun, "bash", "-c",
+		fmt.Sprintf("rm -rf %s %s /var/lib/kubelet /run/k3s /run/flannel /var/lib/cni /etc/cni/net.d $HOME/.kube/config", ConfigDir, DataDir)); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (stepper *Node) renderServiceTo(w io.Writer) error {
+	_, err := tmplutil.New().RenderTo(w, serviceTemplate, stepper)
+	return err
+}
+
+func (stepper *Node) renderRegistriesTo(w io.Writer) error {
+	_, err := tmplutil.New().RenderTo(w, registriesTemplate, stepper)
+	return err
+}
+
+func (stepper *Token) NewInstance() component.ObjectMeta {
+	return &Token{}
+}
+
+func (stepper *Token) Install(ctx context.Context, opts component.Options) ([]byte, error) {
+	if opts.DryRun {
+		return []byte("dry run token"), nil
+	}
+	data, err := os.ReadFile(ServerTokenFile)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.TrimSpace(data), nil
+}
+
+func (stepper *Token) Uninstall(ctx context.Context, opts component.Options) ([]byte, error) {
+	return nil, fmt.Errorf("k3s token no support uninstall")
+}
@@ -0,0 +1,437 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package k3s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	"github.com/kubeclipper/kubeclipper/pkg/component/utils"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1/k8s"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
+)
+
+func init() {
+	if err := component.RegisterClusterProvider(&Provider{}); err != nil {
+		panic(err)
+	}
+}
+
+var _ component.ClusterProvider = (*Provider)(nil)
+
+// Provider runs the cluster by k3s with the embedded etcd, containerd and flannel.
+type Provider struct{}
+
+func (p *Provider) Name() string {
+	return v1.ClusterK3s
+}
+
+func (p *Provider) Validate(c *v1.Cluster) error {
+	if len(c.Masters)%2 == 0 {
+		return fmt.Errorf("k3s cluster requires odd number of servers for the embedded etcd")
+	}
+	if !strings.Contains(c.KubernetesVersion, "+k3s") {
+		return fmt.Errorf("invalid k3s version %s, such as v1.24.8+k3s1", c.KubernetesVersion)
+	}
+	if c.CNI.Type != k8s.CniFlannel {
+		return fmt.Errorf("k3s cluster only supports the embedded flannel cni")
+	}
+	cni, err := k8s.LoadCNI(c.CNI.Type)
+	if err != nil {
+		return err
+	}
+	return cni.Validate(&c.CNI, &c.Networking)
+}
+
+func (p *Provider) InstallSteps(ctx context.Context, c *v1.Cluster) ([]v1.Step, error) {
+	metadata := component.GetExtraMetadata(ctx)
+	masters := utils.UnwrapNodeList(metadata.Masters)
+	workers := utils.UnwrapNodeList(metadata.Workers)
+
+	steps, err := packageSteps(c, c.KubernetesVersion, utils.UnwrapNodeList(metadata.GetAllNodes()), v1.ActionInstall)
+	if err != nil {
+		return nil, err
+	}
+	init := serverNode(c)
+	init.Config.ClusterInit = true
+	step, err := nodeStep("initServer", init, masters[:1], v1.ActionInstall)
+	if err != nil {
+		return nil, err
+	}
+	steps = append(steps, step)
+
+	join := serverNode(c)
+	join.Config.Server = serverURL(masters[0])
+	jSteps, err := joinSteps("joinServers", join, masters[0], masters[1:])
+	if err != nil {
+		return nil, err
+	}
+	steps = append(steps, jSteps...)
+	jSteps, err = joinSteps("joinAgents", agentNode(c, masters[0]), masters[0], workers)
+	if err != nil {
+		return nil, err
+	}
+	steps = append(steps, jSteps...)
+
+	patchSteps, err := k8s.PatchTaintAndLabelStep(c.Masters, c.Workers, &metadata)
+	if err != nil {
+		return nil, err
+	}
+	steps = append(steps, patchSteps...)
+
+	heal := k8s.Health{}
+	healSteps, err := heal.InitStepper().InstallSteps(masters[:1])
+	if err != nil {
+		return nil, err
+	}
+	steps = append(steps, healSteps...)
+
+	kt := k8s.KubectlTerminal{}
+	ktSteps, err := kt.InitStepper(c).InstallSteps(masters[:1])
+	if err != nil {
+		return nil, err
+	}
+	return append(steps, ktSteps...), nil
+}
+
+func (p *Provider) UninstallSteps(ctx context.Context, c *v1.Cluster) ([]v1.Step, error) {
+	metadata := component.GetExtraMetadata(ctx)
+	return removeSteps(c, utils.UnwrapNodeList(metadata.Masters), utils.UnwrapNodeList(metadata.Workers))
+}
+
+func (p *Provider) UpgradeSteps(ctx context.Context, c *v1.Cluster) ([]v1.Step, error) {
+	metadata := component.GetExtraMetadata(ctx)
+	if !strings.Contains(metadata.KubeVersion, "+k3s") {
+		return nil, fmt.Errorf("invalid k3s version %s, such as v1.24.8+k3s1", metadata.KubeVersion)
+	}
+	upgraded := c.DeepCopy()
+	upgraded.LocalRegistry = strutil.StringDefaultIfEmpty(c.LocalRegistry, metadata.LocalRegistry)
+	if metadata.Offline {
+		if upgraded.Annotations == nil {
+			upgraded.Annotations = map[string]string{}
+		}
+		upgraded.Annotations[common.AnnotationOffline] = ""
+	} else {
+		delete(upgraded.Annotations, common.AnnotationOffline)
+	}
+	steps, err := packageSteps(upgraded, metadata.KubeVersion, utils.UnwrapNodeList(metadata.GetAllNodes()), v1.ActionInstall)
+	if err != nil {
+		return nil, err
+	}
+	// servers are upgraded one by one before agents, k3s restarts with the new binary.
+	for _, n := range utils.UnwrapNodeList(metadata.Masters) {
+		steps = append(steps, restartStep(fmt.Sprintf("UpgradeServer-%s", n.Hostname), "k3s", n))
+	}
+	for _, n := range utils.UnwrapNodeList(metadata.Workers) {
+		steps = append(steps, restartStep(fmt.Sprintf("UpgradeAgent-%s", n.Hostname), "k3s-agent", n))
+	}
+	return steps, nil
+}
+
+func (p *Provider) AddNodeSteps(ctx context.Context, c *v1.Cluster, nodes []v1.StepNode, role string) ([]v1.Step, error) {
+	metadata := component.GetExtraMetadata(ctx)
+	masters := utils.UnwrapNodeList(metadata.Masters)
+	steps, err := packageSteps(c, c.KubernetesVersion, nodes, v1.ActionInstall)
+	if err != nil {
+		return nil, err
+	}
+	n := agentNode(c, masters[0])
+	if role == k8s.NodeRoleMaster {
+		n = serverNode(c)
+		n.Config.Server = serverURL(masters[0])
+	}
+	jSteps, err := joinSteps("joinNodes", n, masters[0], nodes)
+	if err != nil {
+		return nil, err
+	}
+	return append(steps, jSteps...), nil
+}
+
+// RemoveNodeSteps drains the nodes, removes the embedded etcd members of the servers and the node
+// objects through a remaining server, then uninstalls k3s on the nodes.
+func (p *Provider) RemoveNodeSteps(ctx context.Context, c *v1.Cluster, nodes []v1.StepNode, role string) ([]v1.Step, error) {
+	metadata := component.GetExtraMetadata(ctx)
+	host, err := remainingServer(utils.UnwrapNodeList(metadata.Masters), nodes)
+	if err != nil {
+		return nil, err
+	}
+	var steps []v1.Step
+	for _, n := range nodes {
+		d := &k8s.Drain{}
+		drainSteps, err := d.InitStepper(n.Hostname, []string{"--ignore-daemonsets", "--delete-emptydir-data"}).UninstallSteps([]v1.StepNode{host})
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, drainSteps...)
+		if role == k8s.NodeRoleMaster {
+			steps = append(steps, removeEtcdMemberStep(n.Hostname, host))
+		}
+		steps = append(steps, deleteNodeStep(n.Hostname, host))
+	}
+	if role == k8s.NodeRoleMaster {
+		rSteps, err := removeSteps(c, nodes, nil)
+		if err != nil {
+			return nil, err
+		}
+		return append(steps, rSteps...), nil
+	}
+	rSteps, err := removeSteps(c, nil, nodes)
+	if err != nil {
+		return nil, err
+	}
+	return append(steps, rSteps...), nil
+}
+
+// remainingServer returns the first server which is not removed, the cluster is operated through it.
+func remainingServer(masters, removed []v1.StepNode) (v1.StepNode, error) {
+	ids := make(map[string]struct{}, len(removed))
+	for _, n := range removed {
+		ids[n.ID] = struct{}{}
+	}
+	for _, m := range masters {
+		if _, ok := ids[m.ID]; !ok {
+			return m, nil
+		}
+	}
+	return v1.StepNode{}, fmt.Errorf("at least one k3s server must be left")
+}
+
+// removeEtcdMemberStep removes the embedded etcd member of the server, k3s removes the member of a node
+// annotated with etcd.k3s.cattle.io/remove and records it in etcd.k3s.cattle.io/removed-node-name.
+func removeEtcdMemberStep(name string, host v1.StepNode) v1.Step {
+	script := fmt.Sprintf(`kubectl annotate node %[1]s etcd.k3s.cattle.io/remove=true --overwrite || exit 1
+for i in $(seq 60); do
+  removed=$(kubectl get node %[1]s -o jsonpath='{.metadata.annotations.etcd\.k3s\.cattle\.io/removed-node-name}')
+  [ -n "$removed" ] && exit 0
+  sleep 5
+done
+echo "etcd member of %[1]s is not removed" >&2
+exit 1`, name)
+	return v1.Step{
+		ID:         strutil.GetUUID(),
+		Name:       fmt.Sprintf("removeEtcdMember-%s", name),
+		Timeout:    metav1.Duration{Duration: 6 * time.Minute},
+		ErrIgnore:  false,
+		RetryTimes: 1,
+		Nodes:      []v1.StepNode{host},
+		Action:     v1.ActionUninstall,
+		Commands: []v1.Command{
+			{
+				Type:         v1.CommandShell,
+				ShellCommand: []string{"/bin/bash", "-c", script},
+			},
+		},
+	}
+}
+
+func deleteNodeStep(name string, host v1.StepNode) v1.Step {
+	return v1.Step{
+		ID:         strutil.GetUUID(),
+		Name:       fmt.Sprintf("deleteNode-%s", name),
+		Timeout:    metav1.Duration{Duration: 1 * time.Minute},
+		ErrIgnore:  false,
+		RetryTimes: 1,
+		Nodes:      []v1.StepNode{host},
+		Action:     v1.ActionUninstall,
+		Commands: []v1.Command{
+			{
+				Type:         v1.CommandShell,
+				ShellCommand: []string{"kubectl", "delete", "node", name, "--ignore-not-found"},
+			},
+		},
+	}
+}
+
+func serverNode(c *v1.Cluster) *Node {
+	n := &Node{
+		Role: RoleServer,
+		Config: Config{
+			ClusterCIDR:           strings.Join(c.Networking.Pods.CIDRBlocks, ","),
+			ServiceCIDR:           strings.Join(c.Networking.Services.CIDRBlocks, ","),
+			ClusterDomain:         c.Networking.DNSDomain,
+			TLSSAN:                c.CertSANs,
+			SystemDefaultRegistry: c.LocalRegistry,
+			// the ingress and load balancer are installed as addons if required
+			Disable: []string{"traefik", "servicelb"},
+		},
+		Registry: c.LocalRegistry,
+	}
+	if c.CNI.Flannel != nil {
+		n.Config.FlannelBackend = c.CNI.Flannel.Backend
+	}
+	return n
+}
+
+func agentNode(c *v1.Cluster, server v1.StepNode) *Node {
+	return &Node{
+		Role:     RoleAgent,
+		Config:   Config{Server: serverURL(server)},
+		Registry: c.LocalRegistry,
+	}
+}
+
+func serverURL(server v1.StepNode) string {
+	return fmt.Sprintf("https://%s:6443", server.IPv4)
+}
+
+func packageSteps(c *v1.Cluster, version string, nodes []v1.StepNode, action v1.StepAction) ([]v1.Step, error) {
+	bytes, err := json.Marshal(&Package{
+		Version:       version,
+		Offline:       c.Offline(),
+		LocalRegistry: c.LocalRegistry,
+	})
+	if err != nil {
+		return nil, err
+	}
+	name := "installK3sPackage"
+	if action == v1.ActionUninstall {
+		name = "removeK3sPackage"
+	}
+	return []v1.Step{
+		{
+			ID:         strutil.GetUUID(),
+			Name:       name,
+			Timeout:    metav1.Duration{Duration: 10 * time.Minute},
+			ErrIgnore:  action == v1.ActionUninstall,
+			RetryTimes: 1,
+			Nodes:      nodes,
+			Action:     action,
+			Commands: []v1.Command{
+				{
+					Type:          v1.CommandCustom,
+					Identity:      fmt.Sprintf(component.RegisterStepKeyFormat, packages, version, component.TypeStep),
+					CustomCommand: bytes,
+				},
+			},
+		},
+	}, nil
+}
+
+func nodeStep(name string, n *Node, nodes []v1.StepNode, action v1.StepAction) (v1.Step, error) {
+	bytes, err := json.Marshal(n)
+	if err != nil {
+		return v1.Step{}, err
+	}
+	return v1.Step{
+		ID:         strutil.GetUUID(),
+		Name:       name,
+		Timeout:    metav1.Duration{Duration: 10 * time.Minute},
+		ErrIgnore:  action == v1.ActionUninstall,
+		RetryTimes: 1,
+		Nodes:      nodes,
+		Action:     action,
+		Commands: []v1.Command{
+			{
+				Type:          v1.CommandCustom,
+				Identity:      fmt.Sprintf(component.RegisterStepKeyFormat, node, version, component.TypeStep),
+				CustomCommand: bytes,
+			},
+		},
+	}, nil
+}
+
+// joinSteps reads the token on the server, which is passed to the nodes joining in the next step.
+func joinSteps(name string, n *Node, server v1.StepNode, nodes []v1.StepNode) ([]v1.Step, error) {
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+	bytes, err := json.Marshal(&Token{})
+	if err != nil {
+		return nil, err
+	}
+	step, err := nodeStep(name, n, nodes, v1.ActionInstall)
+	if err != nil {
+		return nil, err
+	}
+	return []v1.Step{
+		{
+			ID:         strutil.GetUUID(),
+			Name:       "getK3sToken",
+			Timeout:    metav1.Duration{Duration: 10 * time.Second},
+			ErrIgnore:  false,
+			RetryTimes: 1,
+			Nodes:      []v1.StepNode{server},
+			Action:     v1.ActionInstall,
+			Commands: []v1.Command{
+				{
+					Type:          v1.CommandCustom,
+					Identity:      fmt.Sprintf(component.RegisterStepKeyFormat, token, version, component.TypeStep),
+					CustomCommand: bytes,
+				},
+			},
+		},
+		step,
+	}, nil
+}
+
+// removeSteps stops k3s and cleans the nodes, agents are removed before servers.
+func removeSteps(c *v1.Cluster, servers, agents []v1.StepNode) ([]v1.Step, error) {
+	var steps []v1.Step
+	if len(agents) > 0 {
+		step, err := nodeStep("removeAgents", &Node{Role: RoleAgent}, agents, v1.ActionUninstall)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+	if len(servers) > 0 {
+		step, err := nodeStep("removeServers", &Node{Role: RoleServer}, servers, v1.ActionUninstall)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+	nodes := append(append([]v1.StepNode{}, agents...), servers...)
+	cniSteps, err := k8s.CleanCNI(&c.CNI, nodes)
+	if err != nil {
+		return nil, err
+	}
+	steps = append(steps, cniSteps...)
+	pSteps, err := packageSteps(c, c.KubernetesVersion, nodes, v1.ActionUninstall)
+	if err != nil {
+		return nil, err
+	}
+	return append(steps, pSteps...), nil
+}
+
+func restartStep(name, service string, n v1.StepNode) v1.Step {
+	return v1.Step{
+		ID:         strutil.GetUUID(),
+		Name:       name,
+		Timeout:    metav1.Duration{Duration: 10 * time.Minute},
+		ErrIgnore:  false,
+		RetryTimes: 0,
+		Nodes:      []v1.StepNode{n},
+		Action:     v1.ActionInstall,
+		Commands: []v1.Command{
+			{
+				Type:         v1.CommandShell,
+				ShellCommand: []string{"systemctl", "restart", service},
+			},
+		},
+	}
+}
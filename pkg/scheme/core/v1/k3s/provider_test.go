@@ -0,0 +1,226 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package k3s
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1/k8s"
+)
+
+func newCluster(masters, workers int) *v1.Cluster {
+	c := &v1.Cluster{
+		KubernetesVersion: "v1.24.8+k3s1",
+		CertSANs:          []string{"k3s.example.com"},
+		Networking: v1.Networking{
+			IPFamily:  v1.IPFamilyIPv4,
+			Pods:      v1.NetworkRanges{CIDRBlocks: []string{"172.25.0.0/16"}},
+			Services:  v1.NetworkRanges{CIDRBlocks: []string{"10.96.0.0/16"}},
+			DNSDomain: "cluster.local",
+		},
+		CNI: v1.CNI{Type: k8s.CniFlannel, Flannel: &v1.Flannel{Backend: k8s.FlannelBackendVXLAN}},
+	}
+	for i := 0; i < masters; i++ {
+		c.Masters = append(c.Masters, v1.WorkerNode{ID: "m" + string(rune('0'+i))})
+	}
+	for i := 0; i < workers; i++ {
+		c.Workers = append(c.Workers, v1.WorkerNode{ID: "w" + string(rune('0'+i))})
+	}
+	return c
+}
+
+func newMetadata(c *v1.Cluster) component.ExtraMetadata {
+	meta := component.ExtraMetadata{ClusterName: "test", KubeVersion: c.KubernetesVersion}
+	for _, m := range c.Masters {
+		meta.Masters = append(meta.Masters, component.Node{ID: m.ID, IPv4: "192.168.10." + m.ID[1:], Hostname: m.ID})
+	}
+	for _, w := range c.Workers {
+		meta.Workers = append(meta.Workers, component.Node{ID: w.ID, IPv4: "192.168.20." + w.ID[1:], Hostname: w.ID})
+	}
+	return meta
+}
+
+func TestProvider_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(c *v1.Cluster)
+		wantErr bool
+	}{
+		{
+			name:   "valid",
+			modify: func(c *v1.Cluster) {},
+		},
+		{
+			name:    "even number of servers",
+			modify:  func(c *v1.Cluster) { c.Masters = append(c.Masters, v1.WorkerNode{ID: "m1"}) },
+			wantErr: true,
+		},
+		{
+			name:    "kubernetes version",
+			modify:  func(c *v1.Cluster) { c.KubernetesVersion = "v1.24.8" },
+			wantErr: true,
+		},
+		{
+			name: "calico cni",
+			modify: func(c *v1.Cluster) {
+				c.CNI = v1.CNI{Type: k8s.CniCalico, Calico: &v1.Calico{Mode: k8s.CalicoNetworkVXLANAll}}
+			},
+			wantErr: true,
+		},
+		{
+			name:    "flannel without config",
+			modify:  func(c *v1.Cluster) { c.CNI.Flannel = nil },
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCluster(1, 1)
+			tt.modify(c)
+			if err := (&Provider{}).Validate(c); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProvider_InstallSteps(t *testing.T) {
+	tests := []struct {
+		name      string
+		masters   int
+		workers   int
+		wantSteps []string
+	}{
+		{
+			name:      "single server",
+			masters:   1,
+			wantSteps: []string{"installK3sPackage", "initServer"},
+		},
+		{
+			name:      "servers and agents",
+			masters:   3,
+			workers:   2,
+			wantSteps: []string{"installK3sPackage", "initServer", "getK3sToken", "joinServers", "getK3sToken", "joinAgents"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCluster(tt.masters, tt.workers)
+			ctx := component.WithExtraMetadata(context.TODO(), newMetadata(c))
+			steps, err := (&Provider{}).InstallSteps(ctx, c)
+			if err != nil {
+				t.Fatalf("InstallSteps() error = %v", err)
+			}
+			if len(steps) < len(tt.wantSteps) {
+				t.Fatalf("InstallSteps() got %d steps, want at least %d", len(steps), len(tt.wantSteps))
+			}
+			for i, name := range tt.wantSteps {
+				if steps[i].Name != name {
+					t.Errorf("InstallSteps() step %d = %s, want %s", i, steps[i].Name, name)
+				}
+			}
+			n := &Node{}
+			if err := json.Unmarshal(steps[1].Commands[0].CustomCommand, n); err != nil {
+				t.Fatal(err)
+			}
+			if n.Role != RoleServer || !n.Config.ClusterInit || n.Config.ClusterCIDR != "172.25.0.0/16" ||
+				n.Config.FlannelBackend != k8s.FlannelBackendVXLAN {
+				t.Errorf("InstallSteps() unexpected init server %+v", n)
+			}
+			if tt.workers == 0 {
+				return
+			}
+			agent := &Node{}
+			if err := json.Unmarshal(steps[5].Commands[0].CustomCommand, agent); err != nil {
+				t.Fatal(err)
+			}
+			if agent.Role != RoleAgent || agent.Config.Server != "https://192.168.10.0:6443" || len(steps[5].Nodes) != tt.workers {
+				t.Errorf("InstallSteps() unexpected agent %+v", agent)
+			}
+		})
+	}
+}
+
+func TestProvider_RemoveNodeSteps(t *testing.T) {
+	tests := []struct {
+		name      string
+		role      string
+		remove    []string
+		wantHost  string
+		wantSteps []string
+		wantErr   bool
+	}{
+		{
+			name:      "remove agent",
+			role:      k8s.NodeRoleWorker,
+			remove:    []string{"w1"},
+			wantHost:  "m0",
+			wantSteps: []string{"drainNode", "deleteNode-w1", "removeAgents"},
+		},
+		{
+			name:      "remove first server",
+			role:      k8s.NodeRoleMaster,
+			remove:    []string{"m0"},
+			wantHost:  "m1",
+			wantSteps: []string{"drainNode", "removeEtcdMember-m0", "deleteNode-m0", "removeServers"},
+		},
+		{
+			name:    "remove every server",
+			role:    k8s.NodeRoleMaster,
+			remove:  []string{"m0", "m1", "m2"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCluster(3, 2)
+			meta := newMetadata(c)
+			var nodes []v1.StepNode
+			for _, n := range append(meta.Masters, meta.Workers...) {
+				for _, id := range tt.remove {
+					if n.ID == id {
+						nodes = append(nodes, v1.StepNode{ID: n.ID, IPv4: n.IPv4, Hostname: n.Hostname})
+					}
+				}
+			}
+			steps, err := (&Provider{}).RemoveNodeSteps(component.WithExtraMetadata(context.TODO(), meta), c, nodes, tt.role)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RemoveNodeSteps() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(steps) < len(tt.wantSteps) {
+				t.Fatalf("RemoveNodeSteps() got %d steps, want at least %d", len(steps), len(tt.wantSteps))
+			}
+			for i, name := range tt.wantSteps {
+				if steps[i].Name != name {
+					t.Errorf("RemoveNodeSteps() step %d = %s, want %s", i, steps[i].Name, name)
+				}
+				if i < len(tt.wantSteps)-1 && steps[i].Nodes[0].ID != tt.wantHost {
+					t.Errorf("RemoveNodeSteps() step %s runs on %s, want %s", name, steps[i].Nodes[0].ID, tt.wantHost)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package k3s
+
+// https://github.com/k3s-io/k3s/blob/master/install.sh
+const serviceTemplate = `[Unit]
+Description=Lightweight Kubernetes
+Documentation=https://k3s.io
+Wants=network-online.target
+After=network-online.target
+
+[Install]
+WantedBy=multi-user.target
+
+[Service]
+Type={{if eq .Role "agent"}}exec{{else}}notify{{end}}
+KillMode=process
+Delegate=yes
+LimitNOFILE=1048576
+LimitNPROC=infinity
+LimitCORE=infinity
+TasksMax=infinity
+TimeoutStartSec=0
+Restart=always
+RestartSec=5s
+ExecStartPre=-/sbin/modprobe br_netfilter
+ExecStartPre=-/sbin/modprobe overlay
+ExecStart=/usr/local/bin/k3s {{.Role}}
+`
+
+const registriesTemplate = `mirrors:
+  "{{.Registry}}":
+    endpoint:
+      - "http://{{.Registry}}"
+`
+
+// killAllScript stops the containers and unmounts the volumes left by k3s, like k3s-killall.sh does.
+const killAllScript = `
+for pid in $(ps -e -o pid= -o args= | grep -E '/var/lib/rancher/k3s/data/[^/]*/bin/containerd-shim' | grep -v grep | awk '{print $1}'); do
+  kill -9 $pid
+done
+for m in $(awk '{print $2}' /proc/self/mounts | grep -E '^(/run/k3s|/var/lib/rancher/k3s|/var/lib/kubelet/pods|/run/netns/cni-)' | sort -r); do
+  umount -f $m
+done
+`
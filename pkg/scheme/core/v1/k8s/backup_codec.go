@@ -0,0 +1,106 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package k8s
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	bs "github.com/kubeclipper/kubeclipper/pkg/simple/backupstore"
+)
+
+// suffix of the compressed and encrypted backup file on the node
+const encodedFileSuffix = ".enc"
+
+// NewBackupCodec returns the codec of the backup saved in the backup point, it is nil when the backup
+// is neither compressed nor encrypted. A new backup follows the configuration of the backup point,
+// while an existing backup is decoded by the algorithm recorded in its status.
+func NewBackupCodec(bp *v1.BackupPoint, b *v1.Backup) (*bs.Codec, error) {
+	codec := &bs.Codec{}
+	if bp.Encryption != nil {
+		codec.KeyFile = bp.Encryption.KeyFile
+	}
+	if b == nil || b.Status.BackupFileMD5 == "" {
+		codec.Compression = bp.Compression
+		if bp.Encryption != nil {
+			codec.Encryption = bp.Encryption.Algorithm
+		}
+	} else if err := codec.SetAlgorithm(b.Status.Algorithm); err != nil {
+		return nil, err
+	}
+	if codec.Algorithm() == "" {
+		return nil, nil
+	}
+	if err := codec.Validate(); err != nil {
+		return nil, err
+	}
+	return codec, nil
+}
+
+// decodeBackupFile decrypts and decompresses the backup file src into dst.
+func decodeBackupFile(codec *bs.Codec, src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	r, err := codec.Decode(in)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	if _, err = io.Copy(out, r); err != nil {
+		return err
+	}
+	return out.Sync()
+}
+
+// checkBackupFile checks the size, md5 and sha256 checksum of the backup file,
+// the checksum is not checked when it is empty, which is the case of the backups without it recorded.
+func checkBackupFile(path string, size int64, md5Value, checksum string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	md5Hash, sha256Hash := md5.New(), sha256.New()
+	n, err := io.Copy(io.MultiWriter(md5Hash, sha256Hash), f)
+	if err != nil {
+		return err
+	}
+	if n != size {
+		return fmt.Errorf("download backup file size %d is different from backup file size %d", n, size)
+	}
+	if v := fmt.Sprintf("%x", md5Hash.Sum(nil)); v != md5Value {
+		return fmt.Errorf("download backup file md5 value %s is different from backup file md5 value %s", v, md5Value)
+	}
+	if v := fmt.Sprintf("sha256:%x", sha256Hash.Sum(nil)); checksum != "" && v != checksum {
+		return fmt.Errorf("download backup file checksum %s is different from backup file checksum %s", v, checksum)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package k8s
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	bs "github.com/kubeclipper/kubeclipper/pkg/simple/backupstore"
+)
+
+func TestNewBackupCodec(t *testing.T) {
+	encrypted := &v1.BackupPoint{
+		Compression: bs.CompressionZstd,
+		Encryption:  &v1.BackupEncryption{Algorithm: bs.EncryptionAESGCM, KeyFile: "/etc/kubeclipper/backup.key"},
+	}
+	existing := &v1.Backup{}
+	existing.Status.BackupFileMD5 = "e5d2e5e0a3d8a6f2a3b1c0d9e8f7a6b5"
+	gzipped := existing.DeepCopy()
+	gzipped.Status.Algorithm = bs.CompressionGzip
+	tests := []struct {
+		name          string
+		bp            *v1.BackupPoint
+		b             *v1.Backup
+		wantAlgorithm string
+		wantErr       bool
+	}{
+		{
+			name: "plain backup point",
+			bp:   &v1.BackupPoint{},
+			b:    &v1.Backup{},
+		},
+		{
+			name:          "new backup follows the backup point",
+			bp:            encrypted,
+			b:             &v1.Backup{},
+			wantAlgorithm: "zstd+aes-256-gcm",
+		},
+		{
+			name: "existing plain backup",
+			bp:   encrypted,
+			b:    existing,
+		},
+		{
+			name:          "existing backup follows its status",
+			bp:            encrypted,
+			b:             gzipped,
+			wantAlgorithm: bs.CompressionGzip,
+		},
+		{
+			name: "missing key file",
+			bp: &v1.BackupPoint{
+				Encryption: &v1.BackupEncryption{Algorithm: bs.EncryptionAESGCM},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported compression",
+			bp:      &v1.BackupPoint{Compression: "lz4"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewBackupCodec(tt.bp, tt.b)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewBackupCodec() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.Algorithm() != tt.wantAlgorithm {
+				t.Errorf("NewBackupCodec() algorithm = %v, want %v", got.Algorithm(), tt.wantAlgorithm)
+			}
+			if tt.wantAlgorithm == "" && got != nil {
+				t.Errorf("NewBackupCodec() = %v, want nil", got)
+			}
+		})
+	}
+}
+
+func TestBackupCodecPayloads(t *testing.T) {
+	codec := &bs.Codec{Encryption: bs.EncryptionAESGCM, Passphrase: "secret-passphrase", KeyFile: "/etc/kubeclipper/backup.key"}
+	payloads := map[string]interface{}{
+		"ActBackup":    &ActBackup{Codec: codec},
+		"Recovery":     &Recovery{Codec: codec},
+		"VerifyBackup": &VerifyBackup{Codec: codec},
+	}
+	for name, payload := range payloads {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			t.Fatalf("marshal %s error = %v", name, err)
+		}
+		if strings.Contains(string(data), codec.Passphrase) {
+			t.Errorf("%s payload contains the passphrase: %s", name, data)
+		}
+		if !strings.Contains(string(data), codec.KeyFile) {
+			t.Errorf("%s payload does not contain the key file: %s", name, data)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package k8s
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	bs "github.com/kubeclipper/kubeclipper/pkg/simple/backupstore"
+)
+
+// NewBackupStore returns the store of the backup point.
+func NewBackupStore(bp *v1.BackupPoint) (bs.BackupStore, error) {
+	switch bp.StorageType {
+	case bs.FSStorage:
+		if bp.FsConfig == nil {
+			return nil, fmt.Errorf("fs config of the backup point is empty")
+		}
+		store := &bs.FilesystemStore{
+			RootDir: bp.FsConfig.BackupRootDir,
+		}
+		return store.Create()
+	case bs.S3Storage:
+		if bp.S3Config == nil {
+			return nil, fmt.Errorf("s3 config of the backup point is empty")
+		}
+		store := &bs.ObjectStore{
+			Bucket:          bp.S3Config.Bucket,
+			Endpoint:        bp.S3Config.Endpoint,
+			AccessKeyID:     bp.S3Config.AccessKeyID,
+			AccessKeySecret: bp.S3Config.AccessKeySecret,
+		}
+		return store.Create()
+	case bs.SFTPStorage:
+		return newSftpStore(bp.SftpConfig)
+	case bs.WebDAVStorage:
+		return newWebDAVStore(bp.WebDAVConfig)
+	}
+	return nil, fmt.Errorf("unsupported storage type %q", bp.StorageType)
+}
+
+// NewBackupMetadata returns the metadata saved next to the backup file,
+// the file information is filled in when the file is saved.
+func NewBackupMetadata(b *v1.Backup) *bs.Metadata {
+	return &bs.Metadata{
+		Name:              b.Name,
+		ClusterName:       b.Labels[common.LabelClusterName],
+		KubernetesVersion: b.Status.KubernetesVersion,
+		FileName:          b.Status.FileName,
+		ClusterNodes:      b.ClusterNodes,
+	}
+}
+
+// BackupFromMetadata returns the available backup described by the metadata read from the backup point.
+func BackupFromMetadata(m *bs.Metadata, backupPoint string) *v1.Backup {
+	name := m.Name
+	if name == "" {
+		name = m.FileName
+	}
+	b := &v1.Backup{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Backup",
+			APIVersion: v1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				common.LabelClusterName: m.ClusterName,
+			},
+		},
+		Status: v1.BackupStatus{
+			KubernetesVersion:   m.KubernetesVersion,
+			FileName:            m.FileName,
+			BackupFileSize:      m.Size,
+			BackupFileMD5:       m.MD5,
+			ClusterBackupStatus: v1.ClusterBackupAvailable,
+			Algorithm:           m.Algorithm,
+			Checksum:            m.Checksum,
+		},
+		ClusterNodes:    m.ClusterNodes,
+		BackupPointName: backupPoint,
+	}
+	if b.ClusterNodes == nil {
+		b.ClusterNodes = map[string]string{}
+	}
+	return b
+}
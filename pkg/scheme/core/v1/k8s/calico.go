@@ -0,0 +1,138 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
+)
+
+func init() {
+	if err := RegisterCNI(&calico{}); err != nil {
+		panic(err)
+	}
+}
+
+var _ CNI = (*calico)(nil)
+
+type calico struct{}
+
+func (c *calico) Type() string {
+	return CniCalico
+}
+
+func (c *calico) Validate(cni *v1.CNI, networking *v1.Networking) error {
+	if cni.Calico == nil {
+		return fmt.Errorf("calico config is required")
+	}
+	return validatePodCIDR(CniCalico, networking)
+}
+
+func (c *calico) Template(version string) (string, error) {
+	switch version {
+	case "v3.11.2":
+		return calicoV3112, nil
+	case "v3.16.10":
+		return calicoV31610, nil
+	case "v3.21.2":
+		return calicoV3212, nil
+	case "v3.22.4":
+		return calicoV3224, nil
+	}
+	return "", fmt.Errorf("calico no support %s version", version)
+}
+
+func (c *calico) AgentDaemonSet() (namespace, name, selector string) {
+	return "kube-system", "calico-node", "k8s-app=calico-node"
+}
+
+func (c *calico) InstallSteps(info *CNIInfo, allNodes, nodes []v1.StepNode, onlyLoad bool) ([]v1.Step, error) {
+	return manifestInstallSteps(info, allNodes, nodes, onlyLoad)
+}
+
+func (c *calico) UpgradeSteps(info *CNIInfo, allNodes, nodes []v1.StepNode) ([]v1.Step, error) {
+	return manifestUpgradeSteps(c, info, allNodes, nodes)
+}
+
+func (c *calico) UninstallSteps(info *CNIInfo, nodes []v1.StepNode) ([]v1.Step, error) {
+	return manifestUninstallSteps(c, info, nodes)
+}
+
+func (c *calico) ImageLoaderSteps(info *CNIInfo, nodes []v1.StepNode) ([]v1.Step, error) {
+	return manifestImageLoaderSteps(info, nodes)
+}
+
+func (c *calico) LoadImages(ctx context.Context, info *CNIInfo, dryRun bool) error {
+	return manifestLoadImages(ctx, info, dryRun)
+}
+
+func (c *calico) RemoveImages(ctx context.Context, info *CNIInfo, dryRun bool) error {
+	return manifestRemoveImages(ctx, info, dryRun)
+}
+
+func (c *calico) CleanSteps(cni *v1.CNI, nodes []v1.StepNode) []v1.Step {
+	return ClearCalico(cni.Calico, nodes)
+}
+
+func ClearCalico(calico *v1.Calico, nodes []v1.StepNode) []v1.Step {
+	var steps []v1.Step
+
+	switch calico.Mode {
+	case CalicoNetworkIPIPAll, CalicoNetworkIPIPSubnet:
+		steps = append(steps, v1.Step{
+			ID:         strutil.GetUUID(),
+			Name:       "removeTunl",
+			Timeout:    metav1.Duration{Duration: 5 * time.Second},
+			ErrIgnore:  true,
+			Nodes:      nodes,
+			Action:     v1.ActionUninstall,
+			RetryTimes: 1,
+			Commands: []v1.Command{
+				{
+					Type:         v1.CommandShell,
+					ShellCommand: []string{"modprobe", "-r", "ipip"},
+				},
+			},
+		})
+	case CalicoNetworkVXLANAll, CalicoNetworkVXLANSubnet:
+		steps = append(steps, v1.Step{
+			ID:         strutil.GetUUID(),
+			Name:       "removeVtep",
+			Timeout:    metav1.Duration{Duration: 5 * time.Second},
+			ErrIgnore:  true,
+			Nodes:      nodes,
+			Action:     v1.ActionUninstall,
+			RetryTimes: 1,
+			Commands: []v1.Command{
+				{
+					Type:         v1.CommandShell,
+					ShellCommand: []string{"ip", "link", "delete", "vxlan.calico"},
+				},
+			},
+		})
+	}
+
+	return steps
+}
@@ -0,0 +1,120 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
+)
+
+func init() {
+	if err := RegisterCNI(&cilium{}); err != nil {
+		panic(err)
+	}
+}
+
+var _ CNI = (*cilium)(nil)
+
+type cilium struct{}
+
+func (c *cilium) Type() string {
+	return CniCilium
+}
+
+func (c *cilium) Validate(cni *v1.CNI, networking *v1.Networking) error {
+	if cni.Cilium == nil {
+		return fmt.Errorf("cilium config is required")
+	}
+	switch cni.Cilium.TunnelMode {
+	case CiliumTunnelVXLAN, CiliumTunnelGeneve, CiliumTunnelDisabled:
+	default:
+		return fmt.Errorf("unsupported cilium tunnel mode: %s", cni.Cilium.TunnelMode)
+	}
+	switch cni.Cilium.KubeProxyReplacement {
+	case "", CiliumKubeProxyReplacementDisabled, CiliumKubeProxyReplacementPartial:
+	default:
+		return fmt.Errorf("unsupported cilium kube-proxy replacement: %s", cni.Cilium.KubeProxyReplacement)
+	}
+	return validatePodCIDR(CniCilium, networking)
+}
+
+func (c *cilium) Template(version string) (string, error) {
+	switch version {
+	case "v1.12.4":
+		return ciliumV1124, nil
+	}
+	return "", fmt.Errorf("cilium no support %s version", version)
+}
+
+func (c *cilium) AgentDaemonSet() (namespace, name, selector string) {
+	return "kube-system", "cilium", "k8s-app=cilium"
+}
+
+func (c *cilium) InstallSteps(info *CNIInfo, allNodes, nodes []v1.StepNode, onlyLoad bool) ([]v1.Step, error) {
+	return manifestInstallSteps(info, allNodes, nodes, onlyLoad)
+}
+
+func (c *cilium) UpgradeSteps(info *CNIInfo, allNodes, nodes []v1.StepNode) ([]v1.Step, error) {
+	return manifestUpgradeSteps(c, info, allNodes, nodes)
+}
+
+func (c *cilium) UninstallSteps(info *CNIInfo, nodes []v1.StepNode) ([]v1.Step, error) {
+	return manifestUninstallSteps(c, info, nodes)
+}
+
+func (c *cilium) ImageLoaderSteps(info *CNIInfo, nodes []v1.StepNode) ([]v1.Step, error) {
+	return manifestImageLoaderSteps(info, nodes)
+}
+
+func (c *cilium) LoadImages(ctx context.Context, info *CNIInfo, dryRun bool) error {
+	return manifestLoadImages(ctx, info, dryRun)
+}
+
+func (c *cilium) RemoveImages(ctx context.Context, info *CNIInfo, dryRun bool) error {
+	return manifestRemoveImages(ctx, info, dryRun)
+}
+
+func (c *cilium) CleanSteps(cni *v1.CNI, nodes []v1.StepNode) []v1.Step {
+	// cilium keeps its eBPF programs and maps under the bpf filesystem after the agent is gone.
+	script := "ip link delete cilium_host; ip link delete cilium_net; ip link delete cilium_vxlan; ip link delete cilium_geneve; " +
+		"rm -rf /sys/fs/bpf/tc /sys/fs/bpf/cilium /var/run/cilium"
+	return []v1.Step{
+		{
+			ID:         strutil.GetUUID(),
+			Name:       "removeCiliumLinks",
+			Timeout:    metav1.Duration{Duration: 10 * time.Second},
+			ErrIgnore:  true,
+			Nodes:      nodes,
+			Action:     v1.ActionUninstall,
+			RetryTimes: 1,
+			Commands: []v1.Command{
+				{
+					Type:         v1.CommandShell,
+					ShellCommand: []string{"bash", "-c", script},
+				},
+			},
+		},
+	}
+}
@@ -20,10 +20,9 @@ package k8s
 
 import (
 	"context"
-	"crypto/md5"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -81,7 +80,9 @@ type Upgrade struct {
 	Offline       bool           `json:"offline"`
 	Version       string         `json:"version"`
 	LocalRegistry string         `json:"localRegistry"`
-	installSteps  []v1.Step
+	// CNI is upgraded after all nodes, it is nil if the cni version is unchanged.
+	CNI          *CNIInfo `json:"cni,omitempty"`
+	installSteps []v1.Step
 }
 
 type UpgradePackage struct {
@@ -99,6 +100,13 @@ type ActBackup struct {
 	AccessKeySecret    string
 	Region             string
 	SSL                bool
+	// config of the sftp and webdav backup points
+	SftpConfig   *v1.SftpConfig   `json:",omitempty"`
+	WebDAVConfig *v1.WebDAVConfig `json:",omitempty"`
+	// compresses and encrypts the backup file, the file is saved as it is when nil
+	Codec *bs.Codec `json:",omitempty"`
+	// saved next to the backup file, so the backup can be imported by scanning the backup point
+	Metadata *bs.Metadata `json:",omitempty"`
 
 	installSteps   []v1.Step
 	uninstallSteps []v1.Step
@@ -107,6 +115,8 @@ type ActBackup struct {
 type CheckFile struct {
 	BackupFileSize int64
 	BackupFileMD5  string
+	Algorithm      string `json:",omitempty"`
+	Checksum       string `json:",omitempty"`
 }
 
 type FileDir struct {
@@ -129,8 +139,14 @@ type Recovery struct {
 	AccessKeySecret    string
 	Region             string
 	SSL                bool
+	// config of the sftp and webdav backup points
+	SftpConfig         *v1.SftpConfig   `json:",omitempty"`
+	WebDAVConfig       *v1.WebDAVConfig `json:",omitempty"`
 	BackupFileSize     int64
 	BackupFileMD5      string
+	BackupFileChecksum string
+	// decrypts and decompresses the downloaded backup file
+	Codec *bs.Codec `json:",omitempty"`
 	FileDir
 
 	AfterRecovery AfterRecovery
@@ -138,7 +154,10 @@ type Recovery struct {
 	installSteps []v1.Step
 }
 
-type AfterRecovery struct{}
+type AfterRecovery struct {
+	// CNI is the cni type of the cluster, whose daemonset is restarted after recovery.
+	CNI string
+}
 
 func (stepper *Upgrade) InitStepper(metadata *component.ExtraMetadata, c *v1.Cluster) {
 	apiServerDomain := APIServerDomainPrefix +
@@ -160,6 +179,13 @@ func (stepper *Upgrade) InitStepper(metadata *component.ExtraMetadata, c *v1.Clu
 	stepper.Offline = metadata.Offline
 	stepper.Version = metadata.KubeVersion
 	stepper.LocalRegistry = metadata.LocalRegistry
+	if metadata.CNIVersion != "" && metadata.CNIVersion != c.CNI.Version {
+		cni := c.CNI.DeepCopy()
+		cni.Version = metadata.CNIVersion
+		cni.Offline = metadata.Offline
+		cni.LocalRegistry = strutil.StringDefaultIfEmpty(cni.LocalRegistry, metadata.LocalRegistry)
+		stepper.CNI = (&CNIInfo{}).InitStepper(cni, &c.Networking)
+	}
 }
 
 func (stepper *Upgrade) Validate() error {
@@ -381,6 +407,14 @@ systemctl daemon-reload && systemctl restart kubelet`},
 				RetryTimes: 0,
 			}}...)
 	}
+
+	if stepper.CNI != nil {
+		steps, err := stepper.CNI.UpgradeSteps(utils.UnwrapNodeList(extraMetadata.GetAllNodes()), []v1.StepNode{utils.UnwrapNodeList(masters)[0]})
+		if err != nil {
+			return err
+		}
+		stepper.installSteps = append(stepper.installSteps, steps...)
+	}
 	return nil
 }
 
@@ -470,39 +504,69 @@ func (stepper *ActBackup) Install(ctx context.Context, opts component.Options) (
 		return nil, err
 	}
 
-	render, err := os.Open(stepper.BackupFileName)
+	snapshot, err := os.Open(stepper.BackupFileName)
 	if err != nil {
 		logger.Errorf("open backup file %s failed: %s", stepper.BackupFileName, err.Error())
 		return nil, err
 	}
-	defer render.Close()
+	defer snapshot.Close()
+
+	var render io.Reader = snapshot
+	if stepper.Codec != nil {
+		encoded, err := stepper.Codec.EncodeReader(snapshot)
+		if err != nil {
+			logger.Errorf("encode backup file %s failed: %s", stepper.BackupFileName, err.Error())
+			return nil, err
+		}
+		defer encoded.Close()
+		render = encoded
+	}
+	// the saved file is hashed while it is streamed to the backup store
+	checksum := bs.NewChecksumReader(render)
 
 	store, err := stepper.BackupStoreCreate()
 	if err != nil {
 		logger.Errorf("create backup store failed: %s", err.Error())
 		return nil, err
 	}
-	err = store.Save(ctx, render, stepper.BackupFileName)
+	err = store.Save(ctx, checksum, stepper.BackupFileName)
 	if err != nil {
 		logger.Errorf("save backup file %s failed: %s", stepper.BackupFileName, err.Error())
 		return nil, err
 	}
 
-	logger.Info("etcd backup file save successfully")
-
-	// get the backup file's file size and md5 value
-	fileInfo, err := render.Stat()
+	// check the saved file is the same as the one uploaded
+	info, err := store.Stat(ctx, stepper.BackupFileName)
 	if err != nil {
+		logger.Errorf("stat backup file %s failed: %s", stepper.BackupFileName, err.Error())
 		return nil, err
 	}
-	data, err := ioutil.ReadFile(filepath.Join(stepper.BackupPointRootDir, stepper.BackupFileName))
-	if err != nil {
-		return nil, err
+	if info.Size != checksum.Size() || info.MD5 != checksum.MD5() {
+		return nil, fmt.Errorf("saved backup file size %d and md5 value %s are different from uploaded size %d and md5 value %s",
+			info.Size, info.MD5, checksum.Size(), checksum.MD5())
+	}
+
+	logger.Info("etcd backup file save successfully")
+
+	if stepper.Metadata != nil {
+		m := *stepper.Metadata
+		m.FileName = stepper.BackupFileName
+		m.Size = checksum.Size()
+		m.MD5 = checksum.MD5()
+		m.Algorithm = stepper.Codec.Algorithm()
+		m.Checksum = checksum.Checksum()
+		m.CreatedAt = time.Now().UTC()
+		// the backup is usable without the metadata file, which is only needed by scanning
+		if err = bs.SaveMetadata(ctx, store, &m); err != nil {
+			logger.Errorf("save backup metadata file of %s failed: %s", stepper.BackupFileName, err.Error())
+		}
 	}
-	md5Value := md5.Sum(data)
+
 	checkFile := CheckFile{
-		BackupFileSize: fileInfo.Size(),
-		BackupFileMD5:  fmt.Sprintf("%x", md5Value),
+		BackupFileSize: checksum.Size(),
+		BackupFileMD5:  checksum.MD5(),
+		Algorithm:      stepper.Codec.Algorithm(),
+		Checksum:       checksum.Checksum(),
 	}
 	cfJSON, err := json.Marshal(checkFile)
 	if err != nil {
@@ -534,6 +598,12 @@ func (stepper *ActBackup) Uninstall(ctx context.Context, opts component.Options)
 		logger.Errorf("delete backup file %s failed: %s", stepper.BackupFileName, err.Error())
 		return nil, err
 	}
+	// the metadata file does not exist when the backup is created by an old version
+	err = store.Delete(ctx, bs.MetadataFileName(filepath.Base(stepper.BackupFileName)))
+	if err != nil {
+		logger.Errorf("delete backup metadata file of %s failed: %s", stepper.BackupFileName, err.Error())
+		return nil, err
+	}
 
 	logger.Info("etcd backup file delete successfully")
 
@@ -637,7 +707,8 @@ func (stepper *ActBackup) makeUninstallSteps(metadata *component.ExtraMetadata)
 }
 
 func (stepper *ActBackup) BackupStoreCreate() (bs.BackupStore, error) {
-	if stepper.StoreType == bs.S3Storage {
+	switch stepper.StoreType {
+	case bs.S3Storage:
 		store := &bs.ObjectStore{
 			Bucket:          stepper.Bucket,
 			Endpoint:        stepper.Endpoint,
@@ -645,6 +716,10 @@ func (stepper *ActBackup) BackupStoreCreate() (bs.BackupStore, error) {
 			AccessKeySecret: stepper.AccessKeySecret,
 		}
 		return store.Create()
+	case bs.SFTPStorage:
+		return newSftpStore(stepper.SftpConfig)
+	case bs.WebDAVStorage:
+		return newWebDAVStore(stepper.WebDAVConfig)
 	}
 	store := &bs.FilesystemStore{
 		RootDir: stepper.BackupPointRootDir,
@@ -652,6 +727,32 @@ func (stepper *ActBackup) BackupStoreCreate() (bs.BackupStore, error) {
 	return store.Create()
 }
 
+func newSftpStore(c *v1.SftpConfig) (bs.BackupStore, error) {
+	if c == nil {
+		return nil, fmt.Errorf("sftp config of the backup point is empty")
+	}
+	store := &bs.SftpStore{
+		Host:       c.Host,
+		User:       c.Username,
+		Password:   c.Password,
+		PrivateKey: c.PrivateKey,
+		RootDir:    c.BackupRootDir,
+	}
+	return store.Create()
+}
+
+func newWebDAVStore(c *v1.WebDAVConfig) (bs.BackupStore, error) {
+	if c == nil {
+		return nil, fmt.Errorf("webdav config of the backup point is empty")
+	}
+	store := &bs.WebDAVStore{
+		Endpoint: c.Endpoint,
+		User:     c.Username,
+		Password: c.Password,
+	}
+	return store.Create()
+}
+
 func (stepper *Recovery) InitSteps(ctx context.Context) error {
 	extraMetadata := component.GetExtraMetadata(ctx)
 	if len(extraMetadata.Masters) == 0 {
@@ -777,12 +878,18 @@ func (stepper *Recovery) Download(ctx context.Context, opts component.Options) (
 		return nil, err
 	}
 	downloadFile := filepath.Join(stepper.RestoreDir, stepper.BackupFileName)
+	if stepper.Codec != nil {
+		// the encoded file is checked and decoded after downloaded
+		downloadFile += encodedFileSuffix
+		defer os.Remove(downloadFile)
+	}
 
 	writer, err := os.OpenFile(downloadFile, os.O_CREATE|os.O_RDWR, 0666)
 	if err != nil {
 		logger.Errorf("create restore file failed: %s", err.Error())
 		return nil, err
 	}
+	defer writer.Close()
 
 	b, err := stepper.BackupStoreCreate()
 	if err != nil {
@@ -795,6 +902,17 @@ func (stepper *Recovery) Download(ctx context.Context, opts component.Options) (
 		return nil, err
 	}
 
+	if stepper.Codec != nil {
+		if err = checkBackupFile(downloadFile, stepper.BackupFileSize, stepper.BackupFileMD5, stepper.BackupFileChecksum); err != nil {
+			logger.Errorf("check download backup file failed: %s", err.Error())
+			return nil, err
+		}
+		if err = decodeBackupFile(stepper.Codec, downloadFile, filepath.Join(stepper.RestoreDir, stepper.BackupFileName)); err != nil {
+			logger.Errorf("decode download backup file failed: %s", err.Error())
+			return nil, err
+		}
+	}
+
 	logger.Info("download backup file successfully")
 
 	return nil, nil
@@ -809,33 +927,13 @@ func (stepper *Recovery) BeforeRecovery(ctx context.Context, opts component.Opti
 		}
 	}()
 
-	// check the download file's size and md5 value
-	downloadFile := filepath.Join(stepper.RestoreDir, filepath.Base(stepper.BackupFileName))
-	render, err := os.Open(downloadFile)
-	if err != nil {
-		logger.Errorf("open backup file %s failed: %s", downloadFile, err.Error())
-		return nil, err
-	}
-	defer render.Close()
-
-	fileInfo, err := render.Stat()
-	if err != nil {
-		return nil, err
-	}
-	if fileInfo.Size() != stepper.BackupFileSize {
-		logger.Errorf("download backup file size is different from backup file size")
-		return nil, fmt.Errorf("download backup file size is different from backup file size")
-	}
-
-	data, err := ioutil.ReadFile(downloadFile)
-	if err != nil {
-		logger.Errorf("read download backup file failed: %v", err)
-		return nil, err
-	}
-	md5Value := fmt.Sprintf("%x", md5.Sum(data))
-	if md5Value != stepper.BackupFileMD5 {
-		logger.Errorf("download backup file md5 value is different from backup file md5 value")
-		return nil, fmt.Errorf("download backup file md5 value is different from backup file md5 value")
+	// check the download file's size and md5 value, the encoded file is checked before decoded
+	if stepper.Codec == nil {
+		downloadFile := filepath.Join(stepper.RestoreDir, filepath.Base(stepper.BackupFileName))
+		if err = checkBackupFile(downloadFile, stepper.BackupFileSize, stepper.BackupFileMD5, stepper.BackupFileChecksum); err != nil {
+			logger.Errorf("check download backup file failed: %s", err.Error())
+			return nil, err
+		}
 	}
 
 	cmd := fmt.Sprintf(`rm -rf %s && mv -bf %s %s`,
@@ -1013,8 +1111,13 @@ func (stepper *AfterRecovery) Install(ctx context.Context, opts component.Option
 		return nil, err
 	}
 
-	// restart cni(current calico) and kube-proxy
-	ec, err = cmdutil.RunCmdWithContext(ctx, opts.DryRun, "bash", "-c", "kubectl rollout restart ds calico-node -n kube-system")
+	// restart cni and kube-proxy
+	cni, err := LoadCNI(strutil.StringDefaultIfEmpty(CniCalico, stepper.CNI))
+	if err != nil {
+		return nil, err
+	}
+	namespace, name, selector := cni.AgentDaemonSet()
+	ec, err = cmdutil.RunCmdWithContext(ctx, opts.DryRun, "bash", "-c", fmt.Sprintf("kubectl rollout restart ds %s -n %s", name, namespace))
 	if err != nil {
 		if ec != nil {
 			logger.Errorf("restart cni pod and kube-proxy cmd failed: %s", ec.StdErr())
@@ -1023,7 +1126,7 @@ func (stepper *AfterRecovery) Install(ctx context.Context, opts component.Option
 	}
 	for i := 0; i < 3; i++ {
 		err = retryFunc(ctx, 5*time.Second, "check k8s cni pod status", func(ctx context.Context) error {
-			ec, err := cmdutil.RunCmdWithContext(ctx, opts.DryRun, "bash", "-c", `kubectl get po  -n kube-system -l k8s-app=calico-node | grep -v Running |  sed -n '2,$p'`)
+			ec, err := cmdutil.RunCmdWithContext(ctx, opts.DryRun, "bash", "-c", fmt.Sprintf(`kubectl get po  -n %s -l %s | grep -v Running |  sed -n '2,$p'`, namespace, selector))
 			if err == nil && ec.StdOut() == "" {
 				return nil
 			}
@@ -1069,7 +1172,8 @@ func (stepper *AfterRecovery) Uninstall(ctx context.Context, opts component.Opti
 }
 
 func (stepper *Recovery) BackupStoreCreate() (bs.BackupStore, error) {
-	if stepper.StoreType == bs.S3Storage {
+	switch stepper.StoreType {
+	case bs.S3Storage:
 		store := &bs.ObjectStore{
 			Bucket:          stepper.Bucket,
 			Endpoint:        stepper.Endpoint,
@@ -1077,6 +1181,10 @@ func (stepper *Recovery) BackupStoreCreate() (bs.BackupStore, error) {
 			AccessKeySecret: stepper.AccessKeySecret,
 		}
 		return store.Create()
+	case bs.SFTPStorage:
+		return newSftpStore(stepper.SftpConfig)
+	case bs.WebDAVStorage:
+		return newWebDAVStore(stepper.WebDAVConfig)
 	}
 	store := &bs.FilesystemStore{
 		RootDir: stepper.BackupPointRootDir,
@@ -0,0 +1,267 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	"github.com/kubeclipper/kubeclipper/pkg/component/utils"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/downloader"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
+)
+
+// CNI is a network plugin which can be installed in the cluster.
+// The manifest template is rendered with CNIInfo, and the offline images are
+// packaged under the name of the cni type.
+type CNI interface {
+	// Type returns the cni type, such as calico.
+	Type() string
+	// Validate checks the cni config against the cluster networking.
+	Validate(c *v1.CNI, networking *v1.Networking) error
+	// Template returns the manifest template of the cni version.
+	Template(version string) (string, error)
+	// AgentDaemonSet returns the namespace, name and pod label selector of the cni daemonset running on every node.
+	AgentDaemonSet() (namespace, name, selector string)
+	// InstallSteps returns the steps loading the offline images on allNodes and applying the manifest on nodes,
+	// only the images are loaded when onlyLoad is true.
+	InstallSteps(info *CNIInfo, allNodes, nodes []v1.StepNode, onlyLoad bool) ([]v1.Step, error)
+	// UpgradeSteps returns the steps loading the offline images of the new version on allNodes, applying the
+	// manifest on nodes and waiting for the cni rolled out.
+	UpgradeSteps(info *CNIInfo, allNodes, nodes []v1.StepNode) ([]v1.Step, error)
+	// UninstallSteps returns the steps removing the offline images and the virtual network interfaces left on nodes.
+	UninstallSteps(info *CNIInfo, nodes []v1.StepNode) ([]v1.Step, error)
+	// CleanSteps returns the steps removing the virtual network interfaces left on nodes.
+	CleanSteps(c *v1.CNI, nodes []v1.StepNode) []v1.Step
+	// ImageLoaderSteps returns the steps loading the offline images on nodes, there is no step when the
+	// images are pulled from the registry.
+	ImageLoaderSteps(info *CNIInfo, nodes []v1.StepNode) ([]v1.Step, error)
+	// LoadImages loads the offline images on the node.
+	LoadImages(ctx context.Context, info *CNIInfo, dryRun bool) error
+	// RemoveImages removes the offline images package from the node.
+	RemoveImages(ctx context.Context, info *CNIInfo, dryRun bool) error
+}
+
+var (
+	cniLock sync.RWMutex
+	cnis    = make(map[string]CNI)
+)
+
+func RegisterCNI(c CNI) error {
+	cniLock.Lock()
+	defer cniLock.Unlock()
+	if _, ok := cnis[c.Type()]; ok {
+		return fmt.Errorf("cni %s has been registered", c.Type())
+	}
+	cnis[c.Type()] = c
+	return nil
+}
+
+func LoadCNI(typ string) (CNI, error) {
+	cniLock.RLock()
+	defer cniLock.RUnlock()
+	c, ok := cnis[typ]
+	if !ok {
+		return nil, fmt.Errorf("no support cni type: %s", typ)
+	}
+	return c, nil
+}
+
+// CNITypes returns the sorted types of all registered cni.
+func CNITypes() []string {
+	cniLock.RLock()
+	defer cniLock.RUnlock()
+	types := make([]string, 0, len(cnis))
+	for typ := range cnis {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// validatePodCIDR checks the pod cidr blocks match the ip family.
+func validatePodCIDR(typ string, networking *v1.Networking) error {
+	if networking.IPFamily == v1.IPFamilyDualStack &&
+		len(networking.Pods.CIDRBlocks) <= 1 {
+		return fmt.Errorf("ipv4 and ipv6 cidr are both required when %s dual-stack is on", typ)
+	}
+	if networking.IPFamily != v1.IPFamilyDualStack &&
+		len(networking.Pods.CIDRBlocks) == 0 {
+		return fmt.Errorf("%s ipv4 and ipv6 must have at least one", typ)
+	}
+	return nil
+}
+
+// The manifest cni types share the following steps, they differ in the daemonset
+// waited for and the virtual network interfaces cleaned.
+
+func manifestInstallSteps(info *CNIInfo, allNodes, nodes []v1.StepNode, onlyLoad bool) ([]v1.Step, error) {
+	steps, err := manifestImageLoaderSteps(info, allNodes)
+	if err != nil {
+		return nil, err
+	}
+	// load only images for some special scenarios, such as JoinNode
+	if onlyLoad && len(steps) > 0 {
+		return steps, nil
+	}
+	step, err := manifestApplyStep(info, "installCNI", nodes, 1*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	return append(steps, step), nil
+}
+
+func manifestUpgradeSteps(c CNI, info *CNIInfo, allNodes, nodes []v1.StepNode) ([]v1.Step, error) {
+	steps, err := manifestImageLoaderSteps(info, allNodes)
+	if err != nil {
+		return nil, err
+	}
+	step, err := manifestApplyStep(info, "upgradeCNI", nodes, 10*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	namespace, name, _ := c.AgentDaemonSet()
+	step.Commands = append(step.Commands, v1.Command{
+		Type:         v1.CommandShell,
+		ShellCommand: []string{"kubectl", "rollout", "status", "ds", name, "-n", namespace, "--timeout", "9m"},
+	})
+	return append(steps, step), nil
+}
+
+func manifestUninstallSteps(c CNI, info *CNIInfo, nodes []v1.StepNode) ([]v1.Step, error) {
+	var steps []v1.Step
+	if info.CNI.Offline && info.CNI.LocalRegistry == "" {
+		bytes, err := json.Marshal(info)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, v1.Step{
+			ID:         strutil.GetUUID(),
+			Name:       "cniImageUninstaller",
+			Timeout:    metav1.Duration{Duration: 1 * time.Minute},
+			ErrIgnore:  false,
+			RetryTimes: 1,
+			Nodes:      nodes,
+			Action:     v1.ActionUninstall,
+			Commands: []v1.Command{
+				{
+					Type:          v1.CommandCustom,
+					Identity:      fmt.Sprintf(component.RegisterStepKeyFormat, cniInfo, version, component.TypeStep),
+					CustomCommand: bytes,
+				},
+			},
+		})
+	}
+	return append(steps, c.CleanSteps(&info.CNI, nodes)...), nil
+}
+
+func manifestImageLoaderSteps(info *CNIInfo, nodes []v1.StepNode) ([]v1.Step, error) {
+	if !info.CNI.Offline || info.CNI.LocalRegistry != "" || len(nodes) == 0 {
+		return nil, nil
+	}
+	bytes, err := json.Marshal(info)
+	if err != nil {
+		return nil, err
+	}
+	return []v1.Step{
+		{
+			ID:         strutil.GetUUID(),
+			Name:       "cniImageLoader",
+			Timeout:    metav1.Duration{Duration: 5 * time.Minute},
+			ErrIgnore:  false,
+			RetryTimes: 1,
+			Nodes:      nodes,
+			Action:     v1.ActionInstall,
+			Commands: []v1.Command{
+				{
+					Type:          v1.CommandCustom,
+					Identity:      fmt.Sprintf(component.RegisterStepKeyFormat, cniInfo, version, component.TypeStep),
+					CustomCommand: bytes,
+				},
+			},
+		},
+	}, nil
+}
+
+func manifestApplyStep(info *CNIInfo, name string, nodes []v1.StepNode, timeout time.Duration) (v1.Step, error) {
+	bytes, err := json.Marshal(info)
+	if err != nil {
+		return v1.Step{}, err
+	}
+	return v1.Step{
+		ID:         strutil.GetUUID(),
+		Name:       name,
+		Timeout:    metav1.Duration{Duration: timeout},
+		ErrIgnore:  false,
+		RetryTimes: 1,
+		Nodes:      nodes,
+		Commands: []v1.Command{
+			{
+				Type: v1.CommandTemplateRender,
+				Template: &v1.TemplateCommand{
+					Identity: fmt.Sprintf(component.RegisterTemplateKeyFormat, cniInfo, version, component.TypeTemplate),
+					Data:     bytes,
+				},
+			},
+			{
+				Type:         v1.CommandShell,
+				ShellCommand: []string{"kubectl", "apply", "-f", filepath.Join(ManifestDir, "cni.yaml")},
+			},
+		},
+	}, nil
+}
+
+func manifestLoadImages(ctx context.Context, info *CNIInfo, dryRun bool) error {
+	instance, err := downloader.NewInstance(ctx, info.CNI.Type, info.CNI.Version, runtime.GOARCH, !info.CNI.Offline, dryRun)
+	if err != nil {
+		return err
+	}
+	dstFile, err := instance.DownloadImages()
+	if err != nil {
+		return err
+	}
+	// load image package
+	if err = utils.LoadImage(ctx, dryRun, dstFile, info.CNI.CriType); err == nil {
+		logger.Info("cni packages offline install successfully", zap.String("cni", info.CNI.Type))
+	}
+	return err
+}
+
+func manifestRemoveImages(ctx context.Context, info *CNIInfo, dryRun bool) error {
+	instance, err := downloader.NewInstance(ctx, info.CNI.Type, info.CNI.Version, runtime.GOARCH, !info.CNI.Offline, dryRun)
+	if err != nil {
+		return err
+	}
+	if err = instance.RemoveImages(); err != nil {
+		logger.Error("remove cni images compressed file failed", zap.String("cni", info.CNI.Type), zap.Error(err))
+	}
+	return nil
+}
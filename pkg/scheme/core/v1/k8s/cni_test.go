@@ -0,0 +1,321 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package k8s
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func TestCNI_Validate(t *testing.T) {
+	ipv4 := v1.Networking{IPFamily: v1.IPFamilyIPv4, Pods: v1.NetworkRanges{CIDRBlocks: []string{"172.25.0.0/16"}}}
+	dualStack := v1.Networking{IPFamily: v1.IPFamilyDualStack, Pods: v1.NetworkRanges{CIDRBlocks: []string{"172.25.0.0/16"}}}
+	tests := []struct {
+		name       string
+		cni        v1.CNI
+		networking v1.Networking
+		wantErr    bool
+	}{
+		{
+			name:       "calico",
+			cni:        v1.CNI{Type: CniCalico, Calico: &v1.Calico{Mode: CalicoNetworkVXLANAll}},
+			networking: ipv4,
+		},
+		{
+			name:       "calico dual-stack without ipv6 cidr",
+			cni:        v1.CNI{Type: CniCalico, Calico: &v1.Calico{Mode: CalicoNetworkVXLANAll}},
+			networking: dualStack,
+			wantErr:    true,
+		},
+		{
+			name:       "cilium",
+			cni:        v1.CNI{Type: CniCilium, Cilium: &v1.Cilium{TunnelMode: CiliumTunnelVXLAN}},
+			networking: ipv4,
+		},
+		{
+			name:       "cilium without config",
+			cni:        v1.CNI{Type: CniCilium},
+			networking: ipv4,
+			wantErr:    true,
+		},
+		{
+			name:       "cilium unsupported kube-proxy replacement",
+			cni:        v1.CNI{Type: CniCilium, Cilium: &v1.Cilium{TunnelMode: CiliumTunnelVXLAN, KubeProxyReplacement: "strict"}},
+			networking: ipv4,
+			wantErr:    true,
+		},
+		{
+			name:       "flannel",
+			cni:        v1.CNI{Type: CniFlannel, Flannel: &v1.Flannel{Backend: FlannelBackendHostGW}},
+			networking: ipv4,
+		},
+		{
+			name:       "flannel unsupported backend",
+			cni:        v1.CNI{Type: CniFlannel, Flannel: &v1.Flannel{Backend: "udp"}},
+			networking: ipv4,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cni, err := LoadCNI(tt.cni.Type)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := cni.Validate(&tt.cni, &tt.networking); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCNIInfo_renderTo(t *testing.T) {
+	tests := []struct {
+		name    string
+		stepper CNIInfo
+		wantErr bool
+	}{
+		{
+			name: "cilium vxlan",
+			stepper: CNIInfo{
+				CNI: v1.CNI{
+					Type:    CniCilium,
+					Version: "v1.12.4",
+					Cilium:  &v1.Cilium{TunnelMode: CiliumTunnelVXLAN},
+				},
+				PodIPv4CIDR: "172.25.0.0/16",
+			},
+		},
+		{
+			name: "cilium native routing dual-stack",
+			stepper: CNIInfo{
+				CNI: v1.CNI{
+					LocalRegistry: "172.0.0.1:5000",
+					Type:          CniCilium,
+					Version:       "v1.12.4",
+					Cilium:        &v1.Cilium{TunnelMode: CiliumTunnelDisabled, KubeProxyReplacement: CiliumKubeProxyReplacementPartial, MTU: 1450},
+				},
+				DualStack:   true,
+				PodIPv4CIDR: "172.25.0.0/16",
+				PodIPv6CIDR: "fd00::/104",
+			},
+		},
+		{
+			name: "flannel dual-stack",
+			stepper: CNIInfo{
+				CNI: v1.CNI{
+					LocalRegistry: "172.0.0.1:5000",
+					Type:          CniFlannel,
+					Version:       "v0.20.2",
+					Flannel:       &v1.Flannel{Backend: FlannelBackendVXLAN},
+				},
+				DualStack:   true,
+				PodIPv4CIDR: "172.25.0.0/16",
+				PodIPv6CIDR: "fd00::/104",
+			},
+		},
+		{
+			name: "unsupported version",
+			stepper: CNIInfo{
+				CNI: v1.CNI{
+					Type:    CniFlannel,
+					Version: "v0.1.0",
+					Flannel: &v1.Flannel{Backend: FlannelBackendVXLAN},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			err := tt.stepper.renderTo(w)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("renderTo() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			// every document of the manifest must be valid yaml
+			decoder := yaml.NewYAMLOrJSONDecoder(w, 4096)
+			for {
+				obj := map[string]interface{}{}
+				if err := decoder.Decode(&obj); err == io.EOF {
+					break
+				} else if err != nil {
+					t.Fatalf("decode manifest error = %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestCNIInfo_UpgradeSteps(t *testing.T) {
+	nodes := []v1.StepNode{{ID: "1"}, {ID: "2"}}
+	tests := []struct {
+		name      string
+		cni       v1.CNI
+		wantSteps []string
+		wantWait  string
+	}{
+		{
+			name:      "online",
+			cni:       v1.CNI{Type: CniCilium, Version: "v1.12.4"},
+			wantSteps: []string{"upgradeCNI"},
+			wantWait:  "kubectl rollout status ds cilium -n kube-system --timeout 9m",
+		},
+		{
+			name:      "offline",
+			cni:       v1.CNI{Type: CniFlannel, Version: "v0.20.2", Offline: true},
+			wantSteps: []string{"cniImageLoader", "upgradeCNI"},
+			wantWait:  "kubectl rollout status ds kube-flannel-ds -n kube-flannel --timeout 9m",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stepper := &CNIInfo{CNI: tt.cni}
+			steps, err := stepper.UpgradeSteps(nodes, nodes[:1])
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, step := range steps {
+				names = append(names, step.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.wantSteps, ",") {
+				t.Errorf("UpgradeSteps() steps = %v, want %v", names, tt.wantSteps)
+			}
+			cmds := steps[len(steps)-1].Commands
+			if got := strings.Join(cmds[len(cmds)-1].ShellCommand, " "); got != tt.wantWait {
+				t.Errorf("UpgradeSteps() wait command = %s, want %s", got, tt.wantWait)
+			}
+		})
+	}
+}
+
+func TestCNI_InstallSteps(t *testing.T) {
+	nodes := []v1.StepNode{{ID: "1"}, {ID: "2"}}
+	tests := []struct {
+		name      string
+		cni       v1.CNI
+		allNodes  []v1.StepNode
+		onlyLoad  bool
+		wantSteps []string
+	}{
+		{
+			name:      "online",
+			cni:       v1.CNI{Type: CniCalico, Version: "v3.21.2"},
+			allNodes:  nodes,
+			wantSteps: []string{"installCNI"},
+		},
+		{
+			name:      "offline",
+			cni:       v1.CNI{Type: CniCilium, Version: "v1.12.4", Offline: true},
+			allNodes:  nodes,
+			wantSteps: []string{"cniImageLoader", "installCNI"},
+		},
+		{
+			name:      "offline with local registry",
+			cni:       v1.CNI{Type: CniCilium, Version: "v1.12.4", Offline: true, LocalRegistry: "172.0.0.1:5000"},
+			allNodes:  nodes,
+			wantSteps: []string{"installCNI"},
+		},
+		{
+			name:      "only load images",
+			cni:       v1.CNI{Type: CniFlannel, Version: "v0.20.2", Offline: true},
+			allNodes:  nodes,
+			onlyLoad:  true,
+			wantSteps: []string{"cniImageLoader"},
+		},
+		{
+			name:      "images loaded by the caller",
+			cni:       v1.CNI{Type: CniFlannel, Version: "v0.20.2", Offline: true},
+			wantSteps: []string{"installCNI"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cni, err := LoadCNI(tt.cni.Type)
+			if err != nil {
+				t.Fatal(err)
+			}
+			steps, err := cni.InstallSteps(&CNIInfo{CNI: tt.cni}, tt.allNodes, nodes[:1], tt.onlyLoad)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, step := range steps {
+				names = append(names, step.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.wantSteps, ",") {
+				t.Errorf("InstallSteps() steps = %v, want %v", names, tt.wantSteps)
+			}
+		})
+	}
+}
+
+func TestCNI_UninstallSteps(t *testing.T) {
+	nodes := []v1.StepNode{{ID: "1"}, {ID: "2"}}
+	tests := []struct {
+		name      string
+		cni       v1.CNI
+		wantSteps []string
+	}{
+		{
+			name:      "calico offline",
+			cni:       v1.CNI{Type: CniCalico, Version: "v3.21.2", Offline: true, Calico: &v1.Calico{Mode: CalicoNetworkVXLANAll}},
+			wantSteps: []string{"cniImageUninstaller", "removeVtep"},
+		},
+		{
+			name:      "cilium online",
+			cni:       v1.CNI{Type: CniCilium, Version: "v1.12.4", Cilium: &v1.Cilium{TunnelMode: CiliumTunnelVXLAN}},
+			wantSteps: []string{"removeCiliumLinks"},
+		},
+		{
+			name:      "flannel offline with local registry",
+			cni:       v1.CNI{Type: CniFlannel, Version: "v0.20.2", Offline: true, LocalRegistry: "172.0.0.1:5000", Flannel: &v1.Flannel{Backend: FlannelBackendVXLAN}},
+			wantSteps: []string{"removeFlannelLinks"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cni, err := LoadCNI(tt.cni.Type)
+			if err != nil {
+				t.Fatal(err)
+			}
+			steps, err := cni.UninstallSteps(&CNIInfo{CNI: tt.cni}, nodes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, step := range steps {
+				names = append(names, step.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.wantSteps, ",") {
+				t.Errorf("UninstallSteps() steps = %v, want %v", names, tt.wantSteps)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/agent/config"
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/cmdutil"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/netutil"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
+)
+
+func init() {
+	if err := component.RegisterAgentStep(fmt.Sprintf(component.RegisterStepKeyFormat, etcdMaintenance, version, component.TypeStep), &EtcdMaintenance{}); err != nil {
+		panic(err)
+	}
+}
+
+var _ component.StepRunnable = (*EtcdMaintenance)(nil)
+
+const (
+	etcdMaintenance = "etcdMaintenance"
+	// EtcdQuotaBackendBytes is the quota-backend-bytes of etcd in the kubeadm config.
+	EtcdQuotaBackendBytes int64 = 8589934592
+	// EtcdStatusStep is the name of the step measuring the db size of the members,
+	// every node responds with a v1.EtcdMemberStatus without the node id.
+	EtcdStatusStep = "etcdStatus"
+)
+
+const (
+	EtcdCompact = "compact"
+	EtcdDefrag  = "defrag"
+	EtcdDisarm  = "disarm"
+	EtcdStatus  = "status"
+)
+
+// EtcdMaintenance runs etcdctl against the etcd member on the node.
+type EtcdMaintenance struct {
+	Action string `json:"action"`
+}
+
+type etcdEndpointStatus struct {
+	Endpoint string `json:"Endpoint"`
+	Status   struct {
+		Header struct {
+			Revision int64 `json:"revision"`
+		} `json:"header"`
+		DBSize      int64 `json:"dbSize"`
+		DBSizeInUse int64 `json:"dbSizeInUse"`
+	} `json:"Status"`
+}
+
+func (stepper *EtcdMaintenance) NewInstance() component.ObjectMeta {
+	return &EtcdMaintenance{}
+}
+
+// InstallSteps compacts the keyspace on the first master, defragments the members one by one
+// to keep the quorum, disarms the alarms and then measures the db size of every member.
+func (stepper *EtcdMaintenance) InstallSteps(masters []v1.StepNode) ([]v1.Step, error) {
+	if len(masters) == 0 {
+		return nil, fmt.Errorf("etcd maintenance requires at least one master node")
+	}
+	compact, err := etcdMaintenanceStep("etcdCompact", EtcdCompact, masters[:1])
+	if err != nil {
+		return nil, err
+	}
+	steps := []v1.Step{compact}
+	for _, n := range masters {
+		defrag, err := etcdMaintenanceStep(fmt.Sprintf("etcdDefrag-%s", n.Hostname), EtcdDefrag, []v1.StepNode{n})
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, defrag)
+	}
+	disarm, err := etcdMaintenanceStep("etcdAlarmDisarm", EtcdDisarm, masters[:1])
+	if err != nil {
+		return nil, err
+	}
+	status, err := etcdMaintenanceStep(EtcdStatusStep, EtcdStatus, masters)
+	if err != nil {
+		return nil, err
+	}
+	return append(steps, disarm, status), nil
+}
+
+func (stepper *EtcdMaintenance) Install(ctx context.Context, opts component.Options) ([]byte, error) {
+	agentConfig, err := config.TryLoadFromDisk()
+	if err != nil {
+		return nil, errors.WithMessage(err, "load agent config")
+	}
+	ip, err := netutil.GetDefaultIP(true, agentConfig.IPDetect)
+	if err != nil {
+		logger.Errorf("get node ip failed: %s", err.Error())
+		return nil, err
+	}
+	endpoint := fmt.Sprintf("https://%s:2379", ip.String())
+
+	switch stepper.Action {
+	case EtcdCompact:
+		if opts.DryRun {
+			_, err = etcdctl(ctx, true, endpoint, "compact", "0")
+			return nil, err
+		}
+		status, err := endpointStatus(ctx, endpoint)
+		if err != nil {
+			return nil, err
+		}
+		_, err = etcdctl(ctx, false, endpoint, "compact", strconv.FormatInt(status.Status.Header.Revision, 10))
+		if err != nil && !strings.Contains(err.Error(), "required revision has been compacted") {
+			return nil, err
+		}
+		return nil, nil
+	case EtcdDefrag:
+		// the member does not serve requests during defragmentation
+		_, err = etcdctl(ctx, opts.DryRun, endpoint, "defrag", "--command-timeout=5m")
+		return nil, err
+	case EtcdDisarm:
+		_, err = etcdctl(ctx, opts.DryRun, endpoint, "alarm", "disarm")
+		return nil, err
+	case EtcdStatus:
+		if opts.DryRun {
+			return nil, nil
+		}
+		status, err := endpointStatus(ctx, endpoint)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(&v1.EtcdMemberStatus{
+			DBSize:      status.Status.DBSize,
+			DBSizeInUse: status.Status.DBSizeInUse,
+			MeasureTime: metav1.Now(),
+		})
+	}
+	return nil, fmt.Errorf("unsupported etcd maintenance action %s", stepper.Action)
+}
+
+func (stepper *EtcdMaintenance) Uninstall(ctx context.Context, opts component.Options) ([]byte, error) {
+	return nil, nil
+}
+
+func etcdMaintenanceStep(name, action string, nodes []v1.StepNode) (v1.Step, error) {
+	bytes, err := json.Marshal(&EtcdMaintenance{Action: action})
+	if err != nil {
+		return v1.Step{}, err
+	}
+	return v1.Step{
+		ID:         strutil.GetUUID(),
+		Name:       name,
+		Timeout:    metav1.Duration{Duration: 10 * time.Minute},
+		ErrIgnore:  false,
+		RetryTimes: 1,
+		Nodes:      nodes,
+		Action:     v1.ActionInstall,
+		Commands: []v1.Command{
+			{
+				Type:          v1.CommandCustom,
+				Identity:      fmt.Sprintf(component.RegisterStepKeyFormat, etcdMaintenance, version, component.TypeStep),
+				CustomCommand: bytes,
+			},
+		},
+	}, nil
+}
+
+func endpointStatus(ctx context.Context, endpoint string) (*etcdEndpointStatus, error) {
+	ec, err := etcdctl(ctx, false, endpoint, "endpoint", "status", "--write-out=json")
+	if err != nil {
+		return nil, err
+	}
+	var status []etcdEndpointStatus
+	if err = json.Unmarshal([]byte(ec.StdOut()), &status); err != nil {
+		return nil, errors.WithMessage(err, "parse etcd endpoint status")
+	}
+	if len(status) == 0 {
+		return nil, fmt.Errorf("no status of etcd endpoint %s", endpoint)
+	}
+	return &status[0], nil
+}
+
+func etcdctl(ctx context.Context, dryRun bool, endpoint string, args ...string) (*cmdutil.ExecCmd, error) {
+	cmd := fmt.Sprintf("ETCDCTL_API=3 etcdctl --endpoints=%s --cacert=/etc/kubernetes/pki/etcd/ca.crt --cert=/etc/kubernetes/pki/etcd/server.crt --key=/etc/kubernetes/pki/etcd/server.key %s",
+		endpoint, strings.Join(args, " "))
+	ec, err := cmdutil.RunCmdWithContext(ctx, dryRun, "bash", "-c", cmd)
+	if err != nil {
+		if ec != nil {
+			logger.Errorf("etcdctl %s failed: %s", args[0], ec.StdErr())
+		}
+		return ec, err
+	}
+	return ec, nil
+}
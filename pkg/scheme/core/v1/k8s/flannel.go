@@ -0,0 +1,112 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
+)
+
+func init() {
+	if err := RegisterCNI(&flannel{}); err != nil {
+		panic(err)
+	}
+}
+
+var _ CNI = (*flannel)(nil)
+
+type flannel struct{}
+
+func (c *flannel) Type() string {
+	return CniFlannel
+}
+
+func (c *flannel) Validate(cni *v1.CNI, networking *v1.Networking) error {
+	if cni.Flannel == nil {
+		return fmt.Errorf("flannel config is required")
+	}
+	switch cni.Flannel.Backend {
+	case FlannelBackendVXLAN, FlannelBackendHostGW:
+	default:
+		return fmt.Errorf("unsupported flannel backend: %s", cni.Flannel.Backend)
+	}
+	return validatePodCIDR(CniFlannel, networking)
+}
+
+func (c *flannel) Template(version string) (string, error) {
+	switch version {
+	case "v0.20.2":
+		return flannelV0202, nil
+	}
+	return "", fmt.Errorf("flannel no support %s version", version)
+}
+
+func (c *flannel) AgentDaemonSet() (namespace, name, selector string) {
+	return "kube-flannel", "kube-flannel-ds", "app=flannel"
+}
+
+func (c *flannel) InstallSteps(info *CNIInfo, allNodes, nodes []v1.StepNode, onlyLoad bool) ([]v1.Step, error) {
+	return manifestInstallSteps(info, allNodes, nodes, onlyLoad)
+}
+
+func (c *flannel) UpgradeSteps(info *CNIInfo, allNodes, nodes []v1.StepNode) ([]v1.Step, error) {
+	return manifestUpgradeSteps(c, info, allNodes, nodes)
+}
+
+func (c *flannel) UninstallSteps(info *CNIInfo, nodes []v1.StepNode) ([]v1.Step, error) {
+	return manifestUninstallSteps(c, info, nodes)
+}
+
+func (c *flannel) ImageLoaderSteps(info *CNIInfo, nodes []v1.StepNode) ([]v1.Step, error) {
+	return manifestImageLoaderSteps(info, nodes)
+}
+
+func (c *flannel) LoadImages(ctx context.Context, info *CNIInfo, dryRun bool) error {
+	return manifestLoadImages(ctx, info, dryRun)
+}
+
+func (c *flannel) RemoveImages(ctx context.Context, info *CNIInfo, dryRun bool) error {
+	return manifestRemoveImages(ctx, info, dryRun)
+}
+
+func (c *flannel) CleanSteps(cni *v1.CNI, nodes []v1.StepNode) []v1.Step {
+	return []v1.Step{
+		{
+			ID:         strutil.GetUUID(),
+			Name:       "removeFlannelLinks",
+			Timeout:    metav1.Duration{Duration: 5 * time.Second},
+			ErrIgnore:  true,
+			Nodes:      nodes,
+			Action:     v1.ActionUninstall,
+			RetryTimes: 1,
+			Commands: []v1.Command{
+				{
+					Type:         v1.CommandShell,
+					ShellCommand: []string{"bash", "-c", "ip link delete cni0; ip link delete flannel.1; ip link delete flannel-v6.1; rm -rf /run/flannel"},
+				},
+			},
+		},
+	}
+}
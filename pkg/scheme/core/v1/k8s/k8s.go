@@ -19,8 +19,10 @@
 package k8s
 
 const (
-	K8s       = "k8s"
-	CniCalico = "calico"
+	K8s        = "k8s"
+	CniCalico  = "calico"
+	CniCilium  = "cilium"
+	CniFlannel = "flannel"
 
 	NodeRoleMaster = "master"
 	NodeRoleWorker = "worker"
@@ -47,3 +49,17 @@ const (
 	CalicoNetworkVXLANSubnet = "Overlay-Vxlan-Cross-Subnet"
 	CalicoNetworkBGP         = "BGP"
 )
+
+const (
+	CiliumTunnelVXLAN    = "vxlan"
+	CiliumTunnelGeneve   = "geneve"
+	CiliumTunnelDisabled = "disabled"
+
+	CiliumKubeProxyReplacementDisabled = "disabled"
+	CiliumKubeProxyReplacementPartial  = "partial"
+)
+
+const (
+	FlannelBackendVXLAN  = "vxlan"
+	FlannelBackendHostGW = "host-gw"
+)
@@ -44,6 +44,7 @@ import (
 	"github.com/kubeclipper/kubeclipper/pkg/utils/ipvsutil"
 	"github.com/kubeclipper/kubeclipper/pkg/utils/netutil"
 	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/sysutil"
 	tmplutil "github.com/kubeclipper/kubeclipper/pkg/utils/template"
 )
 
@@ -123,6 +124,8 @@ type ControlPlane struct {
 	APIServerDomainName string
 	EtcdDataPath        string
 	ContainerRuntime    string
+	// snapshot restored as the etcd data before kubeadm init, it is only set when the cluster is restored from a backup
+	RestoreSnapshot string `json:",omitempty"`
 }
 
 type ClusterNode struct {
@@ -343,7 +346,21 @@ func (stepper *ControlPlane) Install(ctx context.Context, opts component.Options
 		return nil, err
 	}
 
-	ec, err := cmdutil.RunCmdWithContext(ctx, opts.DryRun, "kubeadm", "init", "--config", "/tmp/.k8s/kubeadm.yaml", "--upload-certs")
+	initArgs := []string{"init", "--config", "/tmp/.k8s/kubeadm.yaml", "--upload-certs"}
+	if stepper.RestoreSnapshot != "" {
+		dataDir := strutil.StringDefaultIfEmpty(EtcdDefaultDataDir, stepper.EtcdDataPath)
+		hostInfo, err := sysutil.HostInfo()
+		if err != nil {
+			logger.Errorf("get host info failed: %s", err.Error())
+			return nil, err
+		}
+		if err = restoreEtcdSnapshot(ctx, opts, stepper.RestoreSnapshot, dataDir, strings.ToLower(hostInfo.Hostname), ipnet.String()); err != nil {
+			return nil, err
+		}
+		// kubeadm generates the certificates and kubeconfig for the new nodes, the etcd data is kept
+		initArgs = append(initArgs, "--ignore-preflight-errors="+etcdDataDirPreflight(dataDir))
+	}
+	ec, err := cmdutil.RunCmdWithContext(ctx, opts.DryRun, "kubeadm", initArgs...)
 	if err != nil {
 		logger.Error("run kubeadm init error", zap.Error(err))
 		return nil, err
@@ -605,75 +622,41 @@ func (stepper *CNIInfo) NewInstance() component.ObjectMeta {
 }
 
 func (stepper *CNIInfo) Install(ctx context.Context, opts component.Options) ([]byte, error) {
-	instance, err := downloader.NewInstance(ctx, stepper.CNI.Type, stepper.CNI.Version, runtime.GOARCH, !stepper.CNI.Offline, opts.DryRun)
-	if err != nil {
-		return nil, err
-	}
-	dstFile, err := instance.DownloadImages()
+	cni, err := LoadCNI(stepper.CNI.Type)
 	if err != nil {
 		return nil, err
 	}
-	// load image package
-	if err = utils.LoadImage(ctx, opts.DryRun, dstFile, stepper.CNI.CriType); err == nil {
-		logger.Info("calico packages offline install successfully")
-	}
-
-	return nil, err
+	return nil, cni.LoadImages(ctx, stepper, opts.DryRun)
 }
 
 func (stepper *CNIInfo) Uninstall(ctx context.Context, opts component.Options) ([]byte, error) {
-	instance, err := downloader.NewInstance(ctx, stepper.CNI.Type, stepper.CNI.Version, runtime.GOARCH, !stepper.CNI.Offline, opts.DryRun)
+	cni, err := LoadCNI(stepper.CNI.Type)
 	if err != nil {
 		return nil, err
 	}
-	if err = instance.RemoveImages(); err != nil {
-		logger.Error("remove calico images compressed file failed", zap.Error(err))
-	}
-	return nil, nil
+	return nil, cni.RemoveImages(ctx, stepper, opts.DryRun)
 }
 
 func (stepper *CNIInfo) Render(ctx context.Context, opts component.Options) error {
-	switch stepper.CNI.Type {
-	case CniCalico:
-		return stepper.renderCalico(ctx, opts.DryRun)
-	default:
-		return fmt.Errorf("unsupported %s cni type", stepper.CNI.Type)
+	if err := os.MkdirAll(ManifestDir, 0755); err != nil {
+		return err
 	}
+	manifestFile := filepath.Join(ManifestDir, "cni.yaml")
+	return fileutil.WriteFileWithContext(ctx, manifestFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644,
+		stepper.renderTo, opts.DryRun)
 }
 
-func (stepper *CNIInfo) renderCalicoTo(w io.Writer) error {
-	at := tmplutil.New()
-	calicoTemp, err := stepper.CalicoTemplate()
+func (stepper *CNIInfo) renderTo(w io.Writer) error {
+	cni, err := LoadCNI(stepper.CNI.Type)
 	if err != nil {
 		return err
 	}
-	if _, err := at.RenderTo(w, calicoTemp, stepper); err != nil {
-		return err
-	}
-	return nil
-}
-
-func (stepper *CNIInfo) CalicoTemplate() (string, error) {
-	switch stepper.CNI.Version {
-	case "v3.11.2":
-		return calicoV3112, nil
-	case "v3.16.10":
-		return calicoV31610, nil
-	case "v3.21.2":
-		return calicoV3212, nil
-	case "v3.22.4":
-		return calicoV3224, nil
-	}
-	return "", fmt.Errorf("calico no support %s version", stepper.CNI.Version)
-}
-
-func (stepper *CNIInfo) renderCalico(ctx context.Context, dryRun bool) error {
-	if err := os.MkdirAll(ManifestDir, 0755); err != nil {
+	tmpl, err := cni.Template(stepper.CNI.Version)
+	if err != nil {
 		return err
 	}
-	manifestFile := filepath.Join(ManifestDir, "cni.yaml")
-	return fileutil.WriteFileWithContext(ctx, manifestFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644,
-		stepper.renderCalicoTo, dryRun)
+	_, err = tmplutil.New().RenderTo(w, tmpl, stepper)
+	return err
 }
 
 func (stepper *Health) NewInstance() component.ObjectMeta {
@@ -78,25 +78,20 @@ func (runnable *Runnable) Validate() error {
 		return fmt.Errorf("init step error, cluster contains at least one master node")
 	}
 
-	// check dualStack and ipv4
-	switch runnable.CNI.Type {
-	case "calico":
-		if runnable.Networking.IPFamily == v1.IPFamilyDualStack &&
-			len(runnable.Networking.Pods.CIDRBlocks) <= 1 {
-			return fmt.Errorf("ipv4 and ipv6 cidr are both required when calico dual-stack is on")
-		}
-		if runnable.Networking.IPFamily != v1.IPFamilyDualStack &&
-			len(runnable.Networking.Pods.CIDRBlocks) == 0 {
-			return fmt.Errorf("calico ipv4 and ipv6 must have at least one")
-		}
+	cni, err := LoadCNI(runnable.CNI.Type)
+	if err != nil {
+		return err
 	}
-
-	return nil
+	return cni.Validate(&runnable.CNI, &runnable.Networking)
 }
 
 func (runnable *Runnable) GetInstallSteps(ctx context.Context) ([]v1.Step, error) {
 	metadata := component.GetExtraMetadata(ctx)
-	return runnable.makeInstallSteps(&metadata)
+	restore := getClusterRestore(ctx)
+	if runnable.RestoreFrom != nil && restore == nil {
+		return nil, fmt.Errorf("backup %s of the restored cluster is not found", runnable.RestoreFrom.Backup)
+	}
+	return runnable.makeInstallSteps(&metadata, restore)
 }
 
 func (runnable *Runnable) GetUninstallSteps(ctx context.Context) ([]v1.Step, error) {
@@ -120,12 +115,12 @@ func (runnable *Runnable) GetUpgradeSteps(ctx context.Context) ([]v1.Step, error
 	}, nil
 }
 
-func (runnable *Runnable) makeInstallSteps(metadata *component.ExtraMetadata) ([]v1.Step, error) {
-	// 1. package download and install
+func (runnable *Runnable) makeInstallSteps(metadata *component.ExtraMetadata, restore *ClusterRestore) ([]v1.Step, error) {
+	// 1. package download and install, on every node on its own
 	// 2. print kubeadm config(template step type)
 	// 3. kubeadm init cluster
 	// 4. join node to cluster
-	// 5. install cni(template and shell step type)
+	// 5. install cni(template and shell step type), the images are loaded right after the packages of a node
 	// 6. patch label and taint(shell step type)
 	// 7. check cluster health
 	// 8. apply kubectl pod
@@ -134,76 +129,132 @@ func (runnable *Runnable) makeInstallSteps(metadata *component.ExtraMetadata) ([
 	masters := utils.UnwrapNodeList(metadata.Masters)
 
 	var installSteps []v1.Step
-	steps, err := EnvSetupSteps(nodes)
+	envSteps, err := EnvSetupSteps(nodes)
 	if err != nil {
 		return nil, err
 	}
-	installSteps = append(installSteps, steps...)
-
-	pack := Package{}
-	steps, err = pack.InitStepper(&c).InstallSteps(nodes)
-	if err != nil {
-		return nil, err
-	}
-	installSteps = append(installSteps, steps...)
+	installSteps = append(installSteps, chainSteps(envSteps)...)
 
 	kubeConf := KubeadmConfig{}
-	steps, err = kubeConf.InitStepper(&c, metadata).InstallSteps([]v1.StepNode{masters[0]})
+	confSteps, err := kubeConf.InitStepper(&c, metadata).InstallSteps([]v1.StepNode{masters[0]})
 	if err != nil {
 		return nil, err
 	}
-	installSteps = append(installSteps, steps...)
+	installSteps = append(installSteps, chainSteps(confSteps, lastStep(envSteps)...)...)
+	controlPlaneDeps := lastStep(confSteps)
 
 	controlPlane := ControlPlane{}
-	steps, err = controlPlane.InitStepper(&c).InstallSteps([]v1.StepNode{masters[0]})
+	controlPlane.InitStepper(&c)
+	if restore != nil {
+		// restore the etcd snapshot before kubeadm init
+		snapshotSteps, err := restore.Snapshot.InstallSteps(masters[0])
+		if err != nil {
+			return nil, err
+		}
+		installSteps = append(installSteps, chainSteps(snapshotSteps, lastStep(envSteps)...)...)
+		controlPlaneDeps = append(controlPlaneDeps, lastStep(snapshotSteps)...)
+		controlPlane.RestoreSnapshot = restore.Snapshot.SnapshotFile
+	}
+
+	// the packages and the cni images of every node are installed in parallel,
+	// a node only waits for its own packages before it joins the cluster.
+	pack := Package{}
+	pack.InitStepper(&c)
+	cn := CNIInfo{}
+	cni := cn.InitStepper(&c.CNI, &c.Networking)
+	packSteps := make(map[string][]v1.Step, len(nodes))
+	var imageSteps []v1.Step
+	for _, node := range nodes {
+		steps, err := pack.InstallSteps([]v1.StepNode{node})
+		if err != nil {
+			return nil, err
+		}
+		installSteps = append(installSteps, chainSteps(steps, lastStep(envSteps)...)...)
+		packSteps[node.ID] = lastStep(steps, lastStep(envSteps)...)
+
+		steps, err = cni.ImageLoaderSteps([]v1.StepNode{node})
+		if err != nil {
+			return nil, err
+		}
+		installSteps = append(installSteps, chainSteps(steps, packSteps[node.ID]...)...)
+		imageSteps = append(imageSteps, lastStep(steps)...)
+	}
+	nodePackSteps := func(nodes []v1.StepNode) []v1.Step {
+		var steps []v1.Step
+		for _, node := range nodes {
+			steps = append(steps, packSteps[node.ID]...)
+		}
+		return steps
+	}
+
+	controlPlaneDeps = append(controlPlaneDeps, packSteps[masters[0].ID]...)
+	steps, err := controlPlane.InstallSteps([]v1.StepNode{masters[0]})
 	if err != nil {
 		return nil, err
 	}
-	installSteps = append(installSteps, steps...)
+	installSteps = append(installSteps, chainSteps(steps, controlPlaneDeps...)...)
+	joined := lastStep(steps)
 
 	if len(runnable.Masters) > 1 {
 		cluNode := ClusterNode{}
-		steps, err = cluNode.InitStepper(&c, metadata).InstallSteps(NodeRoleMaster, utils.UnwrapNodeList(metadata.Masters)[1:])
+		joinMasters := utils.UnwrapNodeList(metadata.Masters)[1:]
+		steps, err = cluNode.InitStepper(&c, metadata).InstallSteps(NodeRoleMaster, joinMasters)
 		if err != nil {
 			return nil, err
 		}
-		installSteps = append(installSteps, steps...)
+		installSteps = append(installSteps, chainSteps(steps, append(joined, nodePackSteps(joinMasters)...)...)...)
+		joined = lastStep(steps)
 	}
 	if len(runnable.Workers) > 0 {
 		cluNode := ClusterNode{}
-		steps, err = cluNode.InitStepper(&c, metadata).InstallSteps(NodeRoleWorker, utils.UnwrapNodeList(metadata.Workers))
+		workers := utils.UnwrapNodeList(metadata.Workers)
+		steps, err = cluNode.InitStepper(&c, metadata).InstallSteps(NodeRoleWorker, workers)
 		if err != nil {
 			return nil, err
 		}
-		installSteps = append(installSteps, steps...)
+		installSteps = append(installSteps, chainSteps(steps, append(joined, nodePackSteps(workers)...)...)...)
+		joined = lastStep(steps)
 	}
 
-	cn := CNIInfo{}
-	steps, err = cn.InitStepper(&c.CNI, &c.Networking).InstallSteps(nodes, []v1.StepNode{masters[0]}, false)
+	// the images are loaded on every node above, so only the manifest is applied.
+	steps, err = cni.InstallSteps(nil, []v1.StepNode{masters[0]}, false)
 	if err != nil {
 		return nil, err
 	}
+	steps = chainSteps(steps, append(joined, imageSteps...)...)
 	installSteps = append(installSteps, steps...)
+	last := lastStep(steps)
 
 	steps, err = PatchTaintAndLabelStep(runnable.Masters, runnable.Workers, metadata)
 	if err != nil {
 		return nil, err
 	}
-	installSteps = append(installSteps, steps...)
+	installSteps = append(installSteps, chainSteps(steps, last...)...)
+	last = lastStep(steps, last...)
 
 	heal := Health{}
 	steps, err = heal.InitStepper().InstallSteps([]v1.StepNode{masters[0]})
 	if err != nil {
 		return nil, err
 	}
-	installSteps = append(installSteps, steps...)
+	installSteps = append(installSteps, chainSteps(steps, last...)...)
+	last = lastStep(steps, last...)
+
+	if restore != nil {
+		steps, err = restore.Nodes.InstallSteps(masters[0])
+		if err != nil {
+			return nil, err
+		}
+		installSteps = append(installSteps, chainSteps(steps, last...)...)
+		last = lastStep(steps, last...)
+	}
 
 	kt := KubectlTerminal{}
 	steps, err = kt.InitStepper(&c).InstallSteps([]v1.StepNode{masters[0]})
 	if err != nil {
 		return nil, err
 	}
-	installSteps = append(installSteps, steps...)
+	installSteps = append(installSteps, chainSteps(steps, last...)...)
 	return installSteps, nil
 }
 
@@ -546,84 +597,38 @@ func (stepper *CNIInfo) InitStepper(c *v1.CNI, networking *v1.Networking) *CNIIn
 }
 
 func (stepper *CNIInfo) InstallSteps(allNodes, nodes []v1.StepNode, onlyLoad bool) ([]v1.Step, error) {
-	var steps []v1.Step
-	bytes, err := json.Marshal(stepper)
+	cni, err := LoadCNI(stepper.CNI.Type)
 	if err != nil {
 		return nil, err
 	}
-	if stepper.CNI.Offline && stepper.CNI.LocalRegistry == "" {
-		steps = []v1.Step{
-			{
-				ID:         strutil.GetUUID(),
-				Name:       "cniImageLoader",
-				Timeout:    metav1.Duration{Duration: 5 * time.Minute},
-				ErrIgnore:  false,
-				RetryTimes: 1,
-				Nodes:      allNodes,
-				Action:     v1.ActionInstall,
-				Commands: []v1.Command{
-					{
-						Type:          v1.CommandCustom,
-						Identity:      fmt.Sprintf(component.RegisterStepKeyFormat, cniInfo, version, component.TypeStep),
-						CustomCommand: bytes,
-					},
-				},
-			},
-		}
-		// load only images for some special scenarios, such as JoinNode
-		if onlyLoad {
-			return steps, nil
-		}
+	return cni.InstallSteps(stepper, allNodes, nodes, onlyLoad)
+}
+
+// UpgradeSteps loads the images of the new cni version on all nodes, then applies the manifest
+// and waits for the cni rolled out.
+func (stepper *CNIInfo) UpgradeSteps(allNodes, nodes []v1.StepNode) ([]v1.Step, error) {
+	cni, err := LoadCNI(stepper.CNI.Type)
+	if err != nil {
+		return nil, err
 	}
-	return append(steps, v1.Step{
-		ID:         strutil.GetUUID(),
-		Name:       "installCNI",
-		Timeout:    metav1.Duration{Duration: 1 * time.Minute},
-		ErrIgnore:  false,
-		RetryTimes: 1,
-		Nodes:      nodes,
-		Commands: []v1.Command{
-			{
-				Type: v1.CommandTemplateRender,
-				Template: &v1.TemplateCommand{
-					Identity: fmt.Sprintf(component.RegisterTemplateKeyFormat, cniInfo, version, component.TypeTemplate),
-					Data:     bytes,
-				},
-			},
-			{
-				Type:         v1.CommandShell,
-				ShellCommand: []string{"kubectl", "apply", "-f", filepath.Join(ManifestDir, "cni.yaml")},
-			},
-		},
-	}), nil
+	return cni.UpgradeSteps(stepper, allNodes, nodes)
 }
 
-func (stepper *CNIInfo) UninstallSteps(nodes []v1.StepNode) ([]v1.Step, error) {
-	bytes, err := json.Marshal(stepper)
+func (stepper *CNIInfo) ImageLoaderSteps(nodes []v1.StepNode) ([]v1.Step, error) {
+	cni, err := LoadCNI(stepper.CNI.Type)
 	if err != nil {
 		return nil, err
 	}
-	if stepper.CNI.Offline && stepper.CNI.LocalRegistry == "" {
-		return []v1.Step{
-			{
-				ID:         strutil.GetUUID(),
-				Name:       "cniImageUninstaller",
-				Timeout:    metav1.Duration{Duration: 1 * time.Minute},
-				ErrIgnore:  false,
-				RetryTimes: 1,
-				Nodes:      nodes,
-				Action:     v1.ActionUninstall,
-				Commands: []v1.Command{
-					{
-						Type:          v1.CommandCustom,
-						Identity:      fmt.Sprintf(component.RegisterStepKeyFormat, cniInfo, version, component.TypeStep),
-						CustomCommand: bytes,
-					},
-				},
-			},
-		}, nil
+	return cni.ImageLoaderSteps(stepper, nodes)
+}
+
+// UninstallSteps removes the offline images and cleans the virtual network interfaces on nodes.
+func (stepper *CNIInfo) UninstallSteps(nodes []v1.StepNode) ([]v1.Step, error) {
+	cni, err := LoadCNI(stepper.CNI.Type)
+	if err != nil {
+		return nil, err
 	}
-	return nil, nil
+	return cni.UninstallSteps(stepper, nodes)
 }
 
 func (stepper *Health) InitStepper() *Health {
@@ -748,9 +753,13 @@ func (stepper *Certification) InitStepper() *Certification {
 	return stepper
 }
 
+// InstallSteps renews the certificates and restarts the control plane of the masters one by one,
+// the next master starts after the api server of the previous one is ready again.
 func (stepper *Certification) InstallSteps(nodes []v1.StepNode) ([]v1.Step, error) {
-	return []v1.Step{
-		{
+	var steps []v1.Step
+	for _, node := range nodes {
+		nodes := []v1.StepNode{node}
+		steps = append(steps, v1.Step{
 			ID:         strutil.GetUUID(),
 			Name:       "updateCerts",
 			Nodes:      nodes,
@@ -763,9 +772,13 @@ func (stepper *Certification) InstallSteps(nodes []v1.StepNode) ([]v1.Step, erro
 					Type:         v1.CommandShell,
 					ShellCommand: []string{"kubeadm", "certs", "renew", "all"},
 				},
+				{
+					// admin.conf is renewed, refresh the copy used by kubectl
+					Type:         v1.CommandShell,
+					ShellCommand: []string{"cp", "-rf", "/etc/kubernetes/admin.conf", "/root/.kube/config"},
+				},
 			},
-		},
-		{
+		}, v1.Step{
 			ID:         strutil.GetUUID(),
 			Name:       "restartPods",
 			Nodes:      nodes,
@@ -799,8 +812,29 @@ func (stepper *Certification) InstallSteps(nodes []v1.StepNode) ([]v1.Step, erro
 					},
 				},
 			},
-		},
-	}, nil
+		}, v1.Step{
+			ID:         strutil.GetUUID(),
+			Name:       "checkControlPlane",
+			Nodes:      nodes,
+			Action:     v1.ActionInstall,
+			Timeout:    metav1.Duration{Duration: 5 * time.Minute},
+			ErrIgnore:  false,
+			RetryTimes: 1,
+			Commands: []v1.Command{
+				{
+					// readyz of the local api server includes the etcd check
+					Type:         v1.CommandShell,
+					ShellCommand: []string{"bash", "-c", controlPlaneReadyCommand(node.IPv4)},
+				},
+			},
+		})
+	}
+	return steps, nil
+}
+
+// controlPlaneReadyCommand waits for the api server on the node to be ready.
+func controlPlaneReadyCommand(ip string) string {
+	return fmt.Sprintf("for i in $(seq 60); do kubectl --kubeconfig=/etc/kubernetes/admin.conf --server=https://%s:6443 get --raw=/readyz >/dev/null 2>&1 && exit 0; sleep 5; done; exit 1", ip)
 }
 
 func (stepper *Container) InitStepper(criType string) *Container {
@@ -871,6 +905,28 @@ func (stepper *Kubectl) UninstallSteps(nodes []v1.StepNode) ([]v1.Step, error) {
 	}, nil
 }
 
+// chainSteps makes the first step depend on the given steps and every other step on the one before it.
+func chainSteps(steps []v1.Step, after ...v1.Step) []v1.Step {
+	for i := range steps {
+		if i > 0 {
+			after = steps[i-1 : i]
+		}
+		steps[i].DependsOn = make([]string, 0, len(after))
+		for _, s := range after {
+			steps[i].DependsOn = append(steps[i].DependsOn, s.ID)
+		}
+	}
+	return steps
+}
+
+// lastStep returns the last one of the steps, or the given steps if there is none.
+func lastStep(steps []v1.Step, otherwise ...v1.Step) []v1.Step {
+	if len(steps) == 0 {
+		return otherwise
+	}
+	return steps[len(steps)-1:]
+}
+
 func EnvSetupSteps(nodes []v1.StepNode) ([]v1.Step, error) {
 	var steps []v1.Step
 	steps = append(steps, v1.Step{
@@ -1054,53 +1110,11 @@ func Clear(c *v1.Cluster, metadata *component.ExtraMetadata) ([]v1.Step, error)
 }
 
 func CleanCNI(c *v1.CNI, nodes []v1.StepNode) ([]v1.Step, error) {
-	switch c.Type {
-	case "calico":
-		return ClearCalico(c.Calico, nodes), nil
-	}
-
-	return nil, fmt.Errorf("no support cni type: %s", c.Type)
-}
-
-func ClearCalico(calico *v1.Calico, nodes []v1.StepNode) []v1.Step {
-	var steps []v1.Step
-
-	switch calico.Mode {
-	case CalicoNetworkIPIPAll, CalicoNetworkIPIPSubnet:
-		steps = append(steps, v1.Step{
-			ID:         strutil.GetUUID(),
-			Name:       "removeTunl",
-			Timeout:    metav1.Duration{Duration: 5 * time.Second},
-			ErrIgnore:  true,
-			Nodes:      nodes,
-			Action:     v1.ActionUninstall,
-			RetryTimes: 1,
-			Commands: []v1.Command{
-				{
-					Type:         v1.CommandShell,
-					ShellCommand: []string{"modprobe", "-r", "ipip"},
-				},
-			},
-		})
-	case CalicoNetworkVXLANAll, CalicoNetworkVXLANSubnet:
-		steps = append(steps, v1.Step{
-			ID:         strutil.GetUUID(),
-			Name:       "removeVtep",
-			Timeout:    metav1.Duration{Duration: 5 * time.Second},
-			ErrIgnore:  true,
-			Nodes:      nodes,
-			Action:     v1.ActionUninstall,
-			RetryTimes: 1,
-			Commands: []v1.Command{
-				{
-					Type:         v1.CommandShell,
-					ShellCommand: []string{"ip", "link", "delete", "vxlan.calico"},
-				},
-			},
-		})
+	cni, err := LoadCNI(c.Type)
+	if err != nil {
+		return nil, err
 	}
-
-	return steps
+	return cni.CleanSteps(c, nodes), nil
 }
 
 func RemoveHostname(c *v1.Cluster, nodes []v1.StepNode) ([]v1.Step, error) {
@@ -20,8 +20,10 @@ package k8s
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 
+	"github.com/kubeclipper/kubeclipper/pkg/component"
 	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
 )
 
@@ -138,12 +140,114 @@ func TestCNI_renderCalicoTo(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			w := &bytes.Buffer{}
-			err := tt.stepper.renderCalicoTo(w)
+			err := tt.stepper.renderTo(w)
 			if err != nil {
-				t.Errorf("renderCalicoTo() error = %v", err)
+				t.Errorf("renderTo() error = %v", err)
 				return
 			}
 			t.Log(w.String())
 		})
 	}
 }
+
+func TestCertification_InstallSteps(t *testing.T) {
+	nodes := []v1.StepNode{
+		{ID: "m1", IPv4: "10.0.0.1"},
+		{ID: "m2", IPv4: "10.0.0.2"},
+	}
+	steps, err := (&Certification{}).InstallSteps(nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		name string
+		node string
+	}{
+		{"updateCerts", "m1"}, {"restartPods", "m1"}, {"checkControlPlane", "m1"},
+		{"updateCerts", "m2"}, {"restartPods", "m2"}, {"checkControlPlane", "m2"},
+	}
+	if len(steps) != len(want) {
+		t.Fatalf("got %d steps, want %d", len(steps), len(want))
+	}
+	for i, w := range want {
+		if steps[i].Name != w.name || len(steps[i].Nodes) != 1 || steps[i].Nodes[0].ID != w.node {
+			t.Errorf("step %d = %s on %v, want %s on %s", i, steps[i].Name, steps[i].Nodes, w.name, w.node)
+		}
+	}
+	if cmd := steps[2].Commands[0].ShellCommand[2]; !strings.Contains(cmd, "--server=https://10.0.0.1:6443") {
+		t.Errorf("health check of m1 is %q", cmd)
+	}
+}
+
+func TestRunnable_makeInstallSteps_dependencies(t *testing.T) {
+	r := Runnable{
+		Masters: v1.WorkerNodeList{{ID: "m1"}, {ID: "m2"}},
+		Workers: v1.WorkerNodeList{{ID: "w1"}},
+	}
+	r.CNI.Type = "calico"
+	r.CNI.Offline = true
+	r.Networking.Pods.CIDRBlocks = []string{"172.25.0.0/16"}
+	metadata := &component.ExtraMetadata{
+		Masters: component.NodeList{{ID: "m1", IPv4: "10.0.0.1"}, {ID: "m2", IPv4: "10.0.0.2"}},
+		Workers: component.NodeList{{ID: "w1", IPv4: "10.0.0.3"}},
+	}
+	steps, err := r.makeInstallSteps(metadata, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	op := &v1.Operation{Steps: steps}
+	if !op.HasStepDependencies() {
+		t.Fatal("install steps declare no dependencies")
+	}
+	// ancestors[i] holds the indexes of all steps which must be done before step i
+	ancestors := make([]map[int]bool, len(steps))
+	for i := range steps {
+		deps, err := op.StepDependencies(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i > 0 && len(deps) == 0 {
+			t.Errorf("step %d %s does not depend on any step", i, steps[i].Name)
+		}
+		ancestors[i] = map[int]bool{}
+		for _, d := range deps {
+			ancestors[i][d] = true
+			for a := range ancestors[d] {
+				ancestors[i][a] = true
+			}
+		}
+	}
+	find := func(name, node string) int {
+		for i, s := range steps {
+			if s.Name == name && s.Nodes[0].ID == node {
+				return i
+			}
+		}
+		t.Fatalf("step %s on %s not found", name, node)
+		return -1
+	}
+	parallel := [][2]int{
+		{find("installPackages", "m1"), find("installPackages", "w1")},
+		{find("cniImageLoader", "w1"), find("initControlPlane", "m1")},
+		{find("cniImageLoader", "m2"), find("joinNode", "w1")},
+		{find("renderKubeadmConfig", "m1"), find("installPackages", "m1")},
+	}
+	for _, p := range parallel {
+		if ancestors[p[0]][p[1]] || ancestors[p[1]][p[0]] {
+			t.Errorf("step %s on %s and step %s on %s do not run in parallel",
+				steps[p[0]].Name, steps[p[0]].Nodes[0].ID, steps[p[1]].Name, steps[p[1]].Nodes[0].ID)
+		}
+	}
+	serial := [][2]int{
+		{find("installPackages", "w1"), find("joinNode", "w1")},
+		{find("joinNode", "m2"), find("joinNode", "w1")},
+		{find("cniImageLoader", "w1"), find("installCNI", "m1")},
+		{find("initControlPlane", "m1"), find("applyKubectlPod", "m1")},
+	}
+	for _, s := range serial {
+		if !ancestors[s[1]][s[0]] {
+			t.Errorf("step %s on %s does not wait for step %s on %s",
+				steps[s[1]].Name, steps[s[1]].Nodes[0].ID, steps[s[0]].Name, steps[s[0]].Nodes[0].ID)
+		}
+	}
+}
@@ -166,19 +166,13 @@ func (stepper *GenNode) MakeSteps(metadata *component.ExtraMetadata, patchNodes
 		}
 		stepper.uninstallSteps = append(stepper.uninstallSteps, steps...)
 
-		// clean CNI config
+		// clean CNI images and virtual network interfaces
 		cn := CNIInfo{}
 		steps, err = cn.InitStepper(&stepper.Cluster.CNI, &stepper.Cluster.Networking).UninstallSteps(patchNodes)
 		if err != nil {
 			return err
 		}
 		stepper.uninstallSteps = append(stepper.uninstallSteps, steps...)
-
-		steps, err = CleanCNI(&stepper.Cluster.CNI, patchNodes)
-		if err != nil {
-			return err
-		}
-		stepper.uninstallSteps = append(stepper.uninstallSteps, steps...)
 		// clean Kubernetes config
 		stepper.uninstallSteps = append(stepper.uninstallSteps,
 			doCommandRemoveStep("removeKubernetesConfig", patchNodes, K8SDefaultConfigDir))
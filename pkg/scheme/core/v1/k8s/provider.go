@@ -0,0 +1,156 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	"github.com/kubeclipper/kubeclipper/pkg/component/utils"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1/cri"
+)
+
+func init() {
+	if err := component.RegisterClusterProvider(&kubeadmProvider{}); err != nil {
+		panic(err)
+	}
+}
+
+var _ component.ClusterProvider = (*kubeadmProvider)(nil)
+
+// kubeadmProvider installs the container runtime and kubernetes by kubeadm.
+type kubeadmProvider struct{}
+
+func (p *kubeadmProvider) Name() string {
+	return v1.ClusterKubeadm
+}
+
+func (p *kubeadmProvider) Validate(c *v1.Cluster) error {
+	runnable := Runnable(*c)
+	return runnable.Validate()
+}
+
+func (p *kubeadmProvider) InstallSteps(ctx context.Context, c *v1.Cluster) ([]v1.Step, error) {
+	metadata := component.GetExtraMetadata(ctx)
+	// Container runtime should be installed on all nodes.
+	steps, err := criSteps(ctx, &c.ContainerRuntime, v1.ActionInstall, utils.UnwrapNodeList(metadata.GetAllNodes()))
+	if err != nil {
+		return nil, err
+	}
+	runnable := Runnable(*c)
+	k8sSteps, err := runnable.GetStep(ctx, v1.ActionInstall)
+	if err != nil {
+		return nil, err
+	}
+	return append(steps, k8sSteps...), nil
+}
+
+func (p *kubeadmProvider) UninstallSteps(ctx context.Context, c *v1.Cluster) ([]v1.Step, error) {
+	metadata := component.GetExtraMetadata(ctx)
+	runnable := Runnable(*c)
+	steps, err := runnable.GetStep(ctx, v1.ActionUninstall)
+	if err != nil {
+		return nil, err
+	}
+	cSteps, err := criSteps(ctx, &c.ContainerRuntime, v1.ActionUninstall, utils.UnwrapNodeList(metadata.GetAllNodes()))
+	if err != nil {
+		return nil, err
+	}
+	return append(steps, cSteps...), nil
+}
+
+func (p *kubeadmProvider) UpgradeSteps(ctx context.Context, c *v1.Cluster) ([]v1.Step, error) {
+	metadata := component.GetExtraMetadata(ctx)
+	upgrade := &Upgrade{}
+	upgrade.InitStepper(&metadata, c)
+	if err := upgrade.Validate(); err != nil {
+		return nil, err
+	}
+	if err := upgrade.InitSteps(ctx); err != nil {
+		return nil, err
+	}
+	return upgrade.GetInstallSteps(), nil
+}
+
+func (p *kubeadmProvider) AddNodeSteps(ctx context.Context, c *v1.Cluster, nodes []v1.StepNode, role string) ([]v1.Step, error) {
+	steps, err := criSteps(ctx, &c.ContainerRuntime, v1.ActionInstall, nodes)
+	if err != nil {
+		return nil, err
+	}
+	pack := &Package{}
+	pSteps, err := pack.InitStepper(c).InstallSteps(nodes)
+	if err != nil {
+		return nil, err
+	}
+	steps = append(steps, pSteps...)
+	nSteps, err := nodeSteps(ctx, c, nodes, role, v1.ActionInstall)
+	if err != nil {
+		return nil, err
+	}
+	return append(steps, nSteps...), nil
+}
+
+func (p *kubeadmProvider) RemoveNodeSteps(ctx context.Context, c *v1.Cluster, nodes []v1.StepNode, role string) ([]v1.Step, error) {
+	steps, err := nodeSteps(ctx, c, nodes, role, v1.ActionUninstall)
+	if err != nil {
+		return nil, err
+	}
+	pack := &Package{}
+	pSteps, err := pack.InitStepper(c).UninstallSteps(nodes)
+	if err != nil {
+		return nil, err
+	}
+	steps = append(steps, pSteps...)
+	cSteps, err := criSteps(ctx, &c.ContainerRuntime, v1.ActionUninstall, nodes)
+	if err != nil {
+		return nil, err
+	}
+	return append(steps, cSteps...), nil
+}
+
+func nodeSteps(ctx context.Context, c *v1.Cluster, nodes []v1.StepNode, role string, action v1.StepAction) ([]v1.Step, error) {
+	metadata := component.GetExtraMetadata(ctx)
+	gen := GenNode{}
+	if err := gen.InitStepper(&metadata, c, role).MakeSteps(&metadata, nodes, role); err != nil {
+		return nil, err
+	}
+	return gen.GetSteps(action), nil
+}
+
+func criSteps(ctx context.Context, c *v1.ContainerRuntime, action v1.StepAction, nodes []v1.StepNode) ([]v1.Step, error) {
+	switch c.Type {
+	case v1.CRIDocker:
+		r := cri.DockerRunnable{}
+		err := r.InitStep(ctx, c, nodes)
+		if err != nil {
+			return nil, err
+		}
+		return r.GetActionSteps(action), nil
+	case v1.CRIContainerd:
+		r := cri.ContainerdRunnable{}
+		err := r.InitStep(ctx, c, nodes)
+		if err != nil {
+			return nil, err
+		}
+		return r.GetActionSteps(action), nil
+	}
+	return nil, fmt.Errorf("no support %v type cri", c.Type)
+}
@@ -0,0 +1,279 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/cmdutil"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
+)
+
+func init() {
+	if err := component.RegisterAgentStep(fmt.Sprintf(component.RegisterStepKeyFormat, restoreSnapshot, version, component.TypeStep), &RestoreSnapshot{}); err != nil {
+		panic(err)
+	}
+	if err := component.RegisterAgentStep(fmt.Sprintf(component.RegisterStepKeyFormat, rewriteNodes, version, component.TypeStep), &RewriteNodes{}); err != nil {
+		panic(err)
+	}
+}
+
+var (
+	_ component.StepRunnable = (*RestoreSnapshot)(nil)
+	_ component.StepRunnable = (*RewriteNodes)(nil)
+)
+
+const (
+	restoreSnapshot = "restoreSnapshot"
+	rewriteNodes    = "rewriteNodes"
+	// RestoreClusterDir is the directory where the snapshot of a new cluster is downloaded.
+	RestoreClusterDir = "/var/lib/kube-restore/.new-cluster"
+)
+
+type restoreCtxKey struct{}
+
+// ClusterRestore restores a backup as a new cluster on different nodes. The snapshot is downloaded
+// to the first master and restored as a single member etcd before kubeadm init, kubeadm generates
+// the certificates and kubeconfig for the new nodes, and the node objects of the nodes in the backup
+// which are not in the new cluster are deleted after the cluster is healthy. The snapshot is kept
+// until then, so that a retried kubeadm init restores it again.
+type ClusterRestore struct {
+	Snapshot RestoreSnapshot
+	Nodes    RewriteNodes
+}
+
+// WithClusterRestore returns a context restoring the cluster being installed from the backup.
+func WithClusterRestore(ctx context.Context, r *ClusterRestore) context.Context {
+	return context.WithValue(ctx, restoreCtxKey{}, r)
+}
+
+func getClusterRestore(ctx context.Context) *ClusterRestore {
+	r, _ := ctx.Value(restoreCtxKey{}).(*ClusterRestore)
+	return r
+}
+
+// NewClusterRestore returns the restore of the backup saved in the backup point, the nodes are the ones of the new cluster.
+func NewClusterRestore(bp *v1.BackupPoint, b *v1.Backup, nodes component.NodeList) (*ClusterRestore, error) {
+	backup, err := NewVerifyBackup(bp, b)
+	if err != nil {
+		return nil, err
+	}
+	r := &ClusterRestore{
+		Snapshot: RestoreSnapshot{
+			Backup:       backup,
+			SnapshotFile: filepath.Join(RestoreClusterDir, filepath.Base(b.Status.FileName)),
+		},
+		Nodes: RewriteNodes{
+			SnapshotFile: filepath.Join(RestoreClusterDir, filepath.Base(b.Status.FileName)),
+			BackupNodes:  b.ClusterNodes,
+			ClusterNodes: make(map[string]string, len(nodes)),
+		},
+	}
+	for _, n := range nodes {
+		r.Nodes.ClusterNodes[n.IPv4] = n.Hostname
+	}
+	return r, nil
+}
+
+// RestoreSnapshot downloads the snapshot of the backup, the snapshot is checked and decoded as the verification does.
+type RestoreSnapshot struct {
+	Backup       *VerifyBackup
+	SnapshotFile string
+}
+
+func (stepper *RestoreSnapshot) NewInstance() component.ObjectMeta {
+	return &RestoreSnapshot{}
+}
+
+func (stepper *RestoreSnapshot) InstallSteps(node v1.StepNode) ([]v1.Step, error) {
+	bytes, err := json.Marshal(stepper)
+	if err != nil {
+		return nil, err
+	}
+	return []v1.Step{
+		{
+			ID:         strutil.GetUUID(),
+			Name:       "downloadSnapshot",
+			Timeout:    metav1.Duration{Duration: 10 * time.Minute},
+			ErrIgnore:  false,
+			RetryTimes: 1,
+			Nodes:      []v1.StepNode{node},
+			Action:     v1.ActionInstall,
+			Commands: []v1.Command{
+				{
+					Type:          v1.CommandCustom,
+					Identity:      fmt.Sprintf(component.RegisterStepKeyFormat, restoreSnapshot, version, component.TypeStep),
+					CustomCommand: bytes,
+				},
+			},
+		},
+	}, nil
+}
+
+func (stepper *RestoreSnapshot) Install(ctx context.Context, opts component.Options) ([]byte, error) {
+	if stepper.Backup == nil {
+		return nil, fmt.Errorf("backup of the snapshot is empty")
+	}
+	if err := os.MkdirAll(filepath.Dir(stepper.SnapshotFile), os.ModePerm); err != nil {
+		logger.Errorf("mkdir restore dir failed: %s", err.Error())
+		return nil, err
+	}
+	checkFile := CheckFile{
+		BackupFileSize: stepper.Backup.BackupFileSize,
+		BackupFileMD5:  stepper.Backup.BackupFileMD5,
+		Checksum:       stepper.Backup.BackupFileChecksum,
+	}
+	if err := stepper.Backup.download(ctx, stepper.SnapshotFile, checkFile); err != nil {
+		return nil, err
+	}
+	logger.Info("download snapshot of the new cluster successfully")
+	return nil, nil
+}
+
+func (stepper *RestoreSnapshot) Uninstall(ctx context.Context, opts component.Options) ([]byte, error) {
+	return nil, os.RemoveAll(stepper.SnapshotFile)
+}
+
+// restoreEtcdSnapshot restores the snapshot as the data of a single member etcd,
+// the other masters are added to the etcd cluster when they join.
+func restoreEtcdSnapshot(ctx context.Context, opts component.Options, snapshot, dataDir, name, ip string) error {
+	peerURL := fmt.Sprintf("https://%s:2380", ip)
+	cmd := fmt.Sprintf("rm -rf %s && ETCDCTL_API=3 etcdctl snapshot restore %s --name %s --initial-cluster %s=%s --initial-advertise-peer-urls %s --data-dir %s",
+		dataDir, snapshot, name, name, peerURL, peerURL, dataDir)
+	ec, err := cmdutil.RunCmdWithContext(ctx, opts.DryRun, "bash", "-c", cmd)
+	if err != nil {
+		if ec != nil {
+			logger.Errorf("etcd snapshot restore failed: %s", ec.StdErr())
+		}
+		return err
+	}
+	return nil
+}
+
+// etcdDataDirPreflight returns the kubeadm preflight check of the etcd data dir, it fails when the dir is not empty.
+func etcdDataDirPreflight(dataDir string) string {
+	return "DirAvailable-" + strings.ReplaceAll(filepath.Clean(dataDir), "/", "-")
+}
+
+// RewriteNodes deletes the node objects restored from the backup, whose nodes are not in the new cluster.
+// The token and ca of the service account token secrets are cleared as well, they are signed by the key
+// of the cluster in the backup and the token controller fills them in again with the new key. The
+// secrets are kept since the controller does not recreate the manually created ones.
+type RewriteNodes struct {
+	// the restored snapshot, it is removed once the control plane is up
+	SnapshotFile string
+	// ip and hostname of the nodes in the backup
+	BackupNodes map[string]string
+	// ip and hostname of the nodes in the new cluster
+	ClusterNodes map[string]string
+}
+
+func (stepper *RewriteNodes) NewInstance() component.ObjectMeta {
+	return &RewriteNodes{}
+}
+
+func (stepper *RewriteNodes) InstallSteps(node v1.StepNode) ([]v1.Step, error) {
+	bytes, err := json.Marshal(stepper)
+	if err != nil {
+		return nil, err
+	}
+	return []v1.Step{
+		{
+			ID:         strutil.GetUUID(),
+			Name:       rewriteNodes,
+			Timeout:    metav1.Duration{Duration: 3 * time.Minute},
+			ErrIgnore:  false,
+			RetryTimes: 1,
+			Nodes:      []v1.StepNode{node},
+			Action:     v1.ActionInstall,
+			Commands: []v1.Command{
+				{
+					Type:          v1.CommandCustom,
+					Identity:      fmt.Sprintf(component.RegisterStepKeyFormat, rewriteNodes, version, component.TypeStep),
+					CustomCommand: bytes,
+				},
+			},
+		},
+	}, nil
+}
+
+// clearServiceAccountTokensCmd removes the token and ca of every service account token secret, a merge patch
+// with null values does not fail when the key is missing.
+const clearServiceAccountTokensCmd = `kubectl get secret --all-namespaces --field-selector type=kubernetes.io/service-account-token ` +
+	`-o jsonpath='{range .items[*]}{.metadata.namespace}{" "}{.metadata.name}{"\n"}{end}' | ` +
+	`while read ns name; do kubectl -n "$ns" patch secret "$name" --type merge -p '{"data":{"token":null,"ca.crt":null}}' || exit 1; done`
+
+// StaleNodes returns the names of the nodes in the backup which are not in the new cluster.
+func (stepper *RewriteNodes) StaleNodes() []string {
+	current := sets.NewString()
+	for _, hostname := range stepper.ClusterNodes {
+		current.Insert(strings.ToLower(hostname))
+	}
+	stale := sets.NewString()
+	for _, hostname := range stepper.BackupNodes {
+		// the node name registered by kubelet is the lower case hostname
+		if name := strings.ToLower(hostname); !current.Has(name) {
+			stale.Insert(name)
+		}
+	}
+	return stale.List()
+}
+
+func (stepper *RewriteNodes) Install(ctx context.Context, opts component.Options) ([]byte, error) {
+	if stale := stepper.StaleNodes(); len(stale) > 0 {
+		ec, err := cmdutil.RunCmdWithContext(ctx, opts.DryRun, "bash", "-c",
+			fmt.Sprintf("kubectl delete node %s --ignore-not-found", strings.Join(stale, " ")))
+		if err != nil {
+			if ec != nil {
+				logger.Errorf("delete stale nodes failed: %s", ec.StdErr())
+			}
+			return nil, err
+		}
+	}
+	ec, err := cmdutil.RunCmdWithContext(ctx, opts.DryRun, "bash", "-c", clearServiceAccountTokensCmd)
+	if err != nil {
+		if ec != nil {
+			logger.Errorf("clear service account tokens failed: %s", ec.StdErr())
+		}
+		return nil, err
+	}
+	if stepper.SnapshotFile != "" && !opts.DryRun {
+		if err = os.Remove(stepper.SnapshotFile); err != nil && !os.IsNotExist(err) {
+			logger.Warnf("remove restored snapshot failed: %s", err.Error())
+		}
+	}
+	logger.Info("rewrite nodes of the restored cluster successfully")
+	return nil, nil
+}
+
+func (stepper *RewriteNodes) Uninstall(ctx context.Context, opts component.Options) ([]byte, error) {
+	return nil, fmt.Errorf("rewrite nodes no support uninstall")
+}
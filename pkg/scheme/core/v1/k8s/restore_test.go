@@ -0,0 +1,71 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package k8s
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRewriteNodes_StaleNodes(t *testing.T) {
+	tests := []struct {
+		name    string
+		stepper RewriteNodes
+		want    []string
+	}{
+		{
+			name: "same nodes",
+			stepper: RewriteNodes{
+				BackupNodes:  map[string]string{"10.0.0.1": "master-1"},
+				ClusterNodes: map[string]string{"10.0.0.2": "master-1"},
+			},
+			want: []string{},
+		},
+		{
+			name: "nodes replaced",
+			stepper: RewriteNodes{
+				BackupNodes:  map[string]string{"10.0.0.1": "master-1", "10.0.0.3": "Worker-1", "10.0.0.4": "worker-2"},
+				ClusterNodes: map[string]string{"10.0.1.1": "new-master", "10.0.1.2": "worker-2"},
+			},
+			want: []string{"master-1", "worker-1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.stepper.StaleNodes(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("StaleNodes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_etcdDataDirPreflight(t *testing.T) {
+	tests := []struct {
+		dataDir string
+		want    string
+	}{
+		{dataDir: "/var/lib/etcd", want: "DirAvailable--var-lib-etcd"},
+		{dataDir: "/data/etcd/", want: "DirAvailable--data-etcd"},
+	}
+	for _, tt := range tests {
+		if got := etcdDataDirPreflight(tt.dataDir); got != tt.want {
+			t.Errorf("etcdDataDirPreflight(%s) = %v, want %v", tt.dataDir, got, tt.want)
+		}
+	}
+}
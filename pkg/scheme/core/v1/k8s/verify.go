@@ -0,0 +1,272 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	bs "github.com/kubeclipper/kubeclipper/pkg/simple/backupstore"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/cmdutil"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
+)
+
+func init() {
+	if err := component.RegisterAgentStep(fmt.Sprintf(component.RegisterStepKeyFormat, verifyBackup, version, component.TypeStep), &VerifyBackup{}); err != nil {
+		panic(err)
+	}
+}
+
+var _ component.StepRunnable = (*VerifyBackup)(nil)
+
+const (
+	verifyBackup = "verifyBackup"
+	// VerifyBackupStep is the name of the step verifying the backup,
+	// it responds with a BackupVerification.
+	VerifyBackupStep = "verifyBackup"
+	// VerifyBackupScratchDir is the directory where the snapshot is downloaded and restored.
+	VerifyBackupScratchDir = "/var/lib/kube-restore/.verify"
+)
+
+// VerifyBackup downloads the snapshot and restores it as a single member etcd in a scratch directory,
+// which proves the backup is restorable without touching the cluster.
+type VerifyBackup struct {
+	BackupFileName     string
+	BackupPointRootDir string
+	StoreType          string
+	Bucket             string
+	Endpoint           string
+	AccessKeyID        string
+	AccessKeySecret    string
+	Region             string
+	SSL                bool
+	// config of the sftp and webdav backup points
+	SftpConfig   *v1.SftpConfig   `json:",omitempty"`
+	WebDAVConfig *v1.WebDAVConfig `json:",omitempty"`
+	// the size and md5 of the backup file, they are read from the response of the
+	// previous step when empty, which is the step creating the backup.
+	BackupFileSize     int64
+	BackupFileMD5      string
+	BackupFileChecksum string
+	// decrypts and decompresses the downloaded backup file
+	Codec      *bs.Codec `json:",omitempty"`
+	ScratchDir string
+}
+
+// BackupVerification is the snapshot status reported by the verification.
+type BackupVerification struct {
+	KeyCount  int64 `json:"totalKey"`
+	Revision  int64 `json:"revision"`
+	Hash      int64 `json:"hash"`
+	TotalSize int64 `json:"totalSize"`
+}
+
+// NewVerifyBackup returns the verification of the backup saved in the backup point,
+// the size and md5 value of the backup are empty when it is being created.
+func NewVerifyBackup(bp *v1.BackupPoint, b *v1.Backup) (*VerifyBackup, error) {
+	codec, err := NewBackupCodec(bp, b)
+	if err != nil {
+		return nil, err
+	}
+	stepper := &VerifyBackup{
+		StoreType:          bp.StorageType,
+		BackupFileName:     b.Status.FileName,
+		BackupFileSize:     b.Status.BackupFileSize,
+		BackupFileMD5:      b.Status.BackupFileMD5,
+		BackupFileChecksum: b.Status.Checksum,
+		Codec:              codec,
+		ScratchDir:         VerifyBackupScratchDir,
+	}
+	switch bp.StorageType {
+	case bs.S3Storage:
+		stepper.AccessKeyID = bp.S3Config.AccessKeyID
+		stepper.AccessKeySecret = bp.S3Config.AccessKeySecret
+		stepper.Bucket = bp.S3Config.Bucket
+		stepper.Endpoint = bp.S3Config.Endpoint
+	case bs.FSStorage:
+		stepper.BackupPointRootDir = bp.FsConfig.BackupRootDir
+	case bs.SFTPStorage:
+		stepper.SftpConfig = bp.SftpConfig
+	case bs.WebDAVStorage:
+		stepper.WebDAVConfig = bp.WebDAVConfig
+	}
+	return stepper, nil
+}
+
+func (stepper *VerifyBackup) NewInstance() component.ObjectMeta {
+	return &VerifyBackup{}
+}
+
+// InstallSteps returns the step verifying the backup on the node, the step is ignored
+// on error when it follows the step creating the backup, so the backup is still available.
+func (stepper *VerifyBackup) InstallSteps(node v1.StepNode, errIgnore bool) ([]v1.Step, error) {
+	if stepper.ScratchDir == "" {
+		stepper.ScratchDir = VerifyBackupScratchDir
+	}
+	bytes, err := json.Marshal(stepper)
+	if err != nil {
+		return nil, err
+	}
+	return []v1.Step{
+		{
+			ID:         strutil.GetUUID(),
+			Name:       VerifyBackupStep,
+			Timeout:    metav1.Duration{Duration: 10 * time.Minute},
+			ErrIgnore:  errIgnore,
+			RetryTimes: 0,
+			Nodes:      []v1.StepNode{node},
+			Action:     v1.ActionInstall,
+			Commands: []v1.Command{
+				{
+					Type:          v1.CommandCustom,
+					Identity:      fmt.Sprintf(component.RegisterStepKeyFormat, verifyBackup, version, component.TypeStep),
+					CustomCommand: bytes,
+				},
+			},
+		},
+	}, nil
+}
+
+func (stepper *VerifyBackup) Install(ctx context.Context, opts component.Options) ([]byte, error) {
+	checkFile := CheckFile{BackupFileSize: stepper.BackupFileSize, BackupFileMD5: stepper.BackupFileMD5, Checksum: stepper.BackupFileChecksum}
+	if checkFile.BackupFileMD5 == "" {
+		if err := json.Unmarshal(component.GetExtraData(ctx), &checkFile); err != nil {
+			return nil, errors.WithMessage(err, "get backup file size and md5 value")
+		}
+	}
+	// the scratch directory is always cleaned, it is as large as the etcd data
+	dir := filepath.Join(stepper.ScratchDir, filepath.Base(stepper.BackupFileName))
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		logger.Errorf("mkdir verify scratch dir failed: %s", err.Error())
+		return nil, err
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			logger.Warnf("remove verify scratch dir failed: %s", err.Error())
+		}
+	}()
+
+	snapshot := filepath.Join(dir, filepath.Base(stepper.BackupFileName))
+	if err := stepper.download(ctx, snapshot, checkFile); err != nil {
+		return nil, err
+	}
+
+	etcdutl := "ETCDCTL_API=3 etcdctl"
+	if _, err := exec.LookPath("etcdutl"); err == nil {
+		etcdutl = "etcdutl"
+	}
+	ec, err := cmdutil.RunCmdWithContext(ctx, opts.DryRun, "bash", "-c", fmt.Sprintf("%s snapshot status %s --write-out=json", etcdutl, snapshot))
+	if err != nil {
+		if ec != nil {
+			logger.Errorf("etcd snapshot status failed: %s", ec.StdErr())
+		}
+		return nil, err
+	}
+	verification := &BackupVerification{}
+	if !opts.DryRun {
+		if err = json.Unmarshal([]byte(ec.StdOut()), verification); err != nil {
+			return nil, errors.WithMessage(err, "parse etcd snapshot status")
+		}
+	}
+	// restore a single member etcd, the snapshot integrity hash is checked by the restore
+	ec, err = cmdutil.RunCmdWithContext(ctx, opts.DryRun, "bash", "-c",
+		fmt.Sprintf("%s snapshot restore %s --name verify --data-dir %s", etcdutl, snapshot, filepath.Join(dir, "etcd")))
+	if err != nil {
+		if ec != nil {
+			logger.Errorf("etcd snapshot restore failed: %s", ec.StdErr())
+		}
+		return nil, err
+	}
+
+	logger.Info("etcd backup file verify successfully")
+
+	return json.Marshal(verification)
+}
+
+func (stepper *VerifyBackup) Uninstall(ctx context.Context, opts component.Options) ([]byte, error) {
+	return nil, fmt.Errorf("verify backup no support uninstall")
+}
+
+// download downloads the snapshot and checks its size and md5 value, the snapshot is decoded after checked.
+func (stepper *VerifyBackup) download(ctx context.Context, snapshot string, checkFile CheckFile) error {
+	downloadFile := snapshot
+	if stepper.Codec != nil {
+		downloadFile += encodedFileSuffix
+		defer os.Remove(downloadFile)
+	}
+	writer, err := os.OpenFile(downloadFile, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0600)
+	if err != nil {
+		logger.Errorf("create verify file failed: %s", err.Error())
+		return err
+	}
+	defer writer.Close()
+
+	store, err := stepper.BackupStoreCreate()
+	if err != nil {
+		logger.Errorf("create backup store failed: %s", err.Error())
+		return err
+	}
+	if err = store.Download(ctx, stepper.BackupFileName, writer); err != nil {
+		logger.Errorf("download backup file failed: %s", err.Error())
+		return err
+	}
+
+	if err = checkBackupFile(downloadFile, checkFile.BackupFileSize, checkFile.BackupFileMD5, checkFile.Checksum); err != nil {
+		return err
+	}
+	if stepper.Codec != nil {
+		return decodeBackupFile(stepper.Codec, downloadFile, snapshot)
+	}
+	return nil
+}
+
+func (stepper *VerifyBackup) BackupStoreCreate() (bs.BackupStore, error) {
+	switch stepper.StoreType {
+	case bs.S3Storage:
+		store := &bs.ObjectStore{
+			Bucket:          stepper.Bucket,
+			Endpoint:        stepper.Endpoint,
+			AccessKeyID:     stepper.AccessKeyID,
+			AccessKeySecret: stepper.AccessKeySecret,
+		}
+		return store.Create()
+	case bs.SFTPStorage:
+		return newSftpStore(stepper.SftpConfig)
+	case bs.WebDAVStorage:
+		return newWebDAVStore(stepper.WebDAVConfig)
+	}
+	store := &bs.FilesystemStore{
+		RootDir: stepper.BackupPointRootDir,
+	}
+	return store.Create()
+}
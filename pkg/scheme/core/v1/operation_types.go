@@ -20,6 +20,7 @@ package v1
 
 import (
 	"errors"
+	"fmt"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -57,6 +58,46 @@ func (op *Operation) GetStep(stepID string) (step Step, ok bool) {
 	return
 }
 
+// HasStepDependencies reports whether any step of the operation declares its dependencies.
+// Steps of such an operation run as soon as their dependencies are done, otherwise they run one after another.
+// The first step is the only step without dependencies in both cases.
+func (op *Operation) HasStepDependencies() bool {
+	for _, s := range op.Steps {
+		if len(s.DependsOn) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// StepDependencies returns the indexes of the steps which must be done before the step with index i.
+// A step can only depend on the steps before it, so the order of steps is always a valid serial order.
+// A step without declared dependencies depends on the step before it, so the steps appended by a
+// generator which does not declare dependencies still run in order.
+func (op *Operation) StepDependencies(i int) ([]int, error) {
+	if !op.HasStepDependencies() || len(op.Steps[i].DependsOn) == 0 {
+		if i == 0 {
+			return nil, nil
+		}
+		return []int{i - 1}, nil
+	}
+	var deps []int
+	for _, id := range op.Steps[i].DependsOn {
+		found := false
+		for j := 0; j < i; j++ {
+			if op.Steps[j].ID == id {
+				deps = append(deps, j)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("step %s depends on %s, which is not a previous step", op.Steps[i].ID, id)
+		}
+	}
+	return deps, nil
+}
+
 // default operation timeout is 90 min
 
 const DefaultOperationTimeoutSecs = "5400"
@@ -68,13 +109,30 @@ const (
 	OperationStatusFailed     OperationStatusType = "failed"
 	OperationStatusUnknown    OperationStatusType = "unknown"
 	OperationStatusSuccessful OperationStatusType = "successful"
+	OperationStatusCancelled  OperationStatusType = "cancelled"
 )
 
 type OperationStatus struct {
 	Status     OperationStatusType  `json:"status,omitempty"`
 	Conditions []OperationCondition `json:"conditions,omitempty"`
+	// (brief) reason for the operation's last status transition, it is only set when the
+	// status is not changed by the steps, e.g. the server running the operation is gone.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Human readable message indicating details about last status transition.
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
+const (
+	// OperationReasonRunnerLost means the server running the operation is gone and
+	// the operation can not be resumed by the leader.
+	OperationReasonRunnerLost = "RunnerLost"
+	// OperationReasonResumed means the operation was resumed by the leader after the
+	// server running it was gone.
+	OperationReasonResumed = "Resumed"
+)
+
 type StepAction string
 
 var ErrInvalidAction = errors.New("invalid step action")
@@ -93,10 +151,12 @@ const (
 	OperationRemoveNodes         = "RemoveNodes"
 	OperationBackupCluster       = "BackupCluster"
 	OperationDeleteBackup        = "DeleteBackup"
+	OperationVerifyBackup        = "VerifyBackup"
 	OperationRecoverCluster      = "RecoveryCluster"
 	OperationInstallComponents   = "InstallComponents"
 	OperationUninstallComponents = "UninstallComponents"
 	OperationUpdateCertification = "UpdateCertifications"
+	OperationEtcdMaintenance     = "EtcdMaintenance"
 )
 
 // Step TODO: add commands struct instead of string
@@ -111,6 +171,22 @@ type Step struct {
 	BeforeRunCommands []Command       `json:"beforeRunCommands,omitempty"`
 	AfterRunCommands  []Command       `json:"afterRunCommands,omitempty"`
 	RetryTimes        int32           `json:"retryTimes,omitempty"`
+	// RetryBackoff controls the wait between two retries of the step,
+	// the server uses a default exponential backoff when it is not set.
+	RetryBackoff *StepBackoff `json:"retryBackoff,omitempty"`
+	// DependsOn is the IDs of the steps which must be done before this step.
+	// A step which does not declare it runs after the step before it.
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+// StepBackoff describes the exponential backoff between step retries.
+type StepBackoff struct {
+	// Duration is the wait before the first retry.
+	Duration metav1.Duration `json:"duration,omitempty"`
+	// Factor multiplies the wait after every retry, values not greater than 1 keep it unchanged.
+	Factor float64 `json:"factor,omitempty"`
+	// Cap is the upper limit of the wait, zero means no limit.
+	Cap metav1.Duration `json:"cap,omitempty"`
 }
 
 type StepNode struct {
@@ -146,6 +222,16 @@ type OperationCondition struct {
 	Status []StepStatus `json:"status,omitempty"`
 }
 
+// NodeStatus returns the latest status of every node recorded in the condition.
+// A node may be recorded more than once when the step is retried, the last record wins.
+func (c *OperationCondition) NodeStatus() map[string]StepStatusType {
+	status := make(map[string]StepStatusType, len(c.Status))
+	for _, s := range c.Status {
+		status[s.Node] = s.Status
+	}
+	return status
+}
+
 type StepStatusType string
 
 const (
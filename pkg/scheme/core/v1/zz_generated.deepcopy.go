@@ -64,7 +64,7 @@ func (in *Backup) DeepCopyInto(out *Backup) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	if in.ClusterNodes != nil {
 		in, out := &in.ClusterNodes, &out.ClusterNodes
 		*out = make(map[string]string, len(*in))
@@ -93,6 +93,39 @@ func (in *Backup) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupCondition) DeepCopyInto(out *BackupCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupCondition.
+func (in *BackupCondition) DeepCopy() *BackupCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupEncryption) DeepCopyInto(out *BackupEncryption) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupEncryption.
+func (in *BackupEncryption) DeepCopy() *BackupEncryption {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupEncryption)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackupList) DeepCopyInto(out *BackupList) {
 	*out = *in
@@ -141,6 +174,26 @@ func (in *BackupPoint) DeepCopyInto(out *BackupPoint) {
 		*out = new(S3Config)
 		**out = **in
 	}
+	if in.SftpConfig != nil {
+		in, out := &in.SftpConfig, &out.SftpConfig
+		*out = new(SftpConfig)
+		**out = **in
+	}
+	if in.WebDAVConfig != nil {
+		in, out := &in.WebDAVConfig, &out.WebDAVConfig
+		*out = new(WebDAVConfig)
+		**out = **in
+	}
+	if in.RetentionPolicy != nil {
+		in, out := &in.RetentionPolicy, &out.RetentionPolicy
+		*out = new(RetentionPolicy)
+		**out = **in
+	}
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		*out = new(BackupEncryption)
+		**out = **in
+	}
 	return
 }
 
@@ -198,6 +251,13 @@ func (in *BackupPointList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackupStatus) DeepCopyInto(out *BackupStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]BackupCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -219,6 +279,16 @@ func (in *CNI) DeepCopyInto(out *CNI) {
 		*out = new(Calico)
 		**out = **in
 	}
+	if in.Cilium != nil {
+		in, out := &in.Cilium, &out.Cilium
+		*out = new(Cilium)
+		**out = **in
+	}
+	if in.Flannel != nil {
+		in, out := &in.Flannel, &out.Flannel
+		*out = new(Flannel)
+		**out = **in
+	}
 	return
 }
 
@@ -248,6 +318,83 @@ func (in *Calico) DeepCopy() *Calico {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Cilium) DeepCopyInto(out *Cilium) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Cilium.
+func (in *Cilium) DeepCopy() *Cilium {
+	if in == nil {
+		return nil
+	}
+	out := new(Cilium)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Flannel) DeepCopyInto(out *Flannel) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Flannel.
+func (in *Flannel) DeepCopy() *Flannel {
+	if in == nil {
+		return nil
+	}
+	out := new(Flannel)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertRotationPolicy) DeepCopyInto(out *CertRotationPolicy) {
+	*out = *in
+	if in.MaintenanceWindow != nil {
+		in, out := &in.MaintenanceWindow, &out.MaintenanceWindow
+		*out = new(MaintenanceWindow)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertRotationPolicy.
+func (in *CertRotationPolicy) DeepCopy() *CertRotationPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(CertRotationPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertRotationStatus) DeepCopyInto(out *CertRotationStatus) {
+	*out = *in
+	if in.NextRotationTime != nil {
+		in, out := &in.NextRotationTime, &out.NextRotationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastRotationTime != nil {
+		in, out := &in.LastRotationTime, &out.LastRotationTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertRotationStatus.
+func (in *CertRotationStatus) DeepCopy() *CertRotationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CertRotationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Certification) DeepCopyInto(out *Certification) {
 	*out = *in
@@ -291,7 +438,7 @@ func (in *Cluster) DeepCopyInto(out *Cluster) {
 		copy(*out, *in)
 	}
 	out.KubeProxy = in.KubeProxy
-	out.Etcd = in.Etcd
+	in.Etcd.DeepCopyInto(&out.Etcd)
 	out.Kubelet = in.Kubelet
 	in.Networking.DeepCopyInto(&out.Networking)
 	in.ContainerRuntime.DeepCopyInto(&out.ContainerRuntime)
@@ -308,6 +455,16 @@ func (in *Cluster) DeepCopyInto(out *Cluster) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.RestoreFrom != nil {
+		in, out := &in.RestoreFrom, &out.RestoreFrom
+		*out = new(RestoreFrom)
+		**out = **in
+	}
+	if in.CertRotationPolicy != nil {
+		in, out := &in.CertRotationPolicy, &out.CertRotationPolicy
+		*out = new(CertRotationPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	in.Status.DeepCopyInto(&out.Status)
 	return
 }
@@ -379,6 +536,8 @@ func (in *ClusterStatus) DeepCopyInto(out *ClusterStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	in.Etcd.DeepCopyInto(&out.Etcd)
+	in.CertRotation.DeepCopyInto(&out.CertRotation)
 	return
 }
 
@@ -544,6 +703,11 @@ func (in *CronBackupSpec) DeepCopyInto(out *CronBackupSpec) {
 		in, out := &in.RunAt, &out.RunAt
 		*out = (*in).DeepCopy()
 	}
+	if in.RetentionPolicy != nil {
+		in, out := &in.RetentionPolicy, &out.RetentionPolicy
+		*out = new(RetentionPolicy)
+		**out = **in
+	}
 	return
 }
 
@@ -684,6 +848,11 @@ func (in *DomainSpec) DeepCopyInto(out *DomainSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Forwarders != nil {
+		in, out := &in.Forwarders, &out.Forwarders
+		*out = new(Forwarders)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -716,6 +885,11 @@ func (in *DomainStatus) DeepCopy() *DomainStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Etcd) DeepCopyInto(out *Etcd) {
 	*out = *in
+	if in.Maintenance != nil {
+		in, out := &in.Maintenance, &out.Maintenance
+		*out = new(EtcdMaintenance)
+		**out = **in
+	}
 	return
 }
 
@@ -729,6 +903,70 @@ func (in *Etcd) DeepCopy() *Etcd {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdMaintenance) DeepCopyInto(out *EtcdMaintenance) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdMaintenance.
+func (in *EtcdMaintenance) DeepCopy() *EtcdMaintenance {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdMaintenance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdMemberStatus) DeepCopyInto(out *EtcdMemberStatus) {
+	*out = *in
+	in.MeasureTime.DeepCopyInto(&out.MeasureTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdMemberStatus.
+func (in *EtcdMemberStatus) DeepCopy() *EtcdMemberStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdMemberStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdStatus) DeepCopyInto(out *EtcdStatus) {
+	*out = *in
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]EtcdMemberStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NextMaintenanceTime != nil {
+		in, out := &in.NextMaintenanceTime, &out.NextMaintenanceTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastMaintenanceTime != nil {
+		in, out := &in.LastMaintenanceTime, &out.LastMaintenanceTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdStatus.
+func (in *EtcdStatus) DeepCopy() *EtcdStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Event) DeepCopyInto(out *Event) {
 	*out = *in
@@ -790,6 +1028,27 @@ func (in *EventList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Forwarders) DeepCopyInto(out *Forwarders) {
+	*out = *in
+	if in.Upstreams != nil {
+		in, out := &in.Upstreams, &out.Upstreams
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Forwarders.
+func (in *Forwarders) DeepCopy() *Forwarders {
+	if in == nil {
+		return nil
+	}
+	out := new(Forwarders)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FsConfig) DeepCopyInto(out *FsConfig) {
 	*out = *in
@@ -855,6 +1114,22 @@ func (in *Kubelet) DeepCopy() *Kubelet {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NetworkRanges) DeepCopyInto(out *NetworkRanges) {
 	*out = *in
@@ -881,6 +1156,11 @@ func (in *Networking) DeepCopyInto(out *Networking) {
 	*out = *in
 	in.Services.DeepCopyInto(&out.Services)
 	in.Pods.DeepCopyInto(&out.Pods)
+	if in.UpstreamDNS != nil {
+		in, out := &in.UpstreamDNS, &out.UpstreamDNS
+		*out = new(Forwarders)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1399,6 +1679,39 @@ func (in *RegionList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreFrom) DeepCopyInto(out *RestoreFrom) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreFrom.
+func (in *RestoreFrom) DeepCopy() *RestoreFrom {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreFrom)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetentionPolicy) DeepCopyInto(out *RetentionPolicy) {
+	*out = *in
+	out.MinAge = in.MinAge
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetentionPolicy.
+func (in *RetentionPolicy) DeepCopy() *RetentionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RetentionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in ResourceList) DeepCopyInto(out *ResourceList) {
 	{
@@ -1437,6 +1750,22 @@ func (in *S3Config) DeepCopy() *S3Config {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SftpConfig) DeepCopyInto(out *SftpConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SftpConfig.
+func (in *SftpConfig) DeepCopy() *SftpConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SftpConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Step) DeepCopyInto(out *Step) {
 	*out = *in
@@ -1467,6 +1796,16 @@ func (in *Step) DeepCopyInto(out *Step) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.RetryBackoff != nil {
+		in, out := &in.RetryBackoff, &out.RetryBackoff
+		*out = new(StepBackoff)
+		**out = **in
+	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -1480,6 +1819,24 @@ func (in *Step) DeepCopy() *Step {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StepBackoff) DeepCopyInto(out *StepBackoff) {
+	*out = *in
+	out.Duration = in.Duration
+	out.Cap = in.Cap
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StepBackoff.
+func (in *StepBackoff) DeepCopy() *StepBackoff {
+	if in == nil {
+		return nil
+	}
+	out := new(StepBackoff)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StepNode) DeepCopyInto(out *StepNode) {
 	*out = *in
@@ -1616,6 +1973,22 @@ func (in *TemplateList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebDAVConfig) DeepCopyInto(out *WebDAVConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebDAVConfig.
+func (in *WebDAVConfig) DeepCopy() *WebDAVConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(WebDAVConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WebTerminal) DeepCopyInto(out *WebTerminal) {
 	*out = *in
@@ -0,0 +1,91 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package validation
+
+import (
+	"net/url"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	corev1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	bs "github.com/kubeclipper/kubeclipper/pkg/simple/backupstore"
+)
+
+var supportedStorageTypes = []string{bs.FSStorage, bs.S3Storage, bs.SFTPStorage, bs.WebDAVStorage}
+
+// ValidateBackupPoint validates the storage config of a BackupPoint.
+func ValidateBackupPoint(bp *corev1.BackupPoint) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	switch bp.StorageType {
+	case bs.FSStorage:
+		fld := field.NewPath("fsConfig")
+		if bp.FsConfig == nil {
+			allErrs = append(allErrs, field.Required(fld, "fsConfig is required for fs storage"))
+		} else if bp.FsConfig.BackupRootDir == "" {
+			allErrs = append(allErrs, field.Required(fld.Child("backupRootDir"), ""))
+		}
+	case bs.S3Storage:
+		fld := field.NewPath("s3Config")
+		if bp.S3Config == nil {
+			allErrs = append(allErrs, field.Required(fld, "s3Config is required for s3 storage"))
+			break
+		}
+		if bp.S3Config.Bucket == "" {
+			allErrs = append(allErrs, field.Required(fld.Child("bucket"), ""))
+		}
+		if bp.S3Config.Endpoint == "" {
+			allErrs = append(allErrs, field.Required(fld.Child("endpoint"), ""))
+		}
+	case bs.SFTPStorage:
+		fld := field.NewPath("sftpConfig")
+		if bp.SftpConfig == nil {
+			allErrs = append(allErrs, field.Required(fld, "sftpConfig is required for sftp storage"))
+			break
+		}
+		if bp.SftpConfig.Host == "" {
+			allErrs = append(allErrs, field.Required(fld.Child("host"), ""))
+		}
+		if bp.SftpConfig.Username == "" {
+			allErrs = append(allErrs, field.Required(fld.Child("username"), ""))
+		}
+		if bp.SftpConfig.Password == "" && bp.SftpConfig.PrivateKey == "" {
+			allErrs = append(allErrs, field.Required(fld.Child("password"), "password or privateKey is required"))
+		}
+	case bs.WebDAVStorage:
+		fld := field.NewPath("webdavConfig")
+		if bp.WebDAVConfig == nil {
+			allErrs = append(allErrs, field.Required(fld, "webdavConfig is required for webdav storage"))
+			break
+		}
+		u, err := url.Parse(bp.WebDAVConfig.Endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(fld.Child("endpoint"), bp.WebDAVConfig.Endpoint, "must be a valid http or https URL"))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("storageType"), bp.StorageType, supportedStorageTypes))
+	}
+
+	if bp.RetentionPolicy != nil {
+		if err := bp.RetentionPolicy.Validate(); err != nil {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("retentionPolicy"), bp.RetentionPolicy, err.Error()))
+		}
+	}
+	return allErrs
+}
@@ -0,0 +1,88 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package validation
+
+import (
+	"testing"
+
+	corev1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func TestValidateBackupPoint(t *testing.T) {
+	tests := []struct {
+		name    string
+		bp      *corev1.BackupPoint
+		wantErr bool
+	}{
+		{
+			name: "fs",
+			bp:   &corev1.BackupPoint{StorageType: "fs", FsConfig: &corev1.FsConfig{BackupRootDir: "/opt/kc/backups"}},
+		},
+		{
+			name:    "fs without root dir",
+			bp:      &corev1.BackupPoint{StorageType: "fs", FsConfig: &corev1.FsConfig{}},
+			wantErr: true,
+		},
+		{
+			name: "s3",
+			bp:   &corev1.BackupPoint{StorageType: "s3", S3Config: &corev1.S3Config{Bucket: "backups", Endpoint: "127.0.0.1:9000"}},
+		},
+		{
+			name:    "s3 without config",
+			bp:      &corev1.BackupPoint{StorageType: "s3"},
+			wantErr: true,
+		},
+		{
+			name: "sftp with private key",
+			bp:   &corev1.BackupPoint{StorageType: "sftp", SftpConfig: &corev1.SftpConfig{Host: "10.0.0.1:22", Username: "backup", PrivateKey: "key"}},
+		},
+		{
+			name:    "sftp without credentials",
+			bp:      &corev1.BackupPoint{StorageType: "sftp", SftpConfig: &corev1.SftpConfig{Host: "10.0.0.1:22", Username: "backup"}},
+			wantErr: true,
+		},
+		{
+			name: "webdav",
+			bp:   &corev1.BackupPoint{StorageType: "webdav", WebDAVConfig: &corev1.WebDAVConfig{Endpoint: "https://dav.example.com/backups"}},
+		},
+		{
+			name:    "webdav with invalid endpoint",
+			bp:      &corev1.BackupPoint{StorageType: "webdav", WebDAVConfig: &corev1.WebDAVConfig{Endpoint: "dav.example.com"}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown storage type",
+			bp:      &corev1.BackupPoint{StorageType: "ftp"},
+			wantErr: true,
+		},
+		{
+			name: "invalid retention policy",
+			bp: &corev1.BackupPoint{StorageType: "fs", FsConfig: &corev1.FsConfig{BackupRootDir: "/opt/kc/backups"},
+				RetentionPolicy: &corev1.RetentionPolicy{Daily: -1}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := ValidateBackupPoint(tt.bp); (len(errs) > 0) != tt.wantErr {
+				t.Errorf("ValidateBackupPoint() = %v, wantErr %v", errs, tt.wantErr)
+			}
+		})
+	}
+}
@@ -19,10 +19,139 @@
 package validation
 
 import (
-	corev1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"net"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	corev1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
 )
 
+var supportedRecordTypes = []string{corev1.RecordTypeA, corev1.RecordTypeAAAA, corev1.RecordTypeCNAME, corev1.RecordTypeSRV, corev1.RecordTypeTXT}
+
+// maxTXTLength is the length limit of a single TXT character-string.
+const maxTXTLength = 255
+
 func ValidateDomain(c *corev1.Domain) field.ErrorList {
-	return ValidateObjectMeta(&c.ObjectMeta, false, ValidateNodeName, field.NewPath("metadata"))
+	allErrs := ValidateObjectMeta(&c.ObjectMeta, false, ValidateNodeName, field.NewPath("metadata"))
+	fld := field.NewPath("spec", "records")
+	for key := range c.Spec.Records {
+		record := c.Spec.Records[key]
+		allErrs = append(allErrs, ValidateRecord(&record, fld.Key(key))...)
+	}
+	if c.Spec.Forwarders != nil {
+		allErrs = append(allErrs, ValidateForwarders(c.Spec.Forwarders, field.NewPath("spec", "forwarders"))...)
+	}
+	return allErrs
+}
+
+var supportedForwardPolicies = []string{corev1.ForwardPolicyRandom, corev1.ForwardPolicyRoundRobin, corev1.ForwardPolicySequential}
+
+// maxUpstreams is the limit of the upstreams of the CoreDNS forward plugin.
+const maxUpstreams = 15
+
+// ValidateForwarders validates the upstreams which are rendered into a CoreDNS forward plugin.
+func ValidateForwarders(f *corev1.Forwarders, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	fld := fldPath.Child("upstreams")
+	switch {
+	case len(f.Upstreams) == 0:
+		allErrs = append(allErrs, field.Required(fld, "at least one upstream is required"))
+	case len(f.Upstreams) > maxUpstreams:
+		allErrs = append(allErrs, field.TooMany(fld, len(f.Upstreams), maxUpstreams))
+	}
+	for i, upstream := range f.Upstreams {
+		if !isUpstream(upstream) {
+			allErrs = append(allErrs, field.Invalid(fld.Index(i), upstream, "must be ip or ip:port"))
+		}
+	}
+	if f.Policy != "" && !sets.NewString(supportedForwardPolicies...).Has(f.Policy) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("policy"), f.Policy, supportedForwardPolicies))
+	}
+	if f.TLSServerName != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(f.TLSServerName) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("tlsServerName"), f.TLSServerName, msg))
+		}
+	}
+	return allErrs
+}
+
+func isUpstream(upstream string) bool {
+	if net.ParseIP(upstream) != nil {
+		return true
+	}
+	host, port, err := net.SplitHostPort(upstream)
+	if err != nil || net.ParseIP(host) == nil {
+		return false
+	}
+	p, err := strconv.Atoi(port)
+	return err == nil && len(validation.IsValidPortNum(p)) == 0
+}
+
+// ValidateRecord validates the resolve records of a domain record.
+func ValidateRecord(r *corev1.Record, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if r.TTL < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("ttl"), r.TTL, "must be greater than or equal to 0"))
+	}
+	fld := fldPath.Child("parseRecord")
+	if len(r.ParseRecord) == 0 {
+		return append(allErrs, field.Required(fld, "resolve record cann not be empty"))
+	}
+
+	var cname int
+	for i, p := range r.ParseRecord {
+		idxPath := fld.Index(i)
+		switch p.RecordType() {
+		case corev1.RecordTypeA:
+			if ip := net.ParseIP(p.IP); ip == nil || ip.To4() == nil {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("ip"), p.IP, "must be a valid ipv4 address"))
+			}
+		case corev1.RecordTypeAAAA:
+			if ip := net.ParseIP(p.IP); ip == nil || ip.To4() != nil {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("ip"), p.IP, "must be a valid ipv6 address"))
+			}
+		case corev1.RecordTypeCNAME:
+			cname++
+			if r.RR == "@" {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("type"), p.Type, "CNAME is not allowed at the zone apex"))
+			}
+			allErrs = append(allErrs, validateTarget(p.Target, idxPath.Child("target"))...)
+		case corev1.RecordTypeSRV:
+			allErrs = append(allErrs, validateTarget(p.Target, idxPath.Child("target"))...)
+			if p.Port == 0 {
+				allErrs = append(allErrs, field.Required(idxPath.Child("port"), "port is required for SRV"))
+			}
+		case corev1.RecordTypeTXT:
+			switch {
+			case p.Text == "":
+				allErrs = append(allErrs, field.Required(idxPath.Child("text"), "text is required for TXT"))
+			case len(p.Text) > maxTXTLength:
+				allErrs = append(allErrs, field.TooLong(idxPath.Child("text"), p.Text, maxTXTLength))
+			case strings.ContainsAny(p.Text, "\"\\\n"):
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("text"), p.Text, `must not contain '"', '\' or newline`))
+			}
+		default:
+			allErrs = append(allErrs, field.NotSupported(idxPath.Child("type"), p.Type, supportedRecordTypes))
+		}
+	}
+	// a CNAME owner can not have any other data.
+	if cname > 0 && len(r.ParseRecord) > 1 {
+		allErrs = append(allErrs, field.Invalid(fld, len(r.ParseRecord), "CNAME can not coexist with other resolve records"))
+	}
+	return allErrs
+}
+
+func validateTarget(target string, fldPath *field.Path) field.ErrorList {
+	if target == "" {
+		return field.ErrorList{field.Required(fldPath, "")}
+	}
+	allErrs := field.ErrorList{}
+	for _, msg := range validation.IsDNS1123Subdomain(strings.ToLower(strings.TrimSuffix(target, "."))) {
+		allErrs = append(allErrs, field.Invalid(fldPath, target, msg))
+	}
+	return allErrs
 }
@@ -0,0 +1,169 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package validation
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	corev1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func TestValidateRecord(t *testing.T) {
+	tests := []struct {
+		name    string
+		record  corev1.Record
+		wantErr bool
+	}{
+		{
+			name:   "a and aaaa",
+			record: corev1.Record{RR: "www", ParseRecord: []corev1.ParseRecord{{Type: "A", IP: "10.0.0.1"}, {Type: "aaaa", IP: "fd00::1"}}},
+		},
+		{
+			name:    "empty",
+			record:  corev1.Record{RR: "www"},
+			wantErr: true,
+		},
+		{
+			name:    "ipv6 as a",
+			record:  corev1.Record{RR: "www", ParseRecord: []corev1.ParseRecord{{Type: "A", IP: "fd00::1"}}},
+			wantErr: true,
+		},
+		{
+			name:    "ipv4 as aaaa",
+			record:  corev1.Record{RR: "www", ParseRecord: []corev1.ParseRecord{{Type: "AAAA", IP: "10.0.0.1"}}},
+			wantErr: true,
+		},
+		{
+			name:    "negative ttl",
+			record:  corev1.Record{RR: "www", TTL: -1, ParseRecord: []corev1.ParseRecord{{Type: "A", IP: "10.0.0.1"}}},
+			wantErr: true,
+		},
+		{
+			name:   "cname",
+			record: corev1.Record{RR: "docs", TTL: 600, ParseRecord: []corev1.ParseRecord{{Type: "CNAME", Target: "example.github.io."}}},
+		},
+		{
+			name:    "cname with other data",
+			record:  corev1.Record{RR: "docs", ParseRecord: []corev1.ParseRecord{{Type: "CNAME", Target: "example.github.io"}, {Type: "A", IP: "10.0.0.1"}}},
+			wantErr: true,
+		},
+		{
+			name:    "cname at apex",
+			record:  corev1.Record{RR: "@", ParseRecord: []corev1.ParseRecord{{Type: "CNAME", Target: "example.github.io"}}},
+			wantErr: true,
+		},
+		{
+			name:    "cname invalid target",
+			record:  corev1.Record{RR: "docs", ParseRecord: []corev1.ParseRecord{{Type: "CNAME", Target: "bad target"}}},
+			wantErr: true,
+		},
+		{
+			name:   "srv",
+			record: corev1.Record{RR: "_ldap._tcp", ParseRecord: []corev1.ParseRecord{{Type: "SRV", Priority: 10, Weight: 5, Port: 389, Target: "ldap.example.com"}}},
+		},
+		{
+			name:    "srv without port",
+			record:  corev1.Record{RR: "_ldap._tcp", ParseRecord: []corev1.ParseRecord{{Type: "SRV", Target: "ldap.example.com"}}},
+			wantErr: true,
+		},
+		{
+			name:   "txt",
+			record: corev1.Record{RR: "@", ParseRecord: []corev1.ParseRecord{{Type: "TXT", Text: "v=spf1 -all"}}},
+		},
+		{
+			// the template delimiters are escaped in the Corefile
+			name:   "txt with template delimiters",
+			record: corev1.Record{RR: "@", ParseRecord: []corev1.ParseRecord{{Type: "TXT", Text: "token={{ .Name }}"}}},
+		},
+		{
+			name:    "txt with quote",
+			record:  corev1.Record{RR: "@", ParseRecord: []corev1.ParseRecord{{Type: "TXT", Text: `say "hi"`}}},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported type",
+			record:  corev1.Record{RR: "www", ParseRecord: []corev1.ParseRecord{{Type: "MX", Target: "mail.example.com"}}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateRecord(&tt.record, field.NewPath("record"))
+			if (len(errs) > 0) != tt.wantErr {
+				t.Errorf("ValidateRecord() errs = %v, wantErr %v", errs, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateForwarders(t *testing.T) {
+	tests := []struct {
+		name       string
+		forwarders corev1.Forwarders
+		wantErr    bool
+	}{
+		{
+			name:       "ip and ip:port",
+			forwarders: corev1.Forwarders{Upstreams: []string{"10.0.0.53", "10.0.0.54:5353", "[fd00::53]:53"}, Policy: corev1.ForwardPolicySequential},
+		},
+		{
+			name:       "dns over tls",
+			forwarders: corev1.Forwarders{Upstreams: []string{"1.1.1.1"}, TLSServerName: "cloudflare-dns.com"},
+		},
+		{
+			name:    "no upstream",
+			wantErr: true,
+		},
+		{
+			name:       "too many upstreams",
+			forwarders: corev1.Forwarders{Upstreams: make([]string, 16)},
+			wantErr:    true,
+		},
+		{
+			name:       "hostname upstream",
+			forwarders: corev1.Forwarders{Upstreams: []string{"dns.example.com"}},
+			wantErr:    true,
+		},
+		{
+			name:       "invalid port",
+			forwarders: corev1.Forwarders{Upstreams: []string{"10.0.0.53:70000"}},
+			wantErr:    true,
+		},
+		{
+			name:       "unsupported policy",
+			forwarders: corev1.Forwarders{Upstreams: []string{"10.0.0.53"}, Policy: "fastest"},
+			wantErr:    true,
+		},
+		{
+			name:       "invalid tls server name",
+			forwarders: corev1.Forwarders{Upstreams: []string{"10.0.0.53"}, TLSServerName: "Bad_Name"},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateForwarders(&tt.forwarders, field.NewPath("forwarders"))
+			if (len(errs) > 0) != tt.wantErr {
+				t.Errorf("ValidateForwarders() errs = %v, wantErr %v", errs, tt.wantErr)
+			}
+		})
+	}
+}
@@ -287,7 +287,7 @@ var Roles = []iamv1.GlobalRole{
 		Rules: []rbacv1.PolicyRule{
 			{
 				APIGroups: []string{"core.kubeclipper.io"},
-				Resources: []string{"backuppoints"},
+				Resources: []string{"backuppoints", "backuppoints/scan"},
 				Verbs:     []string{"create", "delete", "update", "patch"},
 			},
 		},
@@ -363,7 +363,7 @@ var Roles = []iamv1.GlobalRole{
 		Rules: []rbacv1.PolicyRule{
 			{
 				APIGroups: []string{"core.kubeclipper.io"},
-				Resources: []string{"clusters", "nodes", "regions", "operations", "logs", "clusters/upgrade", "nodes/terminal"},
+				Resources: []string{"clusters", "nodes", "regions", "operations", "operations/logs", "logs", "clusters/upgrade", "nodes/terminal"},
 				Verbs:     []string{"get", "list", "watch"},
 			},
 			{
@@ -399,7 +399,7 @@ var Roles = []iamv1.GlobalRole{
 		Rules: []rbacv1.PolicyRule{
 			{
 				APIGroups: []string{"core.kubeclipper.io"},
-				Resources: []string{"clusters", "nodes", "regions", "operations/retry", "clusters/upgrade"},
+				Resources: []string{"clusters", "nodes", "regions", "operations/retry", "operations/cancel", "clusters/upgrade"},
 				Verbs:     []string{"create"},
 			},
 			{
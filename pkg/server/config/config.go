@@ -22,7 +22,9 @@ import (
 	"reflect"
 	"strings"
 
+	"github.com/kubeclipper/kubeclipper/pkg/auditing"
 	authoptions "github.com/kubeclipper/kubeclipper/pkg/authentication/options"
+	"github.com/kubeclipper/kubeclipper/pkg/service/delivery"
 	bs "github.com/kubeclipper/kubeclipper/pkg/simple/backupstore"
 	"github.com/kubeclipper/kubeclipper/pkg/simple/client/cache"
 
@@ -56,6 +58,8 @@ type Config struct {
 	MQOptions               *natsio.NatsOptions                `json:"mq,omitempty" yaml:"mq,omitempty"  mapstructure:"mq"`
 	LogOptions              *logger.Options                    `json:"log,omitempty" yaml:"log,omitempty" mapstructure:"log"`
 	AuthenticationOptions   *authoptions.AuthenticationOptions `json:"authentication,omitempty" yaml:"authentication,omitempty" mapstructure:"authentication"`
+	DeliveryOptions         *delivery.Options                  `json:"delivery,omitempty" yaml:"delivery,omitempty" mapstructure:"delivery"`
+	AuditOptions            *auditing.Options                  `json:"audit,omitempty" yaml:"audit,omitempty" mapstructure:"audit"`
 }
 
 func New() *Config {
@@ -67,6 +71,8 @@ func New() *Config {
 		MQOptions:               natsio.NewOptions(),
 		LogOptions:              logger.NewLogOptions(),
 		AuthenticationOptions:   authoptions.NewAuthenticateOptions(),
+		DeliveryOptions:         delivery.NewOptions(),
+		AuditOptions:            auditing.NewOptions(),
 	}
 }
 
@@ -32,6 +32,7 @@ import (
 	"k8s.io/apiserver/pkg/storage/names"
 
 	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/core/validation"
 )
 
 var (
@@ -88,7 +89,7 @@ func (BackupPointStrategy) PrepareForUpdate(ctx context.Context, obj, old runtim
 }
 
 func (BackupPointStrategy) Validate(ctx context.Context, obj runtime.Object) field.ErrorList {
-	return field.ErrorList{}
+	return validation.ValidateBackupPoint(obj.(*v1.BackupPoint))
 }
 
 func (BackupPointStrategy) AllowCreateOnUpdate() bool {
@@ -103,5 +104,5 @@ func (BackupPointStrategy) Canonicalize(obj runtime.Object) {
 }
 
 func (BackupPointStrategy) ValidateUpdate(ctx context.Context, obj, old runtime.Object) field.ErrorList {
-	return field.ErrorList{}
+	return validation.ValidateBackupPoint(obj.(*v1.BackupPoint))
 }
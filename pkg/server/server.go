@@ -23,8 +23,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/kubeclipper/kubeclipper/pkg/controller/certrotationcontroller"
 	"github.com/kubeclipper/kubeclipper/pkg/controller/cronbackupcontroller"
+	"github.com/kubeclipper/kubeclipper/pkg/controller/etcdmaintenancecontroller"
 
 	corev1 "github.com/kubeclipper/kubeclipper/pkg/apis/core/v1"
 	"github.com/kubeclipper/kubeclipper/pkg/authentication/mfa"
@@ -99,6 +103,7 @@ type APIServer struct {
 	storageFactory        registry.SharedStorageFactory
 	rbacAuthorizer        authorizer.Authorizer
 	databaseAuditBackend  auditing.Backend
+	auditSinkBackends     []auditing.Backend
 	internalInformerUser  string
 	InternalInformerToken string
 }
@@ -208,10 +213,38 @@ func (s *APIServer) buildHandlerChain(stopCh <-chan struct{}) error {
 	if s.databaseAuditBackend != nil {
 		a.AddBackend(s.databaseAuditBackend)
 	}
+	for _, b := range s.auditSinkBackends {
+		a.AddBackend(b)
+	}
 	s.container.Filter(filters.WithAudit(a))
 	return nil
 }
 
+func (s *APIServer) installAuditSinks(stopCh <-chan struct{}) error {
+	opts := s.Config.AuditOptions
+	if opts == nil {
+		return nil
+	}
+	if opts.Webhook != nil {
+		b, err := auditing.NewWebhookBackend(opts.Webhook, stopCh)
+		if err != nil {
+			return fmt.Errorf("create audit webhook backend failed: %v", err)
+		}
+		s.auditSinkBackends = append(s.auditSinkBackends, b)
+	}
+	if opts.File != nil {
+		s.auditSinkBackends = append(s.auditSinkBackends, auditing.NewFileBackend(opts.File, stopCh))
+	}
+	if opts.Syslog != nil {
+		b, err := auditing.NewSyslogBackend(opts.Syslog, stopCh)
+		if err != nil {
+			return fmt.Errorf("create audit syslog backend failed: %v", err)
+		}
+		s.auditSinkBackends = append(s.auditSinkBackends, b)
+	}
+	return nil
+}
+
 func (s *APIServer) installMetricsAPI() {
 	registerMetrics()
 	metrics.Defaults.Install(s.container)
@@ -230,9 +263,23 @@ func (s *APIServer) installVersionAPI() {
 }
 
 func monitorRequest(r *restful.Request, response *restful.Response, chain *restful.FilterChain) {
-	// start := time.Now()
+	start := time.Now()
 	chain.ProcessFilter(r, response)
-	// TODO: Add metrics
+	// the request info is set by the inner filter
+	info, ok := request.InfoFrom(r.Request.Context())
+	if !ok {
+		return
+	}
+	// non-resource requests are recorded without path to keep the cardinality bounded
+	resource := info.Resource
+	if info.Subresource != "" {
+		resource = info.Resource + "/" + info.Subresource
+	}
+	RequestCounter.WithLabelValues(info.Verb, info.APIGroup, info.APIVersion, resource, strconv.Itoa(response.StatusCode())).Inc()
+	// watch and proxy requests are long-running
+	if info.Verb != "watch" && info.Subresource != "proxy" {
+		RequestLatencies.WithLabelValues(info.Verb, info.APIGroup, info.APIVersion, resource).Observe(time.Since(start).Seconds())
+	}
 }
 
 func (s *APIServer) installAPIs(stopCh <-chan struct{}) error {
@@ -252,7 +299,7 @@ func (s *APIServer) installAPIs(stopCh <-chan struct{}) error {
 		s.storageFactory.GlobalRoleBindings(), s.storageFactory.Tokens(), s.storageFactory.LoginRecords())
 	s.rbacAuthorizer = rbac.NewAuthorizer(iamOperator)
 
-	deliverySvc := delivery.NewService(s.Config.MQOptions, clusterOperator, leaseOperator, opOperator)
+	deliverySvc := delivery.NewService(s.Config.MQOptions, s.Config.DeliveryOptions, clusterOperator, leaseOperator, opOperator)
 	s.Services = append(s.Services, deliverySvc)
 
 	platformOperator := platform.NewPlatformOperator(s.storageFactory.PlatformSettings(), s.storageFactory.Events())
@@ -261,6 +308,9 @@ func (s *APIServer) installAPIs(stopCh <-chan struct{}) error {
 	}
 
 	s.databaseAuditBackend = auditing.NewDatabaseBackend(platformOperator, stopCh)
+	if err := s.installAuditSinks(stopCh); err != nil {
+		return err
+	}
 
 	tokenOperator := auth.NewTokenOperator(iamOperator, s.Config.AuthenticationOptions)
 
@@ -278,7 +328,7 @@ func (s *APIServer) installAPIs(stopCh <-chan struct{}) error {
 		return err
 	}
 
-	ctrl, err := manager.NewControllerManager(s.internalInformerUser, s.InternalInformerToken, s.storageFactory, deliverySvc, SetupController)
+	ctrl, err := manager.NewControllerManager(s.internalInformerUser, s.InternalInformerToken, s.storageFactory, deliverySvc, s.setupController)
 	if err != nil {
 		return err
 	}
@@ -365,6 +415,21 @@ func (s *APIServer) migrateUser(operator iam.Operator) error {
 	return nil
 }
 
+func (s *APIServer) setupController(mgr manager.Manager, informerFactory informers.SharedInformerFactory, storageFactory registry.SharedStorageFactory) error {
+	if err := SetupController(mgr, informerFactory, storageFactory); err != nil {
+		return err
+	}
+	if s.Config.AuditOptions != nil && s.Config.AuditOptions.EventTTL > 0 {
+		platformOperator := platform.NewPlatformOperator(storageFactory.PlatformSettings(), storageFactory.Events())
+		(&controller.EventTTLMon{
+			EventReader: platformOperator,
+			EventWriter: platformOperator,
+			TTL:         s.Config.AuditOptions.EventTTL,
+		}).SetupWithManager(mgr)
+	}
+	return nil
+}
+
 func SetupController(mgr manager.Manager, informerFactory informers.SharedInformerFactory, storageFactory registry.SharedStorageFactory) error {
 	var err error
 	clusterOperator := cluster.NewClusterOperator(storageFactory.Clusters(),
@@ -431,6 +496,24 @@ func SetupController(mgr manager.Manager, informerFactory informers.SharedInform
 	}).SetupWithManager(mgr, informerFactory); err != nil {
 		return err
 	}
+	if err = (&etcdmaintenancecontroller.EtcdMaintenanceReconciler{
+		CmdDelivery:     mgr.GetCmdDelivery(),
+		ClusterLister:   informerFactory.Core().V1().Clusters().Lister(),
+		NodeLister:      informerFactory.Core().V1().Nodes().Lister(),
+		ClusterWriter:   clusterOperator,
+		OperationWriter: opOperator,
+	}).SetupWithManager(mgr, informerFactory); err != nil {
+		return err
+	}
+	if err = (&certrotationcontroller.CertRotationReconciler{
+		CmdDelivery:     mgr.GetCmdDelivery(),
+		ClusterLister:   informerFactory.Core().V1().Clusters().Lister(),
+		NodeLister:      informerFactory.Core().V1().Nodes().Lister(),
+		ClusterWriter:   clusterOperator,
+		OperationWriter: opOperator,
+	}).SetupWithManager(mgr, informerFactory); err != nil {
+		return err
+	}
 	if err = (&dnscontroller.DNSReconciler{
 		DomainLister:  informerFactory.Core().V1().Domains().Lister(),
 		DomainWriter:  clusterOperator,
@@ -442,6 +525,8 @@ func SetupController(mgr manager.Manager, informerFactory informers.SharedInform
 		ClusterLister:   informerFactory.Core().V1().Clusters().Lister(),
 		OperationLister: informerFactory.Core().V1().Operations().Lister(),
 		OperationWriter: opOperator,
+		LeaseLister:     informerFactory.Core().V1().Leases().Lister(),
+		CmdDelivery:     mgr.GetCmdDelivery(),
 	}).SetupWithManager(mgr, informerFactory); err != nil {
 		return err
 	}
@@ -461,5 +546,10 @@ func SetupController(mgr manager.Manager, informerFactory informers.SharedInform
 		LeaseLister: informerFactory.Core().V1().Leases().Lister(),
 		NodeWriter:  clusterOperator,
 	}).SetupWithManager(mgr)
+	(&controller.MetricsMon{
+		ClusterLister: informerFactory.Core().V1().Clusters().Lister(),
+		NodeLister:    informerFactory.Core().V1().Nodes().Lister(),
+		LeaseLister:   informerFactory.Core().V1().Leases().Lister(),
+	}).SetupWithManager(mgr)
 	return nil
 }
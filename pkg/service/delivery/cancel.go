@@ -0,0 +1,105 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package delivery
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/service"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/natsio"
+)
+
+func (s *Service) cancelSubject() string {
+	return fmt.Sprintf(service.MsgSubjectFormat, service.MsgCancelOperationSubject, s.subjectSuffix)
+}
+
+// trackOperation records the cancel function of the operation run by the server,
+// the returned function must be called when the operation is done.
+func (s *Service) trackOperation(name string, cancel context.CancelFunc) func() {
+	s.runningLock.Lock()
+	defer s.runningLock.Unlock()
+	s.running[name] = cancel
+	return func() {
+		s.runningLock.Lock()
+		defer s.runningLock.Unlock()
+		delete(s.running, name)
+	}
+}
+
+// cancelOperationHandler cancels the step context of the operation if it is run by the server.
+func (s *Service) cancelOperationHandler(msg *nats.Msg) {
+	name := string(msg.Data)
+	s.runningLock.Lock()
+	cancel, ok := s.running[name]
+	s.runningLock.Unlock()
+	if !ok {
+		return
+	}
+	logger.Info("cancel operation", zap.String("op", name))
+	cancel()
+}
+
+// CancelOperation marks the running operation as cancelled, then tells the server running it to
+// stop delivering steps and the agents of the operation to kill the running step commands.
+func (s *Service) CancelOperation(ctx context.Context, name string) (*v1.Operation, error) {
+	op, err := s.opOperator.GetOperationEx(ctx, name, "0")
+	if err != nil {
+		return nil, err
+	}
+	if op.Status.Status != v1.OperationStatusRunning {
+		return nil, fmt.Errorf("operation %s is %s, only the running operation can be cancelled", name, op.Status.Status)
+	}
+	op.Status.Status = v1.OperationStatusCancelled
+	if op, err = s.opOperator.UpdateOperation(ctx, op); err != nil {
+		return nil, err
+	}
+	if err = s.client.Publish(&natsio.Msg{Subject: s.cancelSubject(), Data: []byte(name)}); err != nil {
+		logger.Error("publish operation cancellation to servers failed", zap.String("op", name), zap.Error(err))
+	}
+	payload, err := initPayload(name, service.OperationCancelTask, nil, nil, nil, false, false)
+	if err != nil {
+		return nil, err
+	}
+	nodes := sets.NewString()
+	for _, step := range op.Steps {
+		for _, node := range step.Nodes {
+			nodes.Insert(node.ID)
+		}
+	}
+	for _, node := range nodes.List() {
+		msg := &natsio.Msg{
+			Subject: fmt.Sprintf(service.MsgSubjectFormat, node, s.subjectSuffix),
+			Data:    payload,
+		}
+		// the agent may be offline, the steps on it fail on timeout then.
+		if err = s.client.Publish(msg); err != nil {
+			logger.Error("publish operation cancellation to agent failed", zap.String("op", name),
+				zap.String("node", node), zap.Error(err))
+		}
+	}
+	go s.SyncClusterCondition(op)
+	return op, nil
+}
@@ -21,9 +21,10 @@ package delivery
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
+	"math"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -36,12 +37,14 @@ import (
 	"github.com/nats-io/nats.go"
 	"go.uber.org/zap"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
 
 	"github.com/kubeclipper/kubeclipper/pkg/logger"
 	"github.com/kubeclipper/kubeclipper/pkg/models/cluster"
 	"github.com/kubeclipper/kubeclipper/pkg/models/lease"
 	"github.com/kubeclipper/kubeclipper/pkg/models/operation"
 	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1/k8s"
 	"github.com/kubeclipper/kubeclipper/pkg/service"
 	"github.com/kubeclipper/kubeclipper/pkg/simple/client/natsio"
 )
@@ -69,9 +72,18 @@ type Service struct {
 	leaseOperator     lease.Operator
 	opOperator        operation.Operator
 	stepStatusChan    chan stepStatus
+	maxConcurrentStep int
+	runner            runner
+	runningLock       sync.Mutex
+	// running is the cancel functions of the operations run by the server.
+	running map[string]context.CancelFunc
 }
 
-func NewService(opts *natsio.NatsOptions, clusterOperator cluster.Operator, leaseOperator lease.Operator, opOperator operation.Operator) *Service {
+func NewService(opts *natsio.NatsOptions, deliveryOpts *Options, clusterOperator cluster.Operator, leaseOperator lease.Operator, opOperator operation.Operator) *Service {
+	if deliveryOpts == nil {
+		deliveryOpts = NewOptions()
+	}
+	registerMetrics()
 	s := &Service{
 		external:          opts.External,
 		client:            natsio.NewNats(opts),
@@ -82,6 +94,9 @@ func NewService(opts *natsio.NatsOptions, clusterOperator cluster.Operator, leas
 		leaseOperator:     leaseOperator,
 		opOperator:        opOperator,
 		stepStatusChan:    make(chan stepStatus, 256),
+		maxConcurrentStep: deliveryOpts.MaxConcurrentSteps,
+		runner:            newRunner(),
+		running:           make(map[string]context.CancelFunc),
 	}
 	s.client.SetReconnectHandler(s.defaultMQReconnectHandler)
 	s.client.SetDisconnectErrHandler(s.defaultMQDisconnectHandler)
@@ -122,7 +137,11 @@ func (s *Service) Run(stopCh <-chan struct{}) error {
 	if err := s.client.QueueSubscribe(s.nodeReportSubject, s.queueGroup, s.nodeStateReportInHandler); err != nil {
 		return err
 	}
+	if err := s.client.Subscribe(s.cancelSubject(), s.cancelOperationHandler); err != nil {
+		return err
+	}
 	go s.stepStatusChannelController()
+	go s.runRunnerLease(stopCh)
 	return nil
 }
 
@@ -156,7 +175,7 @@ func (s *Service) stepStatusChannelController() {
 		if status.DryRun {
 			logger.Debug("dry run update step status", zap.String("op", status.OperationIdentity),
 				zap.String("step", status.OperationCondition.StepID), zap.Any("step_status", status.OperationCondition))
-			return
+			continue
 		}
 		// TODO: 简化更新,允许强制更新?
 		for i := 0; i < updateOperationStatusRetry; i++ {
@@ -167,10 +186,16 @@ func (s *Service) stepStatusChannelController() {
 				continue
 			}
 
-			stepLen := len(o.Status.Conditions)
-			if stepLen > 0 && status.OperationCondition.StepID == o.Status.Conditions[stepLen-1].StepID {
-				o.Status.Conditions[stepLen-1].Status = append(o.Status.Conditions[stepLen-1].Status, status.OperationCondition.Status...)
-			} else {
+			// independent steps may finish in any order, so look up the condition by step ID.
+			found := false
+			for i := range o.Status.Conditions {
+				if o.Status.Conditions[i].StepID == status.OperationCondition.StepID {
+					o.Status.Conditions[i].Status = append(o.Status.Conditions[i].Status, status.OperationCondition.Status...)
+					found = true
+					break
+				}
+			}
+			if !found {
 				o.Status.Conditions = append(o.Status.Conditions, status.OperationCondition)
 			}
 
@@ -197,6 +222,10 @@ func (s *Service) updateOperationStatus(op string, status v1.OperationStatusType
 			logger.Error("update operation status type failed", zap.String("op", op), zap.String("status", string(status)), zap.Error(err))
 			continue
 		}
+		if o.Status.Status == v1.OperationStatusCancelled {
+			// the cancelled operation keeps its status, the steps fail after the cancellation.
+			return
+		}
 		o.Status.Status = status
 		if o, err = s.opOperator.UpdateOperation(context.TODO(), o); err != nil {
 			logger.Error("update operation status type failed", zap.String("op", op), zap.String("status", string(status)), zap.Error(err))
@@ -255,17 +284,10 @@ func (s *Service) syncClusterCondition(op *v1.Operation, clu *v1.Cluster) error
 		return nil
 	case v1.OperationRemoveNodes:
 		if op.Status.Status == v1.OperationStatusSuccessful {
+			// the removed nodes are recorded on the operation, the steps removing them differ by cluster provider.
 			var nodes []string
-			for _, step := range op.Steps {
-				// TODO: 后续考虑其他方式判断需要删除的节点
-				if step.Name == "kubeadmReset" {
-					var ids []string
-					for _, v := range step.Nodes {
-						ids = append(ids, v.ID)
-					}
-					nodes = ids
-					break
-				}
+			if v := op.Annotations[common.AnnotationOperationNodes]; v != "" {
+				nodes = strings.Split(v, ",")
 			}
 			removed := make([]v1.WorkerNode, len(nodes))
 			for i, n := range nodes {
@@ -301,6 +323,9 @@ func (s *Service) syncClusterCondition(op *v1.Operation, clu *v1.Cluster) error
 		if op.Status.Status == v1.OperationStatusSuccessful {
 			clu.Status.Phase = v1.ClusterRunning
 			clu.KubernetesVersion = op.Labels[common.LabelUpgradeVersion]
+			if v, ok := op.Labels[common.LabelUpgradeCNIVersion]; ok {
+				clu.CNI.Version = v
+			}
 		} else {
 			clu.Status.Phase = v1.ClusterUpgradeFailed
 		}
@@ -310,6 +335,9 @@ func (s *Service) syncClusterCondition(op *v1.Operation, clu *v1.Cluster) error
 		clu.Status.Phase = v1.ClusterRunning
 		_, err := s.clusterOperator.UpdateCluster(context.TODO(), clu)
 		return err
+	case v1.OperationVerifyBackup:
+		// the verification runs in a scratch directory, the cluster is unchanged
+		return nil
 	case v1.OperationRecoverCluster:
 		if op.Status.Status == v1.OperationStatusSuccessful {
 			clu.Status.Phase = v1.ClusterRunning
@@ -331,6 +359,8 @@ func (s *Service) syncClusterCondition(op *v1.Operation, clu *v1.Cluster) error
 	case v1.OperationUpdateCertification:
 		if op.Status.Status == v1.OperationStatusSuccessful {
 			clu.Status.Phase = v1.ClusterRunning
+			// the cluster controller regenerates the kubeconfig and the client of the cluster
+			clu.KubeConfig = nil
 		} else {
 			clu.Status.Phase = v1.ClusterUpdateFailed
 		}
@@ -338,6 +368,20 @@ func (s *Service) syncClusterCondition(op *v1.Operation, clu *v1.Cluster) error
 			return err
 		}
 		return nil
+	case v1.OperationEtcdMaintenance:
+		if op.Status.Status == v1.OperationStatusSuccessful {
+			clu.Status.Phase = v1.ClusterRunning
+		} else {
+			clu.Status.Phase = v1.ClusterUpdateFailed
+		}
+		if members := etcdMemberStatus(op); len(members) > 0 {
+			clu.Status.Etcd.QuotaBackendBytes = k8s.EtcdQuotaBackendBytes
+			clu.Status.Etcd.Members = members
+		}
+		if _, err := s.clusterOperator.UpdateCluster(context.TODO(), clu); err != nil {
+			return err
+		}
+		return nil
 	default:
 		logger.Error("unsupported operation action", zap.String("operation", op.Name),
 			zap.String("cluster", clu.Name), zap.String("action", v))
@@ -345,6 +389,44 @@ func (s *Service) syncClusterCondition(op *v1.Operation, clu *v1.Cluster) error
 	}
 }
 
+// etcdMemberStatus returns the db size of the members responded by the etcd status step.
+func etcdMemberStatus(op *v1.Operation) []v1.EtcdMemberStatus {
+	var stepID string
+	for _, step := range op.Steps {
+		if step.Name == k8s.EtcdStatusStep {
+			stepID = step.ID
+			break
+		}
+	}
+	// a retried step records the node more than once, the last record wins.
+	var members []v1.EtcdMemberStatus
+	index := make(map[string]int)
+	for _, c := range op.Status.Conditions {
+		if c.StepID != stepID {
+			continue
+		}
+		for _, status := range c.Status {
+			if status.Status != v1.StepStatusSuccessful || len(status.Response) == 0 {
+				continue
+			}
+			member := v1.EtcdMemberStatus{}
+			if err := json.Unmarshal(status.Response, &member); err != nil {
+				logger.Error("parse etcd member status failed", zap.String("operation", op.Name),
+					zap.String("node", status.Node), zap.Error(err))
+				continue
+			}
+			member.Node = status.Node
+			if i, ok := index[member.Node]; ok {
+				members[i] = member
+				continue
+			}
+			index[member.Node] = len(members)
+			members = append(members, member)
+		}
+	}
+	return members
+}
+
 func (s *Service) updateNodeRoleLabel(clusterName, nodeName string, role common.NodeRole, del bool) error {
 	node, err := s.clusterOperator.GetNodeEx(context.TODO(), nodeName, "0")
 	if err != nil {
@@ -390,68 +472,139 @@ func (s *Service) DeliverTaskOperation(ctx context.Context, operation *v1.Operat
 	// new empty context, pass retry value
 	stepCtx, stepCtxCancel := context.WithCancel(component.WithRetry(context.TODO(), component.GetRetry(ctx)))
 	defer stepCtxCancel()
+	if !opts.DryRun {
+		defer s.trackOperation(operation.Name, stepCtxCancel)()
+	}
 	doneChan := make(chan struct{}, 1)
 	defer close(doneChan)
 	errChan := make(chan error, 1)
 	defer close(errChan)
 	operation.Status.Conditions = make([]v1.OperationCondition, len(operation.Steps))
+	if !opts.DryRun {
+		s.setOperationRunner(operation.Name)
+	}
+	start := time.Now()
+	finish := func(status v1.OperationStatusType) {
+		if !opts.DryRun {
+			observeOperation(operation, start, status)
+		}
+		go s.updateOperationStatus(operation.Name, status, opts.DryRun)
+	}
 	go func() {
 		for {
 			select {
 			case <-ctx.Done():
 				// operation timeout
 				stepCtxCancel()
-				go s.updateOperationStatus(operation.Name, v1.OperationStatusFailed, opts.DryRun)
+				finish(v1.OperationStatusFailed)
 				return
 			case <-doneChan:
 				// all step done, set operation status successful
-				go s.updateOperationStatus(operation.Name, v1.OperationStatusSuccessful, opts.DryRun)
+				finish(v1.OperationStatusSuccessful)
 				return
 			case <-errChan:
 				// step run error and step ignoreError flag is false
-				go s.updateOperationStatus(operation.Name, v1.OperationStatusFailed, opts.DryRun)
+				finish(v1.OperationStatusFailed)
 				return
 			}
 		}
 	}()
+	if err := s.runSteps(stepCtx, component.GetExtraData(ctx), component.GetStepReplies(ctx), operation, opts.DryRun); err != nil {
+		errChan <- err
+	} else {
+		doneChan <- struct{}{}
+	}
+	return nil
+}
+
+// runSteps runs the steps of the operation, a step starts when all of its dependencies are done.
+// At most maxConcurrentStep steps run at the same time, no more step starts after a step failed
+// without ErrIgnore or ctx is done, and runSteps returns when the running steps are done.
+// The replies of resumed steps take the place of the responses of their dependencies which are done.
+func (s *Service) runSteps(ctx context.Context, extraData []byte, replies map[string][]byte, operation *v1.Operation, dryRun bool) error {
+	deps := make([][]int, len(operation.Steps))
+	dependents := make([][]int, len(operation.Steps))
+	remaining := make([]int, len(operation.Steps))
+	var ready []int
+	for i := range operation.Steps {
+		d, err := operation.StepDependencies(i)
+		if err != nil {
+			return err
+		}
+		deps[i] = d
+		remaining[i] = len(d)
+		for _, j := range d {
+			dependents[j] = append(dependents[j], i)
+		}
+		if len(d) == 0 {
+			ready = append(ready, i)
+		}
+	}
+	concurrency := s.maxConcurrentStep
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	type stepResult struct {
+		index int
+		err   error
+	}
+	results := make(chan stepResult, len(operation.Steps))
+	running := 0
 	var err error
-	for i, step := range operation.Steps {
-		// TODO: add retry steps
-		// TODO: refactor
-		// Notice: 目前只针对 CUSTOM 命令有用，下一步骤依赖上一步骤的输出，比如 K8S 安装时初始化一个 K8S 控制节点后得到 kubeadm join 命令，需要传给其他节点进行执行
-		// len(steps) > 0
-		if i-1 > 0 {
-			// Steps will not be run when nodes field is empty,
-			// so there is no running status.
-			// May be out of list range here.
-			if len(operation.Status.Conditions[i-1].Status) < 1 {
-				return errors.New("unexpected error, steps node field must be valid")
+	for {
+		if err == nil && ctx.Err() != nil {
+			// the operation is cancelled or timed out, no more step starts.
+			err = ctx.Err()
+		}
+		for err == nil && running < concurrency && len(ready) > 0 {
+			i := ready[0]
+			ready = ready[1:]
+			// Notice: 目前只针对 CUSTOM 命令有用，下一步骤依赖上一步骤的输出，比如 K8S 安装时初始化一个 K8S 控制节点后得到 kubeadm join 命令，需要传给其他节点进行执行
+			// the first dependency is done before the step starts, so its response is passed to the step.
+			lastReply := extraData
+			if reply, ok := replies[operation.Steps[i].ID]; ok {
+				lastReply = reply
+			} else if len(deps[i]) > 0 {
+				if cond := operation.Status.Conditions[deps[i][0]]; len(cond.Status) > 0 {
+					lastReply = cond.Status[0].Response
+				} else {
+					lastReply = nil
+				}
 			}
-			logger.Info("last response", zap.ByteString("response", operation.Status.Conditions[i-1].Status[0].Response))
-			err = s.deliveryTaskStep(stepCtx, operation.Name, &operation.Steps[i],
-				operation.Status.Conditions[i-1].Status[0].Response, &operation.Status.Conditions[i], opts.DryRun)
-		} else {
-			err = s.deliveryTaskStep(stepCtx, operation.Name, &operation.Steps[i],
-				component.GetExtraData(ctx), &operation.Status.Conditions[i], opts.DryRun)
+			running++
+			go func(i int, lastReply []byte) {
+				start := time.Now()
+				err := s.deliveryTaskStep(ctx, operation.Name, &operation.Steps[i], lastReply, &operation.Status.Conditions[i], dryRun)
+				if !dryRun {
+					observeStep(&operation.Steps[i], start, err)
+				}
+				results <- stepResult{index: i, err: err}
+			}(i, lastReply)
 		}
-		logger.Debug("after delivery task step", zap.Error(err))
-		if err != nil {
-			logger.Error("delivery task step error", zap.Error(err), zap.String("step", step.Name))
-			if step.ErrIgnore {
-				logger.Debug("delivery task step, ignore the error", zap.Error(err), zap.String("step", step.Name))
-				// reset error
-				err = nil
+		if running == 0 {
+			return err
+		}
+		r := <-results
+		running--
+		step := operation.Steps[r.index]
+		logger.Debug("after delivery task step", zap.String("step", step.Name), zap.Error(r.err))
+		if r.err != nil {
+			logger.Error("delivery task step error", zap.Error(r.err), zap.String("step", step.Name))
+			if !step.ErrIgnore {
+				if err == nil {
+					err = r.err
+				}
 				continue
 			}
-			break
+			logger.Debug("delivery task step, ignore the error", zap.Error(r.err), zap.String("step", step.Name))
+		}
+		for _, j := range dependents[r.index] {
+			if remaining[j]--; remaining[j] == 0 {
+				ready = append(ready, j)
+			}
 		}
 	}
-	if err != nil {
-		errChan <- err
-	} else {
-		doneChan <- struct{}{}
-	}
-	return nil
 }
 
 func (s *Service) DeliverLogRequest(ctx context.Context, operation *service.LogOperation) (opResp oplog.LogContentResponse, err error) {
@@ -464,7 +617,9 @@ func (s *Service) DeliverLogRequest(ctx context.Context, operation *service.LogO
 		Data:    pb,
 	}
 	// may be blocked and can be cancelled ny context
+	start := time.Now()
 	data, err := s.client.RequestWithContext(ctx, msg)
+	observeDeliveryRequest(requestStepLog, start, err)
 	if err != nil {
 		return
 	}
@@ -480,6 +635,17 @@ func (s *Service) DeliverLogRequest(ctx context.Context, operation *service.LogO
 	return
 }
 
+func (s *Service) SubscribeOperationLog(ctx context.Context, operation string, handler func(chunk oplog.LogStreamChunk)) error {
+	return s.client.SubscribeWithContext(ctx, service.OperationLogSubject(operation, s.subjectSuffix), func(msg *nats.Msg) {
+		chunk := oplog.LogStreamChunk{}
+		if err := json.Unmarshal(msg.Data, &chunk); err != nil {
+			logger.Error("unmarshal step log chunk error", zap.String("op", operation), zap.Error(err))
+			return
+		}
+		handler(chunk)
+	})
+}
+
 func (s *Service) DeliverCmd(ctx context.Context, toNode string, cmds []string, timeout time.Duration) ([]byte, error) {
 	payload, err := initPayload("", service.OperationRunCmd, &v1.Step{Timeout: metav1.Duration{Duration: timeout}}, nil, cmds, false, component.GetRetry(ctx))
 	if err != nil {
@@ -490,7 +656,9 @@ func (s *Service) DeliverCmd(ctx context.Context, toNode string, cmds []string,
 		Data:    payload,
 	}
 	// may be blocked and can be cancelled ny context
+	start := time.Now()
 	data, err := s.client.RequestWithContext(ctx, msg)
+	observeDeliveryRequest(requestRunCmd, start, err)
 	if err != nil {
 		return nil, err
 	}
@@ -541,16 +709,51 @@ func (s *Service) deliveryTaskStep(ctx context.Context, opName string, step *v1.
 		}
 	}(opName, cond)
 
+	// all nodes run the step at the first time, only the failed nodes run it again when retrying.
+	pending := make([]int, len(step.Nodes))
+	for i := range step.Nodes {
+		pending[i] = i
+	}
+	backoff := newStepBackoff(step.RetryBackoff)
+	for retry := 0; ; retry++ {
+		err = s.deliveryStepToNodes(step, pending, payloadBytes, status)
+		if err == nil {
+			break
+		}
+		if retry >= int(step.RetryTimes) {
+			return err
+		}
+		pending = failedStepNodes(status, pending)
+		wait := backoff.Step()
+		logger.Info("step failed, retry after backoff", zap.String("op", opName), zap.String("step", step.Name),
+			zap.Int("retry", retry+1), zap.Int32("maxRetry", step.RetryTimes), zap.Duration("backoff", wait), zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		// mark the payload as retry, so that the agent separates the step log.
+		if payloadBytes, err = initPayload(opName, service.OperationRunTask, step, lastStepReply, nil, dryRun, true); err != nil {
+			return err
+		}
+	}
+	doneChan <- struct{}{}
+	logger.Debug("deliveryTaskStep method end...")
+	return nil
+}
+
+// deliveryStepToNodes runs the step on the nodes with the given indexes and waits for all of them.
+// The status of each node is written to the same index of status.
+func (s *Service) deliveryStepToNodes(step *v1.Step, indexes []int, payload []byte, status []v1.StepStatus) error {
 	wg := sync.WaitGroup{}
 	// NOTE: per node can send one error only.
-	errChan := make(chan error, len(step.Nodes))
+	errChan := make(chan error, len(indexes))
 	defer close(errChan)
 
-	for i, node := range step.Nodes {
+	for _, i := range indexes {
 		wg.Add(1)
 		// notice: make sure step timeout less than operation timeout
-		// TODO: add step retry
-		go s.deliveryStepToNode(&wg, node.ID, payloadBytes, step.Timeout.Duration+2*time.Second, &status[i], errChan)
+		go s.deliveryStepToNode(&wg, step.Nodes[i].ID, payload, step.Timeout.Duration+2*time.Second, &status[i], errChan)
 	}
 
 	wg.Wait()
@@ -559,11 +762,47 @@ func (s *Service) deliveryTaskStep(ctx context.Context, opName string, step *v1.
 		logger.Debug("err chan has value...")
 		return <-errChan
 	}
-	doneChan <- struct{}{}
-	logger.Debug("deliveryTaskStep method end...")
 	return nil
 }
 
+// failedStepNodes returns the indexes whose status is not successful.
+func failedStepNodes(status []v1.StepStatus, indexes []int) []int {
+	var failed []int
+	for _, i := range indexes {
+		if status[i].Status != v1.StepStatusSuccessful {
+			failed = append(failed, i)
+		}
+	}
+	return failed
+}
+
+const (
+	defaultStepRetryDuration = 5 * time.Second
+	defaultStepRetryFactor   = 2.0
+	defaultStepRetryCap      = 2 * time.Minute
+)
+
+func newStepBackoff(b *v1.StepBackoff) *wait.Backoff {
+	backoff := &wait.Backoff{
+		Duration: defaultStepRetryDuration,
+		Factor:   defaultStepRetryFactor,
+		Steps:    math.MaxInt32,
+		Cap:      defaultStepRetryCap,
+	}
+	if b == nil {
+		return backoff
+	}
+	if b.Duration.Duration > 0 {
+		backoff.Duration = b.Duration.Duration
+	}
+	backoff.Factor = 0
+	if b.Factor > 1 {
+		backoff.Factor = b.Factor
+	}
+	backoff.Cap = b.Cap.Duration
+	return backoff
+}
+
 func (s *Service) deliveryStepToNode(wg *sync.WaitGroup, node string, payload []byte, timeout time.Duration, stepStatus *v1.StepStatus, errChan chan error) {
 	defer wg.Done()
 
@@ -583,6 +822,7 @@ func (s *Service) deliveryStepToNode(wg *sync.WaitGroup, node string, payload []
 		setStepStatus(stepStatus, v1.StepStatusFailed, "run step timeout", "server wait for agent reply timeout", nil)
 		return nil
 	})
+	observeDeliveryRequest(requestRunTask, now, err)
 	if err != nil {
 		setStepStatus(stepStatus, v1.StepStatusFailed, err.Error(), "internal server error for send request to agent", nil)
 		errChan <- err
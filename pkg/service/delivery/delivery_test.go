@@ -19,15 +19,28 @@
 package delivery
 
 import (
+	"context"
 	"encoding/json"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/golang/mock/gomock"
+
+	"github.com/kubeclipper/kubeclipper/pkg/errors"
+	mock_cluster "github.com/kubeclipper/kubeclipper/pkg/models/cluster/mock"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
 	"github.com/kubeclipper/kubeclipper/pkg/service"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/natsio"
+	mock_natsio "github.com/kubeclipper/kubeclipper/pkg/simple/client/natsio/mock"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1/k8s"
 )
 
 func getOperationCase1() *v1.Operation {
@@ -143,3 +156,282 @@ func mustInitPayload(step *v1.Step) []byte {
 		return data
 	}
 }
+
+func Test_newStepBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		backoff *v1.StepBackoff
+		want    []time.Duration
+	}{
+		{
+			name: "default backoff",
+			want: []time.Duration{5 * time.Second, 10 * time.Second, 20 * time.Second, 40 * time.Second, 80 * time.Second, 120 * time.Second, 120 * time.Second},
+		},
+		{
+			name: "constant backoff",
+			backoff: &v1.StepBackoff{
+				Duration: metav1.Duration{Duration: time.Second},
+			},
+			want: []time.Duration{time.Second, time.Second, time.Second},
+		},
+		{
+			name: "capped backoff",
+			backoff: &v1.StepBackoff{
+				Duration: metav1.Duration{Duration: time.Second},
+				Factor:   3,
+				Cap:      metav1.Duration{Duration: 5 * time.Second},
+			},
+			want: []time.Duration{time.Second, 3 * time.Second, 5 * time.Second, 5 * time.Second},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newStepBackoff(tt.backoff)
+			for i, want := range tt.want {
+				if got := b.Step(); got != want {
+					t.Errorf("newStepBackoff() step %d = %v, want %v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func Test_failedStepNodes(t *testing.T) {
+	status := []v1.StepStatus{
+		{Node: "node1", Status: v1.StepStatusSuccessful},
+		{Node: "node2", Status: v1.StepStatusFailed},
+		{Node: "node3"},
+	}
+	got := failedStepNodes(status, []int{0, 1, 2})
+	if len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Errorf("failedStepNodes() = %v, want [1 2]", got)
+	}
+}
+
+func Test_etcdMemberStatus(t *testing.T) {
+	response := func(size int64) []byte {
+		b, _ := json.Marshal(&v1.EtcdMemberStatus{DBSize: size, DBSizeInUse: size / 2})
+		return b
+	}
+	op := &v1.Operation{
+		Steps: []v1.Step{{ID: "defrag", Name: "etcdDefrag-node1"}, {ID: "status", Name: k8s.EtcdStatusStep}},
+		Status: v1.OperationStatus{
+			Conditions: []v1.OperationCondition{
+				{StepID: "defrag", Status: []v1.StepStatus{{Node: "node1", Status: v1.StepStatusSuccessful, Response: response(1)}}},
+				{StepID: "status", Status: []v1.StepStatus{
+					{Node: "node1", Status: v1.StepStatusFailed},
+					{Node: "node2", Status: v1.StepStatusSuccessful, Response: response(200)},
+					{Node: "node1", Status: v1.StepStatusSuccessful, Response: response(100)},
+				}},
+			},
+		},
+	}
+	got := etcdMemberStatus(op)
+	if len(got) != 2 {
+		t.Fatalf("etcdMemberStatus() got %d members, want 2", len(got))
+	}
+	if got[0].Node != "node2" || got[0].DBSize != 200 || got[1].Node != "node1" || got[1].DBSize != 100 || got[1].DBSizeInUse != 50 {
+		t.Errorf("etcdMemberStatus() = %+v", got)
+	}
+}
+
+func TestService_runSteps(t *testing.T) {
+	newStep := func(id string, dependsOn ...string) v1.Step {
+		return v1.Step{
+			ID:        id,
+			Name:      id,
+			Timeout:   metav1.Duration{Duration: time.Second},
+			Nodes:     []v1.StepNode{{ID: "node1"}},
+			DependsOn: dependsOn,
+		}
+	}
+	tests := []struct {
+		name           string
+		steps          []v1.Step
+		concurrency    int
+		failed         string
+		cancelled      string
+		replies        map[string][]byte
+		wantErr        bool
+		wantRun        []string
+		wantConcurrent int32
+		wantReplies    map[string]string
+	}{
+		{
+			name:           "run steps one after another",
+			steps:          []v1.Step{newStep("a"), newStep("b"), newStep("c")},
+			concurrency:    4,
+			wantRun:        []string{"a", "b", "c"},
+			wantConcurrent: 1,
+		},
+		{
+			name:           "run independent steps at the same time",
+			steps:          []v1.Step{newStep("a"), newStep("b", "a"), newStep("c", "a"), newStep("d", "b", "c")},
+			concurrency:    4,
+			wantRun:        []string{"a", "b", "c", "d"},
+			wantConcurrent: 2,
+		},
+		{
+			name:           "limit concurrency",
+			steps:          []v1.Step{newStep("a"), newStep("b", "a"), newStep("c", "a"), newStep("d", "a")},
+			concurrency:    2,
+			wantRun:        []string{"a", "b", "c", "d"},
+			wantConcurrent: 2,
+		},
+		{
+			name:           "stop scheduling after failure",
+			steps:          []v1.Step{newStep("a"), newStep("b", "a"), newStep("c", "b")},
+			concurrency:    4,
+			failed:         "b",
+			wantErr:        true,
+			wantRun:        []string{"a", "b"},
+			wantConcurrent: 1,
+		},
+		{
+			name:           "stop scheduling after cancellation",
+			steps:          []v1.Step{newStep("a"), newStep("b", "a"), newStep("c", "b")},
+			concurrency:    4,
+			cancelled:      "b",
+			wantErr:        true,
+			wantRun:        []string{"a", "b"},
+			wantConcurrent: 1,
+		},
+		{
+			name:           "pass the replies to the resumed steps",
+			steps:          []v1.Step{newStep("a"), newStep("b"), newStep("c", "a")},
+			concurrency:    4,
+			replies:        map[string][]byte{"a": []byte("join"), "c": []byte("init")},
+			wantRun:        []string{"a", "b", "c"},
+			wantConcurrent: 2,
+			wantReplies:    map[string]string{"a": "join", "c": "init"},
+		},
+		{
+			name:    "depend on later step",
+			steps:   []v1.Step{newStep("a", "b"), newStep("b")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCtl := gomock.NewController(t)
+			defer mockCtl.Finish()
+			mockNatsio := mock_natsio.NewMockInterface(mockCtl)
+
+			ctx, cancel := context.WithCancel(context.TODO())
+			defer cancel()
+			var (
+				mu                  sync.Mutex
+				run                 []string
+				current, concurrent int32
+				replies             = make(map[string]string)
+			)
+			mockNatsio.EXPECT().Request(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(msg *natsio.Msg, _ natsio.TimeoutHandler) ([]byte, error) {
+					payload := service.MsgPayload{}
+					if err := json.Unmarshal(msg.Data, &payload); err != nil {
+						return nil, err
+					}
+					n := atomic.AddInt32(&current, 1)
+					defer atomic.AddInt32(&current, -1)
+					mu.Lock()
+					run = append(run, payload.Step.ID)
+					if len(payload.LastTaskReply) > 0 {
+						replies[payload.Step.ID] = string(payload.LastTaskReply)
+					}
+					if n > concurrent {
+						concurrent = n
+					}
+					mu.Unlock()
+					time.Sleep(50 * time.Millisecond)
+					reply := service.CommonReply{}
+					if payload.Step.ID == tt.failed {
+						reply.Error = &errors.StatusError{Message: "failed", Code: 500}
+					}
+					if payload.Step.ID == tt.cancelled {
+						cancel()
+					}
+					return mustJSONMarshal(reply), nil
+				}).AnyTimes()
+
+			s := &Service{
+				client:            mockNatsio,
+				stepStatusChan:    make(chan stepStatus, 256),
+				maxConcurrentStep: tt.concurrency,
+			}
+			op := &v1.Operation{Steps: tt.steps}
+			op.Status.Conditions = make([]v1.OperationCondition, len(op.Steps))
+			err := s.runSteps(ctx, nil, tt.replies, op, true)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("runSteps() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			sort.Strings(run)
+			if !reflect.DeepEqual(run, tt.wantRun) {
+				t.Errorf("runSteps() run steps = %v, want %v", run, tt.wantRun)
+			}
+			if concurrent != tt.wantConcurrent {
+				t.Errorf("runSteps() concurrent steps = %d, want %d", concurrent, tt.wantConcurrent)
+			}
+			if len(replies) > 0 || len(tt.wantReplies) > 0 {
+				if !reflect.DeepEqual(replies, tt.wantReplies) {
+					t.Errorf("runSteps() last replies = %v, want %v", replies, tt.wantReplies)
+				}
+			}
+		})
+	}
+}
+
+func TestService_syncClusterCondition_removeNodes(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+	clusterOperator := mock_cluster.NewMockOperator(mockCtl)
+
+	clu := &v1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "k3s"},
+		Masters:    v1.WorkerNodeList{{ID: "m0"}},
+		Workers:    v1.WorkerNodeList{{ID: "w0"}, {ID: "w1"}},
+	}
+	clu.Provider.Name = v1.ClusterK3s
+	// the k3s steps removing a worker, there is no kubeadmReset step
+	op := &v1.Operation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "op1",
+			Labels: map[string]string{
+				common.LabelClusterName:     "k3s",
+				common.LabelOperationAction: v1.OperationRemoveNodes,
+			},
+			Annotations: map[string]string{common.AnnotationOperationNodes: "w1"},
+		},
+		Steps: []v1.Step{
+			{Name: "drainNode", Nodes: []v1.StepNode{{ID: "m0"}}},
+			{Name: "deleteNode-w1", Nodes: []v1.StepNode{{ID: "m0"}}},
+			{Name: "removeAgents", Nodes: []v1.StepNode{{ID: "w1"}}},
+		},
+		Status: v1.OperationStatus{Status: v1.OperationStatusSuccessful},
+	}
+	clusterOperator.EXPECT().UpdateCluster(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, c *v1.Cluster) (*v1.Cluster, error) {
+		if !reflect.DeepEqual(c.Workers, v1.WorkerNodeList{{ID: "w0"}}) {
+			t.Errorf("syncClusterCondition() workers = %v, want [w0]", c.Workers)
+		}
+		return c, nil
+	})
+	clusterOperator.EXPECT().GetNodeEx(gomock.Any(), "w1", "0").Return(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "w1",
+			Labels: map[string]string{
+				common.LabelNodeRole:    string(common.NodeRoleWorker),
+				common.LabelClusterName: "k3s",
+			},
+		},
+	}, nil)
+	clusterOperator.EXPECT().UpdateNode(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, n *v1.Node) (*v1.Node, error) {
+		if _, ok := n.Labels[common.LabelNodeRole]; ok {
+			t.Errorf("syncClusterCondition() node labels = %v, want the role label removed", n.Labels)
+		}
+		return n, nil
+	})
+
+	s := &Service{clusterOperator: clusterOperator}
+	if err := s.syncClusterCondition(op, clu); err != nil {
+		t.Fatalf("syncClusterCondition() error = %v", err)
+	}
+}
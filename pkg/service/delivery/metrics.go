@@ -0,0 +1,121 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package delivery
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	compbasemetrics "k8s.io/component-base/metrics"
+
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/metrics"
+)
+
+const (
+	requestRunTask = "runTask"
+	requestRunCmd  = "runCmd"
+	requestStepLog = "stepLog"
+)
+
+var (
+	operationDuration = compbasemetrics.NewHistogramVec(
+		&compbasemetrics.HistogramOpts{
+			Name:           "kc_operation_duration_seconds",
+			Help:           "Duration distribution in seconds of the operations by operation type and result status.",
+			Buckets:        []float64{10, 30, 60, 120, 300, 600, 900, 1200, 1800, 3600, 7200},
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"operation", "status"},
+	)
+
+	operationFailures = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Name:           "kc_operation_failures_total",
+			Help:           "Counter of the failed operations by operation type.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"operation"},
+	)
+
+	stepDuration = compbasemetrics.NewHistogramVec(
+		&compbasemetrics.HistogramOpts{
+			Name:           "kc_step_duration_seconds",
+			Help:           "Duration distribution in seconds of the operation steps by step name and result status, including the retries.",
+			Buckets:        []float64{1, 5, 10, 30, 60, 120, 300, 600, 1200, 1800},
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"step", "status"},
+	)
+
+	deliveryRequestLatencies = compbasemetrics.NewHistogramVec(
+		&compbasemetrics.HistogramOpts{
+			Name:           "kc_delivery_request_duration_seconds",
+			Help:           "Latency distribution in seconds of the requests to the agents through the message queue.",
+			Buckets:        compbasemetrics.ExponentialBuckets(0.01, 2, 16),
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"request"},
+	)
+
+	deliveryRequestTimeouts = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Name:           "kc_delivery_request_timeouts_total",
+			Help:           "Counter of the requests to the agents which are timed out waiting for the reply.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"request"},
+	)
+
+	registerMetricsOnce sync.Once
+)
+
+func registerMetrics() {
+	registerMetricsOnce.Do(func() {
+		metrics.MustRegister(operationDuration, operationFailures, stepDuration,
+			deliveryRequestLatencies, deliveryRequestTimeouts)
+	})
+}
+
+func observeOperation(operation *v1.Operation, start time.Time, status v1.OperationStatusType) {
+	action := operation.Labels[common.LabelOperationAction]
+	operationDuration.WithLabelValues(action, string(status)).Observe(time.Since(start).Seconds())
+	if status == v1.OperationStatusFailed {
+		operationFailures.WithLabelValues(action).Inc()
+	}
+}
+
+func observeStep(step *v1.Step, start time.Time, err error) {
+	status := v1.StepStatusSuccessful
+	if err != nil {
+		status = v1.StepStatusFailed
+	}
+	stepDuration.WithLabelValues(step.Name, string(status)).Observe(time.Since(start).Seconds())
+}
+
+func observeDeliveryRequest(request string, start time.Time, err error) {
+	deliveryRequestLatencies.WithLabelValues(request).Observe(time.Since(start).Seconds())
+	if errors.Is(err, nats.ErrTimeout) || errors.Is(err, context.DeadlineExceeded) {
+		deliveryRequestTimeouts.WithLabelValues(request).Inc()
+	}
+}
@@ -0,0 +1,89 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package delivery
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/component-base/metrics/testutil"
+
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func TestObserveDeliveryRequest(t *testing.T) {
+	registerMetrics()
+	tests := []struct {
+		name         string
+		err          error
+		wantTimeouts float64
+	}{
+		{name: "replied"},
+		{name: "failed", err: fmt.Errorf("no responders")},
+		{name: "nats timeout", err: nats.ErrTimeout, wantTimeouts: 1},
+		{name: "context deadline", err: fmt.Errorf("request: %w", context.DeadlineExceeded), wantTimeouts: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := "test-" + tt.name
+			observeDeliveryRequest(request, time.Now(), tt.err)
+			count, err := testutil.GetHistogramMetricCount(deliveryRequestLatencies.WithLabelValues(request))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != 1 {
+				t.Errorf("latency count = %d, want 1", count)
+			}
+			timeouts, err := testutil.GetCounterMetricValue(deliveryRequestTimeouts.WithLabelValues(request))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if timeouts != tt.wantTimeouts {
+				t.Errorf("timeouts = %v, want %v", timeouts, tt.wantTimeouts)
+			}
+		})
+	}
+}
+
+func TestObserveOperation(t *testing.T) {
+	registerMetrics()
+	op := &v1.Operation{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{common.LabelOperationAction: "test-create"}}}
+	observeOperation(op, time.Now(), v1.OperationStatusSuccessful)
+	observeOperation(op, time.Now(), v1.OperationStatusFailed)
+	observeOperation(op, time.Now(), v1.OperationStatusFailed)
+	failures, err := testutil.GetCounterMetricValue(operationFailures.WithLabelValues("test-create"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failures != 2 {
+		t.Errorf("failures = %v, want 2", failures)
+	}
+	count, err := testutil.GetHistogramMetricCount(operationDuration.WithLabelValues("test-create", string(v1.OperationStatusSuccessful)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("successful operations = %d, want 1", count)
+	}
+}
@@ -0,0 +1,53 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package delivery
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+type Options struct {
+	// MaxConcurrentSteps is the maximum number of steps of one operation running at the same time.
+	// It only takes effect for operations whose steps declare their dependencies.
+	MaxConcurrentSteps int `json:"maxConcurrentSteps" yaml:"maxConcurrentSteps" mapstructure:"maxConcurrentSteps"`
+}
+
+func NewOptions() *Options {
+	return &Options{
+		MaxConcurrentSteps: 4,
+	}
+}
+
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	fs.IntVar(&o.MaxConcurrentSteps, "max-concurrent-steps", o.MaxConcurrentSteps,
+		"maximum number of independent steps of one operation running at the same time")
+}
+
+func (o *Options) Validate() []error {
+	if o == nil {
+		return nil
+	}
+	var errs []error
+	if o.MaxConcurrentSteps < 1 {
+		errs = append(errs, fmt.Errorf("max concurrent steps must be greater than 0"))
+	}
+	return errs
+}
@@ -0,0 +1,185 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package delivery
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/models/operation"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/service"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/pointer"
+)
+
+const (
+	// runnerLeaseDuration is how long a server is considered alive after its last runner lease renewal.
+	runnerLeaseDuration   = 40 * time.Second
+	runnerLeaseRenewEvery = 10 * time.Second
+)
+
+// runner identifies the server process running operations, the lease name is
+// stable across restarts while the holder identity changes on every start.
+type runner struct {
+	leaseName string
+	holder    string
+}
+
+func newRunner() runner {
+	name, err := os.Hostname()
+	if err != nil || name == "" {
+		name = uuid.New().String()
+	}
+	return runner{leaseName: name, holder: uuid.New().String()}
+}
+
+func (r runner) String() string {
+	return r.leaseName + "/" + r.holder
+}
+
+// runRunnerLease renews the runner lease of the server until stopCh is closed.
+func (s *Service) runRunnerLease(stopCh <-chan struct{}) {
+	wait.Until(func() {
+		if err := s.renewRunnerLease(); err != nil {
+			logger.Error("renew operation runner lease failed", zap.String("runner", s.runner.String()), zap.Error(err))
+		}
+	}, runnerLeaseRenewEvery, stopCh)
+}
+
+func (s *Service) renewRunnerLease() error {
+	now := metav1.NewMicroTime(time.Now())
+	l, err := s.leaseOperator.GetLeaseWithNamespaceEx(context.TODO(), s.runner.leaseName, common.ServerLeaseNamespace, "0")
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		_, err = s.leaseOperator.CreateLease(context.TODO(), &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      s.runner.leaseName,
+				Namespace: common.ServerLeaseNamespace,
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       pointer.StringPtr(s.runner.holder),
+				LeaseDurationSeconds: pointer.Int32Ptr(int32(runnerLeaseDuration.Seconds())),
+				AcquireTime:          &now,
+				RenewTime:            &now,
+			},
+		})
+		return err
+	}
+	if l.Spec.HolderIdentity == nil || *l.Spec.HolderIdentity != s.runner.holder {
+		// the server restarted, operations run by the previous process are orphaned.
+		l.Spec.HolderIdentity = pointer.StringPtr(s.runner.holder)
+		l.Spec.AcquireTime = &now
+	}
+	l.Spec.LeaseDurationSeconds = pointer.Int32Ptr(int32(runnerLeaseDuration.Seconds()))
+	l.Spec.RenewTime = &now
+	_, err = s.leaseOperator.UpdateLease(context.TODO(), l)
+	return err
+}
+
+// setOperationRunner records the server as the runner of the operation.
+func (s *Service) setOperationRunner(name string) {
+	for i := 0; i < updateOperationStatusRetry; i++ {
+		o, err := s.opOperator.GetOperationEx(context.TODO(), name, "0")
+		if err != nil {
+			logger.Error("get operation failed", zap.String("op", name), zap.Error(err))
+			continue
+		}
+		if o.Annotations[common.AnnotationOperationRunner] == s.runner.String() {
+			return
+		}
+		if o.Annotations == nil {
+			o.Annotations = make(map[string]string)
+		}
+		o.Annotations[common.AnnotationOperationRunner] = s.runner.String()
+		if _, err = s.opOperator.UpdateOperation(context.TODO(), o); err != nil {
+			logger.Error("set operation runner failed", zap.String("op", name), zap.Error(err))
+			continue
+		}
+		return
+	}
+}
+
+// AdoptOperation takes over the running operation whose runner is gone. The operation is resumed
+// from the failed step if it supports retries, otherwise it is marked as failed. Nothing is done
+// if the runner of the stored operation is no longer the given runner.
+func (s *Service) AdoptOperation(ctx context.Context, name, runner string) error {
+	op, err := s.opOperator.GetOperationEx(ctx, name, "0")
+	if err != nil {
+		return err
+	}
+	if op.Status.Status != v1.OperationStatusRunning || op.Annotations[common.AnnotationOperationRunner] != runner {
+		return nil
+	}
+	if op.Annotations == nil {
+		op.Annotations = make(map[string]string)
+	}
+	op.Annotations[common.AnnotationOperationRunner] = s.runner.String()
+
+	lost := func(reason error) error {
+		op.Status.Status = v1.OperationStatusFailed
+		op.Status.Reason = v1.OperationReasonRunnerLost
+		op.Status.Message = fmt.Sprintf("the server %q running the operation is gone: %v", runner, reason)
+		o, err := s.opOperator.UpdateOperation(ctx, op)
+		if err != nil {
+			return err
+		}
+		logger.Info("operation failed since its runner is gone", zap.String("op", name), zap.String("runner", runner))
+		go s.SyncClusterCondition(o)
+		return nil
+	}
+	if !operation.SupportRetry(op) {
+		return lost(fmt.Errorf("%s operation can not be resumed", op.Labels[common.LabelOperationAction]))
+	}
+	steps, replies, err := operation.ParseRetrySteps(op, "")
+	if err != nil {
+		return lost(err)
+	}
+	op.Status.Reason = v1.OperationReasonResumed
+	op.Status.Message = fmt.Sprintf("resumed by the server %q since the server %q running the operation is gone", s.runner, runner)
+	if op, err = s.opOperator.UpdateOperation(ctx, op); err != nil {
+		return err
+	}
+	logger.Info("resume operation since its runner is gone", zap.String("op", name),
+		zap.String("runner", runner), zap.Int("steps", len(steps)))
+	op.Steps = steps
+	stepCtx := component.WithRetry(context.TODO(), true)
+	if len(replies) > 0 {
+		stepCtx = component.WithStepReplies(stepCtx, replies)
+	}
+	go func() {
+		if err := s.DeliverTaskOperation(stepCtx, op, &service.Options{}); err != nil {
+			logger.Error("resume operation failed", zap.String("op", name), zap.Error(err))
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,248 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package delivery
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	mock_cluster "github.com/kubeclipper/kubeclipper/pkg/models/cluster/mock"
+	mock_lease "github.com/kubeclipper/kubeclipper/pkg/models/lease/mock"
+	mock_operation "github.com/kubeclipper/kubeclipper/pkg/models/operation/mock"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/pointer"
+)
+
+func TestService_renewRunnerLease(t *testing.T) {
+	acquired := metav1.NewMicroTime(time.Now().Add(-time.Hour))
+	newLease := func(holder string) *coordinationv1.Lease {
+		return &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{Name: "server1", Namespace: common.ServerLeaseNamespace},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity: pointer.StringPtr(holder),
+				AcquireTime:    &acquired,
+				RenewTime:      &acquired,
+			},
+		}
+	}
+	tests := []struct {
+		name         string
+		lease        *coordinationv1.Lease
+		updateErr    error
+		wantCreate   bool
+		wantAcquired bool
+		wantErr      bool
+	}{
+		{
+			name:       "create missing lease",
+			wantCreate: true,
+		},
+		{
+			name:  "renew own lease",
+			lease: newLease("holder1"),
+		},
+		{
+			name:         "take over lease of previous process",
+			lease:        newLease("holder0"),
+			wantAcquired: true,
+		},
+		{
+			name:      "renewal failure",
+			lease:     newLease("holder1"),
+			updateErr: errors.New("etcd is unavailable"),
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			leaseOperator := mock_lease.NewMockOperator(ctrl)
+			s := &Service{leaseOperator: leaseOperator, runner: runner{leaseName: "server1", holder: "holder1"}}
+
+			var saved *coordinationv1.Lease
+			if tt.lease == nil {
+				leaseOperator.EXPECT().GetLeaseWithNamespaceEx(gomock.Any(), "server1", common.ServerLeaseNamespace, "0").
+					Return(nil, apierrors.NewNotFound(coordinationv1.Resource("lease"), "server1"))
+				leaseOperator.EXPECT().CreateLease(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, l *coordinationv1.Lease) (*coordinationv1.Lease, error) {
+						saved = l
+						return l, nil
+					})
+			} else {
+				leaseOperator.EXPECT().GetLeaseWithNamespaceEx(gomock.Any(), "server1", common.ServerLeaseNamespace, "0").
+					Return(tt.lease, nil)
+				leaseOperator.EXPECT().UpdateLease(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, l *coordinationv1.Lease) (*coordinationv1.Lease, error) {
+						saved = l
+						return l, tt.updateErr
+					})
+			}
+			err := s.renewRunnerLease()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("renewRunnerLease() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if *saved.Spec.HolderIdentity != "holder1" {
+				t.Errorf("renewRunnerLease() holder = %s, want holder1", *saved.Spec.HolderIdentity)
+			}
+			if !saved.Spec.RenewTime.After(acquired.Time) {
+				t.Errorf("renewRunnerLease() renew time %v is not renewed", saved.Spec.RenewTime)
+			}
+			if acquiredNow := saved.Spec.AcquireTime.After(acquired.Time); acquiredNow != (tt.wantCreate || tt.wantAcquired) {
+				t.Errorf("renewRunnerLease() acquire time = %v, want acquired %v", saved.Spec.AcquireTime, tt.wantCreate || tt.wantAcquired)
+			}
+		})
+	}
+}
+
+func TestService_AdoptOperation(t *testing.T) {
+	const goneRunner = "server1/holder1"
+	newOp := func(action string, status v1.OperationStatusType, runner string) *v1.Operation {
+		nodes := []v1.StepNode{{ID: "node1"}}
+		return &v1.Operation{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "op1",
+				Labels: map[string]string{
+					common.LabelClusterName:     "cluster1",
+					common.LabelOperationAction: action,
+					common.LabelTimeoutSeconds:  "60",
+				},
+				Annotations: map[string]string{common.AnnotationOperationRunner: runner},
+			},
+			Steps: []v1.Step{{ID: "s1", Action: v1.ActionInstall, Nodes: nodes}},
+			Status: v1.OperationStatus{
+				Status: status,
+				Conditions: []v1.OperationCondition{{StepID: "s1", Status: []v1.StepStatus{
+					{Node: "node1", Status: v1.StepStatusSuccessful},
+				}}},
+			},
+		}
+	}
+	tests := []struct {
+		name        string
+		op          *v1.Operation
+		wantReasons []string
+		wantStatus  v1.OperationStatusType
+	}{
+		{
+			name:       "runner changed",
+			op:         newOp(v1.OperationCreateCluster, v1.OperationStatusRunning, "server3/holder3"),
+			wantStatus: v1.OperationStatusRunning,
+		},
+		{
+			name:       "operation finished",
+			op:         newOp(v1.OperationCreateCluster, v1.OperationStatusSuccessful, goneRunner),
+			wantStatus: v1.OperationStatusSuccessful,
+		},
+		{
+			name:        "operation can not be resumed",
+			op:          newOp(v1.OperationUpgradeCluster, v1.OperationStatusRunning, goneRunner),
+			wantReasons: []string{v1.OperationReasonRunnerLost},
+			wantStatus:  v1.OperationStatusFailed,
+		},
+		{
+			name: "resume operation",
+			op:   newOp(v1.OperationCreateCluster, v1.OperationStatusRunning, goneRunner),
+			// the status is set by the delivery of the resumed operation
+			wantReasons: []string{v1.OperationReasonResumed, v1.OperationReasonResumed},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			opOperator := mock_operation.NewMockOperator(ctrl)
+			clusterOperator := mock_cluster.NewMockOperator(ctrl)
+			s := &Service{
+				opOperator:      opOperator,
+				clusterOperator: clusterOperator,
+				runner:          runner{leaseName: "server2", holder: "holder2"},
+				running:         make(map[string]context.CancelFunc),
+			}
+
+			// the operations are stored in memory, the cluster is synced when the operation is done.
+			var (
+				mu      sync.Mutex
+				stored  = tt.op.DeepCopy()
+				reasons []string
+			)
+			get := func(ctx context.Context, name string, _ ...string) (*v1.Operation, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				return stored.DeepCopy(), nil
+			}
+			opOperator.EXPECT().GetOperationEx(gomock.Any(), "op1", "0").
+				DoAndReturn(func(ctx context.Context, name, rv string) (*v1.Operation, error) { return get(ctx, name) }).AnyTimes()
+			opOperator.EXPECT().GetOperation(gomock.Any(), "op1").
+				DoAndReturn(func(ctx context.Context, name string) (*v1.Operation, error) { return get(ctx, name) }).AnyTimes()
+			opOperator.EXPECT().UpdateOperation(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, o *v1.Operation) (*v1.Operation, error) {
+					mu.Lock()
+					defer mu.Unlock()
+					stored = o.DeepCopy()
+					reasons = append(reasons, o.Status.Reason)
+					return o, nil
+				}).AnyTimes()
+			synced := make(chan struct{}, 1)
+			clusterOperator.EXPECT().GetClusterEx(gomock.Any(), "cluster1", "0").
+				Return(&v1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster1"}}, nil).AnyTimes()
+			clusterOperator.EXPECT().UpdateCluster(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, c *v1.Cluster) (*v1.Cluster, error) {
+					synced <- struct{}{}
+					return c, nil
+				}).AnyTimes()
+
+			if err := s.AdoptOperation(context.TODO(), "op1", goneRunner); err != nil {
+				t.Fatalf("AdoptOperation() error = %v", err)
+			}
+			if len(tt.wantReasons) > 0 {
+				select {
+				case <-synced:
+				case <-time.After(5 * time.Second):
+					t.Fatal("AdoptOperation() did not sync the cluster")
+				}
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if len(reasons) != len(tt.wantReasons) {
+				t.Fatalf("AdoptOperation() updated the operation with reasons %v, want %v", reasons, tt.wantReasons)
+			}
+			for i := range reasons {
+				if reasons[i] != tt.wantReasons[i] {
+					t.Errorf("AdoptOperation() update %d reason = %s, want %s", i, reasons[i], tt.wantReasons[i])
+				}
+			}
+			if tt.wantStatus != "" && stored.Status.Status != tt.wantStatus {
+				t.Errorf("AdoptOperation() status = %s, want %s", stored.Status.Status, tt.wantStatus)
+			}
+			if len(tt.wantReasons) > 0 && stored.Annotations[common.AnnotationOperationRunner] != s.runner.String() {
+				t.Errorf("AdoptOperation() runner = %s, want %s", stored.Annotations[common.AnnotationOperationRunner], s.runner)
+			}
+		})
+	}
+}
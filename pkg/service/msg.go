@@ -19,6 +19,7 @@
 package service
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/kubeclipper/kubeclipper/pkg/errors"
@@ -40,10 +41,16 @@ const (
 	OperationBackup
 	OperationRecovery
 	OperationRunCmd
+	OperationCancelTask
 )
 
 const (
 	MsgSubjectFormat = "%s.%s"
+	// MsgCancelOperationSubject is subscribed by every server to cancel the operations it runs,
+	// it is formatted with MsgSubjectFormat and the subject suffix.
+	MsgCancelOperationSubject = "operation-cancel"
+	// MsgOperationLogSubjectFormat is formatted with the operation name to publish the step logs.
+	MsgOperationLogSubjectFormat = "operation-log.%s"
 	// action:bakFileName:opID:stepID
 	MsgCreateBackupFormat = "%s:%s:%s:%s"
 	// action:bakFileName:id
@@ -52,6 +59,11 @@ const (
 	MsgStepRecoveryFormat = "%s:%s:%s"
 )
 
+// OperationLogSubject returns the subject which the agents publish the step logs of the operation to.
+func OperationLogSubject(operation, subjectSuffix string) string {
+	return fmt.Sprintf(MsgSubjectFormat, fmt.Sprintf(MsgOperationLogSubjectFormat, operation), subjectSuffix)
+}
+
 type NodeStatusPayload struct {
 	Op       Operation `json:"op,omitempty"`
 	NodeName string    `json:"node_name,omitempty"`
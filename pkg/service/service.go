@@ -42,12 +42,18 @@ type Interface interface {
 
 type IDelivery interface {
 	DeliverLogRequest(ctx context.Context, operation *LogOperation) (oplog.LogContentResponse, error) // request & response synchronously.
+	// SubscribeOperationLog calls handler with the step logs appended by the agents until ctx is done.
+	SubscribeOperationLog(ctx context.Context, operation string, handler func(chunk oplog.LogStreamChunk)) error
 	CmdDelivery
 }
 
 type CmdDelivery interface {
 	DeliverTaskOperation(ctx context.Context, operation *v1.Operation, opts *Options) error
 	DeliverCmd(ctx context.Context, toNode string, cmds []string, timeout time.Duration) ([]byte, error)
+	// AdoptOperation takes over the running operation from the gone runner.
+	AdoptOperation(ctx context.Context, name, runner string) error
+	// CancelOperation stops the running operation on the servers and agents.
+	CancelOperation(ctx context.Context, name string) (*v1.Operation, error)
 }
 
 func HandlerCrash() {
@@ -22,6 +22,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/nats-io/nats.go"
@@ -106,6 +107,12 @@ func (s *Service) taskHandler(msg *nats.Msg) {
 	}
 	logger.Debug("in coming task payload", zap.Int("operation", int(payload.Op)),
 		zap.String("step", payload.Step.Name), zap.ByteString("lastResponse", payload.LastTaskReply), zap.Duration("timeout", payload.Step.Timeout.Duration))
+	if payload.Op == service.OperationCancelTask {
+		// the cancellation is published without waiting for the reply.
+		logger.Info("cancel operation task steps", zap.String("operation", payload.OperationIdentity),
+			zap.Int("steps", s.tasks.cancel(payload.OperationIdentity)))
+		return
+	}
 	ctx, cancel := context.WithTimeout(context.TODO(), payload.Step.Timeout.Duration)
 	defer cancel()
 	var statusError *errors.StatusError
@@ -151,17 +158,16 @@ func (s *Service) taskHandler(msg *nats.Msg) {
 			return
 		}
 	case service.OperationRunTask:
+		// step retry is driven by the server, which re-delivers the step to the failed nodes with backoff.
 		var replyData []byte
-		for i := 0; i <= int(payload.Step.RetryTimes); i++ {
-			// reset retry field
-			if i > 0 {
-				payload.Retry = true
-			}
-			replyData, statusError = s.runTaskStep(ctx, payload, msg.Subject)
-			if statusError == nil {
-				break
-			}
-			logger.Debug("run task step failed", zap.String("step", payload.Step.Name), zap.Int("retry", i), zap.Int32("maxRetry", payload.Step.RetryTimes))
+		defer s.tasks.add(payload.OperationIdentity, cancel)()
+		start := time.Now()
+		replyData, statusError = s.runTaskStep(ctx, payload, msg.Subject)
+		if !payload.DryRun {
+			observeStep(&payload.Step, start, statusError)
+		}
+		if statusError != nil {
+			logger.Debug("run task step failed", zap.String("step", payload.Step.Name), zap.Bool("retry", payload.Retry))
 		}
 		responseMessage(msg, replyData, statusError)
 	default:
@@ -173,6 +179,47 @@ func (s *Service) taskHandler(msg *nats.Msg) {
 	}
 }
 
+// taskCancels tracks the cancel functions of the running task steps by operation.
+type taskCancels struct {
+	mu    sync.Mutex
+	next  int
+	tasks map[string]map[int]context.CancelFunc
+}
+
+// add records the cancel function of a task step of the operation, the returned
+// function must be called when the step is done.
+func (t *taskCancels) add(op string, cancel context.CancelFunc) func() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.tasks == nil {
+		t.tasks = make(map[string]map[int]context.CancelFunc)
+	}
+	if t.tasks[op] == nil {
+		t.tasks[op] = make(map[int]context.CancelFunc)
+	}
+	id := t.next
+	t.next++
+	t.tasks[op][id] = cancel
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		delete(t.tasks[op], id)
+		if len(t.tasks[op]) == 0 {
+			delete(t.tasks, op)
+		}
+	}
+}
+
+// cancel cancels the running task steps of the operation and returns the number of them.
+func (t *taskCancels) cancel(op string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, cancel := range t.tasks[op] {
+		cancel()
+	}
+	return len(t.tasks[op])
+}
+
 func runShellCommand(ctx context.Context, cmds []string, dryRun bool) error {
 	_, err := cmdutil.RunCmdWithContext(ctx, dryRun, cmds[0], cmds[1:]...)
 	return err
@@ -0,0 +1,112 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package task
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+
+	"go.uber.org/zap"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/oplog"
+	"github.com/kubeclipper/kubeclipper/pkg/service"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/natsio"
+)
+
+// publishFunc publishes the data appended to the step log file at offset, offset is -1 if unknown.
+type publishFunc func(opID, stepID string, offset int64, data []byte)
+
+var _ component.OperationLogFile = (*streamingOplog)(nil)
+
+// streamingOplog publishes the data appended to the step log files besides writing them.
+type streamingOplog struct {
+	component.OperationLogFile
+	publish publishFunc
+}
+
+func (l *streamingOplog) CreateStepLogFile(opID, stepID string) (io.WriteCloser, error) {
+	f, err := l.OperationLogFile.CreateStepLogFile(opID, stepID)
+	if err != nil {
+		return nil, err
+	}
+	return &streamingWriter{WriteCloser: f, publish: func(offset int64, data []byte) {
+		l.publish(opID, stepID, offset, data)
+	}}, nil
+}
+
+func (l *streamingOplog) CreateStepLogFileAndAppend(opID, stepID string, data []byte) error {
+	if err := l.OperationLogFile.CreateStepLogFileAndAppend(opID, stepID, data); err != nil {
+		return err
+	}
+	offset := int64(-1)
+	if path, err := l.GetStepLogFile(opID, stepID); err == nil {
+		if stat, err := os.Stat(path); err == nil {
+			offset = stat.Size() - int64(len(data))
+		}
+	}
+	l.publish(opID, stepID, offset, data)
+	return nil
+}
+
+// streamingWriter publishes the data written to the step log file.
+type streamingWriter struct {
+	io.WriteCloser
+	publish func(offset int64, data []byte)
+}
+
+func (w *streamingWriter) Write(p []byte) (int, error) {
+	n, err := w.WriteCloser.Write(p)
+	if n > 0 {
+		offset := int64(-1)
+		// the file is opened for appending, so the current offset is the end of the written data.
+		if seeker, ok := w.WriteCloser.(io.Seeker); ok {
+			if end, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+				offset = end - int64(n)
+			}
+		}
+		w.publish(offset, p[:n])
+	}
+	return n, err
+}
+
+func (s *Service) publishStepLog(opID, stepID string, offset int64, data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	chunk, err := json.Marshal(oplog.LogStreamChunk{
+		Node:    s.AgentID,
+		StepID:  stepID,
+		Offset:  offset,
+		Content: string(data),
+	})
+	if err != nil {
+		logger.Error("marshal step log chunk failed", zap.Error(err))
+		return
+	}
+	// nobody may follow the logs, the message is dropped by the server then.
+	if err = s.mqClient.Publish(&natsio.Msg{
+		Subject: service.OperationLogSubject(opID, s.subjectSuffix),
+		Data:    chunk,
+	}); err != nil {
+		logger.Debug("publish step log failed", zap.String("operation", opID), zap.String("step", stepID), zap.Error(err))
+	}
+}
@@ -0,0 +1,74 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package task
+
+import (
+	"sync"
+	"time"
+
+	compbasemetrics "k8s.io/component-base/metrics"
+
+	"github.com/kubeclipper/kubeclipper/pkg/errors"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/metrics"
+)
+
+var (
+	agentStepDuration = compbasemetrics.NewHistogramVec(
+		&compbasemetrics.HistogramOpts{
+			Name:           "kc_agent_step_duration_seconds",
+			Help:           "Duration distribution in seconds of the steps run by the agent by step name and result status.",
+			Buckets:        []float64{1, 5, 10, 30, 60, 120, 300, 600, 1200, 1800},
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"step", "status"},
+	)
+
+	agentLastHeartbeat = compbasemetrics.NewGauge(
+		&compbasemetrics.GaugeOpts{
+			Name:           "kc_agent_last_heartbeat_timestamp_seconds",
+			Help:           "Unix timestamp of the last time the agent renewed its node lease.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+	)
+
+	agentHeartbeatFailures = compbasemetrics.NewCounter(
+		&compbasemetrics.CounterOpts{
+			Name:           "kc_agent_heartbeat_failures_total",
+			Help:           "Counter of the failed node lease renewals of the agent.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+	)
+
+	registerMetricsOnce sync.Once
+)
+
+func registerMetrics() {
+	registerMetricsOnce.Do(func() {
+		metrics.MustRegister(agentStepDuration, agentLastHeartbeat, agentHeartbeatFailures)
+	})
+}
+
+func observeStep(step *v1.Step, start time.Time, statusError *errors.StatusError) {
+	status := v1.StepStatusSuccessful
+	if statusError != nil {
+		status = v1.StepStatusFailed
+	}
+	agentStepDuration.WithLabelValues(step.Name, string(status)).Observe(time.Since(start).Seconds())
+}
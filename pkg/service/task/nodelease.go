@@ -70,8 +70,10 @@ func (s *Service) tryUpdateNodeLease(base *coordinationv1.Lease) error {
 		lease, err := s.updateNodeLease(leaseToUpdate)
 		if err == nil {
 			s.latestLease = lease
+			agentLastHeartbeat.SetToCurrentTime()
 			return nil
 		}
+		agentHeartbeatFailures.Inc()
 		// etcd OptimisticLockError requires getting the newer version of lease to proceed.
 		if errors.IsConflict(err) {
 			base, _ = s.backoffEnsureNodeLease()
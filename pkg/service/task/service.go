@@ -60,6 +60,7 @@ type Service struct {
 	IPDetect          string
 	Region            string
 	AgentSubject      string
+	subjectSuffix     string
 	RegisterNode      bool
 
 	// lastStatusReportTime is the time when node status was last reported.
@@ -99,6 +100,8 @@ type Service struct {
 	oplog       component.OperationLogFile
 	backupStore bs.BackupStore
 	repoMirror  string
+	// tasks is the cancel functions of the running task steps.
+	tasks taskCancels
 }
 
 type ServiceOption func(*Service)
@@ -155,6 +158,7 @@ func defaultRepeatedHeartbeatFailure() {
 }
 
 func NewService(agentID, region, ipDetectMethod string, registerNode bool, natOpts *natsio.NatsOptions, opts ...ServiceOption) *Service {
+	registerMetrics()
 	nc := natsio.NewNats(natOpts)
 	nc.SetReconnectHandler(defaultMQReconnectHandler)
 	nc.SetDisconnectErrHandler(defaultMQDisconnectHandler)
@@ -167,6 +171,7 @@ func NewService(agentID, region, ipDetectMethod string, registerNode bool, natOp
 		IPDetect:                   ipDetectMethod,
 		Region:                     region,
 		AgentSubject:               fmt.Sprintf(service.MsgSubjectFormat, agentID, natOpts.Client.SubjectSuffix),
+		subjectSuffix:              natOpts.Client.SubjectSuffix,
 		RegisterNode:               registerNode,
 		clock:                      clock.RealClock{},
 		onRepeatedHeartbeatFailure: defaultRepeatedHeartbeatFailure,
@@ -174,6 +179,10 @@ func NewService(agentID, region, ipDetectMethod string, registerNode bool, natOp
 	for _, opt := range opts {
 		opt(s)
 	}
+	if s.oplog != nil {
+		// stream the step logs to the followers of the operation logs
+		s.oplog = &streamingOplog{OperationLogFile: s.oplog, publish: s.publishStepLog}
+	}
 
 	s.leaseRenewInterval = time.Duration(float64(time.Duration(s.leaseDurationSeconds)*time.Second) * nodeLeaseRenewIntervalFraction)
 	s.setNodeStatusFuncs = s.defaultNodeStatusFuncs()
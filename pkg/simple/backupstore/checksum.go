@@ -0,0 +1,74 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package backupstore
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io"
+)
+
+// ChecksumReader computes the size, md5 and sha256 value of the content while it is read,
+// so the backup file is hashed when it is streamed to the backup store.
+type ChecksumReader struct {
+	io.Reader
+	size   int64
+	md5    hash.Hash
+	sha256 hash.Hash
+}
+
+func NewChecksumReader(r io.Reader) *ChecksumReader {
+	c := &ChecksumReader{md5: md5.New(), sha256: sha256.New()}
+	c.Reader = io.TeeReader(r, io.MultiWriter(c.md5, c.sha256, (*counter)(&c.size)))
+	return c
+}
+
+// Size returns the number of bytes read.
+func (c *ChecksumReader) Size() int64 {
+	return c.size
+}
+
+// MD5 returns the hex encoded md5 value of the content read.
+func (c *ChecksumReader) MD5() string {
+	return fmt.Sprintf("%x", c.md5.Sum(nil))
+}
+
+// Checksum returns the sha256 checksum of the content read, e.g. sha256:<hex>.
+func (c *ChecksumReader) Checksum() string {
+	return fmt.Sprintf("sha256:%x", c.sha256.Sum(nil))
+}
+
+type counter int64
+
+func (c *counter) Write(p []byte) (int, error) {
+	*c += counter(len(p))
+	return len(p), nil
+}
+
+// fileMD5 returns the size and md5 value of the content of r.
+func fileMD5(r io.Reader) (*FileInfo, error) {
+	h := md5.New()
+	size, err := io.Copy(h, r)
+	if err != nil {
+		return nil, err
+	}
+	return &FileInfo{Size: size, MD5: fmt.Sprintf("%x", h.Sum(nil))}, nil
+}
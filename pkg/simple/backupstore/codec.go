@@ -0,0 +1,408 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package backupstore
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+
+	EncryptionAESGCM = "aes-256-gcm"
+)
+
+// the layout of the encrypted file is
+//
+//	magic | salt | nonce | sealed data key | chunk ...
+//
+// the data key is generated for each file and sealed by the key derived from the passphrase,
+// the content is sealed by the data key in chunks, the last chunk is authenticated as the last one,
+// so a truncated file is detected.
+var encryptionMagic = []byte("KCBAK1")
+
+const (
+	saltSize    = 16
+	keySize     = 32
+	chunkSize   = 64 * 1024
+	sealedSize  = chunkSize + 16
+	headerSize  = 6 + saltSize + 12 + keySize + 16
+	scryptN     = 1 << 15
+	scryptR     = 8
+	scryptParam = 1
+)
+
+// Codec compresses and encrypts the backup files before they are saved in the backup store.
+type Codec struct {
+	// gzip or zstd, the file is not compressed when it is empty
+	Compression string `json:"compression,omitempty"`
+	// aes-256-gcm, the file is not encrypted when it is empty
+	Encryption string `json:"encryption,omitempty"`
+	// the passphrase the encryption key is derived from, it is never serialized into the step
+	// payloads, the codec sent to the node references the key file instead.
+	Passphrase string `json:"-"`
+	// the file containing the passphrase on the node, it is used when the passphrase is empty
+	KeyFile string `json:"keyFile,omitempty"`
+}
+
+// Algorithm returns the algorithm of the codec, e.g. zstd+aes-256-gcm.
+func (c *Codec) Algorithm() string {
+	if c == nil {
+		return ""
+	}
+	var algs []string
+	if c.Compression != "" {
+		algs = append(algs, c.Compression)
+	}
+	if c.Encryption != "" {
+		algs = append(algs, c.Encryption)
+	}
+	return strings.Join(algs, "+")
+}
+
+// SetAlgorithm sets the compression and encryption of the codec by the algorithm.
+func (c *Codec) SetAlgorithm(algorithm string) error {
+	c.Compression, c.Encryption = "", ""
+	if algorithm == "" {
+		return nil
+	}
+	for _, alg := range strings.Split(algorithm, "+") {
+		switch alg {
+		case CompressionGzip, CompressionZstd:
+			c.Compression = alg
+		case EncryptionAESGCM:
+			c.Encryption = alg
+		default:
+			return fmt.Errorf("unsupported backup algorithm %s", alg)
+		}
+	}
+	return nil
+}
+
+func (c *Codec) Validate() error {
+	switch c.Compression {
+	case "", CompressionGzip, CompressionZstd:
+	default:
+		return fmt.Errorf("unsupported backup compression %s", c.Compression)
+	}
+	switch c.Encryption {
+	case "":
+	case EncryptionAESGCM:
+		if (c.Passphrase == "") == (c.KeyFile == "") {
+			return fmt.Errorf("either passphrase or key file must be specified for the backup encryption")
+		}
+	default:
+		return fmt.Errorf("unsupported backup encryption %s", c.Encryption)
+	}
+	return nil
+}
+
+// Encode returns the writer compressing and encrypting the content into w,
+// the writer must be closed to flush the content.
+func (c *Codec) Encode(w io.Writer) (io.WriteCloser, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	var closers []io.Closer
+	if c.Encryption != "" {
+		passphrase, err := c.passphrase()
+		if err != nil {
+			return nil, err
+		}
+		ew, err := newEncryptWriter(w, passphrase)
+		if err != nil {
+			return nil, err
+		}
+		w = ew
+		closers = append(closers, ew)
+	}
+	switch c.Compression {
+	case CompressionGzip:
+		gw := gzip.NewWriter(w)
+		w = gw
+		closers = append(closers, gw)
+	case CompressionZstd:
+		zw, err := zstd.NewWriter(w)
+		if err != nil {
+			return nil, err
+		}
+		w = zw
+		closers = append(closers, zw)
+	}
+	return &encodeWriter{Writer: w, closers: closers}, nil
+}
+
+// EncodeReader returns the reader of the compressed and encrypted content of r,
+// the content is encoded while it is read.
+func (c *Codec) EncodeReader(r io.Reader) (io.ReadCloser, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	pr, pw := io.Pipe()
+	// the header is written when the writer is created, so it is created after the pipe is read
+	go func() {
+		w, err := c.Encode(pw)
+		if err == nil {
+			if _, err = io.Copy(w, r); err == nil {
+				err = w.Close()
+			}
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr, nil
+}
+
+// Decode returns the reader decrypting and decompressing the content of r.
+func (c *Codec) Decode(r io.Reader) (io.ReadCloser, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	if c.Encryption != "" {
+		passphrase, err := c.passphrase()
+		if err != nil {
+			return nil, err
+		}
+		if r, err = newDecryptReader(r, passphrase); err != nil {
+			return nil, err
+		}
+	}
+	switch c.Compression {
+	case CompressionGzip:
+		return gzip.NewReader(r)
+	case CompressionZstd:
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return zr.IOReadCloser(), nil
+	}
+	return io.NopCloser(r), nil
+}
+
+func (c *Codec) passphrase() ([]byte, error) {
+	if c.Passphrase != "" {
+		return []byte(c.Passphrase), nil
+	}
+	data, err := os.ReadFile(c.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("read backup key file: %w", err)
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, fmt.Errorf("backup key file %s is empty", c.KeyFile)
+	}
+	return data, nil
+}
+
+// encodeWriter closes the writers from the outermost to the innermost one.
+type encodeWriter struct {
+	io.Writer
+	closers []io.Closer
+}
+
+func (w *encodeWriter) Close() error {
+	for i := len(w.closers) - 1; i >= 0; i-- {
+		if err := w.closers[i].Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func deriveKey(passphrase, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(passphrase, salt, scryptN, scryptR, scryptParam, keySize)
+	if err != nil {
+		return nil, err
+	}
+	return newGCM(key)
+}
+
+// chunkNonce returns the nonce of the chunk, it is unique because the data key is used for one file only.
+func chunkNonce(size int, counter uint64) []byte {
+	nonce := make([]byte, size)
+	binary.BigEndian.PutUint64(nonce[size-8:], counter)
+	return nonce
+}
+
+func chunkAdditionalData(last bool) []byte {
+	if last {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+type encryptWriter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	buf     []byte
+	counter uint64
+}
+
+func newEncryptWriter(w io.Writer, passphrase []byte) (*encryptWriter, error) {
+	header := make([]byte, 0, headerSize)
+	header = append(header, encryptionMagic...)
+	salt := make([]byte, saltSize)
+	dataKey := make([]byte, keySize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, err
+	}
+	kek, err := deriveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, kek.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	header = append(header, salt...)
+	header = append(header, nonce...)
+	header = kek.Seal(header, nonce, dataKey, encryptionMagic)
+	if _, err = w.Write(header); err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptWriter{w: w, aead: aead, buf: make([]byte, 0, chunkSize)}, nil
+}
+
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	n := 0
+	for len(p) > 0 {
+		// the full chunk is sealed when more content comes, so the last chunk is never empty unless the file is
+		if len(e.buf) == chunkSize {
+			if err := e.seal(false); err != nil {
+				return n, err
+			}
+		}
+		c := copy(e.buf[len(e.buf):chunkSize], p)
+		e.buf = e.buf[:len(e.buf)+c]
+		p = p[c:]
+		n += c
+	}
+	return n, nil
+}
+
+func (e *encryptWriter) Close() error {
+	return e.seal(true)
+}
+
+func (e *encryptWriter) seal(last bool) error {
+	sealed := e.aead.Seal(nil, chunkNonce(e.aead.NonceSize(), e.counter), e.buf, chunkAdditionalData(last))
+	e.counter++
+	e.buf = e.buf[:0]
+	_, err := e.w.Write(sealed)
+	return err
+}
+
+type decryptReader struct {
+	r       *bufio.Reader
+	aead    cipher.AEAD
+	buf     []byte
+	sealed  []byte
+	counter uint64
+	done    bool
+}
+
+func newDecryptReader(r io.Reader, passphrase []byte) (*decryptReader, error) {
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("read backup encryption header: %w", err)
+	}
+	if !bytes.Equal(header[:len(encryptionMagic)], encryptionMagic) {
+		return nil, errors.New("backup file is not encrypted")
+	}
+	header = header[len(encryptionMagic):]
+	kek, err := deriveKey(passphrase, header[:saltSize])
+	if err != nil {
+		return nil, err
+	}
+	header = header[saltSize:]
+	dataKey, err := kek.Open(nil, header[:kek.NonceSize()], header[kek.NonceSize():], encryptionMagic)
+	if err != nil {
+		return nil, errors.New("decrypt backup data key failed, the passphrase may be wrong")
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &decryptReader{r: bufio.NewReaderSize(r, sealedSize), aead: aead, sealed: make([]byte, sealedSize)}, nil
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.buf) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+		if err := d.open(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, d.buf)
+	d.buf = d.buf[n:]
+	return n, nil
+}
+
+func (d *decryptReader) open() error {
+	n, err := io.ReadFull(d.r, d.sealed)
+	switch {
+	case err == io.ErrUnexpectedEOF || err == io.EOF:
+		d.done = true
+	case err != nil:
+		return err
+	default:
+		if _, err = d.r.Peek(1); err == io.EOF {
+			d.done = true
+		}
+	}
+	plain, err := d.aead.Open(d.sealed[:0], chunkNonce(d.aead.NonceSize(), d.counter), d.sealed[:n], chunkAdditionalData(d.done))
+	if err != nil {
+		return errors.New("decrypt backup file failed, the file may be truncated or corrupted")
+	}
+	d.counter++
+	d.buf = plain
+	return nil
+}
@@ -0,0 +1,192 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package backupstore
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func encode(t *testing.T, c *Codec, data []byte) []byte {
+	buf := &bytes.Buffer{}
+	w, err := c.Encode(buf)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if _, err = w.Write(data); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func decode(c *Codec, data []byte) ([]byte, error) {
+	r, err := c.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+func TestCodec(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(keyFile, []byte("key file passphrase\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	random := make([]byte, 3*chunkSize+100)
+	if _, err := rand.Read(random); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		codec *Codec
+		data  []byte
+	}{
+		{
+			name:  "gzip",
+			codec: &Codec{Compression: CompressionGzip},
+			data:  bytes.Repeat([]byte("etcd"), chunkSize),
+		},
+		{
+			name:  "zstd",
+			codec: &Codec{Compression: CompressionZstd},
+			data:  bytes.Repeat([]byte("etcd"), chunkSize),
+		},
+		{
+			name:  "encryption of empty file",
+			codec: &Codec{Encryption: EncryptionAESGCM, Passphrase: "passphrase"},
+			data:  []byte{},
+		},
+		{
+			name:  "encryption of full chunks",
+			codec: &Codec{Encryption: EncryptionAESGCM, Passphrase: "passphrase"},
+			data:  random[:2*chunkSize],
+		},
+		{
+			name:  "encryption with key file",
+			codec: &Codec{Encryption: EncryptionAESGCM, KeyFile: keyFile},
+			data:  random,
+		},
+		{
+			name:  "compression and encryption",
+			codec: &Codec{Compression: CompressionZstd, Encryption: EncryptionAESGCM, Passphrase: "passphrase"},
+			data:  random,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded := encode(t, tt.codec, tt.data)
+			if tt.codec.Encryption != "" && len(tt.data) > 0 && bytes.Contains(encoded, tt.data[:64]) {
+				t.Errorf("encoded content contains the plaintext")
+			}
+			got, err := decode(tt.codec, encoded)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if !bytes.Equal(got, tt.data) {
+				t.Errorf("Decode() got %d bytes, want %d bytes", len(got), len(tt.data))
+			}
+		})
+	}
+}
+
+func TestCodec_DecodeError(t *testing.T) {
+	c := &Codec{Encryption: EncryptionAESGCM, Passphrase: "passphrase"}
+	data := bytes.Repeat([]byte("etcd"), chunkSize)
+	encoded := encode(t, c, data)
+
+	if _, err := decode(&Codec{Encryption: EncryptionAESGCM, Passphrase: "wrong"}, encoded); err == nil {
+		t.Errorf("Decode() with wrong passphrase, want error")
+	}
+	if _, err := decode(c, encoded[:headerSize+sealedSize]); err == nil {
+		t.Errorf("Decode() truncated file, want error")
+	}
+	corrupted := append([]byte{}, encoded...)
+	corrupted[len(corrupted)-1] ^= 1
+	if _, err := decode(c, corrupted); err == nil {
+		t.Errorf("Decode() corrupted file, want error")
+	}
+	if _, err := decode(c, data); err == nil {
+		t.Errorf("Decode() plaintext file, want error")
+	}
+}
+
+func TestCodec_SetAlgorithm(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		wantErr   bool
+	}{
+		{algorithm: ""},
+		{algorithm: "gzip"},
+		{algorithm: "aes-256-gcm"},
+		{algorithm: "zstd+aes-256-gcm"},
+		{algorithm: "lz4", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			c := &Codec{}
+			err := c.SetAlgorithm(tt.algorithm)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetAlgorithm() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && c.Algorithm() != tt.algorithm {
+				t.Errorf("Algorithm() = %v, want %v", c.Algorithm(), tt.algorithm)
+			}
+		})
+	}
+}
+
+func TestCodec_EncodeReader(t *testing.T) {
+	c := &Codec{Compression: CompressionGzip, Encryption: EncryptionAESGCM, Passphrase: "passphrase"}
+	data := bytes.Repeat([]byte("etcd"), 3*chunkSize)
+	r, err := c.EncodeReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("EncodeReader() error = %v", err)
+	}
+	defer r.Close()
+	checksum := NewChecksumReader(r)
+	encoded, err := io.ReadAll(checksum)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if checksum.Size() != int64(len(encoded)) {
+		t.Errorf("Size() = %d, want %d", checksum.Size(), len(encoded))
+	}
+	info, err := fileMD5(bytes.NewReader(encoded))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checksum.MD5() != info.MD5 {
+		t.Errorf("MD5() = %s, want %s", checksum.MD5(), info.MD5)
+	}
+	got, err := decode(c, encoded)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("Decode() got %d bytes, want %d bytes", len(got), len(data))
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 func init() {
@@ -95,3 +96,27 @@ func (fs *FilesystemStore) Download(ctx context.Context, fileName string, w io.W
 	}
 	return write.Flush()
 }
+
+func (fs *FilesystemStore) Stat(ctx context.Context, fileName string) (*FileInfo, error) {
+	f, err := os.Open(filepath.Join(fs.RootDir, fileName))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return fileMD5(f)
+}
+
+func (fs *FilesystemStore) List(ctx context.Context, prefix string) ([]string, error) {
+	entries, err := os.ReadDir(fs.RootDir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	// the entries are sorted by file name
+	for _, e := range entries {
+		if e.Type().IsRegular() && strings.HasPrefix(e.Name(), prefix) {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
@@ -22,6 +22,9 @@ import (
 	"bytes"
 	"context"
 	"io"
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -179,3 +182,83 @@ func TestFilesystemStore_Delete(t *testing.T) {
 		})
 	}
 }
+
+func TestFilesystemStore_Stat(t *testing.T) {
+	fs := &FilesystemStore{RootDir: t.TempDir()}
+	content := []byte("fs file test")
+	if err := fs.Save(context.TODO(), bytes.NewReader(content), fsFilename); err != nil {
+		t.Fatalf("fs client save failed: %v", err)
+	}
+	tests := []struct {
+		name     string
+		fileName string
+		want     *FileInfo
+		wantErr  bool
+	}{
+		{
+			name:     "base",
+			fileName: fsFilename,
+			want:     &FileInfo{Size: int64(len(content)), MD5: "7fadd9b200890e94a6f29e0ad983f664"},
+		},
+		{
+			name:     "not exist",
+			fileName: "not-exist.txt",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fs.Stat(context.TODO(), tt.fileName)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Stat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Stat() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilesystemStore_List(t *testing.T) {
+	fs := &FilesystemStore{RootDir: t.TempDir()}
+	for _, name := range []string{"c1-backup2", "c1-backup1", "c2-backup1"} {
+		if err := fs.Save(context.TODO(), bytes.NewReader([]byte(name)), name); err != nil {
+			t.Fatalf("fs client save failed: %v", err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(fs.RootDir, "c1-dir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		prefix string
+		want   []string
+	}{
+		{
+			name:   "all",
+			prefix: "",
+			want:   []string{"c1-backup1", "c1-backup2", "c2-backup1"},
+		},
+		{
+			name:   "prefix",
+			prefix: "c1-",
+			want:   []string{"c1-backup1", "c1-backup2"},
+		},
+		{
+			name:   "no match",
+			prefix: "c3-",
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fs.List(context.TODO(), tt.prefix)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("List() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package backupstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// MetadataSuffix is the suffix of the sidecar metadata file saved next to a backup file.
+const MetadataSuffix = ".meta.json"
+
+// Metadata describes a backup file, it is saved next to the backup file
+// so the backups in a backup point can be imported by scanning it.
+type Metadata struct {
+	// name of the backup object
+	Name              string `json:"name"`
+	ClusterName       string `json:"clusterName"`
+	KubernetesVersion string `json:"kubernetesVersion"`
+	FileName          string `json:"fileName"`
+	// size, md5 value and checksum of the saved backup file
+	Size      int64  `json:"size"`
+	MD5       string `json:"md5"`
+	Algorithm string `json:"algorithm,omitempty"`
+	Checksum  string `json:"checksum,omitempty"`
+	// ip and hostname of the cluster nodes
+	ClusterNodes map[string]string `json:"clusterNodes,omitempty"`
+	CreatedAt    time.Time         `json:"createdAt"`
+}
+
+// MetadataFileName returns the name of the metadata file of the backup file.
+func MetadataFileName(fileName string) string {
+	return fileName + MetadataSuffix
+}
+
+// IsMetadataFile reports whether the file is a metadata file.
+func IsMetadataFile(fileName string) bool {
+	return strings.HasSuffix(fileName, MetadataSuffix)
+}
+
+// SaveMetadata saves the metadata file of the backup file described by m.
+func SaveMetadata(ctx context.Context, store BackupStore, m *Metadata) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return store.Save(ctx, bytes.NewReader(data), MetadataFileName(m.FileName))
+}
+
+// ReadMetadata reads the metadata file.
+func ReadMetadata(ctx context.Context, store BackupStore, metadataFileName string) (*Metadata, error) {
+	buf := &bytes.Buffer{}
+	if err := store.Download(ctx, metadataFileName, buf); err != nil {
+		return nil, err
+	}
+	m := &Metadata{}
+	if err := json.Unmarshal(buf.Bytes(), m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
@@ -0,0 +1,60 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package backupstore
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMetadata(t *testing.T) {
+	fs := &FilesystemStore{RootDir: t.TempDir()}
+	m := &Metadata{
+		Name:              "backup-1",
+		ClusterName:       "c1",
+		KubernetesVersion: "v1.23.6",
+		FileName:          "c1-backup-1",
+		Size:              12,
+		MD5:               "7fadd9b200890e94a6f29e0ad983f664",
+		ClusterNodes:      map[string]string{"10.0.0.1": "master-1"},
+		CreatedAt:         time.Date(2022, 7, 1, 2, 0, 0, 0, time.UTC),
+	}
+	if err := SaveMetadata(context.TODO(), fs, m); err != nil {
+		t.Fatalf("SaveMetadata() error = %v", err)
+	}
+	names, err := fs.List(context.TODO(), "c1-")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(names) != 1 || !IsMetadataFile(names[0]) || names[0] != MetadataFileName(m.FileName) {
+		t.Fatalf("List() = %v, want [%s]", names, MetadataFileName(m.FileName))
+	}
+	got, err := ReadMetadata(context.TODO(), fs, names[0])
+	if err != nil {
+		t.Fatalf("ReadMetadata() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, m) {
+		t.Errorf("ReadMetadata() = %v, want %v", got, m)
+	}
+	if _, err = ReadMetadata(context.TODO(), &FilesystemStore{RootDir: t.TempDir()}, names[0]); err == nil {
+		t.Errorf("ReadMetadata() not exist file, want error")
+	}
+}
@@ -60,5 +60,5 @@ func (o *Options) Validate() error {
 }
 
 func (o *Options) AddFlags(fs *pflag.FlagSet) {
-	fs.StringVar(&o.Type, "backupstore-type", o.Type, "backup store type: fs/s3")
+	fs.StringVar(&o.Type, "backupstore-type", o.Type, "backup store type: fs/s3/sftp/webdav")
 }
@@ -23,6 +23,8 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/minio/minio-go/v7"
@@ -37,6 +39,9 @@ var _ BackupStore = (*ObjectStore)(nil)
 
 const (
 	defaultBucket = "kc-backups"
+	// the part is buffered in memory when the size of the file is unknown,
+	// so it is limited instead of the size computed from the maximum object size.
+	defaultPartSize = 64 << 20
 )
 
 type ObjectStore struct {
@@ -94,8 +99,10 @@ func (receiver *ObjectStore) Create() (BackupStore, error) {
 
 func (receiver *ObjectStore) Save(ctx context.Context, r io.Reader, fileName string) (err error) {
 	defer logProbe(ctx, fmt.Sprintf("save backup to %s/%s", receiver.Bucket, fileName), err)
-	// -1: stream size is unknown to us
-	_, err = receiver.Client.PutObject(ctx, receiver.Bucket, fileName, r, -1, minio.PutObjectOptions{})
+	// -1: stream size is unknown to us, the file is uploaded in parts
+	_, err = receiver.Client.PutObject(ctx, receiver.Bucket, fileName, r, -1, minio.PutObjectOptions{
+		PartSize: defaultPartSize,
+	})
 	return err
 }
 
@@ -118,3 +125,37 @@ func (receiver *ObjectStore) Download(ctx context.Context, fileName string, w io
 	_, err = bufio.NewReader(obj).WriteTo(w)
 	return err
 }
+
+func (receiver *ObjectStore) Stat(ctx context.Context, fileName string) (*FileInfo, error) {
+	info, err := receiver.Client.StatObject(ctx, receiver.Bucket, fileName, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	// the etag of a single part object is its md5 value,
+	// while the one of a multipart object is not, the object is read to compute the md5 value.
+	if len(info.ETag) == 32 && !strings.Contains(info.ETag, "-") {
+		return &FileInfo{Size: info.Size, MD5: strings.ToLower(info.ETag)}, nil
+	}
+	obj, err := receiver.Client.GetObject(ctx, receiver.Bucket, fileName, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+	return fileMD5(obj)
+}
+
+func (receiver *ObjectStore) List(ctx context.Context, prefix string) ([]string, error) {
+	var names []string
+	for obj := range receiver.Client.ListObjects(ctx, receiver.Bucket, minio.ListObjectsOptions{Prefix: prefix}) {
+		if obj.Err != nil {
+			return nil, obj.Err
+		}
+		// objects under a sub directory are not listed without Recursive, only their common prefix ends with '/'
+		if strings.HasSuffix(obj.Key, "/") {
+			continue
+		}
+		names = append(names, obj.Key)
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,161 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package backupstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/kubeclipper/kubeclipper/pkg/utils/sshutils"
+)
+
+func init() {
+	RegisterProvider(&SftpStore{})
+}
+
+var _ BackupStore = (*SftpStore)(nil)
+
+type SftpStore struct {
+	Host       string `json:"host" yaml:"host"` // host or host:port of the sftp server
+	User       string `json:"user" yaml:"user"`
+	Password   string `json:"password,omitempty" yaml:"password,omitempty"`
+	PrivateKey string `json:"privateKey,omitempty" yaml:"privateKey,omitempty"` // content of the private key
+	RootDir    string `json:"rootDir,omitempty" yaml:"rootDir,omitempty"`       // directory for storing backup files on the server
+}
+
+func (s *SftpStore) Type() string {
+	return SFTPStorage
+}
+
+func (s *SftpStore) Create() (BackupStore, error) {
+	if s.Host == "" {
+		return nil, errors.New("sftp host must be specified")
+	}
+	if s.RootDir == "" {
+		s.RootDir = defaultRootDir
+	}
+	c, err := s.client()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	// the root directory is created when it does not exist
+	if err = c.MkdirAll(s.RootDir); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *SftpStore) client() (*sshutils.SftpClient, error) {
+	ss := &sshutils.SSH{
+		User:       s.User,
+		Password:   s.Password,
+		PrivateKey: s.PrivateKey,
+	}
+	return ss.NewSftpClient(s.Host)
+}
+
+func (s *SftpStore) Save(ctx context.Context, r io.Reader, fileName string) (err error) {
+	defer logProbe(ctx, fmt.Sprintf("save backup to sftp://%s%s", s.Host, path.Join(s.RootDir, fileName)), err)
+	c, err := s.client()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	f, err := c.Create(path.Join(s.RootDir, fileName))
+	if err != nil {
+		return err
+	}
+	if _, err = f.ReadFrom(r); err != nil {
+		_ = f.Close()
+		return err
+	}
+	// the pending writes fail on close, the backup is incomplete then
+	return f.Close()
+}
+
+func (s *SftpStore) Delete(ctx context.Context, fileName string) (err error) {
+	defer logProbe(ctx, fmt.Sprintf("delete backup from sftp://%s%s", s.Host, path.Join(s.RootDir, fileName)), err)
+	c, err := s.client()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if _, err = c.Stat(path.Join(s.RootDir, fileName)); errors.Is(err, os.ErrNotExist) {
+		// The target file is already deleted.
+		return nil
+	}
+	return c.Remove(path.Join(s.RootDir, fileName))
+}
+
+func (s *SftpStore) Download(ctx context.Context, fileName string, w io.Writer) (err error) {
+	defer logProbe(ctx, fmt.Sprintf("download backup from sftp://%s%s", s.Host, path.Join(s.RootDir, fileName)), err)
+	c, err := s.client()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	f, err := c.Open(path.Join(s.RootDir, fileName))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteTo(w)
+	return err
+}
+
+func (s *SftpStore) Stat(ctx context.Context, fileName string) (*FileInfo, error) {
+	c, err := s.client()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	f, err := c.Open(path.Join(s.RootDir, fileName))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return fileMD5(f)
+}
+
+func (s *SftpStore) List(ctx context.Context, prefix string) ([]string, error) {
+	c, err := s.client()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	entries, err := c.ReadDir(s.RootDir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if e.Mode().IsRegular() && strings.HasPrefix(e.Name(), prefix) {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,161 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package backupstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	sftpUser     = "backup"
+	sftpPassword = "backup-password"
+)
+
+// startSftpServer starts an in-process sftp server, which serves the local filesystem.
+func startSftpServer(t *testing.T) string {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if c.User() == sftpUser && string(pass) == sftpPassword {
+				return nil, nil
+			}
+			return nil, errors.New("password rejected")
+		},
+	}
+	config.AddHostKey(signer)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serveSftp(conn, config)
+		}
+	}()
+	return l.Addr().String()
+}
+
+func serveSftp(conn net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		go func(in <-chan *ssh.Request) {
+			for req := range in {
+				_ = req.Reply(req.Type == "subsystem" && string(req.Payload[4:]) == "sftp", nil)
+			}
+		}(requests)
+		server, err := sftp.NewServer(channel)
+		if err != nil {
+			return
+		}
+		go func() {
+			_ = server.Serve()
+			_ = server.Close()
+		}()
+	}
+}
+
+func TestSftpStore(t *testing.T) {
+	host := startSftpServer(t)
+	rootDir := filepath.Join(t.TempDir(), "backups")
+	content := []byte("sftp file test")
+	ctx := context.TODO()
+
+	if _, err := (&SftpStore{Host: host, User: sftpUser, Password: "wrong", RootDir: rootDir}).Create(); err == nil {
+		t.Errorf("Create() with wrong password, want error")
+	}
+	store, err := (&SftpStore{Host: host, User: sftpUser, Password: sftpPassword, RootDir: rootDir}).Create()
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err = store.Save(ctx, bytes.NewReader(content), "snapshot"); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(rootDir, "snapshot")); err != nil || !bytes.Equal(data, content) {
+		t.Errorf("saved file = %q, %v, want %q", data, err, content)
+	}
+	w := &bytes.Buffer{}
+	if err = store.Download(ctx, "snapshot", w); err != nil || !bytes.Equal(w.Bytes(), content) {
+		t.Errorf("Download() = %q, %v, want %q", w.Bytes(), err, content)
+	}
+	info, err := store.Stat(ctx, "snapshot")
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if want, _ := fileMD5(bytes.NewReader(content)); *info != *want {
+		t.Errorf("Stat() = %v, want %v", info, want)
+	}
+	if err = store.Save(ctx, bytes.NewReader(content), "other"); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err = os.Mkdir(filepath.Join(rootDir, "snapshot-dir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if names, err := store.List(ctx, "snap"); err != nil || !reflect.DeepEqual(names, []string{"snapshot"}) {
+		t.Errorf("List() = %v, %v, want [snapshot]", names, err)
+	}
+	if names, err := store.List(ctx, ""); err != nil || !reflect.DeepEqual(names, []string{"other", "snapshot"}) {
+		t.Errorf("List() = %v, %v, want [other snapshot]", names, err)
+	}
+	if err = store.Delete(ctx, "snapshot"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err = os.Stat(filepath.Join(rootDir, "snapshot")); !os.IsNotExist(err) {
+		t.Errorf("file is not deleted: %v", err)
+	}
+	if err = store.Delete(ctx, "snapshot"); err != nil {
+		t.Errorf("Delete() deleted file error = %v", err)
+	}
+}
@@ -32,8 +32,10 @@ import (
 )
 
 const (
-	FSStorage = "fs"
-	S3Storage = "s3"
+	FSStorage     = "fs"
+	S3Storage     = "s3"
+	SFTPStorage   = "sftp"
+	WebDAVStorage = "webdav"
 )
 
 var providerFactories = make(map[string]ProviderFactory)
@@ -51,6 +53,16 @@ type BackupStore interface {
 	Save(ctx context.Context, r io.Reader, fileName string) error
 	Delete(ctx context.Context, fileName string) error
 	Download(ctx context.Context, fileName string, w io.Writer) error
+	Stat(ctx context.Context, fileName string) (*FileInfo, error)
+	// List returns the names of the files starting with the prefix in lexical order.
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// FileInfo describes a file saved in the backup store.
+type FileInfo struct {
+	Size int64
+	// hex encoded md5 value of the file content
+	MD5 string
 }
 
 func GetProviderFactoryType() []string {
@@ -0,0 +1,211 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package backupstore
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+)
+
+func init() {
+	RegisterProvider(&WebDAVStore{})
+}
+
+var _ BackupStore = (*WebDAVStore)(nil)
+
+type WebDAVStore struct {
+	Endpoint string       `json:"endpoint" yaml:"endpoint"` // URL of the directory for storing backup files
+	User     string       `json:"user,omitempty" yaml:"user,omitempty"`
+	Password string       `json:"password,omitempty" yaml:"password,omitempty"`
+	Client   *http.Client `json:"-" yaml:"-"`
+}
+
+func (s *WebDAVStore) Type() string {
+	return WebDAVStorage
+}
+
+func (s *WebDAVStore) Create() (BackupStore, error) {
+	if s.Endpoint == "" {
+		return nil, errors.New("webdav endpoint must be specified")
+	}
+	if _, err := url.ParseRequestURI(s.Endpoint); err != nil {
+		return nil, err
+	}
+	if s.Client == nil {
+		s.Client = http.DefaultClient
+	}
+	// the directory is created when it does not exist, 405 is returned when it exists
+	resp, err := s.do(context.Background(), "MKCOL", strings.TrimSuffix(s.Endpoint, "/")+"/", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusMethodNotAllowed {
+		return nil, webDAVError("create directory", resp)
+	}
+	return s, nil
+}
+
+func (s *WebDAVStore) fileURL(fileName string) string {
+	return strings.TrimSuffix(s.Endpoint, "/") + "/" + url.PathEscape(fileName)
+}
+
+func (s *WebDAVStore) do(ctx context.Context, method, target string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	if s.User != "" {
+		req.SetBasicAuth(s.User, s.Password)
+	}
+	return s.Client.Do(req)
+}
+
+func webDAVError(action string, resp *http.Response) error {
+	return fmt.Errorf("webdav %s failed: %s", action, resp.Status)
+}
+
+func (s *WebDAVStore) Save(ctx context.Context, r io.Reader, fileName string) (err error) {
+	defer logProbe(ctx, fmt.Sprintf("save backup to %s", s.fileURL(fileName)), err)
+	resp, err := s.do(ctx, http.MethodPut, s.fileURL(fileName), r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		return nil
+	}
+	return webDAVError("save", resp)
+}
+
+func (s *WebDAVStore) Delete(ctx context.Context, fileName string) (err error) {
+	defer logProbe(ctx, fmt.Sprintf("delete backup from %s", s.fileURL(fileName)), err)
+	resp, err := s.do(ctx, http.MethodDelete, s.fileURL(fileName), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	// The target file is already deleted when it is not found.
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	}
+	return webDAVError("delete", resp)
+}
+
+func (s *WebDAVStore) Download(ctx context.Context, fileName string, w io.Writer) (err error) {
+	defer logProbe(ctx, fmt.Sprintf("download backup from %s", s.fileURL(fileName)), err)
+	body, err := s.get(ctx, fileName)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	_, err = io.Copy(w, body)
+	return err
+}
+
+func (s *WebDAVStore) Stat(ctx context.Context, fileName string) (*FileInfo, error) {
+	body, err := s.get(ctx, fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return fileMD5(body)
+}
+
+func (s *WebDAVStore) get(ctx context.Context, fileName string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, s.fileURL(fileName), nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, webDAVError("download", resp)
+	}
+	return resp.Body, nil
+}
+
+const propfindResourceType = `<?xml version="1.0" encoding="utf-8"?><D:propfind xmlns:D="DAV:"><D:prop><D:resourcetype/></D:prop></D:propfind>`
+
+// multistatus is the part of the PROPFIND response used for listing files.
+type multistatus struct {
+	Responses []struct {
+		Href     string `xml:"DAV: href"`
+		Propstat []struct {
+			ResourceType struct {
+				Collection *struct{} `xml:"DAV: collection"`
+			} `xml:"DAV: prop>resourcetype"`
+		} `xml:"DAV: propstat"`
+	} `xml:"DAV: response"`
+}
+
+func (s *WebDAVStore) List(ctx context.Context, prefix string) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, "PROPFIND", strings.TrimSuffix(s.Endpoint, "/")+"/", strings.NewReader(propfindResourceType))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Depth", "1")
+	req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+	if s.User != "" {
+		req.SetBasicAuth(s.User, s.Password)
+	}
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, webDAVError("list", resp)
+	}
+	ms := multistatus{}
+	if err = xml.NewDecoder(resp.Body).Decode(&ms); err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, r := range ms.Responses {
+		collection := false
+		for _, p := range r.Propstat {
+			if p.ResourceType.Collection != nil {
+				collection = true
+			}
+		}
+		if collection {
+			continue
+		}
+		// the href is an escaped absolute URL or absolute path
+		u, err := url.Parse(r.Href)
+		if err != nil {
+			return nil, err
+		}
+		if name := path.Base(u.Path); strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,100 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package backupstore
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"golang.org/x/net/webdav"
+)
+
+func TestWebDAVStore(t *testing.T) {
+	dir := t.TempDir()
+	handler := &webdav.Handler{
+		FileSystem: webdav.Dir(dir),
+		LockSystem: webdav.NewMemLS(),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "backup" || pass != "backup-password" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	content := []byte("webdav file test")
+	ctx := context.TODO()
+
+	if _, err := (&WebDAVStore{Endpoint: server.URL + "/backups", User: "backup"}).Create(); err == nil {
+		t.Errorf("Create() without password, want error")
+	}
+	store, err := (&WebDAVStore{Endpoint: server.URL + "/backups", User: "backup", Password: "backup-password"}).Create()
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	// the existing directory is used
+	if _, err = (&WebDAVStore{Endpoint: server.URL + "/backups/", User: "backup", Password: "backup-password"}).Create(); err != nil {
+		t.Fatalf("Create() existing directory error = %v", err)
+	}
+	if err = store.Save(ctx, bytes.NewReader(content), "snapshot"); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "backups", "snapshot")); err != nil || !bytes.Equal(data, content) {
+		t.Errorf("saved file = %q, %v, want %q", data, err, content)
+	}
+	w := &bytes.Buffer{}
+	if err = store.Download(ctx, "snapshot", w); err != nil || !bytes.Equal(w.Bytes(), content) {
+		t.Errorf("Download() = %q, %v, want %q", w.Bytes(), err, content)
+	}
+	info, err := store.Stat(ctx, "snapshot")
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if want, _ := fileMD5(bytes.NewReader(content)); *info != *want {
+		t.Errorf("Stat() = %v, want %v", info, want)
+	}
+	if err = store.Save(ctx, bytes.NewReader(content), "other snapshot"); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err = os.Mkdir(filepath.Join(dir, "backups", "snapshot-dir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if names, err := store.List(ctx, "snap"); err != nil || !reflect.DeepEqual(names, []string{"snapshot"}) {
+		t.Errorf("List() = %v, %v, want [snapshot]", names, err)
+	}
+	if names, err := store.List(ctx, ""); err != nil || !reflect.DeepEqual(names, []string{"other snapshot", "snapshot"}) {
+		t.Errorf("List() = %v, %v, want [other snapshot snapshot]", names, err)
+	}
+	if err = store.Delete(ctx, "snapshot"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err = store.Delete(ctx, "snapshot"); err != nil {
+		t.Errorf("Delete() deleted file error = %v", err)
+	}
+	if _, err = store.Stat(ctx, "snapshot"); err == nil {
+		t.Errorf("Stat() deleted file, want error")
+	}
+}
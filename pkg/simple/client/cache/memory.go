@@ -56,7 +56,7 @@ func (m *memoryKV) Update(key, value string) error {
 		return err
 	}
 	e.value = value
-	m.storage.Store(key, e)
+	m.storage.Store(key, *e)
 	return nil
 }
 
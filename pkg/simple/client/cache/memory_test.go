@@ -29,3 +29,14 @@ func TestMemoryKVStorage(t *testing.T) {
 	require.NoError(t, err)
 	CacheCommonTest(t, kv)
 }
+
+func TestMemoryKVUpdate(t *testing.T) {
+	kv, err := NewMemory()
+	require.NoError(t, err)
+	require.True(t, IsNotExists(kv.Update("foo", "bar")))
+	require.NoError(t, kv.Set("foo", "bar", NoExpiration))
+	require.NoError(t, kv.Update("foo", "baz"))
+	value, err := kv.Get("foo")
+	require.NoError(t, err)
+	require.Equal(t, "baz", value)
+}
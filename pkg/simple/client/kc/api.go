@@ -22,6 +22,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/kubeclipper/kubeclipper/pkg/query"
 
 	iamv1 "github.com/kubeclipper/kubeclipper/pkg/scheme/iam/v1"
 
@@ -33,11 +37,20 @@ import (
 const (
 	listNodesPath     = "/api/core.kubeclipper.io/v1/nodes"
 	clustersPath      = "/api/core.kubeclipper.io/v1/clusters"
+	operationsPath    = "/api/core.kubeclipper.io/v1/operations"
+	logsPath          = "/api/core.kubeclipper.io/v1/logs"
 	usersPath         = "/api/iam.kubeclipper.io/v1/users"
 	rolesPath         = "/api/iam.kubeclipper.io/v1/roles"
 	platformPath      = "/api/config.kubeclipper.io/v1/template"
 	versionPath       = "/version"
 	componentMetaPath = "/api/config.kubeclipper.io/v1/componentmeta"
+	backupPointsPath  = "/api/core.kubeclipper.io/v1/backuppoints"
+	backupsPath       = "/api/core.kubeclipper.io/v1/backups"
+	cronBackupsPath   = "/api/core.kubeclipper.io/v1/cronbackups"
+	regionsPath       = "/api/core.kubeclipper.io/v1/regions"
+	domainsPath       = "/api/core.kubeclipper.io/v1/domains"
+	templatesPath     = "/api/core.kubeclipper.io/v1/templates"
+	tokensPath        = "/api/iam.kubeclipper.io/v1/tokens"
 )
 
 func (cli *Client) ListNodes(ctx context.Context, query Queries) (*NodesList, error) {
@@ -226,6 +239,54 @@ func (cli *Client) DeleteCluster(ctx context.Context, name string) error {
 	return nil
 }
 
+func (cli *Client) ListBackupPoints(ctx context.Context, query Queries) (*BackupPointList, error) {
+	serverResp, err := cli.get(ctx, backupPointsPath, query.ToRawQuery(), nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	bps := BackupPointList{}
+	err = json.NewDecoder(serverResp.body).Decode(&bps)
+	return &bps, err
+}
+
+func (cli *Client) DescribeBackupPoint(ctx context.Context, name string) (*BackupPointList, error) {
+	serverResp, err := cli.get(ctx, fmt.Sprintf("%s/%s", backupPointsPath, name), nil, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	bp := v1.BackupPoint{}
+	err = json.NewDecoder(serverResp.body).Decode(&bp)
+	bps := BackupPointList{
+		Items: []v1.BackupPoint{bp},
+	}
+	return &bps, err
+}
+
+func (cli *Client) CreateBackupPoint(ctx context.Context, bp *v1.BackupPoint) (*BackupPointList, error) {
+	serverResp, err := cli.post(ctx, backupPointsPath, nil, bp, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	v := v1.BackupPoint{}
+	err = json.NewDecoder(serverResp.body).Decode(&v)
+	bps := BackupPointList{
+		Items: []v1.BackupPoint{v},
+	}
+	return &bps, err
+}
+
+func (cli *Client) DeleteBackupPoint(ctx context.Context, name string) error {
+	serverResp, err := cli.delete(ctx, fmt.Sprintf("%s/%s", backupPointsPath, name), nil, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 func (cli *Client) GetPlatformSetting(ctx context.Context) (*v1.DockerRegistry, error) {
 	serverResp, err := cli.get(ctx, platformPath, nil, nil)
 	defer ensureReaderClosed(serverResp)
@@ -247,3 +308,504 @@ func (cli *Client) GetComponentMeta(ctx context.Context) (*ComponentMeta, error)
 	err = json.NewDecoder(serverResp.body).Decode(&v)
 	return &v, err
 }
+
+func (cli *Client) DescribeOperation(ctx context.Context, name string) (*OperationsList, error) {
+	serverResp, err := cli.get(ctx, fmt.Sprintf("%s/%s", operationsPath, name), nil, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	v := v1.Operation{}
+	err = json.NewDecoder(serverResp.body).Decode(&v)
+	operations := OperationsList{
+		Items: []v1.Operation{v},
+	}
+	return &operations, err
+}
+
+// GetStepLog returns the step log of the operation on the node from offset.
+func (cli *Client) GetStepLog(ctx context.Context, operation, step, node string, offset int64) (*StepLog, error) {
+	q := url.Values{}
+	q.Set(query.ParameterOperation, operation)
+	q.Set(query.ParameterStep, step)
+	q.Set(query.ParameterNode, node)
+	q.Set(query.ParameterOffset, strconv.FormatInt(offset, 10))
+	serverResp, err := cli.get(ctx, logsPath, q, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	v := StepLog{}
+	err = json.NewDecoder(serverResp.body).Decode(&v)
+	return &v, err
+}
+
+// StreamOperationLog opens the stream of the step logs appended on all nodes, all steps are streamed
+// if step is empty. The stream ends when the operation is done, and it must be closed by the caller.
+func (cli *Client) StreamOperationLog(ctx context.Context, operation, step string) (*LogStream, error) {
+	q := url.Values{}
+	if step != "" {
+		q.Set(query.ParameterStep, step)
+	}
+	serverResp, err := cli.get(ctx, fmt.Sprintf("%s/%s/logs", operationsPath, operation), q, nil)
+	if err != nil {
+		ensureReaderClosed(serverResp)
+		return nil, err
+	}
+	return &LogStream{body: serverResp.body, decoder: json.NewDecoder(serverResp.body)}, nil
+}
+
+func (cli *Client) CancelOperation(ctx context.Context, name string) (*OperationsList, error) {
+	// the route consumes json, so the content type is set for the empty body.
+	headers := map[string][]string{"Content-Type": {"application/json"}}
+	serverResp, err := cli.post(ctx, fmt.Sprintf("%s/%s/cancel", operationsPath, name), nil, nil, headers)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	v := v1.Operation{}
+	err = json.NewDecoder(serverResp.body).Decode(&v)
+	operations := OperationsList{
+		Items: []v1.Operation{v},
+	}
+	return &operations, err
+}
+
+func (cli *Client) ListOperations(ctx context.Context, query Queries) (*OperationsList, error) {
+	serverResp, err := cli.get(ctx, operationsPath, query.ToRawQuery(), nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	operations := OperationsList{}
+	err = json.NewDecoder(serverResp.body).Decode(&operations)
+	return &operations, err
+}
+
+// RetryOperation retries the failed operation, the retry is resumed from the failed step if fromStep is empty.
+func (cli *Client) RetryOperation(ctx context.Context, name, fromStep string) error {
+	q := url.Values{}
+	if fromStep != "" {
+		q.Set(query.ParameterFromStep, fromStep)
+	}
+	headers := map[string][]string{"Content-Type": {"application/json"}}
+	serverResp, err := cli.post(ctx, fmt.Sprintf("%s/%s/retry", operationsPath, name), q, nil, headers)
+	defer ensureReaderClosed(serverResp)
+	return err
+}
+
+func (cli *Client) PatchClusterNodes(ctx context.Context, name string, patch *PatchNodes) (*ClustersList, error) {
+	serverResp, err := cli.put(ctx, fmt.Sprintf("%s/%s/nodes", clustersPath, name), nil, patch, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	v := v1.Cluster{}
+	err = json.NewDecoder(serverResp.body).Decode(&v)
+	clusters := ClustersList{
+		Items: []v1.Cluster{v},
+	}
+	return &clusters, err
+}
+
+func (cli *Client) UpgradeCluster(ctx context.Context, name string, upgrade *ClusterUpgrade) error {
+	serverResp, err := cli.post(ctx, fmt.Sprintf("%s/%s/upgrade", clustersPath, name), nil, upgrade, nil)
+	defer ensureReaderClosed(serverResp)
+	return err
+}
+
+func (cli *Client) PatchClusterComponents(ctx context.Context, name string, patch *PatchComponents) (*ClustersList, error) {
+	serverResp, err := cli.patch(ctx, fmt.Sprintf("%s/%s/plugins", clustersPath, name), nil, patch, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	v := v1.Cluster{}
+	err = json.NewDecoder(serverResp.body).Decode(&v)
+	clusters := ClustersList{
+		Items: []v1.Cluster{v},
+	}
+	return &clusters, err
+}
+
+// PlanCluster returns the changes which bring the live cluster to the given one, nothing is applied.
+func (cli *Client) PlanCluster(ctx context.Context, cluster *v1.Cluster) (*ClusterPlan, error) {
+	serverResp, err := cli.post(ctx, fmt.Sprintf("%s/%s/plan", clustersPath, cluster.Name), nil, cluster, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	plan := ClusterPlan{}
+	err = json.NewDecoder(serverResp.body).Decode(&plan)
+	return &plan, err
+}
+
+func (cli *Client) UpdateClusterCertification(ctx context.Context, name string) (*ClustersList, error) {
+	headers := map[string][]string{"Content-Type": {"application/json"}}
+	serverResp, err := cli.post(ctx, fmt.Sprintf("%s/%s/certification", clustersPath, name), nil, nil, headers)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	v := v1.Cluster{}
+	err = json.NewDecoder(serverResp.body).Decode(&v)
+	clusters := ClustersList{
+		Items: []v1.Cluster{v},
+	}
+	return &clusters, err
+}
+
+func (cli *Client) ListBackups(ctx context.Context, query Queries) (*BackupList, error) {
+	serverResp, err := cli.get(ctx, backupsPath, query.ToRawQuery(), nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	backups := BackupList{}
+	err = json.NewDecoder(serverResp.body).Decode(&backups)
+	return &backups, err
+}
+
+func (cli *Client) DescribeBackup(ctx context.Context, name string) (*BackupList, error) {
+	serverResp, err := cli.get(ctx, fmt.Sprintf("%s/%s", backupsPath, name), nil, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	b := v1.Backup{}
+	err = json.NewDecoder(serverResp.body).Decode(&b)
+	backups := BackupList{
+		Items: []v1.Backup{b},
+	}
+	return &backups, err
+}
+
+func (cli *Client) CreateBackup(ctx context.Context, cluster string, backup *v1.Backup) (*BackupList, error) {
+	serverResp, err := cli.post(ctx, fmt.Sprintf("%s/%s/backups", clustersPath, cluster), nil, backup, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	v := v1.Backup{}
+	err = json.NewDecoder(serverResp.body).Decode(&v)
+	backups := BackupList{
+		Items: []v1.Backup{v},
+	}
+	return &backups, err
+}
+
+func (cli *Client) DeleteBackup(ctx context.Context, cluster, name string) error {
+	serverResp, err := cli.delete(ctx, fmt.Sprintf("%s/%s/backups/%s", clustersPath, cluster, name), nil, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cli *Client) VerifyBackup(ctx context.Context, cluster, name string) (*BackupList, error) {
+	headers := map[string][]string{"Content-Type": {"application/json"}}
+	serverResp, err := cli.post(ctx, fmt.Sprintf("%s/%s/backups/%s/verify", clustersPath, cluster, name), nil, nil, headers)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	v := v1.Backup{}
+	err = json.NewDecoder(serverResp.body).Decode(&v)
+	backups := BackupList{
+		Items: []v1.Backup{v},
+	}
+	return &backups, err
+}
+
+// ScanBackupPoint imports the backups found in the storage of the backup point.
+func (cli *Client) ScanBackupPoint(ctx context.Context, name string) (*BackupList, error) {
+	headers := map[string][]string{"Content-Type": {"application/json"}}
+	serverResp, err := cli.post(ctx, fmt.Sprintf("%s/%s/scan", backupPointsPath, name), nil, nil, headers)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	backups := BackupList{}
+	err = json.NewDecoder(serverResp.body).Decode(&backups)
+	return &backups, err
+}
+
+func (cli *Client) CreateRecovery(ctx context.Context, cluster string, recovery *v1.Recovery) (*RecoveryList, error) {
+	serverResp, err := cli.post(ctx, fmt.Sprintf("%s/%s/recovery", clustersPath, cluster), nil, recovery, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	v := v1.Recovery{}
+	err = json.NewDecoder(serverResp.body).Decode(&v)
+	recoveries := RecoveryList{
+		Items: []v1.Recovery{v},
+	}
+	return &recoveries, err
+}
+
+func (cli *Client) ListCronBackups(ctx context.Context, query Queries) (*CronBackupList, error) {
+	serverResp, err := cli.get(ctx, cronBackupsPath, query.ToRawQuery(), nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	cbs := CronBackupList{}
+	err = json.NewDecoder(serverResp.body).Decode(&cbs)
+	return &cbs, err
+}
+
+func (cli *Client) DescribeCronBackup(ctx context.Context, name string) (*CronBackupList, error) {
+	serverResp, err := cli.get(ctx, fmt.Sprintf("%s/%s", cronBackupsPath, name), nil, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	cb := v1.CronBackup{}
+	err = json.NewDecoder(serverResp.body).Decode(&cb)
+	cbs := CronBackupList{
+		Items: []v1.CronBackup{cb},
+	}
+	return &cbs, err
+}
+
+func (cli *Client) CreateCronBackup(ctx context.Context, cb *v1.CronBackup) (*CronBackupList, error) {
+	serverResp, err := cli.post(ctx, cronBackupsPath, nil, cb, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	v := v1.CronBackup{}
+	err = json.NewDecoder(serverResp.body).Decode(&v)
+	cbs := CronBackupList{
+		Items: []v1.CronBackup{v},
+	}
+	return &cbs, err
+}
+
+func (cli *Client) DeleteCronBackup(ctx context.Context, name string) error {
+	serverResp, err := cli.delete(ctx, fmt.Sprintf("%s/%s", cronBackupsPath, name), nil, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cli *Client) EnableCronBackup(ctx context.Context, name string) (*CronBackupList, error) {
+	return cli.patchCronBackup(ctx, name, "enable")
+}
+
+func (cli *Client) DisableCronBackup(ctx context.Context, name string) (*CronBackupList, error) {
+	return cli.patchCronBackup(ctx, name, "disable")
+}
+
+func (cli *Client) patchCronBackup(ctx context.Context, name, action string) (*CronBackupList, error) {
+	headers := map[string][]string{"Content-Type": {"application/json"}}
+	serverResp, err := cli.patch(ctx, fmt.Sprintf("%s/%s/%s", cronBackupsPath, name, action), nil, nil, headers)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	v := v1.CronBackup{}
+	err = json.NewDecoder(serverResp.body).Decode(&v)
+	cbs := CronBackupList{
+		Items: []v1.CronBackup{v},
+	}
+	return &cbs, err
+}
+
+func (cli *Client) ListRegions(ctx context.Context, query Queries) (*RegionList, error) {
+	serverResp, err := cli.get(ctx, regionsPath, query.ToRawQuery(), nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	regions := RegionList{}
+	err = json.NewDecoder(serverResp.body).Decode(&regions)
+	return &regions, err
+}
+
+func (cli *Client) DescribeRegion(ctx context.Context, name string) (*RegionList, error) {
+	serverResp, err := cli.get(ctx, fmt.Sprintf("%s/%s", regionsPath, name), nil, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	region := v1.Region{}
+	err = json.NewDecoder(serverResp.body).Decode(&region)
+	regions := RegionList{
+		Items: []v1.Region{region},
+	}
+	return &regions, err
+}
+
+func (cli *Client) ListDomains(ctx context.Context, query Queries) (*DomainList, error) {
+	serverResp, err := cli.get(ctx, domainsPath, query.ToRawQuery(), nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	domains := DomainList{}
+	err = json.NewDecoder(serverResp.body).Decode(&domains)
+	return &domains, err
+}
+
+func (cli *Client) DescribeDomain(ctx context.Context, name string) (*DomainList, error) {
+	serverResp, err := cli.get(ctx, fmt.Sprintf("%s/%s", domainsPath, name), nil, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	domain := v1.Domain{}
+	err = json.NewDecoder(serverResp.body).Decode(&domain)
+	domains := DomainList{
+		Items: []v1.Domain{domain},
+	}
+	return &domains, err
+}
+
+func (cli *Client) CreateDomain(ctx context.Context, domain *v1.Domain) (*DomainList, error) {
+	serverResp, err := cli.post(ctx, domainsPath, nil, domain, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	v := v1.Domain{}
+	err = json.NewDecoder(serverResp.body).Decode(&v)
+	domains := DomainList{
+		Items: []v1.Domain{v},
+	}
+	return &domains, err
+}
+
+func (cli *Client) DeleteDomain(ctx context.Context, name string) error {
+	serverResp, err := cli.delete(ctx, fmt.Sprintf("%s/%s", domainsPath, name), nil, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cli *Client) ListRecords(ctx context.Context, domain string, query Queries) (*RecordList, error) {
+	serverResp, err := cli.get(ctx, fmt.Sprintf("%s/%s/records", domainsPath, domain), query.ToRawQuery(), nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	records := RecordList{}
+	err = json.NewDecoder(serverResp.body).Decode(&records)
+	return &records, err
+}
+
+func (cli *Client) DescribeRecord(ctx context.Context, domain, rr string) (*RecordList, error) {
+	serverResp, err := cli.get(ctx, fmt.Sprintf("%s/%s/records/%s", domainsPath, domain, rr), nil, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	record := v1.Record{}
+	err = json.NewDecoder(serverResp.body).Decode(&record)
+	records := RecordList{
+		Items: []v1.Record{record},
+	}
+	return &records, err
+}
+
+func (cli *Client) CreateRecord(ctx context.Context, domain string, record *v1.Record) (*RecordList, error) {
+	serverResp, err := cli.post(ctx, fmt.Sprintf("%s/%s/records", domainsPath, domain), nil, record, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	v := v1.Record{}
+	err = json.NewDecoder(serverResp.body).Decode(&v)
+	records := RecordList{
+		Items: []v1.Record{v},
+	}
+	return &records, err
+}
+
+func (cli *Client) DeleteRecord(ctx context.Context, domain, rr string) error {
+	serverResp, err := cli.delete(ctx, fmt.Sprintf("%s/%s/records/%s", domainsPath, domain, rr), nil, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cli *Client) ListTemplates(ctx context.Context, query Queries) (*TemplateList, error) {
+	serverResp, err := cli.get(ctx, templatesPath, query.ToRawQuery(), nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	templates := TemplateList{}
+	err = json.NewDecoder(serverResp.body).Decode(&templates)
+	return &templates, err
+}
+
+func (cli *Client) DescribeTemplate(ctx context.Context, name string) (*TemplateList, error) {
+	serverResp, err := cli.get(ctx, fmt.Sprintf("%s/%s", templatesPath, name), nil, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	template := v1.Template{}
+	err = json.NewDecoder(serverResp.body).Decode(&template)
+	templates := TemplateList{
+		Items: []v1.Template{template},
+	}
+	return &templates, err
+}
+
+func (cli *Client) CreateTemplate(ctx context.Context, template *v1.Template) (*TemplateList, error) {
+	serverResp, err := cli.post(ctx, templatesPath, nil, template, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	v := v1.Template{}
+	err = json.NewDecoder(serverResp.body).Decode(&v)
+	templates := TemplateList{
+		Items: []v1.Template{v},
+	}
+	return &templates, err
+}
+
+func (cli *Client) DeleteTemplate(ctx context.Context, name string) error {
+	serverResp, err := cli.delete(ctx, fmt.Sprintf("%s/%s", templatesPath, name), nil, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cli *Client) ListTokens(ctx context.Context, query Queries) (*TokenList, error) {
+	serverResp, err := cli.get(ctx, tokensPath, query.ToRawQuery(), nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	tokens := TokenList{}
+	err = json.NewDecoder(serverResp.body).Decode(&tokens)
+	return &tokens, err
+}
+
+func (cli *Client) DescribeToken(ctx context.Context, name string) (*TokenList, error) {
+	serverResp, err := cli.get(ctx, fmt.Sprintf("%s/%s", tokensPath, name), nil, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	token := iamv1.Token{}
+	err = json.NewDecoder(serverResp.body).Decode(&token)
+	tokens := TokenList{
+		Items: []iamv1.Token{token},
+	}
+	return &tokens, err
+}
@@ -107,6 +107,15 @@ func (cli *Client) putRaw(ctx context.Context, path string, query url.Values, bo
 	return cli.sendRequest(ctx, "PUT", path, query, body, headers)
 }
 
+// patch sends an http request to the docker API using the method PATCH.
+func (cli *Client) patch(ctx context.Context, path string, query url.Values, obj interface{}, headers map[string][]string) (serverResponse, error) {
+	body, headers, err := encodeBody(obj, headers)
+	if err != nil {
+		return serverResponse{}, err
+	}
+	return cli.sendRequest(ctx, "PATCH", path, query, body, headers)
+}
+
 // delete sends an http request to the docker API using the method DELETE.
 func (cli *Client) delete(ctx context.Context, path string, query url.Values, headers map[string][]string) (serverResponse, error) {
 	return cli.sendRequest(ctx, "DELETE", path, query, nil, headers)
@@ -161,7 +170,7 @@ func (cli *Client) checkResponseErr(serverResp serverResponse) error {
 }
 
 func (cli *Client) buildRequest(method, path string, body io.Reader, h headers) (*http.Request, error) {
-	expectedPayload := method == "POST" || method == "PUT"
+	expectedPayload := method == "POST" || method == "PUT" || method == "PATCH"
 	if expectedPayload && body == nil {
 		body = bytes.NewReader([]byte{})
 	}
@@ -19,7 +19,15 @@
 package kc
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/oplog"
 
 	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
 
@@ -148,6 +156,64 @@ func (n *ClustersList) YAMLPrint() ([]byte, error) {
 	return printer.YAMLPrinter(n)
 }
 
+// StepLog is a piece of the step log on a node.
+type StepLog struct {
+	Content      string `json:"content,omitempty"`
+	Node         string `json:"node,omitempty"`
+	DeliverySize int64  `json:"deliverySize,omitempty"`
+	LogSize      int64  `json:"logSize,omitempty"`
+}
+
+// LogStream is the stream of the step logs of an operation.
+type LogStream struct {
+	body    io.ReadCloser
+	decoder *json.Decoder
+}
+
+// Next returns the next log chunk, io.EOF is returned when the stream ends.
+func (s *LogStream) Next() (*oplog.LogStreamChunk, error) {
+	chunk := &oplog.LogStreamChunk{}
+	if err := s.decoder.Decode(chunk); err != nil {
+		return nil, err
+	}
+	return chunk, nil
+}
+
+func (s *LogStream) Close() error {
+	return s.body.Close()
+}
+
+var _ printer.ResourcePrinter = (*OperationsList)(nil)
+
+type OperationsList struct {
+	Items      []v1.Operation `json:"items" description:"paging data"`
+	TotalCount int            `json:"totalCount,omitempty" description:"total count"`
+}
+
+func (n *OperationsList) JSONPrint() ([]byte, error) {
+	if len(n.Items) == 1 {
+		return printer.JSONPrinter(n.Items[0])
+	}
+	return printer.JSONPrinter(n)
+}
+
+func (n *OperationsList) TablePrint() ([]string, [][]string) {
+	headers := []string{"name", "cluster", "action", "status", "create_timestamp"}
+	var data [][]string
+	for _, op := range n.Items {
+		data = append(data, []string{op.Name, op.Labels[common.LabelClusterName],
+			op.Labels[common.LabelOperationAction], string(op.Status.Status), op.CreationTimestamp.String()})
+	}
+	return headers, data
+}
+
+func (n *OperationsList) YAMLPrint() ([]byte, error) {
+	if len(n.Items) == 1 {
+		return printer.YAMLPrinter(n.Items[0])
+	}
+	return printer.YAMLPrinter(n)
+}
+
 var _ printer.ResourcePrinter = (*RoleList)(nil)
 
 type RoleList struct {
@@ -197,6 +263,36 @@ func (n *RoleList) YAMLPrint() ([]byte, error) {
 	return printer.YAMLPrinter(n)
 }
 
+var _ printer.ResourcePrinter = (*BackupPointList)(nil)
+
+type BackupPointList struct {
+	Items      []v1.BackupPoint `json:"items" description:"paging data"`
+	TotalCount int              `json:"totalCount,omitempty" description:"total count"`
+}
+
+func (n *BackupPointList) JSONPrint() ([]byte, error) {
+	if len(n.Items) == 1 {
+		return printer.JSONPrinter(n.Items[0])
+	}
+	return printer.JSONPrinter(n)
+}
+
+func (n *BackupPointList) YAMLPrint() ([]byte, error) {
+	if len(n.Items) == 1 {
+		return printer.YAMLPrinter(n.Items[0])
+	}
+	return printer.YAMLPrinter(n)
+}
+
+func (n *BackupPointList) TablePrint() ([]string, [][]string) {
+	headers := []string{"name", "storage_type", "create_timestamp"}
+	var data [][]string
+	for _, bp := range n.Items {
+		data = append(data, []string{bp.Name, bp.StorageType, bp.CreationTimestamp.String()})
+	}
+	return headers, data
+}
+
 type ComponentMetas struct {
 	Node                   string `json:"-"`
 	scheme.PackageMetadata `json:"items" description:"paging data"`
@@ -230,3 +326,372 @@ func (n *ComponentMetas) TablePrint() ([]string, [][]string) {
 type ComponentMeta struct {
 	Items []scheme.MetaResource `json:"items"`
 }
+
+// PatchNodes is the request to add nodes to or remove nodes from a cluster.
+type PatchNodes struct {
+	Operation string            `json:"operation"`
+	Nodes     v1.WorkerNodeList `json:"nodes"`
+	Role      common.NodeRole   `json:"role"`
+}
+
+const (
+	NodesOperationAdd    = "add"
+	NodesOperationRemove = "remove"
+)
+
+// ClusterUpgrade is the request to upgrade the kubernetes version of a cluster.
+type ClusterUpgrade struct {
+	Version       string `json:"version"`
+	Offline       bool   `json:"offline"`
+	LocalRegistry string `json:"localRegistry"`
+}
+
+// PatchComponents is the request to install or uninstall addons of a cluster.
+type PatchComponents struct {
+	Uninstall bool       `json:"uninstall"`
+	Addons    []v1.Addon `json:"addons"`
+}
+
+// ClusterChange is one change of a cluster plan, exactly one of Nodes, Components and Upgrade is
+// set and Operation is the operation the server runs for it. Reconfigure marks the uninstall and
+// the install of a reconfigured addon.
+type ClusterChange struct {
+	Action      string           `json:"action"`
+	Nodes       *PatchNodes      `json:"nodes,omitempty"`
+	Components  *PatchComponents `json:"components,omitempty"`
+	Upgrade     *ClusterUpgrade  `json:"upgrade,omitempty"`
+	Reconfigure bool             `json:"reconfigure,omitempty"`
+	Operation   *v1.Operation    `json:"operation,omitempty"`
+}
+
+var _ printer.ResourcePrinter = (*ClusterPlan)(nil)
+
+// ClusterPlan is the ordered list of changes which bring a cluster to the desired one.
+type ClusterPlan struct {
+	Changes []ClusterChange `json:"changes"`
+}
+
+func (n *ClusterPlan) JSONPrint() ([]byte, error) {
+	return printer.JSONPrinter(n)
+}
+
+func (n *ClusterPlan) YAMLPrint() ([]byte, error) {
+	return printer.YAMLPrinter(n)
+}
+
+func (n *ClusterPlan) TablePrint() ([]string, [][]string) {
+	headers := []string{"action", "change", "steps"}
+	var data [][]string
+	for _, c := range n.Changes {
+		var steps []string
+		if c.Operation != nil {
+			for _, s := range c.Operation.Steps {
+				steps = append(steps, s.Name)
+			}
+		}
+		change := clusterChangeString(c)
+		if c.Reconfigure {
+			change += " (reconfigure, disruptive)"
+		}
+		data = append(data, []string{c.Action, change, strings.Join(steps, ",")})
+	}
+	return headers, data
+}
+
+func clusterChangeString(c ClusterChange) string {
+	switch {
+	case c.Nodes != nil:
+		return strings.Join(c.Nodes.Nodes.GetNodeIDs(), ",")
+	case c.Components != nil:
+		var addons []string
+		for _, a := range c.Components.Addons {
+			addons = append(addons, fmt.Sprintf("%s-%s", a.Name, a.Version))
+		}
+		return strings.Join(addons, ",")
+	case c.Upgrade != nil:
+		return c.Upgrade.Version
+	}
+	return ""
+}
+
+var _ printer.ResourcePrinter = (*BackupList)(nil)
+
+type BackupList struct {
+	Items      []v1.Backup `json:"items" description:"paging data"`
+	TotalCount int         `json:"totalCount,omitempty" description:"total count"`
+}
+
+func (n *BackupList) JSONPrint() ([]byte, error) {
+	if len(n.Items) == 1 {
+		return printer.JSONPrinter(n.Items[0])
+	}
+	return printer.JSONPrinter(n)
+}
+
+func (n *BackupList) YAMLPrint() ([]byte, error) {
+	if len(n.Items) == 1 {
+		return printer.YAMLPrinter(n.Items[0])
+	}
+	return printer.YAMLPrinter(n)
+}
+
+func (n *BackupList) TablePrint() ([]string, [][]string) {
+	headers := []string{"name", "cluster", "backup_point", "status", "size", "kubernetes_version", "create_timestamp"}
+	var data [][]string
+	for _, b := range n.Items {
+		data = append(data, []string{b.Name, b.Labels[common.LabelClusterName], b.BackupPointName,
+			string(b.Status.ClusterBackupStatus), strconv.FormatInt(b.Status.BackupFileSize, 10),
+			b.Status.KubernetesVersion, b.CreationTimestamp.String()})
+	}
+	return headers, data
+}
+
+var _ printer.ResourcePrinter = (*RecoveryList)(nil)
+
+type RecoveryList struct {
+	Items      []v1.Recovery `json:"items" description:"paging data"`
+	TotalCount int           `json:"totalCount,omitempty" description:"total count"`
+}
+
+func (n *RecoveryList) JSONPrint() ([]byte, error) {
+	if len(n.Items) == 1 {
+		return printer.JSONPrinter(n.Items[0])
+	}
+	return printer.JSONPrinter(n)
+}
+
+func (n *RecoveryList) YAMLPrint() ([]byte, error) {
+	if len(n.Items) == 1 {
+		return printer.YAMLPrinter(n.Items[0])
+	}
+	return printer.YAMLPrinter(n)
+}
+
+func (n *RecoveryList) TablePrint() ([]string, [][]string) {
+	headers := []string{"name", "cluster", "backup", "create_timestamp"}
+	var data [][]string
+	for _, r := range n.Items {
+		data = append(data, []string{r.Name, r.Labels[common.LabelClusterName], r.UseBackupName, r.CreationTimestamp.String()})
+	}
+	return headers, data
+}
+
+var _ printer.ResourcePrinter = (*CronBackupList)(nil)
+
+type CronBackupList struct {
+	Items      []v1.CronBackup `json:"items" description:"paging data"`
+	TotalCount int             `json:"totalCount,omitempty" description:"total count"`
+}
+
+func (n *CronBackupList) JSONPrint() ([]byte, error) {
+	if len(n.Items) == 1 {
+		return printer.JSONPrinter(n.Items[0])
+	}
+	return printer.JSONPrinter(n)
+}
+
+func (n *CronBackupList) YAMLPrint() ([]byte, error) {
+	if len(n.Items) == 1 {
+		return printer.YAMLPrinter(n.Items[0])
+	}
+	return printer.YAMLPrinter(n)
+}
+
+func (n *CronBackupList) TablePrint() ([]string, [][]string) {
+	headers := []string{"name", "cluster", "schedule", "enabled", "last_schedule_time", "next_schedule_time"}
+	var data [][]string
+	for _, cb := range n.Items {
+		_, disabled := cb.Labels[common.LabelCronBackupDisable]
+		data = append(data, []string{cb.Name, cb.Spec.ClusterName, cronSchedule(&cb.Spec),
+			strconv.FormatBool(!disabled), timeString(cb.Status.LastScheduleTime), timeString(cb.Status.NextScheduleTime)})
+	}
+	return headers, data
+}
+
+// cronSchedule returns the schedule of the cron backup, the one-time backup has no schedule but run at.
+func cronSchedule(spec *v1.CronBackupSpec) string {
+	if spec.Schedule == "" && spec.RunAt != nil {
+		return "at " + spec.RunAt.String()
+	}
+	return spec.Schedule
+}
+
+func timeString(t *metav1.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.String()
+}
+
+var _ printer.ResourcePrinter = (*RegionList)(nil)
+
+type RegionList struct {
+	Items      []v1.Region `json:"items" description:"paging data"`
+	TotalCount int         `json:"totalCount,omitempty" description:"total count"`
+}
+
+func (n *RegionList) JSONPrint() ([]byte, error) {
+	if len(n.Items) == 1 {
+		return printer.JSONPrinter(n.Items[0])
+	}
+	return printer.JSONPrinter(n)
+}
+
+func (n *RegionList) YAMLPrint() ([]byte, error) {
+	if len(n.Items) == 1 {
+		return printer.YAMLPrinter(n.Items[0])
+	}
+	return printer.YAMLPrinter(n)
+}
+
+func (n *RegionList) TablePrint() ([]string, [][]string) {
+	headers := []string{"name", "create_timestamp"}
+	var data [][]string
+	for _, region := range n.Items {
+		data = append(data, []string{region.Name, region.CreationTimestamp.String()})
+	}
+	return headers, data
+}
+
+var _ printer.ResourcePrinter = (*DomainList)(nil)
+
+type DomainList struct {
+	Items      []v1.Domain `json:"items" description:"paging data"`
+	TotalCount int         `json:"totalCount,omitempty" description:"total count"`
+}
+
+func (n *DomainList) JSONPrint() ([]byte, error) {
+	if len(n.Items) == 1 {
+		return printer.JSONPrinter(n.Items[0])
+	}
+	return printer.JSONPrinter(n)
+}
+
+func (n *DomainList) YAMLPrint() ([]byte, error) {
+	if len(n.Items) == 1 {
+		return printer.YAMLPrinter(n.Items[0])
+	}
+	return printer.YAMLPrinter(n)
+}
+
+func (n *DomainList) TablePrint() ([]string, [][]string) {
+	headers := []string{"name", "records", "sync_cluster", "description", "create_timestamp"}
+	var data [][]string
+	for _, domain := range n.Items {
+		data = append(data, []string{domain.Name, strconv.Itoa(len(domain.Spec.Records)),
+			strings.Join(domain.Spec.SyncCluster, ","), domain.Spec.Description, domain.CreationTimestamp.String()})
+	}
+	return headers, data
+}
+
+var _ printer.ResourcePrinter = (*RecordList)(nil)
+
+type RecordList struct {
+	Items      []v1.Record `json:"items" description:"paging data"`
+	TotalCount int         `json:"totalCount,omitempty" description:"total count"`
+}
+
+func (n *RecordList) JSONPrint() ([]byte, error) {
+	if len(n.Items) == 1 {
+		return printer.JSONPrinter(n.Items[0])
+	}
+	return printer.JSONPrinter(n)
+}
+
+func (n *RecordList) YAMLPrint() ([]byte, error) {
+	if len(n.Items) == 1 {
+		return printer.YAMLPrinter(n.Items[0])
+	}
+	return printer.YAMLPrinter(n)
+}
+
+func (n *RecordList) TablePrint() ([]string, [][]string) {
+	headers := []string{"rr", "domain", "type", "value", "ttl", "create_timestamp"}
+	var data [][]string
+	for _, record := range n.Items {
+		for _, pr := range record.ParseRecord {
+			data = append(data, []string{record.RR, record.Domain, pr.RecordType(), recordValue(pr),
+				strconv.Itoa(int(record.TTLSeconds())), record.CreateTime.String()})
+		}
+	}
+	return headers, data
+}
+
+func recordValue(pr v1.ParseRecord) string {
+	switch pr.RecordType() {
+	case v1.RecordTypeCNAME:
+		return pr.Target
+	case v1.RecordTypeSRV:
+		return fmt.Sprintf("%d %d %d %s", pr.Priority, pr.Weight, pr.Port, pr.Target)
+	case v1.RecordTypeTXT:
+		return pr.Text
+	default:
+		return pr.IP
+	}
+}
+
+var _ printer.ResourcePrinter = (*TemplateList)(nil)
+
+type TemplateList struct {
+	Items      []v1.Template `json:"items" description:"paging data"`
+	TotalCount int           `json:"totalCount,omitempty" description:"total count"`
+}
+
+func (n *TemplateList) JSONPrint() ([]byte, error) {
+	if len(n.Items) == 1 {
+		return printer.JSONPrinter(n.Items[0])
+	}
+	return printer.JSONPrinter(n)
+}
+
+func (n *TemplateList) YAMLPrint() ([]byte, error) {
+	if len(n.Items) == 1 {
+		return printer.YAMLPrinter(n.Items[0])
+	}
+	return printer.YAMLPrinter(n)
+}
+
+func (n *TemplateList) TablePrint() ([]string, [][]string) {
+	headers := []string{"name", "category", "component", "version", "create_timestamp"}
+	var data [][]string
+	for _, t := range n.Items {
+		data = append(data, []string{t.Name, t.Labels[common.LabelCategory], t.Labels[common.LabelComponentName],
+			t.Labels[common.LabelComponentVersion], t.CreationTimestamp.String()})
+	}
+	return headers, data
+}
+
+var _ printer.ResourcePrinter = (*TokenList)(nil)
+
+type TokenList struct {
+	Items      []iamv1.Token `json:"items" description:"paging data"`
+	TotalCount int           `json:"totalCount,omitempty" description:"total count"`
+}
+
+func (n *TokenList) JSONPrint() ([]byte, error) {
+	if len(n.Items) == 1 {
+		return printer.JSONPrinter(n.Items[0])
+	}
+	return printer.JSONPrinter(n)
+}
+
+func (n *TokenList) YAMLPrint() ([]byte, error) {
+	if len(n.Items) == 1 {
+		return printer.YAMLPrinter(n.Items[0])
+	}
+	return printer.YAMLPrinter(n)
+}
+
+func (n *TokenList) TablePrint() ([]string, [][]string) {
+	headers := []string{"name", "username", "type", "ttl", "create_timestamp"}
+	var data [][]string
+	for _, token := range n.Items {
+		ttl := ""
+		if token.Spec.TTL != nil {
+			ttl = strconv.FormatInt(*token.Spec.TTL, 10)
+		}
+		data = append(data, []string{token.Name, token.Spec.Username, string(token.Spec.TokenType), ttl,
+			token.CreationTimestamp.String()})
+	}
+	return headers, data
+}
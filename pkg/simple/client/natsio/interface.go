@@ -48,6 +48,7 @@ type Interface interface {
 	InitConn(stopCh <-chan struct{}) error
 	Publish(msg *Msg) error
 	Subscribe(subj string, handler nats.MsgHandler) error
+	SubscribeWithContext(ctx context.Context, subj string, handler nats.MsgHandler) error
 	QueueSubscribe(subj string, queue string, handler nats.MsgHandler) error
 	Request(msg *Msg, timeoutHandler TimeoutHandler) ([]byte, error)
 	RequestWithContext(ctx context.Context, msg *Msg) ([]byte, error)
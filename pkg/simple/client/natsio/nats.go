@@ -23,6 +23,7 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/google/uuid"
@@ -132,7 +133,8 @@ func (c *Client) setupServerOptions(opts *NatsOptions) {
 		// when use message queue cluster client can set multiple server addresses split by command
 		// e.g. 10.0.0.100:2222,10.0.0.101:2222,10.0.0.102:2222
 		// won`t abort program even the ip:port not open yet
-		Routes:    natServer.RoutesFromStr(fmt.Sprintf("nats://%s", opts.Server.Cluster.LeaderHost)),
+		// routes lists the other masters as well, so the cluster survives losing the leader
+		Routes:    natServer.RoutesFromStr(clusterRoutes(opts.Server.Cluster)),
 		TLSCaCert: opts.Server.TLSCaPath,
 		TLSCert:   opts.Server.TLSCertPath,
 		TLSKey:    opts.Server.TLSKeyPath,
@@ -144,6 +146,19 @@ func (c *Client) setupServerOptions(opts *NatsOptions) {
 	}
 }
 
+// clusterRoutes joins the leader host and the extra routes into the
+// comma separated url list expected by RoutesFromStr.
+func clusterRoutes(opts ClusterOptions) string {
+	routes := []string{fmt.Sprintf("nats://%s", opts.LeaderHost)}
+	for _, route := range opts.Routes {
+		if route == "" || route == opts.LeaderHost {
+			continue
+		}
+		routes = append(routes, fmt.Sprintf("nats://%s", route))
+	}
+	return strings.Join(routes, ",")
+}
+
 func (c *Client) RunServer(stopCh <-chan struct{}) error {
 	c.serverRunningMux.Lock()
 	defer c.serverRunningMux.Unlock()
@@ -186,6 +201,20 @@ func (c *Client) Subscribe(subj string, handler nats.MsgHandler) error {
 	return err
 }
 
+// SubscribeWithContext subscribes the subject until ctx is done.
+func (c *Client) SubscribeWithContext(ctx context.Context, subj string, handler nats.MsgHandler) error {
+	sub, err := c.conn.Subscribe(subj, handler)
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		// it fails only when the connection is closed, the subscription is gone then.
+		_ = sub.Unsubscribe()
+	}()
+	return nil
+}
+
 func (c *Client) QueueSubscribe(subj string, queue string, handler nats.MsgHandler) error {
 	_, err := c.conn.QueueSubscribe(subj, queue, handler)
 	return err
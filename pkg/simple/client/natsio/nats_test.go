@@ -0,0 +1,43 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package natsio
+
+import "testing"
+
+func TestClusterRoutes(t *testing.T) {
+	tests := []struct {
+		name string
+		opts ClusterOptions
+		want string
+	}{
+		{"leader only", ClusterOptions{LeaderHost: "10.0.0.1:9890"}, "nats://10.0.0.1:9890"},
+		{
+			"leader in routes",
+			ClusterOptions{LeaderHost: "10.0.0.1:9890", Routes: []string{"10.0.0.1:9890", "10.0.0.2:9890", ""}},
+			"nats://10.0.0.1:9890,nats://10.0.0.2:9890",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := clusterRoutes(tt.opts); got != tt.want {
+				t.Errorf("clusterRoutes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
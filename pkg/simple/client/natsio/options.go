@@ -67,9 +67,10 @@ type ServerOptions struct {
 }
 
 type ClusterOptions struct {
-	Host       string `yaml:"host" json:"host"`
-	Port       int    `yaml:"port" json:"port"`
-	LeaderHost string `yaml:"leaderHost" json:"leader_host"`
+	Host       string   `yaml:"host" json:"host"`
+	Port       int      `yaml:"port" json:"port"`
+	LeaderHost string   `yaml:"leaderHost" json:"leader_host"`
+	Routes     []string `yaml:"routes" json:"routes"`
 }
 
 type AuthOptions struct {
@@ -145,6 +146,8 @@ func (s *NatsOptions) AddFlags(fs *pflag.FlagSet) {
 		"mq cluster host addr port, only used in mq server")
 	fs.StringVar(&s.Server.Cluster.LeaderHost, "mq-cluster-leader", s.Server.Cluster.LeaderHost, ""+
 		"mq cluster leader addr, format at ip:port. only used in mq server")
+	fs.StringSliceVar(&s.Server.Cluster.Routes, "mq-cluster-routes", s.Server.Cluster.Routes, ""+
+		"mq cluster peer addrs besides the leader, format at ip:port. only used in mq server")
 	fs.StringVar(&s.Server.TLSCaPath, "mq-server-ca-cert", s.Server.TLSCaPath,
 		"message queue ca cert file path")
 	fs.StringVar(&s.Server.TLSCertPath, "mq-server-cert", s.Server.TLSCertPath,
@@ -163,6 +166,13 @@ func (s *NatsOptions) Validate() []error {
 			err = append(err, fmt.Errorf("leader host %s must be ip:port", s.Server.Cluster.LeaderHost))
 		}
 	}
+	if !s.External {
+		for _, route := range s.Server.Cluster.Routes {
+			if parts := strings.Split(route, ":"); len(parts) != 2 {
+				err = append(err, fmt.Errorf("cluster route %s must be ip:port", route))
+			}
+		}
+	}
 	if len(s.Client.ServerAddress) == 0 {
 		err = append(err, fmt.Errorf("at least have one server address"))
 	}
@@ -23,7 +23,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os"
+	"io"
 	"os/exec"
 	"time"
 
@@ -142,7 +142,7 @@ func CmdPipeline(cmds ...*exec.Cmd) (pipeLineOutput, collectedStandardError []by
 
 // CheckContextAndGetStepLogFile checks whether the context contains instance data.
 // Caution: when check is true, you should close the file descriptor.
-func CheckContextAndGetStepLogFile(ctx context.Context) (*os.File, bool, error) {
+func CheckContextAndGetStepLogFile(ctx context.Context) (io.WriteCloser, bool, error) {
 	opID := component.GetOperationID(ctx)
 	stepID := component.GetStepID(ctx)
 	opLog := component.GetOplog(ctx)
@@ -0,0 +1,52 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package sshutils
+
+import (
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// SftpClient is the sftp client of a host, the ssh connection is closed with it.
+type SftpClient struct {
+	*sftp.Client
+	conn *ssh.Client
+}
+
+// NewSftpClient connects to the sftp subsystem of the host.
+func (ss *SSH) NewSftpClient(host string) (*SftpClient, error) {
+	conn, err := ss.connect(host)
+	if err != nil {
+		return nil, err
+	}
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return &SftpClient{Client: client, conn: conn}, nil
+}
+
+func (c *SftpClient) Close() error {
+	err := c.Client.Close()
+	if cErr := c.conn.Close(); err == nil {
+		err = cErr
+	}
+	return err
+}
@@ -40,6 +40,8 @@ type SSH struct {
 	PkFile            string         `json:"pkFile" yaml:"pkFile,omitempty"`
 	PkPassword        string         `json:"pkPassword" yaml:"pkPassword,omitempty"`
 	ConnectionTimeout *time.Duration `json:"connectionTimeout,omitempty" yaml:"connectionTimeout,omitempty"`
+	// content of the private key, it is used before the private key file
+	PrivateKey string `json:"privateKey,omitempty" yaml:"privateKey,omitempty"`
 }
 
 func (ss *SSH) Connect(host string) (*ssh.Session, error) {
@@ -100,6 +102,12 @@ func (ss *SSH) addrReformat(host string) string {
 }
 
 func (ss *SSH) sshAuthMethod(passwd, pkFile, pkPasswd string) (auth []ssh.AuthMethod) {
+	if ss.PrivateKey != "" {
+		am, err := ss.sshPrivateKeyDataMethod([]byte(ss.PrivateKey), pkPasswd)
+		if err == nil {
+			auth = append(auth, am)
+		}
+	}
 	if fileExist(pkFile) {
 		am, err := ss.sshPrivateKeyMethod(pkFile, pkPasswd)
 		if err == nil {
@@ -114,7 +122,10 @@ func (ss *SSH) sshAuthMethod(passwd, pkFile, pkPasswd string) (auth []ssh.AuthMe
 }
 
 func (ss *SSH) sshPrivateKeyMethod(pkFile, pkPassword string) (am ssh.AuthMethod, err error) {
-	pkData := ss.readFile(pkFile)
+	return ss.sshPrivateKeyDataMethod(ss.readFile(pkFile), pkPassword)
+}
+
+func (ss *SSH) sshPrivateKeyDataMethod(pkData []byte, pkPassword string) (am ssh.AuthMethod, err error) {
 	var pk ssh.Signer
 	if pkPassword == "" {
 		pk, err = ssh.ParsePrivateKey(pkData)